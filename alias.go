@@ -0,0 +1,71 @@
+package eventbus
+
+import (
+	"fmt"
+)
+
+// Alias runs Alias on package-level bus singleton
+func Alias(oldTopic, newTopic string) error {
+	return b.Load().Alias(oldTopic, newTopic)
+}
+
+// Alias renames oldTopic to newTopic: subscribing, publishing and
+// unsubscribing with the old name work against the new one, logging a
+// deprecation warning the first time the old name is used. The handlers
+// subscribed to the old name move to the new one.
+// Returns error if newTopic is an alias of oldTopic.
+func (bus *Bus) Alias(oldTopic, newTopic string) error {
+	names := &bus.topicNames
+	names.Lock()
+	if canonical, ok := names.aliases[newTopic]; ok {
+		newTopic = canonical
+	}
+	if oldTopic == newTopic {
+		names.Unlock()
+		return fmt.Errorf("topic %s can't be an alias of itself", oldTopic)
+	}
+	if names.aliases == nil {
+		names.aliases = make(map[string]string)
+	}
+	for legacy, canonical := range names.aliases {
+		if canonical == oldTopic {
+			names.aliases[legacy] = newTopic
+		}
+	}
+	names.aliases[oldTopic] = newTopic
+	names.Unlock()
+
+	// names is released first: subscribes take it while holding the bus lock.
+	// Those resolving oldTopic before the alias existed are moved below.
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	if handlers := bus.handlersOf(oldTopic); len(handlers) > 0 {
+		bus.setHandlers(newTopic, append(bus.handlersOf(newTopic), handlers...))
+	}
+	bus.setHandlers(oldTopic, nil)
+	return nil
+}
+
+// canonicalTopic returns the canonical name of a topic, resolving aliases
+func (bus *Bus) canonicalTopic(topic string) string {
+	names := &bus.topicNames
+	names.RLock()
+	canonical, ok := names.aliases[topic]
+	warned := names.warned[topic]
+	names.RUnlock()
+	if !ok {
+		return topic
+	}
+	if !warned {
+		names.Lock()
+		if !names.warned[topic] {
+			if names.warned == nil {
+				names.warned = make(map[string]bool)
+			}
+			names.warned[topic] = true
+			bus.warn(fmt.Sprintf("topic %s is deprecated, use %s", topic, canonical), "topic", topic, "canonical", canonical)
+		}
+		names.Unlock()
+	}
+	return canonical
+}
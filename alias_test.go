@@ -0,0 +1,91 @@
+package eventbus
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAlias(t *testing.T) {
+	var output bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&output)
+
+	bus := New()
+	calls := make([]string, 0)
+	legacy := func(s string) { calls = append(calls, "legacy:"+s) }
+	current := func(s string) { calls = append(calls, "current:"+s) }
+	bus.Subscribe("user_created", legacy)
+	bus.Subscribe("user.created", current)
+
+	if bus.Alias("user_created", "user.created") != nil {
+		t.Fail()
+	}
+	if bus.Alias("user.created", "user_created") == nil {
+		t.Fail() // would alias the topic to itself
+	}
+	bus.Publish("user_created", "a")
+	bus.Publish("user.created", "b")
+	if len(calls) != 4 || calls[0] != "current:a" || calls[1] != "legacy:a" || calls[3] != "legacy:b" {
+		t.Error(calls)
+	}
+	if !bus.HasCallback("user_created") {
+		t.Fail()
+	}
+	if bus.Unsubscribe("user_created", legacy) != nil {
+		t.Fail()
+	}
+
+	// chains resolve to the latest name
+	if bus.Alias("user.created", "users.created") != nil {
+		t.Fail()
+	}
+	if bus.canonicalTopic("user_created") != "users.created" || !bus.HasCallback("users.created") {
+		t.Fail()
+	}
+	if strings.Count(output.String(), "topic user_created is deprecated, use user.created") != 1 {
+		t.Error(output.String())
+	}
+}
+
+func TestAliasConcurrentSubscribe(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard) // deprecation warnings
+	bus := New()
+	wg := sync.WaitGroup{}
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				bus.Subscribe(fmt.Sprintf("old:%d", i), func() {})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				bus.Alias(fmt.Sprintf("old:%d", i), fmt.Sprintf("new:%d", i))
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Subscribe and Alias deadlocked")
+	}
+	for i := 0; i < 500; i++ {
+		if !bus.HasCallback(fmt.Sprintf("new:%d", i)) {
+			t.Fatal("handler left under the old name", i)
+		}
+	}
+}
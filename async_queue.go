@@ -0,0 +1,214 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy - what a publish does with a new async delivery once the
+// async queue of its topic is full, see Config.BufferSize
+type OverflowPolicy int
+
+const (
+	// OverflowInherit - use the policy of the enclosing configuration level,
+	// OverflowBlock if none is set
+	OverflowInherit OverflowPolicy = iota
+	// OverflowBlock - the publisher waits for room in the queue, or until its
+	// context is done
+	OverflowBlock
+	// OverflowDropOldest - queued deliveries that didn't start yet are
+	// dropped, oldest first, to make room; the new one if they all started
+	OverflowDropOldest
+	// OverflowDropNewest - the new delivery is dropped
+	OverflowDropNewest
+	// OverflowError - the new delivery is dropped and the publish returns
+	// ErrQueueFull
+	OverflowError
+)
+
+func (policy OverflowPolicy) String() string {
+	switch policy {
+	case OverflowInherit:
+		return "inherit"
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowDropNewest:
+		return "drop-newest"
+	case OverflowError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// ErrQueueFull - error returned by a publish when the async queue of the
+// topic is full and its overflow policy is OverflowError
+var ErrQueueFull = errors.New("async queue full")
+
+// errDropped - an async delivery was dropped by the overflow policy
+var errDropped = errors.New("async delivery dropped")
+
+// QueueMetrics - optionally implemented by the Metrics given to WithMetrics
+// to count the async deliveries dropped by full async queues
+type QueueMetrics interface {
+	Dropped(topic string)
+}
+
+// QueueStats - state of the async queue of a topic
+type QueueStats struct {
+	Depth    int    // async deliveries queued or running
+	Capacity int    // zero if unbounded
+	Dropped  uint64 // deliveries dropped or refused by the overflow policy
+}
+
+// asyncQueues - async deliveries of the topics with a bounded queue
+type asyncQueues struct {
+	topics map[string]*asyncQueue
+	sync.Mutex
+}
+
+type asyncQueue struct {
+	depth   int
+	dropped uint64
+	pending []*queuedDelivery // queued deliveries that didn't start, oldest first
+	room    chan struct{}     // closed when a delivery leaves the queue
+}
+
+// QueueStats returns the state of the async queue of a topic
+func (bus *Bus) QueueStats(topic string) QueueStats {
+	stats := QueueStats{Capacity: bus.Config(topic).BufferSize}
+	queues := &bus.queues
+	queues.Lock()
+	defer queues.Unlock()
+	if q, ok := queues.topics[topic]; ok {
+		stats.Depth, stats.Dropped = q.depth, q.dropped
+	}
+	return stats
+}
+
+// admitAsync takes a place in the async queue of topic for a new delivery,
+// applying the overflow policy of the topic if the queue is full. Returns nil
+// if the queue is unbounded, errDropped if the delivery must be dropped.
+// The bus lock and shard must be held shared; they are released while
+// OverflowBlock waits, which sets released.
+func (bus *Bus) admitAsync(ctx context.Context, topic string, shard *topicShard, released *bool) (*queuedDelivery, error) {
+	config := bus.Config(topic)
+	if config.BufferSize <= 0 {
+		return nil, nil
+	}
+	queues := &bus.queues
+	queues.Lock()
+	defer queues.Unlock()
+	if queues.topics == nil {
+		queues.topics = make(map[string]*asyncQueue)
+	}
+	q, ok := queues.topics[topic]
+	if !ok {
+		q = &asyncQueue{room: make(chan struct{})}
+		queues.topics[topic] = q
+	}
+	for q.depth >= config.BufferSize {
+		switch config.Overflow {
+		case OverflowDropOldest:
+			if q.dropOldest() {
+				bus.dropped(topic)
+				continue
+			}
+			fallthrough
+		case OverflowDropNewest:
+			q.dropped++
+			bus.dropped(topic)
+			return nil, errDropped
+		case OverflowError:
+			q.dropped++
+			bus.dropped(topic)
+			return nil, ErrQueueFull
+		default:
+			room := q.room
+			queues.Unlock()
+			bus.unlocked(shard, func() {
+				select {
+				case <-room:
+				case <-ctx.Done():
+				}
+			})
+			*released = true
+			queues.Lock()
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+		}
+	}
+	delivery := &queuedDelivery{}
+	q.depth++
+	q.pending = append(q.pending, delivery)
+	return delivery, nil
+}
+
+// dropOldest drops the oldest queued delivery that didn't start, returning
+// false if there is none
+func (q *asyncQueue) dropOldest() bool {
+	for len(q.pending) > 0 {
+		oldest := q.pending[0]
+		q.pending = q.pending[1:]
+		if atomic.CompareAndSwapInt32(&oldest.state, queuedPending, queuedDropped) {
+			q.dropped++
+			q.leave()
+			return true
+		}
+	}
+	return false
+}
+
+// leave frees the place of a delivery and wakes up the blocked publishers
+func (q *asyncQueue) leave() {
+	q.depth--
+	close(q.room)
+	q.room = make(chan struct{})
+}
+
+// start reports whether a queued delivery may run, false if it was dropped
+func (queues *asyncQueues) start(topic string, delivery *queuedDelivery) bool {
+	if delivery == nil {
+		return true
+	}
+	if !atomic.CompareAndSwapInt32(&delivery.state, queuedPending, queuedStarted) {
+		return false
+	}
+	queues.Lock()
+	defer queues.Unlock()
+	if q, ok := queues.topics[topic]; ok {
+		if idx := indexOf(q.pending, delivery); idx >= 0 {
+			q.pending = append(q.pending[:idx], q.pending[idx+1:]...)
+		}
+	}
+	return true
+}
+
+// done frees the place of a delivery that ran or won't run, unless the
+// overflow policy dropped it
+func (queues *asyncQueues) done(topic string, delivery *queuedDelivery) {
+	if delivery == nil || atomic.SwapInt32(&delivery.state, queuedDropped) == queuedDropped {
+		return
+	}
+	queues.Lock()
+	defer queues.Unlock()
+	if q, ok := queues.topics[topic]; ok {
+		if idx := indexOf(q.pending, delivery); idx >= 0 {
+			q.pending = append(q.pending[:idx], q.pending[idx+1:]...)
+		}
+		q.leave()
+	}
+}
+
+// dropped reports an async delivery dropped by a full queue
+func (bus *Bus) dropped(topic string) {
+	bus.log(slog.LevelWarn, "async queue full, delivery dropped", "topic", topic)
+	if metrics, ok := bus.metrics.(QueueMetrics); ok {
+		metrics.Dropped(topic)
+	}
+}
@@ -0,0 +1,82 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAsyncQueueOverflow(t *testing.T) {
+	for _, test := range []struct {
+		policy    OverflowPolicy
+		err       error
+		delivered []int
+	}{
+		{OverflowDropNewest, nil, []int{1, 2}},
+		{OverflowDropOldest, nil, []int{1, 3}},
+		{OverflowError, ErrQueueFull, []int{1, 2}},
+	} {
+		bus := New()
+		bus.SetTopicConfig("topic", Config{BufferSize: 2, Overflow: test.policy})
+		lock := sync.Mutex{}
+		var delivered []int
+		release := make(chan struct{})
+		bus.SubscribeAsync("topic", func(n int) {
+			<-release
+			lock.Lock()
+			defer lock.Unlock()
+			delivered = append(delivered, n)
+		}, true)
+
+		bus.Publish("topic", 1)
+		time.Sleep(5 * time.Millisecond) // 1 started, 2 waits behind it
+		bus.Publish("topic", 2)
+		if err := bus.Publish("topic", 3); err != test.err {
+			t.Fatal(test.policy, err)
+		}
+		if stats := bus.QueueStats("topic"); stats.Depth != 2 || stats.Capacity != 2 || stats.Dropped != 1 {
+			t.Fatal(test.policy, stats)
+		}
+		close(release)
+		bus.WaitAsync()
+		if len(delivered) != 2 || delivered[0] != test.delivered[0] || delivered[1] != test.delivered[1] {
+			t.Fatal(test.policy, delivered)
+		}
+		if stats := bus.QueueStats("topic"); stats.Depth != 0 {
+			t.Fatal(test.policy, stats)
+		}
+	}
+}
+
+func TestAsyncQueueBlock(t *testing.T) {
+	bus := New()
+	bus.SetDefaults(Config{BufferSize: 1})
+	release := make(chan struct{})
+	bus.SubscribeAsync("topic", func() { <-release }, false)
+	bus.Subscribe("other", func() {})
+
+	bus.Publish("topic")
+	published := make(chan struct{})
+	go func() {
+		bus.Publish("topic")
+		close(published)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	select {
+	case <-published:
+		t.Fatal("publish didn't block")
+	default:
+	}
+	bus.Publish("other") // the blocked publisher doesn't hold the bus
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := bus.PublishCtx(ctx, "topic"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	close(release)
+	<-published
+	bus.WaitAsync()
+}
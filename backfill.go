@@ -0,0 +1,151 @@
+package eventbus
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"time"
+)
+
+// TopicBackfill - control topic receiving BackfillProgress events
+const TopicBackfill = "bus:backfill"
+
+// defaultProgressInterval - events between progress reports of an unlimited backfill
+const defaultProgressInterval = 1000
+
+// BackfillProgress - progress of a backfill, published on TopicBackfill
+// roughly once per second of backfill and when it ends
+type BackfillProgress struct {
+	Topic     string
+	Published int
+	Done      bool
+	Err       error // context error if the backfill was cancelled, or the error of the publish that stopped it
+}
+
+// BackfillJob - handle of a running backfill
+type BackfillJob struct {
+	topic     string
+	published int
+	paused    bool
+	resumed   chan struct{}
+	done      chan struct{}
+	err       error
+	sync.Mutex
+}
+
+// Backfill publishes every element of src onto a topic in the background, at
+// most rate events per second (unlimited if rate <= 0 or over a billion),
+// until src is exhausted, ctx is done or a publish fails, paced by
+// WithReplayThrottle. Progress is reported on TopicBackfill.
+func (bus *Bus) Backfill(ctx context.Context, topic string, src iter.Seq[[]interface{}], rate int) *BackfillJob {
+	job := &BackfillJob{topic: topic, done: make(chan struct{})}
+	go job.run(ctx, bus, src, rate)
+	return job
+}
+
+func (job *BackfillJob) run(ctx context.Context, bus *Bus, src iter.Seq[[]interface{}], rate int) {
+	defer close(job.done)
+	var tick <-chan time.Time
+	progressEvery := defaultProgressInterval
+	if rate > 0 {
+		progressEvery = rate
+	}
+	if rate > 0 && rate <= int(time.Second) {
+		ticker := time.NewTicker(time.Second / time.Duration(rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for args := range src {
+		err := job.waitResumed(ctx)
+		if err == nil {
+			err = bus.throttle.wait(ctx)
+		}
+		if err != nil {
+			job.finish(bus, err)
+			return
+		}
+		if tick != nil {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				job.finish(bus, ctx.Err())
+				return
+			}
+		}
+		if err := bus.Publish(job.topic, args...); err != nil {
+			job.finish(bus, err)
+			return
+		}
+		if published := job.increment(); published%progressEvery == 0 {
+			bus.publishSystem(TopicBackfill, BackfillProgress{Topic: job.topic, Published: published})
+		}
+	}
+	job.finish(bus, ctx.Err())
+}
+
+func (job *BackfillJob) waitResumed(ctx context.Context) error {
+	job.Lock()
+	resumed := job.resumed
+	job.Unlock()
+	if resumed == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-resumed:
+		return ctx.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (job *BackfillJob) increment() int {
+	job.Lock()
+	defer job.Unlock()
+	job.published++
+	return job.published
+}
+
+func (job *BackfillJob) finish(bus *Bus, err error) {
+	job.Lock()
+	job.err = err
+	published := job.published
+	job.Unlock()
+	bus.publishSystem(TopicBackfill, BackfillProgress{job.topic, published, true, err})
+}
+
+// Pause suspends the backfill before its next event
+func (job *BackfillJob) Pause() {
+	job.Lock()
+	defer job.Unlock()
+	if !job.paused {
+		job.paused = true
+		job.resumed = make(chan struct{})
+	}
+}
+
+// Resume continues a paused backfill
+func (job *BackfillJob) Resume() {
+	job.Lock()
+	defer job.Unlock()
+	if job.paused {
+		job.paused = false
+		close(job.resumed)
+		job.resumed = nil
+	}
+}
+
+// Published returns the number of events published so far
+func (job *BackfillJob) Published() int {
+	job.Lock()
+	defer job.Unlock()
+	return job.published
+}
+
+// Wait blocks until the backfill ends and returns the context error if it was
+// cancelled, or the error of the publish that stopped it
+func (job *BackfillJob) Wait() error {
+	<-job.done
+	job.Lock()
+	defer job.Unlock()
+	return job.err
+}
@@ -0,0 +1,79 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+	"testing"
+	"time"
+)
+
+func numbers(n int) iter.Seq[[]interface{}] {
+	return func(yield func([]interface{}) bool) {
+		for i := 0; i < n; i++ {
+			if !yield([]interface{}{i}) {
+				return
+			}
+		}
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	bus := New()
+	lock := sync.Mutex{}
+	received := make([]int, 0)
+	bus.Subscribe("history", func(i int) {
+		lock.Lock()
+		received = append(received, i)
+		lock.Unlock()
+	})
+	var progress []BackfillProgress
+	bus.Subscribe(TopicBackfill, func(p BackfillProgress) {
+		progress = append(progress, p)
+	})
+
+	job := bus.Backfill(context.Background(), "history", numbers(2500), 0)
+	if err := job.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2500 || received[2499] != 2499 || job.Published() != 2500 {
+		t.Fail()
+	}
+	if len(progress) != 3 || progress[1].Published != 2000 || !progress[2].Done || progress[2].Published != 2500 {
+		t.Log(progress)
+		t.Fail()
+	}
+}
+
+func TestBackfillPauseAndCancel(t *testing.T) {
+	bus := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	job := bus.Backfill(ctx, "history", numbers(1000000), 1000)
+	job.Pause()
+	time.Sleep(20 * time.Millisecond)
+	paused := job.Published()
+	time.Sleep(20 * time.Millisecond)
+	if job.Published() != paused || paused > 20 {
+		t.Log(paused, job.Published())
+		t.Fail()
+	}
+
+	job.Resume()
+	time.Sleep(20 * time.Millisecond)
+	if job.Published() == paused {
+		t.Fail()
+	}
+	cancel()
+	if job.Wait() != context.Canceled {
+		t.Fail()
+	}
+}
+
+func TestBackfillStopsOnPublishError(t *testing.T) {
+	bus := New(WithNoSubscriberPolicy(NoSubscriberError))
+	job := bus.Backfill(context.Background(), "history", numbers(10), int(time.Second)+1) // unlimited
+	if err := job.Wait(); !errors.Is(err, ErrNoSubscribers) || job.Published() != 0 {
+		t.Fatal(err, job.Published())
+	}
+}
@@ -0,0 +1,84 @@
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// SetCheckpoint attaches a checkpoint callback to the subscription of fn on a
+// topic. The callback runs whenever a barrier is injected into the topic, after
+// the handler processed every event published before the barrier.
+// Only ordered subscriptions (synchronous or transactional async) take part in
+// barriers. Returns error if fn is not subscribed to the topic.
+func (bus *Bus) SetCheckpoint(topic string, fn interface{}, checkpoint func(topic, barrier string)) error {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	idx := bus.findHandlerIdx(topic, reflect.ValueOf(fn))
+	if idx < 0 {
+		return fmt.Errorf("handler is not subscribed to topic %s", topic)
+	}
+	handler := bus.handlersOf(topic)[idx]
+	if async, transactional := bus.deliveryMode(handler, topic); async && !transactional {
+		return fmt.Errorf("checkpoints require an ordered subscription to topic %s", topic)
+	}
+	handler.checkpoint = checkpoint
+	if handler.calls == nil {
+		handler.calls = &sync.WaitGroup{}
+	}
+	return nil
+}
+
+// InjectBarrier injects a barrier into the topics and returns once every
+// ordered subscriber with a checkpoint ran it, after processing every event
+// published before the barrier. Transactional async subscribers process the
+// events published after the barrier only after their checkpoint, so their
+// checkpoints observe a consistent cut. Synchronous subscribers run their
+// checkpoint once the deliveries in progress when the barrier was injected
+// returned; events published concurrently with InjectBarrier may reach them
+// before it.
+// With a single-threaded scheduler the scheduled work must be run from another
+// goroutine while InjectBarrier waits.
+func (bus *Bus) InjectBarrier(barrier string, topics ...string) {
+	type checkpoint struct {
+		topic   string
+		handler *eventHandler
+		calls   *sync.WaitGroup // synchronous deliveries started before the barrier
+		reached chan struct{}   // closed once earlier deliveries completed
+		release chan struct{}   // closed to let later deliveries run
+	}
+	bus.lock.Lock()
+	pending := make([]checkpoint, 0)
+	for _, topic := range topics {
+		for _, handler := range bus.handlersOf(topic) {
+			if handler.checkpoint == nil {
+				continue
+			}
+			p := checkpoint{topic: topic, handler: handler}
+			if _, transactional := bus.deliveryMode(handler, topic); transactional {
+				// queued behind earlier deliveries, holds back later ones
+				p.reached, p.release = make(chan struct{}), make(chan struct{})
+				handler.serial.push(bus.scheduler, func() {
+					close(p.reached)
+					<-p.release
+				})
+			} else {
+				// later deliveries count on a new group, so the wait ends
+				p.calls, handler.calls = handler.calls, &sync.WaitGroup{}
+			}
+			pending = append(pending, p)
+		}
+	}
+	bus.lock.Unlock()
+	for _, p := range pending {
+		if p.reached != nil {
+			<-p.reached
+		} else {
+			p.calls.Wait()
+		}
+		p.handler.checkpoint(p.topic, barrier)
+		if p.release != nil {
+			close(p.release)
+		}
+	}
+}
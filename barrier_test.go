@@ -0,0 +1,103 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInjectBarrier(t *testing.T) {
+	bus := New()
+	lock := sync.Mutex{}
+	orders, payments := 0, 0
+	snapshots := make(map[string]int)
+
+	onOrder := func(n int) {
+		time.Sleep(time.Millisecond)
+		lock.Lock()
+		orders += n
+		lock.Unlock()
+	}
+	onPayment := func(n int) {
+		payments += n
+	}
+	bus.SubscribeAsync("orders", onOrder, true)
+	bus.Subscribe("payments", onPayment)
+	bus.SubscribeAsync("unordered", func() {}, false)
+
+	if bus.SetCheckpoint("orders", onOrder, func(topic, barrier string) {
+		lock.Lock()
+		snapshots[topic+"/"+barrier] = orders
+		lock.Unlock()
+	}) != nil {
+		t.Fail()
+	}
+	bus.SetCheckpoint("payments", onPayment, func(topic, barrier string) {
+		snapshots[topic+"/"+barrier] = payments
+	})
+	if bus.SetCheckpoint("unknown", onPayment, func(topic, barrier string) {}) == nil {
+		t.Fail()
+	}
+
+	for i := 1; i <= 5; i++ {
+		bus.Publish("orders", i)
+		bus.Publish("payments", i)
+	}
+	bus.InjectBarrier("b1", "orders", "payments")
+	bus.Publish("orders", 100)
+	bus.Publish("payments", 100)
+	bus.WaitAsync()
+
+	if snapshots["orders/b1"] != 15 || snapshots["payments/b1"] != 15 {
+		t.Log(snapshots)
+		t.Fail()
+	}
+	if orders != 115 || payments != 115 {
+		t.Fail()
+	}
+}
+
+func TestSetCheckpointUnordered(t *testing.T) {
+	bus := New()
+	fn := func() {}
+	bus.SubscribeAsync("topic", fn, false)
+	if bus.SetCheckpoint("topic", fn, func(topic, barrier string) {}) == nil {
+		t.Fail()
+	}
+}
+
+func TestInjectBarrierWaitsSyncHandlers(t *testing.T) {
+	bus := New()
+	entered, resume := make(chan struct{}), make(chan struct{})
+	handled := 0
+	onPayment := func(n int) {
+		if n == 1 {
+			close(entered)
+			<-resume
+		}
+		handled += n
+	}
+	bus.Subscribe("payments", onPayment)
+	snapshot := -1
+	bus.SetCheckpoint("payments", onPayment, func(topic, barrier string) {
+		snapshot = handled
+	})
+
+	go bus.Publish("payments", 1)
+	<-entered
+	done := make(chan struct{})
+	go func() {
+		bus.InjectBarrier("b1", "payments")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("barrier returned while an earlier event was being handled")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(resume)
+	<-done
+	if snapshot != 1 {
+		t.Fatalf("checkpoint saw %d handled, expected 1", snapshot)
+	}
+}
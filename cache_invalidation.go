@@ -0,0 +1,112 @@
+package eventbus
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Invalidatable - cache whose entries can be dropped by key
+type Invalidatable interface {
+	Invalidate(keys ...string)
+}
+
+// InvalidationStats - counters of a CacheInvalidator
+type InvalidationStats struct {
+	Events  int // events that matched a registered cache
+	Keys    int // keys invalidated, after de-duplication within batches
+	Batches int // Invalidate calls made
+}
+
+type cacheRegistration struct {
+	cache Invalidatable
+	keys  func(args ...interface{}) []string
+}
+
+// CacheInvalidator - invalidates registered caches when matching events are published
+type CacheInvalidator struct {
+	bus     *Bus
+	window  time.Duration
+	pending map[*cacheRegistration]map[string]struct{}
+	timer   *time.Timer
+	stats   InvalidationStats
+	sync.Mutex
+}
+
+// NewCacheInvalidator - create an invalidator collecting keys for batchWindow
+// before invalidating them in one call per cache (immediately if zero)
+func NewCacheInvalidator(bus *Bus, batchWindow time.Duration) *CacheInvalidator {
+	return &CacheInvalidator{
+		bus:     bus,
+		window:  batchWindow,
+		pending: make(map[*cacheRegistration]map[string]struct{}),
+	}
+}
+
+// Register invalidates, in cache, the keys extracted by keys from every event
+// published on topic.
+// Returns error if the subscription fails.
+func (invalidator *CacheInvalidator) Register(topic string, cache Invalidatable, keys func(args ...interface{}) []string) error {
+	registration := &cacheRegistration{cache, keys}
+	return invalidator.bus.Subscribe(topic, func(args ...interface{}) {
+		invalidator.add(registration, registration.keys(args...))
+	})
+}
+
+func (invalidator *CacheInvalidator) add(registration *cacheRegistration, keys []string) {
+	invalidator.Lock()
+	invalidator.stats.Events++
+	pending, ok := invalidator.pending[registration]
+	if !ok {
+		pending = make(map[string]struct{})
+		invalidator.pending[registration] = pending
+	}
+	for _, key := range keys {
+		pending[key] = struct{}{}
+	}
+	if invalidator.window <= 0 {
+		invalidator.Unlock()
+		invalidator.Flush()
+		return
+	}
+	if invalidator.timer == nil {
+		invalidator.timer = time.AfterFunc(invalidator.window, invalidator.Flush)
+	}
+	invalidator.Unlock()
+}
+
+// Flush invalidates all pending keys now
+func (invalidator *CacheInvalidator) Flush() {
+	invalidator.Lock()
+	pending := invalidator.pending
+	invalidator.pending = make(map[*cacheRegistration]map[string]struct{})
+	if invalidator.timer != nil {
+		invalidator.timer.Stop()
+		invalidator.timer = nil
+	}
+	invalidator.Unlock()
+
+	for registration, keySet := range pending {
+		if len(keySet) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(keySet))
+		for key := range keySet {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		registration.cache.Invalidate(keys...)
+
+		invalidator.Lock()
+		invalidator.stats.Keys += len(keys)
+		invalidator.stats.Batches++
+		invalidator.Unlock()
+	}
+}
+
+// Stats returns the invalidation counters
+func (invalidator *CacheInvalidator) Stats() InvalidationStats {
+	invalidator.Lock()
+	defer invalidator.Unlock()
+	return invalidator.stats
+}
@@ -0,0 +1,78 @@
+package eventbus
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeCache struct {
+	calls [][]string
+	sync.Mutex
+}
+
+func (cache *fakeCache) Invalidate(keys ...string) {
+	cache.Lock()
+	defer cache.Unlock()
+	cache.calls = append(cache.calls, keys)
+}
+
+func (cache *fakeCache) Calls() [][]string {
+	cache.Lock()
+	defer cache.Unlock()
+	return cache.calls
+}
+
+func userKey(args ...interface{}) []string {
+	return []string{fmt.Sprintf("user:%v", args[0])}
+}
+
+func TestCacheInvalidatorImmediate(t *testing.T) {
+	bus := New()
+	cache := &fakeCache{}
+	invalidator := NewCacheInvalidator(bus, 0)
+	if invalidator.Register("users:updated", cache, userKey) != nil {
+		t.Fail()
+	}
+	bus.Publish("users:updated", 1, "name")
+	bus.Publish("users:updated", 2, "email")
+	calls := cache.Calls()
+	if len(calls) != 2 || calls[1][0] != "user:2" {
+		t.Log(calls)
+		t.Fail()
+	}
+}
+
+func TestCacheInvalidatorBatches(t *testing.T) {
+	bus := New()
+	users, sessions := &fakeCache{}, &fakeCache{}
+	invalidator := NewCacheInvalidator(bus, 10*time.Millisecond)
+	invalidator.Register("users:updated", users, userKey)
+	invalidator.Register("users:deleted", users, userKey)
+	invalidator.Register("users:deleted", sessions, func(args ...interface{}) []string {
+		return []string{fmt.Sprintf("session:%v:a", args[0]), fmt.Sprintf("session:%v:b", args[0])}
+	})
+
+	bus.Publish("users:updated", 1)
+	bus.Publish("users:updated", 1)
+	bus.Publish("users:deleted", 2)
+	if len(users.Calls()) != 0 {
+		t.Fail()
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if calls := users.Calls(); len(calls) != 2 {
+		t.Log(calls)
+		t.Fail()
+	}
+	if calls := sessions.Calls(); len(calls) != 1 || len(calls[0]) != 2 {
+		t.Log(calls)
+		t.Fail()
+	}
+	stats := invalidator.Stats()
+	if stats.Events != 4 || stats.Keys != 4 || stats.Batches != 3 {
+		t.Log(stats)
+		t.Fail()
+	}
+}
@@ -0,0 +1,159 @@
+package eventbus
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+)
+
+const (
+	// TopicCanary - control topic receiving CanaryRollback events
+	TopicCanary = "bus:canary"
+	// canaryMinAttempts - canary deliveries needed before the error rate is evaluated
+	canaryMinAttempts = 10
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// CanaryRollback - published when a canary is rolled back because of its error rate
+type CanaryRollback struct {
+	Topic     string
+	Attempts  int
+	Failures  int
+	ErrorRate float64
+}
+
+// Canary - traffic split between a stable and a canary handler of a topic
+type Canary struct {
+	topic        string
+	percent      int
+	maxErrorRate float64
+	attempts     int
+	failures     int
+	rolledBack   bool
+	sync.Mutex
+}
+
+// SubscribeCanary subscribes a stable/canary handler pair to a topic. Each event
+// goes to the canary with the given probability (in percent) and to the stable
+// handler otherwise. A canary delivery fails when the canary panics or returns a
+// non-nil error as its last result; the event is then handed to the stable
+// handler. Once the canary made at least 10 attempts with an error rate above
+// maxErrorRate, it is rolled back (percent drops to 0) and a CanaryRollback is
+// published on TopicCanary. Unsubscribe with stable removes the pair.
+// Returns error if the handlers are not functions with the same parameters.
+func (bus *Bus) SubscribeCanary(topic string, stable, canary interface{}, percent int, maxErrorRate float64) (*Canary, error) {
+	stableValue, canaryValue := reflect.ValueOf(stable), reflect.ValueOf(canary)
+	if stableValue.Kind() != reflect.Func || canaryValue.Kind() != reflect.Func {
+		return nil, fmt.Errorf("canary handlers must be of type reflect.Func")
+	}
+	if !sameParameters(stableValue.Type(), canaryValue.Type()) {
+		return nil, fmt.Errorf("canary handlers have different parameters: %s and %s", stableValue.Type(), canaryValue.Type())
+	}
+	c := &Canary{topic: topic, maxErrorRate: maxErrorRate}
+	c.SetPercent(percent)
+	callStable, callCanary := stableValue.Call, canaryValue.Call
+	if stableValue.Type().IsVariadic() {
+		callStable, callCanary = stableValue.CallSlice, canaryValue.CallSlice
+	}
+	wrapper := reflect.MakeFunc(stableValue.Type(), func(args []reflect.Value) []reflect.Value {
+		if !c.pick() {
+			return callStable(args)
+		}
+		results, report := callRecovered(callCanary, args)
+		if report != nil {
+			bus.reportPanic(report.describe(topic, canaryValue, interfaces(args)))
+		}
+		failed := report != nil || resultError(results) != nil
+		if rollback, ok := c.record(failed); ok {
+			bus.publishControl(TopicCanary, rollback)
+		}
+		if failed {
+			return callStable(args)
+		}
+		return results
+	})
+	err := bus.doSubscribe(topic, stable, &eventHandler{
+		callBack: wrapper, subscribed: stableValue,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Percent returns the share of events currently routed to the canary
+func (c *Canary) Percent() int {
+	c.Lock()
+	defer c.Unlock()
+	return c.percent
+}
+
+// SetPercent changes the share of events routed to the canary (clamped to 0-100).
+// It doesn't undo a rollback.
+func (c *Canary) SetPercent(percent int) {
+	c.Lock()
+	defer c.Unlock()
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	if !c.rolledBack {
+		c.percent = percent
+	}
+}
+
+// RolledBack returns true once the canary was rolled back
+func (c *Canary) RolledBack() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.rolledBack
+}
+
+// ErrorRate returns the share of failed canary deliveries
+func (c *Canary) ErrorRate() float64 {
+	c.Lock()
+	defer c.Unlock()
+	if c.attempts == 0 {
+		return 0
+	}
+	return float64(c.failures) / float64(c.attempts)
+}
+
+func (c *Canary) pick() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.percent > 0 && rand.Intn(100) < c.percent
+}
+
+// record counts a canary delivery and reports whether it triggered a rollback
+func (c *Canary) record(failed bool) (CanaryRollback, bool) {
+	c.Lock()
+	defer c.Unlock()
+	c.attempts++
+	if failed {
+		c.failures++
+	}
+	rate := float64(c.failures) / float64(c.attempts)
+	if c.rolledBack || c.attempts < canaryMinAttempts || rate <= c.maxErrorRate {
+		return CanaryRollback{}, false
+	}
+	c.rolledBack = true
+	c.percent = 0
+	return CanaryRollback{c.topic, c.attempts, c.failures, rate}, true
+}
+
+// resultError returns the last result of a handler if it is a non-nil error
+func resultError(results []reflect.Value) error {
+	if len(results) == 0 {
+		return nil
+	}
+	last := results[len(results)-1]
+	if last.Type() != errorType || last.IsNil() {
+		return nil
+	}
+	return last.Interface().(error)
+}
@@ -0,0 +1,83 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSubscribeCanary(t *testing.T) {
+	bus := New()
+	stableCalls, canaryCalls := 0, 0
+	stable := func(a int) error {
+		stableCalls++
+		return nil
+	}
+	canary := func(a int) error {
+		canaryCalls++
+		return nil
+	}
+	c, err := bus.SubscribeCanary("topic", stable, canary, 100, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		bus.Publish("topic", i)
+	}
+	if canaryCalls != 10 || stableCalls != 0 {
+		t.Fail()
+	}
+
+	c.SetPercent(0)
+	bus.Publish("topic", 0)
+	if canaryCalls != 10 || stableCalls != 1 || c.Percent() != 0 {
+		t.Fail()
+	}
+
+	if _, err := bus.SubscribeCanary("topic", stable, func(a string) {}, 10, 0); err == nil {
+		t.Fail()
+	}
+	if bus.Unsubscribe("topic", stable) != nil || bus.HasCallback("topic") {
+		t.Fail()
+	}
+}
+
+func TestCanaryRollback(t *testing.T) {
+	bus := New()
+	rollbacks := make(chan CanaryRollback, 1)
+	bus.Subscribe(TopicCanary, func(rollback CanaryRollback) {
+		rollbacks <- rollback
+	})
+
+	stableCalls := 0
+	c, _ := bus.SubscribeCanary("topic", func(a int) error {
+		stableCalls++
+		return nil
+	}, func(a int) error {
+		if a%2 == 0 {
+			panic("canary bug")
+		}
+		return errors.New("canary error")
+	}, 100, 0.2)
+
+	for i := 0; i < 15; i++ {
+		bus.Publish("topic", i)
+	}
+	bus.WaitAsync()
+
+	if !c.RolledBack() || c.Percent() != 0 || c.ErrorRate() != 1 {
+		t.Fail()
+	}
+	if stableCalls != 15 {
+		t.Log(stableCalls)
+		t.Fail()
+	}
+	rollback := <-rollbacks
+	if rollback.Topic != "topic" || rollback.Attempts != canaryMinAttempts {
+		t.Log(rollback)
+		t.Fail()
+	}
+	c.SetPercent(50)
+	if c.Percent() != 0 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,69 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrCascadeTooDeep - reason of the dead letters of the events published
+// deeper than the limit set with WithMaxCascadeDepth
+var ErrCascadeTooDeep = errors.New("event cascade too deep")
+
+// cascadeKey - context key of the depth of the cascade a handler runs in
+type cascadeKey struct{}
+
+// WithMaxCascadeDepth protects against event loops, e.g. two handlers
+// publishing to each other's topic: the contexts passed to handlers count how
+// many handler→publish→handler hops led to their event, and an event
+// published with such a context (see PublishCtx) more than depth hops deep is
+// not delivered. It is published as a DeadLetter on its dead-letter topic
+// (see WithDeadLetters) with a reason wrapping ErrCascadeTooDeep, logged, and
+// the publish returns the error. Events published from handlers without
+// their context start a new cascade.
+func WithMaxCascadeDepth(depth int) Option {
+	return func(bus *Bus) {
+		bus.maxCascade = depth
+	}
+}
+
+// CascadeDepth returns the number of handler→publish→handler hops that led to
+// the event of a handler given ctx, 0 for an event published from outside a
+// handler. Depths are only counted with WithMaxCascadeDepth.
+func CascadeDepth(ctx context.Context) int {
+	depth, _ := ctx.Value(cascadeKey{}).(int)
+	return depth
+}
+
+// deeper returns the context of the handlers of an event published with ctx,
+// one hop deeper in the cascade
+func (bus *Bus) deeper(ctx context.Context) context.Context {
+	if bus.maxCascade <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, cascadeKey{}, CascadeDepth(ctx)+1)
+}
+
+// checkCascade returns an error, after dead-lettering the event, if an event
+// published on topic with ctx is deeper than the cascade limit
+func (bus *Bus) checkCascade(ctx context.Context, topic string, args []interface{}) error {
+	depth := CascadeDepth(ctx)
+	if bus.maxCascade <= 0 || depth <= bus.maxCascade {
+		return nil
+	}
+	err := fmt.Errorf("%w: event of topic %s published %d hops deep, limit is %d", ErrCascadeTooDeep, topic, depth, bus.maxCascade)
+	bus.warn(err.Error(), "topic", topic, "depth", depth)
+	if strings.HasPrefix(topic, controlPrefix) || strings.HasPrefix(topic, DeadLetterPrefix) {
+		return err
+	}
+	route := DeadLetterTopic
+	if bus.deadLetters != nil {
+		route = bus.deadLetters
+	}
+	if letters := route(topic); letters != topic {
+		bus.publishControl(letters, DeadLetter{topic, args, err, time.Now(), ""})
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithMaxCascadeDepth(t *testing.T) {
+	bus := New(WithMaxCascadeDepth(3))
+	var depths []int
+	var publishErr error
+	bounce := func(next string) func(ctx context.Context, n int) {
+		return func(ctx context.Context, n int) {
+			depths = append(depths, CascadeDepth(ctx))
+			if err := bus.PublishCtx(ctx, next, n+1); err != nil {
+				publishErr = err
+			}
+		}
+	}
+	bus.Subscribe("ping", bounce("pong"))
+	bus.Subscribe("pong", bounce("ping"))
+	var letters []DeadLetter
+	bus.Subscribe(DeadLetterTopic("ping"), func(letter DeadLetter) {
+		letters = append(letters, letter)
+	})
+
+	if err := bus.Publish("ping", 0); err != nil {
+		t.Fatal(err)
+	}
+	bus.WaitAsync()
+
+	if len(depths) != 4 || depths[0] != 1 || depths[3] != 4 {
+		t.Fatal(depths)
+	}
+	if !errors.Is(publishErr, ErrCascadeTooDeep) {
+		t.Fatal(publishErr)
+	}
+	if len(letters) != 1 || letters[0].Topic != "ping" || letters[0].Args[0] != 4 || !errors.Is(letters[0].Reason, ErrCascadeTooDeep) {
+		t.Fatal(letters)
+	}
+}
+
+func TestCascadeDepthUnlimited(t *testing.T) {
+	bus := New()
+	depth := -1
+	bus.Subscribe("topic", func(ctx context.Context) {
+		depth = CascadeDepth(ctx)
+	})
+	bus.Publish("topic")
+	if depth != 0 {
+		t.Fatal("depth counted without a limit", depth)
+	}
+}
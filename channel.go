@@ -0,0 +1,47 @@
+package eventbus
+
+import "sync"
+
+// SubscribeChannel runs SubscribeChannel on package-level bus singleton
+func SubscribeChannel(topic string, buffer int) (<-chan Event, func()) {
+	return b.Load().SubscribeChannel(topic, buffer)
+}
+
+// SubscribeChannel subscribes to a topic (possibly a wildcard pattern) and
+// returns a channel receiving its events, buffering up to buffer of them, and
+// a function cancelling the subscription. Once the buffer is full publishes
+// block until the consumer catches up or the subscription is cancelled, which
+// closes the channel, so consumers can range over it or select on it.
+// The channel is closed right away if the topic can't be subscribed to.
+func (bus *Bus) SubscribeChannel(topic string, buffer int) (<-chan Event, func()) {
+	events := make(chan Event, buffer)
+	done := make(chan struct{})
+	var lock sync.RWMutex // held for writing to close events
+	closed := false
+	sub, err := bus.SubscribeHandle(topic, func(ev Event) {
+		lock.RLock()
+		defer lock.RUnlock()
+		if closed {
+			return
+		}
+		select {
+		case events <- ev:
+		case <-done:
+		}
+	})
+	if err != nil {
+		close(events)
+		return events, func() {}
+	}
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			sub.Unsubscribe()
+			close(done) // unblocks the deliveries waiting for room
+			lock.Lock()
+			closed = true
+			close(events)
+			lock.Unlock()
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSubscribeChannel(t *testing.T) {
+	bus := New(WithSeparator("/"))
+	events, cancel := bus.SubscribeChannel("orders/+", 2)
+	bus.Publish("orders/new", 1)
+	bus.PublishEvent(Event{Topic: "orders/paid", Args: []interface{}{2}, ID: "id"})
+
+	ev := <-events
+	if ev.Topic != "orders/new" || ev.Args[0] != 1 || ev.Time.IsZero() {
+		t.Fatal(ev)
+	}
+	ev = <-events
+	if ev.Topic != "orders/paid" || ev.Args[0] != 2 || ev.ID != "id" {
+		t.Fatal(ev)
+	}
+
+	cancel()
+	cancel()
+	if _, ok := <-events; ok {
+		t.Fail()
+	}
+	if bus.HasCallback("orders/+") {
+		t.Fail()
+	}
+}
+
+func TestSubscribeChannelCancelUnblocksPublish(t *testing.T) {
+	bus := New()
+	events, cancel := bus.SubscribeChannel("topic", 0)
+	published := make(chan struct{})
+	go func() {
+		bus.Publish("topic", 1)
+		close(published)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("publish still blocked")
+	}
+	for range events {
+		t.Fail()
+	}
+}
+
+func TestSubscribeChannelClosedBus(t *testing.T) {
+	bus := New()
+	bus.Close(context.Background())
+	events, cancel := bus.SubscribeChannel("topic", 1)
+	defer cancel()
+	if _, ok := <-events; ok {
+		t.Fail()
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/rpc"
 	"sync"
+	"time"
 )
 
 const (
@@ -16,8 +17,12 @@ const (
 
 // ClientArg - object containing event for client to publish locally
 type ClientArg struct {
-	Args  []interface{}
-	Topic string
+	Args     []interface{}
+	Topic    string
+	Deadline time.Time // when the event expires, zero if it doesn't
+	// Provenance - topics the event went through, up to the topic of the
+	// remote bus it was published on, see Event.Provenance
+	Provenance []Hop
 }
 
 // Client - object capable of subscribing to a remote event bus
@@ -110,7 +115,16 @@ type ClientService struct {
 
 // PushEvent - exported service to listening to remote events
 func (service *ClientService) PushEvent(arg *ClientArg, reply *bool) error {
-	service.client.eventBus.Publish(arg.Topic, arg.Args...)
+	var err error
+	if arg.Deadline.IsZero() && len(arg.Provenance) == 0 {
+		err = service.client.eventBus.Publish(arg.Topic, arg.Args...)
+	} else {
+		// not delivered once expired
+		err = service.client.eventBus.PublishEvent(Event{Topic: arg.Topic, Args: arg.Args, Deadline: arg.Deadline, Provenance: arg.Provenance})
+	}
+	if err != nil {
+		return err
+	}
 	*reply = true
 	return nil
 }
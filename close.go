@@ -0,0 +1,72 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrBusClosed - error returned by the methods of a closed bus
+var ErrBusClosed = errors.New("bus closed")
+
+// resources - background work started on a bus, stopped by Close
+type resources struct {
+	next  int
+	stops map[int]func()
+	sync.Mutex
+}
+
+// Close runs Close on package-level bus singleton
+func Close(ctx context.Context) error {
+	return b.Load().Close(ctx)
+}
+
+// Close shuts the bus down: later publishes and subscriptions return
+// ErrBusClosed, parked events are dropped and the emitters, watchdog and signal
+// relays started on the bus are stopped. It then waits for the async deliveries
+// in flight, which may still emit control events, until ctx is done.
+// Returns a *PendingWorkError wrapping ctx.Err() if the deliveries didn't
+// finish in time, ErrBusClosed if the bus was already closed.
+func (bus *Bus) Close(ctx context.Context) error {
+	bus.lock.Lock()
+	if bus.closed {
+		bus.lock.Unlock()
+		return ErrBusClosed
+	}
+	bus.closed = true
+	bus.lock.Unlock()
+
+	bus.parking.Lock()
+	bus.parking.parked = nil
+	bus.parking.Unlock()
+	bus.resources.Lock()
+	stops := bus.resources.stops
+	bus.resources.stops = nil
+	bus.resources.Unlock()
+	for _, stop := range stops {
+		stop()
+	}
+
+	return bus.WaitAsyncCtx(ctx)
+}
+
+// track registers the stop function of background work so Close stops it.
+// The returned function stops the work and unregisters it; it is safe to call
+// more than once if stop is.
+func (bus *Bus) track(stop func()) func() {
+	res := &bus.resources
+	res.Lock()
+	if res.stops == nil {
+		res.stops = make(map[int]func())
+	}
+	id := res.next
+	res.next++
+	res.stops[id] = stop
+	res.Unlock()
+	return func() {
+		res.Lock()
+		delete(res.stops, id)
+		res.Unlock()
+		stop()
+	}
+}
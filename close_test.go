@@ -0,0 +1,70 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	done := false
+	bus.SubscribeAsync("topic", func() {
+		<-release
+		done = true
+	}, false)
+	emitted := make(chan struct{}, 100)
+	bus.Subscribe("tick", func() { emitted <- struct{}{} })
+	stopEmitter := bus.EmitEvery("tick", time.Millisecond, nil)
+	bus.Publish("topic")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	if !errors.Is(bus.Publish("topic"), ErrBusClosed) || !errors.Is(bus.Subscribe("topic", func() {}), ErrBusClosed) {
+		t.Fail()
+	}
+	if errs := bus.PublishWithResult("topic"); len(errs) != 1 || errs[0] != ErrBusClosed {
+		t.Fail()
+	}
+
+	close(release)
+	bus.WaitAsync()
+	if !done {
+		t.Fail()
+	}
+	for len(emitted) > 0 {
+		<-emitted
+	}
+	time.Sleep(5 * time.Millisecond)
+	if len(emitted) != 0 {
+		t.Fatal("emitter still running")
+	}
+	stopEmitter() // safe after Close
+	if bus.Close(context.Background()) != ErrBusClosed {
+		t.Fail()
+	}
+}
+
+func TestCloseDeliversControlEvents(t *testing.T) {
+	bus := New(WithRecovery(nil))
+	reports := make(chan *PanicReport, 1)
+	bus.Subscribe(TopicPanic, func(report *PanicReport) {
+		reports <- report
+	})
+	bus.SubscribeAsync("topic", func() {
+		time.Sleep(10 * time.Millisecond)
+		panic("boom")
+	}, false)
+	bus.Publish("topic")
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fail()
+	}
+}
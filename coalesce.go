@@ -0,0 +1,62 @@
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// flight - handler call shared by coalesced deliveries
+type flight struct {
+	done      chan struct{}
+	results   []reflect.Value
+	recovered *PanicReport
+}
+
+// SubscribeCoalesced subscribes fn to a topic as an async handler whose
+// concurrent deliveries are coalesced: while fn handles an event, deliveries
+// of events with the same key (computed from the arguments by key) wait for it
+// and share its results instead of calling fn again. This keeps event storms
+// from repeating expensive work, e.g. for request/reply topics.
+// Unsubscribe with fn removes the handler.
+// Returns error if `fn` is not a function.
+func (bus *Bus) SubscribeCoalesced(topic string, fn interface{}, key func(args ...interface{}) string) error {
+	fnValue := reflect.ValueOf(fn)
+	if fnValue.Kind() != reflect.Func {
+		return fmt.Errorf("%s is not of type reflect.Func", fnValue.Kind())
+	}
+	call := fnValue.Call
+	if fnValue.Type().IsVariadic() {
+		call = fnValue.CallSlice
+	}
+	lock := sync.Mutex{}
+	flights := make(map[string]*flight)
+	wrapper := reflect.MakeFunc(fnValue.Type(), func(args []reflect.Value) []reflect.Value {
+		k := key(interfaces(args)...)
+		lock.Lock()
+		if f, ok := flights[k]; ok {
+			lock.Unlock()
+			<-f.done
+			if f.recovered != nil {
+				panic(f.recovered.Recovered)
+			}
+			return f.results
+		}
+		f := &flight{done: make(chan struct{})}
+		flights[k] = f
+		lock.Unlock()
+
+		f.results, f.recovered = callRecovered(call, args)
+		lock.Lock()
+		delete(flights, k)
+		lock.Unlock()
+		close(f.done)
+		if f.recovered != nil {
+			panic(f.recovered.Recovered)
+		}
+		return f.results
+	})
+	return bus.doSubscribe(topic, fn, &eventHandler{
+		callBack: wrapper, subscribed: fnValue, async: true,
+	})
+}
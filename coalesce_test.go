@@ -0,0 +1,80 @@
+package eventbus
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubscribeCoalesced(t *testing.T) {
+	bus := New()
+	var calls int32
+	release := make(chan struct{})
+	lookup := func(user string) int {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return len(user)
+	}
+	err := bus.SubscribeCoalesced("lookup", lookup, func(args ...interface{}) string {
+		return args[0].(string)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.Subscribe("lookup", func(user string) {}) // unrelated handler
+
+	for i := 0; i < 5; i++ {
+		bus.Publish("lookup", "bob")
+	}
+	bus.Publish("lookup", "alice")
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	bus.WaitAsync()
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("handler called %d times", calls)
+	}
+
+	// later events are handled again
+	bus.Publish("lookup", "bob")
+	bus.WaitAsync()
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Fail()
+	}
+
+	if bus.Unsubscribe("lookup", lookup) != nil {
+		t.Fail()
+	}
+	if bus.SubscribeCoalesced("lookup", "String", nil) == nil {
+		t.Fail()
+	}
+}
+
+func TestSubscribeCoalescedSharesResults(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	var calls int32
+	square := func(n int) int {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return n * n
+	}
+	bus.SubscribeCoalesced("square", square, func(args ...interface{}) string {
+		return fmt.Sprint(args...)
+	})
+	bus.EnableTrace("square", 10, 64)
+	bus.Publish("square", 3)
+	bus.Publish("square", 3)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	bus.WaitAsync()
+	traces := bus.Traces("square")
+	if atomic.LoadInt32(&calls) != 1 || len(traces) != 2 {
+		t.Fatalf("%d calls, %d traces", calls, len(traces))
+	}
+	for _, trace := range traces {
+		if trace.Results != "[9]" {
+			t.Error(trace.Results)
+		}
+	}
+}
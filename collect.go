@@ -0,0 +1,55 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Collect runs Collect on package-level bus singleton
+func Collect(ctx context.Context, topic string, n int, timeout time.Duration) ([]Event, error) {
+	return b.Load().Collect(ctx, topic, n, timeout)
+}
+
+// Collect gathers the events published on a topic (possibly a wildcard
+// pattern) until n events arrived (no limit if n <= 0) or timeout elapsed, and
+// returns them in arrival order, e.g. to gather the answers of whoever replies
+// to a discovery request within 2 seconds.
+// Returns the events gathered so far and ctx.Err() if ctx is done first, or
+// the error subscribing to the topic.
+func (bus *Bus) Collect(ctx context.Context, topic string, n int, timeout time.Duration) ([]Event, error) {
+	lock := sync.Mutex{}
+	var events []Event
+	full := make(chan struct{})
+	sub, err := bus.SubscribeHandle(topic, func(...interface{}) {})
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Unsubscribe()
+	sub.Use(func(next DeliverFunc) DeliverFunc {
+		return func(delivery *Delivery) error {
+			lock.Lock()
+			defer lock.Unlock()
+			if n > 0 && len(events) == n {
+				return nil
+			}
+			events = append(events, Event{Topic: delivery.Topic, Args: delivery.Args, Time: time.Now()})
+			if len(events) == n {
+				close(full)
+			}
+			return nil
+		}
+	})
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-full:
+	case <-timer.C:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	return append([]Event(nil), events...), err
+}
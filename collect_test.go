@@ -0,0 +1,46 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCollect(t *testing.T) {
+	bus := New()
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		bus.Publish("reply", "a")
+		bus.Publish("reply", "b")
+		bus.Publish("reply", "c")
+	}()
+	events, err := bus.Collect(context.Background(), "reply", 2, time.Second)
+	if err != nil || len(events) != 2 {
+		t.Fatal(events, err)
+	}
+	if events[0].Topic != "reply" || events[0].Args[0] != "a" || events[1].Args[0] != "b" || events[0].Time.IsZero() {
+		t.Fatal(events)
+	}
+	if bus.HasCallback("reply") {
+		t.Fail()
+	}
+}
+
+func TestCollectTimeout(t *testing.T) {
+	bus := New(WithSeparator("/"))
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		bus.Publish("reply/node1", 1)
+	}()
+	events, err := bus.Collect(context.Background(), "reply/+", 0, 30*time.Millisecond)
+	if err != nil || len(events) != 1 || events[0].Topic != "reply/node1" {
+		t.Fatal(events, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := bus.Collect(ctx, "reply/+", 0, time.Second); !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,91 @@
+package eventbus
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Skip drops the next n deliveries to the subscription.
+// Combinators apply to the deliveries filtered by the ones chained before them.
+func (sub *Subscription) Skip(n int) *Subscription {
+	lock := sync.Mutex{}
+	return sub.Use(func(next DeliverFunc) DeliverFunc {
+		return func(delivery *Delivery) error {
+			lock.Lock()
+			skip := n > 0
+			if skip {
+				n--
+			}
+			lock.Unlock()
+			if skip {
+				return nil
+			}
+			return next(delivery)
+		}
+	})
+}
+
+// Take lets n more deliveries through to the subscription, then unsubscribes it.
+// With n zero or negative, it unsubscribes it right away, dropping the
+// deliveries already queued.
+func (sub *Subscription) Take(n int) *Subscription {
+	lock := sync.Mutex{}
+	if n <= 0 {
+		defer sub.Unsubscribe()
+	}
+	return sub.Use(func(next DeliverFunc) DeliverFunc {
+		return func(delivery *Delivery) error {
+			lock.Lock()
+			take := n > 0
+			if take {
+				n--
+			}
+			last := take && n == 0
+			lock.Unlock()
+			if !take {
+				return nil
+			}
+			if last {
+				sub.bus.evict(sub.handler, nil)
+			}
+			return next(delivery)
+		}
+	})
+}
+
+// DistinctUntilChanged drops the deliveries whose arguments are equal to the
+// ones of the previous delivery let through, compared with equal
+// (reflect.DeepEqual if nil).
+func (sub *Subscription) DistinctUntilChanged(equal func(previous, next []interface{}) bool) *Subscription {
+	if equal == nil {
+		equal = func(previous, next []interface{}) bool { return reflect.DeepEqual(previous, next) }
+	}
+	lock := sync.Mutex{}
+	var previous []interface{}
+	started := false
+	return sub.Use(func(next DeliverFunc) DeliverFunc {
+		return func(delivery *Delivery) error {
+			lock.Lock()
+			changed := !started || !equal(previous, delivery.Args)
+			if changed {
+				previous = append([]interface{}(nil), delivery.Args...)
+				started = true
+			}
+			lock.Unlock()
+			if !changed {
+				return nil
+			}
+			return next(delivery)
+		}
+	})
+}
+
+// StartWith delivers an event with the given arguments to the subscription
+// right away, on the calling goroutine, through the combinators chained
+// before it.
+func (sub *Subscription) StartWith(args ...interface{}) *Subscription {
+	if sub.IsActive() {
+		sub.bus.invoke(sub.handler, sub.topic, args)
+	}
+	return sub
+}
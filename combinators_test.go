@@ -0,0 +1,72 @@
+package eventbus
+
+import (
+	"testing"
+)
+
+func TestSkipTake(t *testing.T) {
+	bus := New()
+	var received []int
+	sub, _ := bus.SubscribeHandle("topic", func(a int) {
+		received = append(received, a)
+	})
+	sub.Skip(1).Take(2)
+	for i := 1; i <= 5; i++ {
+		bus.Publish("topic", i)
+	}
+	bus.WaitAsync()
+	if len(received) != 2 || received[0] != 2 || received[1] != 3 {
+		t.Fatal(received)
+	}
+	if sub.IsActive() {
+		t.Fail()
+	}
+}
+
+func TestTakeZero(t *testing.T) {
+	bus := New()
+	received := 0
+	sub, _ := bus.SubscribeHandle("topic", func(a int) {
+		received++
+	})
+	if sub.Take(0).IsActive() {
+		t.Fatal("still subscribed")
+	}
+	bus.Publish("topic", 1)
+	bus.WaitAsync()
+	if received != 0 {
+		t.Fatal(received)
+	}
+}
+
+func TestDistinctUntilChanged(t *testing.T) {
+	bus := New()
+	var received []string
+	sub, _ := bus.SubscribeHandle("topic", func(state string, attempt int) {
+		received = append(received, state)
+	})
+	sub.DistinctUntilChanged(func(previous, next []interface{}) bool {
+		return previous[0] == next[0] // ignore the attempt
+	})
+	bus.Publish("topic", "a", 1)
+	bus.Publish("topic", "a", 2)
+	bus.Publish("topic", "b", 3)
+	bus.Publish("topic", "a", 4)
+	if len(received) != 3 || received[1] != "b" || received[2] != "a" {
+		t.Fatal(received)
+	}
+}
+
+func TestStartWith(t *testing.T) {
+	bus := New()
+	var received []int
+	sub, _ := bus.SubscribeHandle("topic", func(a int) {
+		received = append(received, a)
+	})
+	sub.DistinctUntilChanged(nil).StartWith(0)
+	bus.Publish("topic", 0)
+	bus.Publish("topic", 1)
+	if len(received) != 2 || received[0] != 0 || received[1] != 1 {
+		t.Fatal(received)
+	}
+}
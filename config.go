@@ -0,0 +1,134 @@
+package eventbus
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// DeliveryMode - how a subscription's handler is called
+type DeliveryMode int
+
+const (
+	// ModeInherit - use the mode of the enclosing configuration level
+	ModeInherit DeliveryMode = iota
+	// ModeSync - handler is called by Publish
+	ModeSync
+	// ModeAsync - handler runs asynchronously, deliveries run concurrently
+	ModeAsync
+	// ModeTransactional - handler runs asynchronously, deliveries run serially
+	ModeTransactional
+)
+
+func (mode DeliveryMode) String() string {
+	switch mode {
+	case ModeInherit:
+		return "inherit"
+	case ModeSync:
+		return "sync"
+	case ModeAsync:
+		return "async"
+	case ModeTransactional:
+		return "transactional"
+	}
+	return "unknown"
+}
+
+// Config - delivery policy, layered from bus defaults over topic settings to
+// single subscriptions. Zero fields inherit the value of the enclosing level.
+type Config struct {
+	Mode        DeliveryMode   // applies to subscriptions made with SubscribeWithConfig
+	MaxAttempts int            // deliveries attempted while the handler returns an error
+	Timeout     time.Duration  // deadline of the context passed to handlers taking one
+	BufferSize  int            // capacity of the async queue of a topic, unbounded if zero
+	Overflow    OverflowPolicy // what a publish does once the async queue is full
+}
+
+// inherit returns c with its zero fields taken from parent
+func (c Config) inherit(parent Config) Config {
+	if c.Mode == ModeInherit {
+		c.Mode = parent.Mode
+	}
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = parent.MaxAttempts
+	}
+	if c.Timeout == 0 {
+		c.Timeout = parent.Timeout
+	}
+	if c.BufferSize == 0 {
+		c.BufferSize = parent.BufferSize
+	}
+	if c.Overflow == OverflowInherit {
+		c.Overflow = parent.Overflow
+	}
+	return c
+}
+
+// builtinConfig - behavior of the bus when nothing is configured
+var builtinConfig = Config{Mode: ModeSync, MaxAttempts: 1}
+
+// configs holds the bus defaults and the topic settings
+type configs struct {
+	defaults Config
+	topics   map[string]Config
+	sync.RWMutex
+}
+
+// SetDefaults sets the bus-level configuration inherited by every topic
+func (bus *Bus) SetDefaults(config Config) {
+	bus.configs.Lock()
+	defer bus.configs.Unlock()
+	bus.configs.defaults = config
+}
+
+// SetTopicConfig sets the configuration of a topic, overriding the bus
+// defaults for its non-zero fields. A zero Config removes the topic settings.
+func (bus *Bus) SetTopicConfig(topic string, config Config) {
+	bus.configs.Lock()
+	defer bus.configs.Unlock()
+	if config == (Config{}) {
+		delete(bus.configs.topics, topic)
+		return
+	}
+	if bus.configs.topics == nil {
+		bus.configs.topics = make(map[string]Config)
+	}
+	bus.configs.topics[topic] = config
+}
+
+// Config returns the effective configuration of a topic
+func (bus *Bus) Config(topic string) Config {
+	return bus.resolveConfig(topic, nil)
+}
+
+// resolveConfig returns the effective configuration of a subscription to a
+// topic, subscription may be nil
+func (bus *Bus) resolveConfig(topic string, subscription *Config) Config {
+	bus.configs.RLock()
+	config := bus.configs.topics[topic].inherit(bus.configs.defaults)
+	bus.configs.RUnlock()
+	if subscription != nil {
+		config = subscription.inherit(config)
+	}
+	return config.inherit(builtinConfig)
+}
+
+// SubscribeWithConfig subscribes to a topic with a subscription-level
+// configuration. Its delivery mode and the other fields left zero follow
+// the topic settings and bus defaults, as they are at publishing time.
+// Returns error if `fn` is not a function.
+func (bus *Bus) SubscribeWithConfig(topic string, fn interface{}, config Config) error {
+	return bus.doSubscribe(topic, fn, &eventHandler{
+		callBack: reflect.ValueOf(fn), config: &config,
+	})
+}
+
+// deliveryMode returns whether a handler receives the events of a topic
+// asynchronously and serially, resolving the mode of configured subscriptions
+func (bus *Bus) deliveryMode(handler *eventHandler, topic string) (async, transactional bool) {
+	if handler.config == nil {
+		return handler.async, handler.transactional
+	}
+	mode := bus.resolveConfig(topic, handler.config).Mode
+	return mode == ModeAsync || mode == ModeTransactional, mode == ModeTransactional
+}
@@ -0,0 +1,89 @@
+package eventbus
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfigInheritance(t *testing.T) {
+	bus := New()
+	if bus.Config("topic") != builtinConfig {
+		t.Fail()
+	}
+	bus.SetDefaults(Config{Mode: ModeAsync, MaxAttempts: 3, Timeout: time.Second})
+	bus.SetTopicConfig("topic", Config{MaxAttempts: 5, BufferSize: 10})
+	expected := Config{Mode: ModeAsync, MaxAttempts: 5, Timeout: time.Second, BufferSize: 10}
+	if bus.Config("topic") != expected {
+		t.Error(bus.Config("topic"))
+	}
+	if bus.Config("other").MaxAttempts != 3 {
+		t.Fail()
+	}
+	subscription := Config{Mode: ModeTransactional, Timeout: time.Minute}
+	expected = Config{Mode: ModeTransactional, MaxAttempts: 5, Timeout: time.Minute, BufferSize: 10}
+	if bus.resolveConfig("topic", &subscription) != expected {
+		t.Fail()
+	}
+	bus.SetTopicConfig("topic", Config{})
+	if bus.Config("topic").MaxAttempts != 3 {
+		t.Fail()
+	}
+}
+
+func TestSubscribeWithConfigMode(t *testing.T) {
+	bus := New()
+	lock := sync.Mutex{}
+	calls := 0
+	release := make(chan struct{})
+	handler := func() {
+		<-release
+		lock.Lock()
+		calls++
+		lock.Unlock()
+	}
+	if bus.SubscribeWithConfig("topic", handler, Config{}) != nil {
+		t.Fail()
+	}
+	if bus.SubscribeWithConfig("topic", "String", Config{}) == nil {
+		t.Fail()
+	}
+	bus.SetTopicConfig("topic", Config{Mode: ModeAsync})
+	bus.Publish("topic") // would block if delivered synchronously
+	close(release)
+	bus.WaitAsync()
+	bus.SetTopicConfig("topic", Config{Mode: ModeSync})
+	bus.Publish("topic")
+	if calls != 2 {
+		t.Fail()
+	}
+}
+
+func TestConfigMaxAttempts(t *testing.T) {
+	bus := New()
+	attempts := 0
+	bus.Subscribe("topic", func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	bus.Publish("topic")
+	if attempts != 1 {
+		t.Fail()
+	}
+	attempts = 0
+	bus.SetDefaults(Config{MaxAttempts: 5})
+	bus.Publish("topic")
+	if attempts != 3 {
+		t.Fail()
+	}
+	attempts = 0
+	bus.SetTopicConfig("topic", Config{MaxAttempts: 2})
+	bus.Publish("topic")
+	if attempts != 2 {
+		t.Fail()
+	}
+}
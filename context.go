@@ -0,0 +1,70 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// takesContext reports whether the first parameter of the handler is a context.Context
+func takesContext(handler *eventHandler) bool {
+	fnType := handler.callBack.Type()
+	return fnType.NumIn() > 0 && fnType.In(0) == contextType
+}
+
+// withContext returns the arguments of a delivery to handler, with ctx first
+// when the handler takes a context the arguments don't start with
+func withContext(ctx context.Context, handler *eventHandler, args []interface{}) []interface{} {
+	if !takesContext(handler) {
+		return args
+	}
+	if len(args) > 0 {
+		if _, ok := args[0].(context.Context); ok {
+			return args
+		}
+	}
+	return append([]interface{}{ctx}, args...)
+}
+
+// handlerContext returns the context passed to the handlers of an event
+// published with ctx: the events they publish with it are neither gathered
+// (see PublishGather and PublishWithResult) nor retained (see
+// PublishRetained), and they are stored even if the event is replayed (see
+// ReplayFromStore)
+func handlerContext(ctx context.Context) context.Context {
+	if gatheringFrom(ctx) != nil {
+		ctx = context.WithValue(ctx, gatherKey{}, (*gathering)(nil))
+	}
+	if result, _ := ctx.Value(resultKey{}).(*[]error); result != nil {
+		ctx = context.WithValue(ctx, resultKey{}, (*[]error)(nil))
+	}
+	for _, key := range []interface{}{retainKey{}, replayKey{}} {
+		if marked, _ := ctx.Value(key).(bool); marked {
+			ctx = context.WithValue(ctx, key, false)
+		}
+	}
+	return ctx
+}
+
+// contextArg returns the context passed to handler in args, if it takes one
+func contextArg(handler *eventHandler, args []interface{}) (context.Context, bool) {
+	if !takesContext(handler) || len(args) == 0 {
+		return nil, false
+	}
+	ctx, ok := args[0].(context.Context)
+	return ctx, ok
+}
+
+// withTimeout returns the arguments of a delivery to handler with their
+// context bounded by timeout, and the function releasing it
+func withTimeout(handler *eventHandler, args []interface{}, timeout time.Duration) ([]interface{}, context.CancelFunc) {
+	ctx, ok := contextArg(handler, args)
+	if timeout <= 0 || !ok {
+		return args, func() {}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	bounded := append([]interface{}{ctx}, args[1:]...)
+	return bounded, cancel
+}
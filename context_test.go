@@ -0,0 +1,95 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type ctxKey struct{}
+
+func TestPublishCtx(t *testing.T) {
+	bus := New()
+	values := make([]interface{}, 0)
+	bus.Subscribe("topic", func(ctx context.Context, n int) {
+		values = append(values, ctx.Value(ctxKey{}), n)
+	})
+	bus.Subscribe("plain", func(n int) { values = append(values, n) })
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	if bus.PublishCtx(ctx, "topic", 1) != nil || bus.PublishCtx(ctx, "plain", 2) != nil {
+		t.Fail()
+	}
+	bus.Publish("topic", 3)
+	bus.Publish("topic", ctx, 4) // explicit context argument
+	expected := []interface{}{"request", 1, 2, nil, 3, "request", 4}
+	if len(values) != len(expected) {
+		t.Fatal(values)
+	}
+	for i := range values {
+		if values[i] != expected[i] {
+			t.Fatal(values)
+		}
+	}
+}
+
+func TestPublishCtxCancelled(t *testing.T) {
+	bus := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := make([]string, 0)
+	bus.Subscribe("topic", func() {
+		calls = append(calls, "first")
+		cancel()
+	})
+	bus.Subscribe("topic", func() { calls = append(calls, "second") })
+	bus.PublishCtx(ctx, "topic")
+	if len(calls) != 1 {
+		t.Error(calls)
+	}
+}
+
+func TestPublishCtxAsync(t *testing.T) {
+	bus := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	lock := sync.Mutex{}
+	started := make(chan struct{})
+	observed := false
+	calls := 0
+	bus.SubscribeAsync("topic", func(ctx context.Context) {
+		lock.Lock()
+		calls++
+		first := calls == 1
+		lock.Unlock()
+		if !first {
+			return
+		}
+		close(started)
+		select {
+		case <-ctx.Done():
+			observed = true
+		case <-time.After(time.Second):
+		}
+	}, true)
+	bus.PublishCtx(ctx, "topic")
+	bus.PublishCtx(ctx, "topic") // queued behind the first, skipped once cancelled
+	<-started
+	cancel()
+	bus.WaitAsync()
+	if !observed || calls != 1 {
+		t.Fail()
+	}
+}
+
+func TestConfigTimeout(t *testing.T) {
+	bus := New()
+	bus.SetTopicConfig("topic", Config{Timeout: 10 * time.Millisecond})
+	var err error
+	bus.Subscribe("topic", func(ctx context.Context) {
+		<-ctx.Done()
+		err = ctx.Err()
+	})
+	bus.Publish("topic")
+	if err != context.DeadlineExceeded {
+		t.Fail()
+	}
+}
@@ -0,0 +1,48 @@
+package eventbus
+
+import (
+	"strings"
+	"time"
+)
+
+// WithDeadLetters republishes the events a handler failed on (returned an
+// error, panicked with WithRecovery or timed out, after all its attempts) as a
+// DeadLetter naming the handler, so operators can inspect, alert on or replay
+// them. route returns the dead-letter topic of a topic; nil routes to
+// DeadLetterTopic(topic). Failures on control topics and dead-letter topics
+// aren't republished.
+func WithDeadLetters(route func(topic string) string) Option {
+	return func(bus *Bus) {
+		if route == nil {
+			route = DeadLetterTopic
+		}
+		bus.deadLetters = route
+	}
+}
+
+// deadLetter republishes an event a handler failed on, see WithDeadLetters
+func (bus *Bus) deadLetter(topic string, handler *eventHandler, args []interface{}, err error) {
+	if bus.deadLetters == nil || strings.HasPrefix(topic, controlPrefix) || strings.HasPrefix(topic, DeadLetterPrefix) {
+		return
+	}
+	route := bus.deadLetters(topic)
+	if route == topic {
+		return
+	}
+	letter := DeadLetter{topic, publishedArgs(handler, args), err, time.Now(), handlerName(handler.callBack.Pointer())}
+	bus.publishControl(route, letter)
+}
+
+// publishedArgs returns the published arguments of a delivery to handler,
+// without the context or envelope prepended to them
+func publishedArgs(handler *eventHandler, args []interface{}) []interface{} {
+	if _, ok := contextArg(handler, args); ok {
+		return args[1:]
+	}
+	if fnType := handler.callBack.Type(); fnType.NumIn() > 0 && fnType.In(0) == eventType && len(args) > 0 {
+		if ev, ok := args[0].(Event); ok {
+			return ev.Args
+		}
+	}
+	return args
+}
@@ -0,0 +1,95 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithDeadLetters(t *testing.T) {
+	bus := New(WithDeadLetters(nil), WithRecovery(nil))
+	lock := sync.Mutex{}
+	var letters []DeadLetter
+	bus.Subscribe(DeadLetterTopic("orders"), func(letter DeadLetter) {
+		lock.Lock()
+		defer lock.Unlock()
+		letters = append(letters, letter)
+	})
+	bus.Subscribe("orders", func(ctx context.Context, n int) error {
+		if n == 1 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	bus.SubscribeAsync("orders", func(n int) {
+		if n == 2 {
+			panic("broken")
+		}
+	}, false)
+
+	bus.Publish("orders", 1)
+	bus.Publish("orders", 2)
+	bus.Publish("orders", 3)
+	bus.WaitAsync()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(letters) != 2 {
+		t.Fatal(letters)
+	}
+	for i, letter := range letters {
+		if letter.Topic != "orders" || len(letter.Args) != 1 || letter.Args[0] != i+1 || !strings.Contains(letter.Handler, "TestWithDeadLetters") {
+			t.Fatal(letter)
+		}
+		var handlerErr *HandlerError
+		if !errors.As(letter.Reason, &handlerErr) {
+			t.Fatal(letter.Reason)
+		}
+	}
+	var report *PanicReport
+	if !errors.As(letters[1].Reason, &report) {
+		t.Fatal(letters[1].Reason)
+	}
+}
+
+func TestWithDeadLettersRoute(t *testing.T) {
+	bus := New(WithDeadLetters(func(topic string) string { return "failures" }))
+	bus.SubscribeWithConfig("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, Config{Timeout: 5 * time.Millisecond})
+	var reasons []error
+	bus.Subscribe("failures", func(letter DeadLetter) error {
+		reasons = append(reasons, letter.Reason)
+		return errors.New("failed too") // not republished on its own topic
+	})
+	bus.Publish("slow")
+	bus.WaitAsync()
+	if len(reasons) != 1 || !errors.Is(reasons[0], context.DeadlineExceeded) {
+		t.Fatal(reasons)
+	}
+}
+
+func TestDeadLettersOrdered(t *testing.T) {
+	bus := New(WithDeadLetters(nil))
+	var got []int
+	bus.Subscribe(DeadLetterTopic("jobs"), func(letter DeadLetter) {
+		got = append(got, letter.Args[0].(int)) // control events are delivered one at a time
+	})
+	bus.Subscribe("jobs", func(n int) error { return errors.New("failed") })
+	for i := 0; i < 100; i++ {
+		bus.Publish("jobs", i)
+	}
+	bus.WaitAsync()
+	for i, n := range got {
+		if n != i {
+			t.Fatal(got)
+		}
+	}
+	if len(got) != 100 {
+		t.Fatal(len(got))
+	}
+}
@@ -0,0 +1,6 @@
+//go:build eventbus_debug
+
+package eventbus
+
+// debugMode enables the payload checks of debug builds (-tags eventbus_debug)
+const debugMode = true
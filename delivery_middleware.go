@@ -0,0 +1,80 @@
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Delivery - delivery of an event to a single subscription, as seen by DeliveryMiddleware
+type Delivery struct {
+	Topic   string
+	Handler string        // name of the subscribed function
+	Args    []interface{} // copy of the published arguments, owned by the delivery
+}
+
+// DeliverFunc - delivers an event to a subscription. Returns the error returned
+// by the handler, if any.
+type DeliverFunc func(delivery *Delivery) error
+
+// DeliveryMiddleware - wraps the deliveries to a subscription. It may change
+// the delivery's arguments, skip the delivery by not calling next or observe
+// its outcome.
+type DeliveryMiddleware func(next DeliverFunc) DeliverFunc
+
+// WrapSubscription applies middleware to the later deliveries to the
+// subscription of fn on topic (synchronous or async). Middleware runs in the
+// order it was added, the first added being the outermost. The topic may be a
+// deprecated name of its topic, see Alias.
+// Returns error if fn is not subscribed to the topic.
+func (bus *Bus) WrapSubscription(topic string, fn interface{}, middleware ...DeliveryMiddleware) error {
+	topic = bus.canonicalTopic(topic)
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	idx := bus.findHandlerIdx(topic, reflect.ValueOf(fn))
+	if idx < 0 {
+		return fmt.Errorf("handler is not subscribed to topic %s", topic)
+	}
+	bus.handlersOf(topic)[idx].wrap(middleware)
+	return nil
+}
+
+// wrap appends middleware to the delivery middleware of a handler
+func (handler *eventHandler) wrap(middleware []DeliveryMiddleware) {
+	appendMiddleware(&handler.middleware, middleware)
+}
+
+// call calls the handler with args through its delivery middleware, as many
+// times as the topic configuration allows while it returns an error. The
+// context of handlers taking one is bounded by the configured timeout.
+func (bus *Bus) call(handler *eventHandler, topic string, args []interface{}) []reflect.Value {
+	config := bus.resolveConfig(topic, handler.config)
+	args, cancel := withTimeout(handler, args, config.Timeout)
+	defer cancel()
+	attempts := config.MaxAttempts
+	if handler.retry.Load() != nil {
+		attempts = 1 // retried by doPublishRetried
+	}
+	results := bus.callOnce(handler, topic, args)
+	for attempt := 1; attempt < attempts && resultError(results) != nil; attempt++ {
+		results = bus.callOnce(handler, topic, args)
+	}
+	return results
+}
+
+// callOnce calls the handler with args through its delivery middleware
+func (bus *Bus) callOnce(handler *eventHandler, topic string, args []interface{}) []reflect.Value {
+	middleware := bus.deliveryMiddleware(handler)
+	if middleware == nil {
+		return handler.callBack.Call(bus.setUpPublish(handler, topic, args...))
+	}
+	var results []reflect.Value
+	deliver := func(delivery *Delivery) error {
+		results = handler.callBack.Call(bus.setUpPublish(handler, delivery.Topic, delivery.Args...))
+		return resultError(results)
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		deliver = middleware[i](deliver)
+	}
+	deliver(&Delivery{Topic: topic, Handler: handlerName(handler.callBack.Pointer()), Args: append([]interface{}(nil), args...)})
+	return results
+}
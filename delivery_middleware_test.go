@@ -0,0 +1,107 @@
+package eventbus
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWrapSubscription(t *testing.T) {
+	bus := New()
+	calls := make([]string, 0)
+	handler := func(user string) { calls = append(calls, "handler:"+user) }
+	other := func(user string) { calls = append(calls, "other:"+user) }
+	bus.Subscribe("topic", handler)
+	bus.Subscribe("topic", other)
+
+	trace := func(name string) DeliveryMiddleware {
+		return func(next DeliverFunc) DeliverFunc {
+			return func(d *Delivery) error {
+				calls = append(calls, name)
+				return next(d)
+			}
+		}
+	}
+	upper := func(next DeliverFunc) DeliverFunc {
+		return func(d *Delivery) error {
+			d.Args[0] = strings.ToUpper(d.Args[0].(string))
+			return next(d)
+		}
+	}
+	if bus.WrapSubscription("topic", handler, trace("outer"), upper) != nil {
+		t.Fail()
+	}
+	if bus.WrapSubscription("topic", handler, trace("inner")) != nil {
+		t.Fail()
+	}
+	bus.Publish("topic", "bob")
+	expected := []string{"outer", "inner", "handler:BOB", "other:bob"}
+	if len(calls) != len(expected) {
+		t.Fatalf("got %v", calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Fatalf("got %v", calls)
+		}
+	}
+
+	if bus.WrapSubscription("topic", func() {}, upper) == nil {
+		t.Fail()
+	}
+}
+
+
+func TestWrapSubscriptionAlias(t *testing.T) {
+	bus := New()
+	wrapped := false
+	handler := func() {}
+	bus.Subscribe("user.created", handler)
+	bus.Alias("user_created", "user.created")
+	err := bus.WrapSubscription("user_created", handler, func(next DeliverFunc) DeliverFunc {
+		return func(d *Delivery) error {
+			wrapped = true
+			return next(d)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish("user.created")
+	if !wrapped {
+		t.Fatal("middleware not applied")
+	}
+}
+
+func TestWrapSubscriptionSkipAndError(t *testing.T) {
+	bus := New()
+	calls := 0
+	handler := func(allowed bool) error {
+		calls++
+		return errors.New("failed")
+	}
+	bus.SubscribeAsync("topic", handler, false)
+	lock := sync.Mutex{}
+	var handlerErr error
+	bus.WrapSubscription("topic", handler, func(next DeliverFunc) DeliverFunc {
+		return func(d *Delivery) error {
+			if !d.Args[0].(bool) {
+				return nil // unauthorized, skipped
+			}
+			err := next(d)
+			lock.Lock()
+			handlerErr = err
+			lock.Unlock()
+			if d.Topic != "topic" || !strings.Contains(d.Handler, "TestWrapSubscriptionSkipAndError") {
+				t.Error(d)
+			}
+			return err
+		}
+	})
+	bus.Publish("topic", false)
+	bus.Publish("topic", true)
+	bus.WaitAsync()
+	if calls != 1 || handlerErr == nil {
+		t.Fail()
+	}
+}
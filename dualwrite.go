@@ -0,0 +1,83 @@
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TopicDivergence - control topic receiving DivergenceReport events
+const TopicDivergence = "bus:divergence"
+
+// DivergenceReport - published when the old and new handler of a dual-write
+// subscription disagree on an event
+type DivergenceReport struct {
+	Topic string
+	Args  []interface{}
+	Old   []interface{} // results of the old handler
+	New   []interface{} // results of the new handler, nil if it panicked
+	Panic interface{}   // value the new handler panicked with, if any
+}
+
+// SubscribeDualWrite subscribes oldFn to a topic and runs newFn with the same
+// arguments right after it. Their results are compared with equal
+// (reflect.DeepEqual if nil) and a DivergenceReport is published on
+// TopicDivergence whenever they differ. Only the old handler's results count:
+// a panicking new handler is reported, not propagated.
+// Unsubscribe with oldFn removes both handlers.
+// Returns error if the handlers are not functions with the same parameters.
+func (bus *Bus) SubscribeDualWrite(topic string, oldFn, newFn interface{}, equal func(old, new []interface{}) bool) error {
+	oldValue, newValue := reflect.ValueOf(oldFn), reflect.ValueOf(newFn)
+	if oldValue.Kind() != reflect.Func || newValue.Kind() != reflect.Func {
+		return fmt.Errorf("dual write handlers must be of type reflect.Func")
+	}
+	if !sameParameters(oldValue.Type(), newValue.Type()) {
+		return fmt.Errorf("dual write handlers have different parameters: %s and %s", oldValue.Type(), newValue.Type())
+	}
+	if equal == nil {
+		equal = func(old, new []interface{}) bool { return reflect.DeepEqual(old, new) }
+	}
+	callOld, callNew := oldValue.Call, newValue.Call
+	if oldValue.Type().IsVariadic() {
+		callOld, callNew = oldValue.CallSlice, newValue.CallSlice
+	}
+	wrapper := reflect.MakeFunc(oldValue.Type(), func(args []reflect.Value) []reflect.Value {
+		oldResults := callOld(args)
+		newResults, report := callRecovered(callNew, args)
+		old, new := interfaces(oldResults), interfaces(newResults)
+		var recovered interface{}
+		if report != nil {
+			recovered = report.Recovered
+			bus.reportPanic(report.describe(topic, newValue, interfaces(args)))
+		}
+		if report != nil || !equal(old, new) {
+			bus.publishControl(TopicDivergence, DivergenceReport{topic, interfaces(args), old, new, recovered})
+		}
+		return oldResults
+	})
+	return bus.doSubscribe(topic, oldFn, &eventHandler{
+		callBack: wrapper, subscribed: oldValue,
+	})
+}
+
+func sameParameters(a, b reflect.Type) bool {
+	if a.NumIn() != b.NumIn() || a.IsVariadic() != b.IsVariadic() {
+		return false
+	}
+	for i := 0; i < a.NumIn(); i++ {
+		if a.In(i) != b.In(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func interfaces(values []reflect.Value) []interface{} {
+	if values == nil {
+		return nil
+	}
+	result := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		result = append(result, value.Interface())
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSubscribeDualWrite(t *testing.T) {
+	bus := New()
+	var reports []DivergenceReport
+	lock := sync.Mutex{}
+	bus.Subscribe(TopicDivergence, func(report DivergenceReport) {
+		lock.Lock()
+		reports = append(reports, report)
+		lock.Unlock()
+	})
+
+	oldCalls, newCalls := 0, 0
+	oldFn := func(a int) int {
+		oldCalls++
+		return a * 2
+	}
+	newFn := func(a int) int {
+		newCalls++
+		if a == 3 {
+			panic("boom")
+		}
+		if a > 1 {
+			return a + a + 1
+		}
+		return a + a
+	}
+	if bus.SubscribeDualWrite("topic", oldFn, newFn, nil) != nil {
+		t.Fail()
+	}
+	if bus.SubscribeDualWrite("topic", oldFn, func(a string) {}, nil) == nil {
+		t.Fail()
+	}
+	if bus.SubscribeDualWrite("topic", oldFn, "String", nil) == nil {
+		t.Fail()
+	}
+
+	bus.Publish("topic", 1)
+	bus.Publish("topic", 2)
+	bus.Publish("topic", 3)
+	bus.WaitAsync()
+
+	if oldCalls != 3 || newCalls != 3 {
+		t.Fail()
+	}
+	if len(reports) != 2 {
+		t.Fatal(reports)
+	}
+	if reports[0].Args[0] == 3 {
+		reports[0], reports[1] = reports[1], reports[0] // reports are delivered asynchronously
+	}
+	if reports[0].Old[0] != 4 || reports[0].New[0] != 5 || reports[0].Args[0] != 2 {
+		t.Log(reports[0])
+		t.Fail()
+	}
+	if reports[1].Panic != "boom" || reports[1].New != nil {
+		t.Log(reports[1])
+		t.Fail()
+	}
+
+	if bus.Unsubscribe("topic", oldFn) != nil || bus.HasCallback("topic") {
+		t.Fail()
+	}
+}
+
+func TestSubscribeDualWriteVariadic(t *testing.T) {
+	bus := New()
+	sum := func(values ...int) int {
+		total := 0
+		for _, v := range values {
+			total += v
+		}
+		return total
+	}
+	result := make(chan int, 1)
+	bus.SubscribeDualWrite("topic", sum, sum, func(old, new []interface{}) bool {
+		result <- new[0].(int)
+		return true
+	})
+	bus.Publish("topic", 1, 2, 3)
+	if <-result != 6 {
+		t.Fail()
+	}
+}
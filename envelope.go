@@ -0,0 +1,108 @@
+package eventbus
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"time"
+)
+
+// Event - an event published on the bus, with its metadata. Handlers whose
+// first parameter is an Event receive it, see PublishEvent.
+type Event struct {
+	Topic         string
+	Args          []interface{}
+	Time          time.Time // when it was published
+	ID            string
+	CorrelationID string            // ID of the event or request that caused it, if any
+	Headers       map[string]string // arbitrary metadata, e.g. tenant or trace context
+	Tags          map[string]string // routing tags, see TagEvents
+	Deadline      time.Time         // when the event expires, zero if it doesn't
+	// Provenance - topics the event went through before Topic, oldest first,
+	// e.g. the topic of the event whose handler published it, a deprecated
+	// alias or the topic of a remote bus, to diagnose loops and routing
+	Provenance []Hop
+}
+
+var eventType = reflect.TypeOf(Event{})
+
+// eventKey - context key of the envelope given to PublishEvent
+type eventKey struct{}
+
+// PublishEvent runs PublishEvent on package-level bus singleton
+func PublishEvent(ev Event) error {
+	return b.Load().PublishEvent(ev)
+}
+
+// PublishEvent publishes ev.Args on ev.Topic like Publish, attaching the
+// envelope's metadata: handlers whose first parameter is an Event receive the
+// envelope, the others receive the arguments only. An empty ID is generated
+// by the ID generator of the bus (see WithIDGenerator) and a zero Time set
+// to the current time. A Deadline bounds the delivery like the deadline of
+// the context given to PublishCtx: once expired, the event is no longer
+// delivered, e.g. when received from a remote bus or replayed from a store.
+func (bus *Bus) PublishEvent(ev Event) error {
+	if ev.ID == "" {
+		ev.ID = bus.NewID()
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	return bus.publishEvent(context.Background(), ev)
+}
+
+// publishEvent publishes an envelope with complete metadata, see PublishEvent
+func (bus *Bus) publishEvent(ctx context.Context, ev Event) error {
+	if !ev.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, ev.Deadline)
+		// async deliveries outlive the publish, release the context once expired
+		time.AfterFunc(time.Until(ev.Deadline), cancel)
+	}
+	ctx = context.WithValue(WithTags(withProvenance(ctx, ev.Provenance), ev.Tags), eventKey{}, &ev)
+	if bus.spans != nil {
+		ctx = bus.spans.Extract(ctx, ev.Headers) // continue the trace of the event
+	}
+	return bus.PublishCtx(ctx, ev.Topic, ev.Args...)
+}
+
+// Expired reports whether the deadline of the event passed
+func (ev Event) Expired() bool {
+	return !ev.Deadline.IsZero() && !time.Now().Before(ev.Deadline)
+}
+
+// withEvent returns the arguments of a delivery to handler, with the envelope
+// of the event first when the handler takes one the arguments don't start
+// with. Handlers taking only an Event receive the envelope alone. Events not
+// published with PublishEvent get an envelope without ID. The deadline and
+// provenance of the envelope are those of the publish context. With a Tracer,
+// the headers carry the trace context of the publish.
+func (bus *Bus) withEvent(ctx context.Context, handler *eventHandler, topic string, published time.Time, args []interface{}) []interface{} {
+	fnType := handler.callBack.Type()
+	if fnType.NumIn() == 0 || fnType.In(0) != eventType {
+		return args
+	}
+	if len(args) > 0 {
+		if _, ok := args[0].(Event); ok {
+			return args
+		}
+	}
+	ev := Event{Time: published}
+	if envelope, ok := ctx.Value(eventKey{}).(*Event); ok {
+		ev = *envelope
+	}
+	ev.Topic, ev.Args, ev.Tags = topic, args, TagsFromContext(ctx)
+	ev.Deadline, _ = ctx.Deadline()
+	ev.Provenance = Provenance(ctx)
+	if bus.spans != nil {
+		ev.Headers = maps.Clone(ev.Headers)
+		if ev.Headers == nil {
+			ev.Headers = make(map[string]string)
+		}
+		bus.spans.Inject(ctx, ev.Headers)
+	}
+	if fnType.NumIn() == 1 && !fnType.IsVariadic() {
+		return []interface{}{ev}
+	}
+	return append([]interface{}{ev}, args...)
+}
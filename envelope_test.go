@@ -0,0 +1,72 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPublishEvent(t *testing.T) {
+	bus := New()
+	var envelope Event
+	bus.Subscribe("orders", func(ev Event) {
+		envelope = ev
+	})
+	var withArgs []interface{}
+	bus.Subscribe("orders", func(ev Event, id int) {
+		withArgs = []interface{}{ev.CorrelationID, id}
+	})
+	var plain int
+	bus.Subscribe("orders", func(id int) {
+		plain = id
+	})
+
+	err := bus.PublishEvent(Event{Topic: "orders", Args: []interface{}{42}, CorrelationID: "req-1", Headers: map[string]string{"tenant": "acme"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if envelope.Topic != "orders" || envelope.ID == "" || envelope.Time.IsZero() || envelope.Headers["tenant"] != "acme" || envelope.Args[0] != 42 {
+		t.Fatal(envelope)
+	}
+	if len(withArgs) != 2 || withArgs[0] != "req-1" || withArgs[1] != 42 {
+		t.Fatal(withArgs)
+	}
+	if plain != 42 {
+		t.Fatal(plain)
+	}
+
+	previous := envelope.ID
+	bus.Publish("orders", 7)
+	if envelope.ID != "" || envelope.Args[0] != 7 || envelope.Time.IsZero() || plain != 7 {
+		t.Fatal(envelope)
+	}
+	bus.PublishEvent(Event{Topic: "orders", Args: []interface{}{8}})
+	if envelope.ID == "" || envelope.ID == previous {
+		t.Fatal(envelope.ID, previous)
+	}
+}
+
+func TestPublishEventDeadline(t *testing.T) {
+	bus := New()
+	var received []Event
+	bus.Subscribe("orders", func(ev Event) {
+		received = append(received, ev)
+	})
+	deadline := time.Now().Add(time.Hour)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	bus.PublishCtx(ctx, "orders", 1)
+	bus.PublishEvent(Event{Topic: "orders", Args: []interface{}{2}, Deadline: deadline})
+	bus.PublishEvent(Event{Topic: "orders", Args: []interface{}{3}, Deadline: time.Now().Add(-time.Second)})
+	bus.Publish("orders", 4)
+
+	if len(received) != 3 {
+		t.Fatal("expired event delivered", received)
+	}
+	if !received[0].Deadline.Equal(deadline) || !received[1].Deadline.Equal(deadline) || !received[2].Deadline.IsZero() {
+		t.Fatal(received)
+	}
+	if received[0].Expired() || !(Event{Deadline: time.Now()}).Expired() || (Event{}).Expired() {
+		t.Fatal("wrong expiry")
+	}
+}
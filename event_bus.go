@@ -1,12 +1,20 @@
 package eventbus
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
+	"runtime/pprof"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-//Subscriber defines subscription-related bus behavior
+// Subscriber defines subscription-related bus behavior
 type Subscriber interface {
 	Subscribe(topic string, fn interface{}) error
 	SubscribeAsync(topic string, fn interface{}, transactional bool) error
@@ -15,74 +23,186 @@ type Subscriber interface {
 	Unsubscribe(topic string, handler interface{}) error
 }
 
-//Publisher defines publishing-related bus behavior
+// ExclusiveSubscriber defines exclusive subscriptions, see Bus.SubscribeExclusive.
+// Kept out of Subscriber so existing implementations of it still satisfy it.
+type ExclusiveSubscriber interface {
+	SubscribeExclusive(topic string, fn interface{}) error
+}
+
+// Publisher defines publishing-related bus behavior. Publish returns an error
+// since the next major version, see CHANGELOG.md.
 type Publisher interface {
-	Publish(topic string, args ...interface{})
+	Publish(topic string, args ...interface{}) error
 }
 
-//Controller defines bus control behavior (checking handler's presence, synchronization)
+// Controller defines bus control behavior (checking handler's presence, synchronization)
 type Controller interface {
 	HasCallback(topic string) bool
 	WaitAsync()
 }
 
-var b *Bus
+var b atomic.Pointer[Bus] // package-level bus singleton, see Migrate
 
 func init() {
-	b = New()
+	b.Store(New())
 }
 
 // Bus - box for handlers and callbacks.
 type Bus struct {
-	handlers map[string][]*eventHandler
-	lock     sync.Mutex // a lock for the map
-	wg       sync.WaitGroup
+	shards [topicShards]topicShard // handlers by topic, pattern or regex key
+	lock   sync.RWMutex            // held shared by publishes and changes to plain topics, exclusively by changes to the whole bus; released while synchronous handlers run
+	wg     sync.WaitGroup
+	tracer tracer
+	slos   sloRegistry
+
+	scheduler Scheduler
+	controls  serialQueue // control events, delivered one at a time in publishing order
+
+	namespaces namespaces
+	flow       flowControl
+	watchdog   watchdog
+	configs    configs
+	topicNames topicNames
+	hierarchy  topicTree
+	regexes    regexIndex
+	memory     memoryAccounting
+	queues     asyncQueues
+	latencies  latencyRegistry
+	injected   latencyInjection
+	throttle   replayThrottle
+	activity   activity
+
+	noSubscribers noSubscribers
+	parking       parking
+	resources     resources
+	slowConsumers slowConsumers
+	interceptors  interceptors
+	metrics       Metrics
+	spans         Tracer
+	logging       logging
+	deadLetters   func(topic string) string // see WithDeadLetters
+	maxCascade    int                       // see WithMaxCascadeDepth
+	inherit       bool                      // see WithPriorityInheritance
+	ids           IDGenerator               // see WithIDGenerator
+	flags         FeatureFlags              // see WithFeatureFlags
+	store         Store                     // see WithStore
+	storeTopics   map[string]bool
+	quarantine    quarantine
+	replies       replies
+	journals      journals
+	retained      retainedEvents // see PublishRetained
+	closed        bool           // set by Close, guarded by lock
+
+	copyPayloads   bool
+	profilerLabels bool
+	errorSink      func(err *HandlerError)
+	recovery       func(topic string, handler interface{}, recovered interface{})
+	panicLimit     int
+	payloads       payloadTracker // debug builds only
 }
 
+// Option configures a Bus created with New
+type Option func(*Bus)
+
 type eventHandler struct {
 	callBack      reflect.Value
+	subscribed    reflect.Value // function given by the subscriber when callBack wraps it
 	flagOnce      bool
+	until         func(args ...interface{}) bool // removes the handler once true, see SubscribeUntil
+	priority      int                            // see SubscribeWithPriority
 	async         bool
 	transactional bool
-	sync.Mutex    // lock for an event handler - useful for running async callbacks serially
+	exclusive     bool
+	shadow        bool
+	checkpoint    func(topic, barrier string)
+	calls         *sync.WaitGroup // synchronous deliveries started since the last barrier if checkpoint is set, guarded by the bus lock
+	config        *Config         // set by SubscribeWithConfig
+	serial        serialQueue     // queue for an event handler - useful for running async callbacks serially
+	middleware    atomic.Pointer[[]DeliveryMiddleware]
+	panics        atomic.Int32 // consecutive panics recovered by WithRecovery
+	name          string       // set by Subscription.Named
+	after         []string     // names of the subscriptions delivered first
+	pending       atomic.Int32 // async deliveries queued or running, see WithSlowConsumerEviction
+	overSince     atomic.Int64 // unix nanoseconds since pending is over the limit, 0 if it isn't
+	evicted       atomic.Bool
+	tags          map[string]string                 // routing tags events must carry, see Subscription.MatchTags
+	flag          string                            // feature flag gating the deliveries, see Subscription.WithFlag
+	labels        atomic.Pointer[map[string]string] // metric labels of the deliveries, see Subscription.WithMetricLabels
+	retry         atomic.Pointer[retryPolicy]
+	removing      atomic.Bool // set by the publish delivering the last event of a once or until handler
 }
 
 // New returns new Bus with empty handlers.
-func New() *Bus {
-	return &Bus{
-		make(map[string][]*eventHandler),
-		sync.Mutex{},
-		sync.WaitGroup{},
+func New(opts ...Option) *Bus {
+	bus := &Bus{
+		scheduler: goroutineScheduler{},
 	}
+	for _, opt := range opts {
+		opt(bus)
+	}
+	return bus
 }
 
 // doSubscribe handles the subscription logic and is utilized by the public Subscribe functions
 func (bus *Bus) doSubscribe(topic string, fn interface{}, handler *eventHandler) error {
-	bus.lock.Lock()
-	defer bus.lock.Unlock()
+	canonical, err := bus.checkTopic(topic, false)
+	if err != nil {
+		return err
+	}
+	unlock := bus.lockTopic(canonical)
+	defer unlock()
+	_, err = bus.subscribeLocked(topic, fn, handler)
+	return err
+}
+
+// subscribeLocked works like doSubscribe with the topic locked (see
+// lockTopic), returning the canonical name of the topic
+func (bus *Bus) subscribeLocked(topic string, fn interface{}, handler *eventHandler) (string, error) {
+	if bus.closed {
+		return topic, ErrBusClosed
+	}
 	if !(reflect.TypeOf(fn).Kind() == reflect.Func) {
-		return fmt.Errorf("%s is not of type reflect.Func", reflect.TypeOf(fn).Kind())
+		return topic, fmt.Errorf("%s is not of type reflect.Func", reflect.TypeOf(fn).Kind())
 	}
-	bus.handlers[topic] = append(bus.handlers[topic], handler)
-	return nil
+	topic, err := bus.checkTopic(topic, false)
+	if err != nil {
+		return topic, err
+	}
+	if bus.hierarchy.isPattern(topic) {
+		if err := bus.hierarchy.validate(topic); err != nil {
+			return topic, err
+		}
+		bus.hierarchy.add(topic)
+	}
+	bus.setHandlers(topic, append(bus.handlersOf(topic), handler))
+	if bus.ordered(topic) {
+		bus.orderHandlers(topic) // can't fail, the new handler has no dependencies
+	}
+	bus.log(slog.LevelInfo, "subscribed", "topic", topic, "handler", handlerName(handler.callBack.Pointer()), "async", handler.async)
+	if bus.hierarchy.isPattern(topic) {
+		bus.unpark()
+	} else {
+		bus.unpark(topic)
+	}
+	return topic, nil
 }
 
 // Subscribe runs Subscribe on package-level bus singleton
 func Subscribe(topic string, fn interface{}) error {
-	return b.Subscribe(topic, fn)
+	return b.Load().Subscribe(topic, fn)
 }
 
 // Subscribe subscribes to a topic.
 // Returns error if `fn` is not a function.
 func (bus *Bus) Subscribe(topic string, fn interface{}) error {
 	return bus.doSubscribe(topic, fn, &eventHandler{
-		reflect.ValueOf(fn), false, false, false, sync.Mutex{},
+		callBack: reflect.ValueOf(fn),
 	})
 }
 
 // SubscribeAsync runs SubscribeAsync on package-level bus singleton
 func SubscribeAsync(topic string, fn interface{}, transactional bool) error {
-	return b.SubscribeAsync(topic, fn, transactional)
+	return b.Load().SubscribeAsync(topic, fn, transactional)
 }
 
 // SubscribeAsync subscribes to a topic with an asynchronous callback
@@ -91,26 +211,41 @@ func SubscribeAsync(topic string, fn interface{}, transactional bool) error {
 // Returns error if `fn` is not a function.
 func (bus *Bus) SubscribeAsync(topic string, fn interface{}, transactional bool) error {
 	return bus.doSubscribe(topic, fn, &eventHandler{
-		reflect.ValueOf(fn), false, true, transactional, sync.Mutex{},
+		callBack: reflect.ValueOf(fn), async: true, transactional: transactional,
 	})
 }
 
 // SubscribeOnce runs SubscribeOnce on package-level bus singleton
 func SubscribeOnce(topic string, fn interface{}) error {
-	return b.SubscribeOnce(topic, fn)
+	return b.Load().SubscribeOnce(topic, fn)
 }
 
 // SubscribeOnce subscribes to a topic once. Handler will be removed after executing.
 // Returns error if `fn` is not a function.
 func (bus *Bus) SubscribeOnce(topic string, fn interface{}) error {
 	return bus.doSubscribe(topic, fn, &eventHandler{
-		reflect.ValueOf(fn), true, false, false, sync.Mutex{},
+		callBack: reflect.ValueOf(fn), flagOnce: true,
+	})
+}
+
+// SubscribeUntil runs SubscribeUntil on package-level bus singleton
+func SubscribeUntil(topic string, fn interface{}, done func(args ...interface{}) bool) error {
+	return b.Load().SubscribeUntil(topic, fn, done)
+}
+
+// SubscribeUntil subscribes to a topic until done returns true for the
+// arguments of an event. That event is still handled, then the handler is
+// removed before any other event is delivered.
+// Returns error if `fn` is not a function.
+func (bus *Bus) SubscribeUntil(topic string, fn interface{}, done func(args ...interface{}) bool) error {
+	return bus.doSubscribe(topic, fn, &eventHandler{
+		callBack: reflect.ValueOf(fn), until: done,
 	})
 }
 
 // SubscribeOnceAsync runs SubscribeOnceAsync on package-level bus singleton
 func SubscribeOnceAsync(topic string, fn interface{}) error {
-	return b.SubscribeOnceAsync(topic, fn)
+	return b.Load().SubscribeOnceAsync(topic, fn)
 }
 
 // SubscribeOnceAsync subscribes to a topic once with an asynchronous callback
@@ -118,120 +253,640 @@ func SubscribeOnceAsync(topic string, fn interface{}) error {
 // Returns error if `fn` is not a function.
 func (bus *Bus) SubscribeOnceAsync(topic string, fn interface{}) error {
 	return bus.doSubscribe(topic, fn, &eventHandler{
-		reflect.ValueOf(fn), true, true, false, sync.Mutex{},
+		callBack: reflect.ValueOf(fn), flagOnce: true, async: true,
+	})
+}
+
+// SubscribeExclusive runs SubscribeExclusive on package-level bus singleton
+func SubscribeExclusive(topic string, fn interface{}) error {
+	return b.Load().SubscribeExclusive(topic, fn)
+}
+
+// SubscribeExclusive subscribes to a topic as an exclusive handler.
+// Only the earliest registered exclusive handler of a topic is active; the
+// others stay on standby and take over, in registration order, as soon as the
+// active one is unsubscribed. Non-exclusive handlers are not affected.
+// Returns error if `fn` is not a function.
+func (bus *Bus) SubscribeExclusive(topic string, fn interface{}) error {
+	return bus.doSubscribe(topic, fn, &eventHandler{
+		callBack: reflect.ValueOf(fn), exclusive: true,
+	})
+}
+
+// SubscribeShadow runs SubscribeShadow on package-level bus singleton
+func SubscribeShadow(topic string, fn interface{}) error {
+	return b.Load().SubscribeShadow(topic, fn)
+}
+
+// SubscribeShadow subscribes an observation tap to a topic. The handler runs in
+// its own goroutine and is isolated from normal delivery: WaitAsync doesn't wait
+// for it, its panics are only reported on TopicPanic and it is left out of
+// traces and SLOs.
+// Returns error if `fn` is not a function.
+func (bus *Bus) SubscribeShadow(topic string, fn interface{}) error {
+	return bus.doSubscribe(topic, fn, &eventHandler{
+		callBack: reflect.ValueOf(fn), shadow: true,
 	})
 }
 
 // HasCallback runs HasCallback on package-level bus singleton
 func HasCallback(topic string) bool {
-	return b.HasCallback(topic)
+	return b.Load().HasCallback(topic)
 }
 
 // HasCallback returns true if exists any callback subscribed to the topic.
 func (bus *Bus) HasCallback(topic string) bool {
-	bus.lock.Lock()
-	defer bus.lock.Unlock()
-	_, ok := bus.handlers[topic]
-	if ok {
-		return len(bus.handlers[topic]) > 0
+	topic = bus.canonicalTopic(topic)
+	bus.lock.RLock()
+	defer bus.lock.RUnlock()
+	shard := bus.shard(topic)
+	shard.RLock()
+	defer shard.RUnlock()
+	return bus.hasCallback(topic)
+}
+
+// hasCallback works like HasCallback with the bus lock held and the topic's
+// shard locked
+func (bus *Bus) hasCallback(topic string) bool {
+	if len(bus.handlersOf(topic)) > 0 {
+		return true
 	}
-	return false
+	if bus.hierarchy.isPattern(topic) {
+		return false
+	}
+	return len(bus.hierarchy.match(topic)) > 0 || len(bus.regexes.match(topic)) > 0
 }
 
 // Unsubscribe runs Unsubscribe on package-level bus singleton
 func Unsubscribe(topic string, handler interface{}) error {
-	return b.Unsubscribe(topic, handler)
+	return b.Load().Unsubscribe(topic, handler)
 }
 
 // Unsubscribe removes callback defined for a topic.
 // Returns error if there are no callbacks subscribed to the topic.
 func (bus *Bus) Unsubscribe(topic string, handler interface{}) error {
-	bus.lock.Lock()
-	defer bus.lock.Unlock()
-	if _, ok := bus.handlers[topic]; ok && len(bus.handlers[topic]) > 0 {
-		bus.removeHandler(topic, bus.findHandlerIdx(topic, reflect.ValueOf(handler)))
+	topic = bus.canonicalTopic(topic)
+	unlock := bus.lockTopic(topic)
+	defer unlock()
+	if len(bus.handlersOf(topic)) > 0 {
+		idx := bus.findHandlerIdx(topic, reflect.ValueOf(handler))
+		if idx < 0 {
+			return fmt.Errorf("handler is not subscribed to topic %s", topic)
+		}
+		bus.removeHandler(topic, idx)
 		return nil
 	}
 	return fmt.Errorf("topic %s doesn't exist", topic)
 }
 
+// UnsubscribeAll runs UnsubscribeAll on package-level bus singleton
+func UnsubscribeAll(topic string) error {
+	return b.Load().UnsubscribeAll(topic)
+}
+
+// UnsubscribeAll removes every callback defined for a topic.
+// Returns error if there are no callbacks subscribed to the topic.
+func (bus *Bus) UnsubscribeAll(topic string) error {
+	topic = bus.canonicalTopic(topic)
+	unlock := bus.lockTopic(topic)
+	defer unlock()
+	if len(bus.handlersOf(topic)) == 0 {
+		return fmt.Errorf("topic %s doesn't exist", topic)
+	}
+	bus.removeAll(topic)
+	return nil
+}
+
+// Reset runs Reset on package-level bus singleton
+func Reset() {
+	b.Load().Reset()
+}
+
+// Reset removes every callback of every topic, including wildcard and regex
+// subscriptions, and drops the parked events. Settings such as topic rules,
+// configurations and policies are kept.
+func (bus *Bus) Reset() {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	for _, topic := range bus.keys() {
+		bus.removeAll(topic)
+	}
+	bus.parking.Lock()
+	bus.parking.parked = nil
+	bus.parking.Unlock()
+}
+
+// removeAll removes the handlers of a topic, which must be locked (see
+// lockTopic)
+func (bus *Bus) removeAll(topic string) {
+	for l := len(bus.handlersOf(topic)); l > 0; l-- {
+		bus.removeHandler(topic, l-1)
+	}
+}
+
 // Publish runs Publish on package-level bus singleton
-func Publish(topic string, args ...interface{}) {
-	b.Publish(topic, args...)
+func Publish(topic string, args ...interface{}) error {
+	return b.Load().Publish(topic, args...)
 }
 
 // Publish executes callback defined for a topic. Any additional argument will be transferred to the callback.
-func (bus *Bus) Publish(topic string, args ...interface{}) {
-	bus.lock.Lock() // will unlock if handler is not found or always after setUpPublish
-	defer bus.lock.Unlock()
-	if handlers, ok := bus.handlers[topic]; ok {
-		for i, handler := range handlers {
-			if handler.flagOnce {
-				bus.removeHandler(topic, i)
+// Returns error, without publishing, if the topic breaks the topic rules or is
+// a wildcard pattern or the bus is closed, and ErrNoSubscribers under the
+// NoSubscriberError policy.
+func (bus *Bus) Publish(topic string, args ...interface{}) error {
+	return bus.PublishCtx(context.Background(), topic, args...)
+}
+
+// PublishCtx runs PublishCtx on package-level bus singleton
+func PublishCtx(ctx context.Context, topic string, args ...interface{}) error {
+	return b.Load().PublishCtx(ctx, topic, args...)
+}
+
+// PublishCtx works like Publish, passing ctx to the handlers whose first
+// parameter is a context.Context (unless the first argument already is one).
+// Once ctx is done, the remaining handlers are skipped, including async
+// deliveries that didn't start yet.
+func (bus *Bus) PublishCtx(ctx context.Context, topic string, args ...interface{}) error {
+	return bus.intercept(ctx, topic, args, bus.publishChecked)
+}
+
+// publishChecked publishes an event after checking its topic, see PublishCtx
+func (bus *Bus) publishChecked(ctx context.Context, topic string, args []interface{}) error {
+	canonical, err := bus.checkPublish(topic)
+	if err != nil {
+		return err
+	}
+	if canonical != topic {
+		ctx = withHop(ctx, Hop{topic, time.Now(), "alias"})
+	}
+	topic = canonical
+	if err := bus.checkCascade(ctx, topic, args); err != nil {
+		return err
+	}
+	if err := bus.persist(ctx, topic, args); err != nil {
+		return err
+	}
+	if bus.spans != nil {
+		var span Span
+		ctx, span = bus.spans.StartPublish(ctx, topic)
+		defer func() { span.End(err) }()
+	}
+	errs := bus.publish(ctx, topic, args...)
+	if result, ok := ctx.Value(resultKey{}).(*[]error); ok && result != nil {
+		*result = errs
+	}
+	for _, rejected := range errs {
+		if _, ok := rejected.(*HandlerError); !ok {
+			err = rejected // not a handler error: the publish was rejected
+			break
+		}
+	}
+	return err
+}
+
+// checkPublish returns the canonical name of a topic, or an error if it can't be published on
+func (bus *Bus) checkPublish(topic string) (string, error) {
+	topic, err := bus.checkTopic(topic, true)
+	if err != nil {
+		return topic, err
+	}
+	if bus.hierarchy.isPattern(topic) {
+		return topic, fmt.Errorf("can't publish on wildcard topic %s", topic)
+	}
+	return topic, nil
+}
+
+// publish delivers an event to the handlers of a topic and of the topic
+// patterns matching it. Returns the errors returned by synchronous handlers,
+// or the error rejecting the event (ErrBusClosed, no subscriber policy).
+// Publishes only hold the bus lock shared, and the shard locks of the keys
+// they deliver to shared, so they don't contend with each other, only with
+// subscription changes to the same shards and to the whole bus.
+func (bus *Bus) publish(ctx context.Context, topic string, args ...interface{}) (errs []error) {
+	bus.lock.RLock()
+	defer bus.lock.RUnlock()
+	if bus.closed {
+		return []error{ErrBusClosed}
+	}
+	if retain, _ := ctx.Value(retainKey{}).(bool); retain {
+		bus.retain(topic, args)
+	}
+	return bus.publishLocked(ctx, topic, true, args...)
+}
+
+// publishLocked works like publish with the bus lock held shared. The event is
+// queued behind the parked events of the topic being flushed if behindFlush
+// is set.
+func (bus *Bus) publishLocked(ctx context.Context, topic string, behindFlush bool, args ...interface{}) (errs []error) {
+	// a subscription to the topic parks or flushes with its shard locked
+	shard := bus.shard(topic)
+	shard.RLock()
+	if behindFlush && bus.parking.behindFlush(topic, args) {
+		shard.RUnlock()
+		return nil // delivered after the parked events of the topic
+	}
+	bus.activity.begin()
+	defer bus.activity.end()
+	if bus.metrics != nil {
+		bus.published(ctx, topic)
+	}
+	bus.log(slog.LevelDebug, "published", "topic", topic, "args", len(args))
+	published := time.Now()
+	bus.record(topic, published, args)
+	if !bus.hasCallback(topic) {
+		err := bus.dropUnsubscribed(topic, args)
+		shard.RUnlock()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return errs
+	}
+	shard.RUnlock()
+	bus.flow.consume(topic)
+	keys := append([]string{topic}, bus.hierarchy.match(topic)...)
+	keys = append(keys, bus.regexes.match(topic)...)
+	for _, key := range keys {
+		var stopped bool
+		if errs, stopped = bus.deliver(ctx, key, topic, published, errs, args...); stopped {
+			break
+		}
+	}
+	return errs
+}
+
+// deliver delivers an event published on topic to the handlers subscribed to
+// key, appending the errors returned by synchronous handlers to errs. Returns
+// true if a handler stopped the propagation of the event.
+func (bus *Bus) deliver(ctx context.Context, key, topic string, published time.Time, errs []error, args ...interface{}) (_ []error, stopped bool) {
+	shard := bus.shard(key)
+	shard.RLock()
+	defer shard.RUnlock()
+	if handlers, ok := shard.handlers[key]; ok {
+		exclusiveDelivered, released := false, false
+		gathered, handlerCtx := gatheringFrom(ctx), bus.inherited(bus.deeper(handlerContext(ctx)))
+		handlerCtx = withHop(handlerCtx, Hop{topic, published, "handler"})
+		for _, handler := range handlers {
+			if ctx.Err() != nil {
+				break // the publisher gave up, skip the remaining handlers
+			}
+			if !matchTags(ctx, handler) || !bus.flagEnabled(ctx, handler) {
+				continue
+			}
+			if handler.exclusive {
+				if exclusiveDelivered {
+					continue // standby handler
+				}
+				exclusiveDelivered = true
+			}
+			if released && !slices.Contains(shard.handlers[key], handler) {
+				continue // unsubscribed while a synchronous handler ran
+			}
+			if handler.removing.Load() {
+				continue // delivered its last event to a concurrent publish
 			}
-			if !handler.async {
-				bus.doPublish(handler, topic, args...)
+			if handler.flagOnce || (handler.until != nil && handler.until(args...)) {
+				if !handler.removing.CompareAndSwap(false, true) {
+					continue
+				}
+				// removed before the call so concurrent publishes skip it
+				bus.unlocked(shard, func() { bus.detach(key, handler) })
+				released = true
+			}
+			async, transactional := bus.deliveryMode(handler, topic)
+			args := bus.withEvent(ctx, handler, topic, published, withContext(handlerCtx, handler, args))
+			if handler.shadow {
+				passedArguments := bus.setUpPublish(handler, topic, args...)
+				bus.scheduler.Schedule(func() { bus.callShadow(handler, topic, passedArguments) })
+			} else if !async {
+				calls := handler.calls
+				if calls != nil {
+					calls.Add(1) // waited for by the next barrier
+				}
+				var err error
+				bus.unlocked(shard, func() {
+					if calls != nil {
+						defer calls.Done()
+					}
+					err = bus.doPublishRetried(ctx, handler, topic, published, false, args...)
+				})
+				released = true
+				if errors.Is(err, ErrStopPropagation) {
+					stopped = true
+					break
+				} else if err != nil {
+					errs = append(errs, err)
+					gathered.fail(err)
+					bus.deadLetter(topic, handler, args, err)
+				}
 			} else {
-				bus.wg.Add(1)
-				if handler.transactional {
-					handler.Lock()
+				if !bus.queue(handler, topic) {
+					continue // evicted as a slow consumer
+				}
+				slot, err := bus.admitAsync(ctx, topic, shard, &released)
+				if err != nil {
+					bus.dequeue(handler)
+					if err != errDropped {
+						errs = append(errs, err)
+					}
+					continue // the topic's async queue is full
 				}
-				go bus.doPublishAsync(handler, topic, args...)
+				queued, ok := bus.memory.admit(topic, args)
+				if !ok {
+					bus.queues.done(topic, slot)
+					bus.dequeue(handler)
+					continue // over the topic's memory cap
+				}
+				bus.wg.Add(1)
+				bus.started(topic)
+				bus.throttle.queued()
+				bus.activity.begin()
+				gathered.add()
+				scopeFrom(ctx).add()
+				bus.scheduleAsync(ctx, handler, transactional, func() { bus.doPublishAsync(ctx, handler, topic, slot, queued, published, args...) })
 			}
 		}
 	}
+	return errs, stopped
 }
 
-func (bus *Bus) doPublish(handler *eventHandler, topic string, args ...interface{}) {
-	passedArguments := bus.setUpPublish(topic, args...)
-	handler.callBack.Call(passedArguments)
+// unlocked calls fn with the bus lock and a shard lock released, so that
+// synchronous handlers can subscribe, unsubscribe and publish; both must be
+// held shared
+func (bus *Bus) unlocked(shard *topicShard, fn func()) {
+	shard.RUnlock()
+	bus.lock.RUnlock()
+	defer shard.RLock()
+	defer bus.lock.RLock()
+	fn()
+}
+
+// detach removes a handler from a handler map key if it is still there,
+// returning whether it was
+func (bus *Bus) detach(key string, handler *eventHandler) bool {
+	unlock := bus.lockTopic(key)
+	defer unlock()
+	if idx := slices.Index(bus.handlersOf(key), handler); idx >= 0 {
+		bus.removeHandler(key, idx)
+		return true
+	}
+	return false
+}
+
+// doPublish calls a handler, returning a *HandlerError if it returned an error
+func (bus *Bus) doPublish(ctx context.Context, handler *eventHandler, topic string, published time.Time, args ...interface{}) (err error) {
+	if bus.spans != nil {
+		var span Span
+		ctx, span, args = bus.startHandlerSpan(ctx, handler, topic, args)
+		defer func() { span.End(err) }()
+	}
+	if bus.profilerLabels {
+		labelCtx := ctx
+		argCtx, passed := contextArg(handler, args)
+		if passed {
+			labelCtx = argCtx
+		}
+		pprof.Do(labelCtx, handlerLabels(topic, handler), func(labeled context.Context) {
+			if passed {
+				args = append([]interface{}{labeled}, args[1:]...)
+			}
+			err = bus.doPublishLabeled(ctx, handler, topic, published, args...)
+		})
+		return err
+	}
+	return bus.doPublishLabeled(ctx, handler, topic, published, args...)
+}
+
+func (bus *Bus) doPublishLabeled(ctx context.Context, handler *eventHandler, topic string, published time.Time, args ...interface{}) error {
+	if debugMode && !bus.copyPayloads {
+		defer bus.instrument(topic, handler, args)()
+	}
+	traced, slo, stats := bus.isTraced(topic), bus.hasSLO(topic), bus.hasLatencyStats(topic)
+	if !traced && !slo && !stats && bus.metrics == nil && !bus.logging.timed() {
+		results, report := bus.invoke(handler, topic, args)
+		err := handlerError(topic, handler, results, report)
+		if err == nil {
+			gatheringFrom(ctx).collect(results)
+		}
+		return bus.logHandled(topic, handler, err, 0)
+	}
+	bus.log(slog.LevelDebug, "handler started", "topic", topic, "handler", handlerName(handler.callBack.Pointer()))
+	start := time.Now()
+	results, report := bus.invoke(handler, topic, args)
+	end := time.Now()
+	if traced {
+		resultValues := make([]interface{}, 0, len(results))
+		for _, result := range results {
+			resultValues = append(resultValues, result.Interface())
+		}
+		bus.recordTrace(topic, handler, args, resultValues, Provenance(ctx), published, start, end)
+	}
+	if slo {
+		bus.observeSLO(topic, start.Sub(published), end.Sub(start))
+	}
+	if stats {
+		bus.observeLatency(topic, start.Sub(published), end.Sub(published))
+	}
+	err := handlerError(topic, handler, results, report)
+	if err == nil {
+		gatheringFrom(ctx).collect(results)
+	}
+	if bus.metrics != nil {
+		bus.handled(ctx, topic, handler, end.Sub(start), err)
+	}
+	return bus.logHandled(topic, handler, err, end.Sub(start))
 }
 
-func (bus *Bus) doPublishAsync(handler *eventHandler, topic string, args ...interface{}) {
+func (bus *Bus) doPublishAsync(ctx context.Context, handler *eventHandler, topic string, slot, queued *queuedDelivery, published time.Time, args ...interface{}) {
 	defer bus.wg.Done()
-	if handler.transactional {
-		defer handler.Unlock()
+	defer bus.activity.end()
+	defer bus.finished(topic)
+	defer bus.dequeue(handler)
+	bus.throttle.dequeued(time.Since(published))
+	gathered := gatheringFrom(ctx)
+	defer gathered.release()
+	defer scopeFrom(ctx).release()
+	defer bus.queues.done(topic, slot)
+	if !bus.memory.start(topic, queued) {
+		return // dropped to stay under the topic's memory cap
 	}
-	bus.doPublish(handler, topic, args...)
+	defer bus.memory.done(topic, queued)
+	if !bus.queues.start(topic, slot) {
+		return // dropped to make room in the topic's async queue
+	}
+	if !bus.delayDelivery(ctx, topic) || ctx.Err() != nil {
+		return // the publisher gave up before the delivery started
+	}
+	defer bus.watch(topic, handler)()
+	err := bus.doPublishRetried(ctx, handler, topic, published, true, args...)
+	if err == nil {
+		return
+	}
+	gathered.fail(err)
+	bus.deadLetter(topic, handler, args, err)
+	if bus.errorSink != nil {
+		bus.errorSink(err.(*HandlerError))
+	}
+}
+
+// callShadow calls a shadow handler. A panic is reported from the shadow's own
+// goroutine, which WaitAsync doesn't wait for either.
+func (bus *Bus) callShadow(handler *eventHandler, topic string, args []reflect.Value) {
+	if _, report := callRecovered(handler.callBack.Call, args); report != nil && topic != TopicPanic {
+		report.describe(topic, handler.callBack, interfaces(args))
+		bus.publishInternal(TopicPanic, report)
+	}
+}
+
+// publishControl publishes an event emitted by the bus itself (e.g. on a
+// bus: control topic). Delivery is asynchronous so it is safe to call while
+// the bus lock is held, and serialized so control events are delivered in the
+// order they were emitted; WaitAsync waits for it.
+func (bus *Bus) publishControl(topic string, args ...interface{}) {
+	bus.wg.Add(1)
+	bus.activity.begin()
+	bus.controls.push(bus.scheduler, func() {
+		defer bus.wg.Done()
+		defer bus.activity.end()
+		bus.publishInternal(topic, args...)
+	})
 }
 
+// publishInternal publishes an event emitted by the bus itself, which is
+// delivered even while the bus is closing
+func (bus *Bus) publishInternal(topic string, args ...interface{}) {
+	bus.lock.RLock()
+	defer bus.lock.RUnlock()
+	bus.publishLocked(context.Background(), topic, false, args...)
+}
+
+// publishSystem publishes an event on a control topic on behalf of a bus API
+// (e.g. PublishLifecycle), delivered like Publish but bypassing the check
+// reserving control topics to the bus
+func (bus *Bus) publishSystem(topic string, args ...interface{}) {
+	bus.publish(context.Background(), topic, args...)
+}
+
+// removeHandler removes the handler at idx of a topic, which must be locked
+// (see lockTopic)
 func (bus *Bus) removeHandler(topic string, idx int) {
-	if _, ok := bus.handlers[topic]; !ok {
+	handlers := bus.handlersOf(topic)
+	if len(handlers) == 0 {
 		return
 	}
-	l := len(bus.handlers[topic])
+	l := len(handlers)
+	bus.log(slog.LevelInfo, "unsubscribed", "topic", topic, "handler", handlerName(handlers[idx].callBack.Pointer()))
 
-	copy(bus.handlers[topic][idx:], bus.handlers[topic][idx+1:])
-	bus.handlers[topic][l-1] = nil // or the zero value of T
-	bus.handlers[topic] = bus.handlers[topic][:l-1]
+	// copy on write, publishes in progress keep ranging over the old slice
+	bus.setHandlers(topic, slices.Delete(slices.Clone(handlers), idx, idx+1))
+	if l == 1 && bus.hierarchy.isPattern(topic) {
+		bus.hierarchy.remove(topic)
+	}
+	if l == 1 && strings.HasPrefix(topic, regexKeyPrefix) {
+		bus.regexes.remove(topic)
+	}
 }
 
 func (bus *Bus) findHandlerIdx(topic string, callback reflect.Value) int {
-	if _, ok := bus.handlers[topic]; ok {
-		for idx, handler := range bus.handlers[topic] {
+	if handlers := bus.handlersOf(topic); len(handlers) > 0 {
+		for idx, handler := range handlers {
 			if handler.callBack == callback || handler.callBack.Pointer() == callback.Pointer() {
 				return idx
 			}
+			if handler.subscribed.IsValid() && handler.subscribed.Pointer() == callback.Pointer() {
+				return idx
+			}
 		}
 	}
 	return -1
 }
 
-func (bus *Bus) setUpPublish(topic string, args ...interface{}) []reflect.Value {
-
+func (bus *Bus) setUpPublish(handler *eventHandler, topic string, args ...interface{}) []reflect.Value {
+	callbackType := handler.callBack.Type()
 	passedArguments := make([]reflect.Value, 0, len(args))
-	for _, arg := range args {
+	for i, arg := range args {
+		if bus.copyPayloads {
+			arg = copyPayload(arg)
+		}
+		if arg == nil {
+			// a nil interface has no type, pass the zero value of the parameter
+			passedArguments = append(passedArguments, reflect.Zero(parameterType(callbackType, i)))
+			continue
+		}
 		passedArguments = append(passedArguments, reflect.ValueOf(arg))
 	}
 	return passedArguments
 }
 
+// parameterType returns the type of the i-th argument passed to a function of type fn
+func parameterType(fn reflect.Type, i int) reflect.Type {
+	if fn.IsVariadic() && i >= fn.NumIn()-1 {
+		return fn.In(fn.NumIn() - 1).Elem()
+	}
+	if i >= fn.NumIn() {
+		return reflect.TypeOf((*interface{})(nil)).Elem() // Call reports the count mismatch
+	}
+	return fn.In(i)
+}
+
 // WaitAsync runs WaitAsync on package-level bus singleton
 func WaitAsync() {
-	b.WaitAsync()
+	b.Load().WaitAsync()
 }
 
 // WaitAsync waits for all async callbacks to complete
 func (bus *Bus) WaitAsync() {
 	bus.wg.Wait()
 }
+
+// PendingWorkError - returned when waiting for async callbacks gave up
+type PendingWorkError struct {
+	Pending map[string]int // async deliveries queued or running per topic
+	Err     error          // why the wait gave up, e.g. context.DeadlineExceeded
+}
+
+func (e *PendingWorkError) Error() string {
+	topics := make([]string, 0, len(e.Pending))
+	for topic, n := range e.Pending {
+		topics = append(topics, fmt.Sprintf("%s (%d)", topic, n))
+	}
+	slices.Sort(topics)
+	return fmt.Sprintf("%v with async work pending on %s", e.Err, strings.Join(topics, ", "))
+}
+
+func (e *PendingWorkError) Unwrap() error {
+	return e.Err
+}
+
+// WaitAsyncCtx runs WaitAsyncCtx on package-level bus singleton
+func WaitAsyncCtx(ctx context.Context) error {
+	return b.Load().WaitAsyncCtx(ctx)
+}
+
+// WaitAsyncCtx waits for all async callbacks to complete or ctx to be done.
+// Returns a *PendingWorkError wrapping ctx.Err() and listing the topics with
+// async deliveries left if ctx is done first.
+func (bus *Bus) WaitAsyncCtx(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		bus.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return &PendingWorkError{bus.flow.pending(), ctx.Err()}
+	}
+}
+
+// WaitAsyncTimeout runs WaitAsyncTimeout on package-level bus singleton
+func WaitAsyncTimeout(timeout time.Duration) error {
+	return b.Load().WaitAsyncTimeout(timeout)
+}
+
+// WaitAsyncTimeout works like WaitAsyncCtx, giving up after timeout
+func (bus *Bus) WaitAsyncTimeout(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return bus.WaitAsyncCtx(ctx)
+}
@@ -1,6 +1,11 @@
 package eventbus
 
 import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -139,3 +144,265 @@ func TestSubscribeAsync(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestSubscribeExclusive(t *testing.T) {
+	bus := New()
+	calls := make([]string, 0)
+	first := func() { calls = append(calls, "first") }
+	second := func() { calls = append(calls, "second") }
+	if bus.SubscribeExclusive("topic", first) != nil {
+		t.Fail()
+	}
+	if bus.SubscribeExclusive("topic", second) != nil {
+		t.Fail()
+	}
+	if bus.SubscribeExclusive("topic", "String") == nil {
+		t.Fail()
+	}
+	bus.Subscribe("topic", func() { calls = append(calls, "shared") })
+
+	bus.Publish("topic")
+	if len(calls) != 2 || calls[0] != "first" || calls[1] != "shared" {
+		t.Log(calls)
+		t.Fail()
+	}
+
+	calls = calls[:0]
+	bus.Unsubscribe("topic", first)
+	bus.Publish("topic")
+	if len(calls) != 2 || calls[0] != "second" || calls[1] != "shared" {
+		t.Log(calls)
+		t.Fail()
+	}
+}
+
+func TestSubscribeShadow(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	observed := make(chan int, 2)
+	bus.SubscribeShadow("topic", func(a int) {
+		if a == 1 {
+			panic("shadow failure")
+		}
+		<-release
+		observed <- a
+	})
+	delivered := 0
+	bus.Subscribe("topic", func(a int) {
+		delivered++
+	})
+
+	bus.Publish("topic", 1)
+	bus.Publish("topic", 2)
+	bus.WaitAsync() // must not wait for the blocked shadow handler
+
+	if delivered != 2 {
+		t.Fail()
+	}
+	close(release)
+	select {
+	case a := <-observed:
+		if a != 2 {
+			t.Fail()
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shadow handler not called")
+	}
+}
+
+func TestPublishNilArgument(t *testing.T) {
+	bus := New()
+	called := false
+	bus.Subscribe("topic", func(err error, values ...[]int) {
+		called = err == nil && len(values) == 1 && values[0] == nil
+	})
+	bus.Publish("topic", nil, nil)
+	if !called {
+		t.Fail()
+	}
+}
+
+func TestSubscribeOnceAmongHandlers(t *testing.T) {
+	bus := New()
+	calls := make([]string, 0)
+	first := func() { calls = append(calls, "once") }
+	second := func() { calls = append(calls, "always") }
+	bus.SubscribeOnce("topic", first)
+	bus.Subscribe("topic", second)
+	bus.Publish("topic")
+	bus.Publish("topic")
+	if len(calls) != 3 || calls[0] != "once" || calls[1] != "always" || calls[2] != "always" {
+		t.Fail()
+	}
+}
+
+func TestUnsubscribeUnknownHandler(t *testing.T) {
+	bus := New()
+	bus.Subscribe("topic", func() {})
+	if bus.Unsubscribe("topic", func(int) {}) == nil {
+		t.Fail()
+	}
+	if !bus.HasCallback("topic") {
+		t.Fail()
+	}
+}
+
+func TestUnsubscribeAll(t *testing.T) {
+	bus := New()
+	bus.Subscribe("topic", func() {})
+	bus.SubscribeAsync("topic", func() {}, false)
+	bus.Subscribe("other", func() {})
+	if bus.UnsubscribeAll("topic") != nil || bus.HasCallback("topic") || !bus.HasCallback("other") {
+		t.Fail()
+	}
+	if bus.UnsubscribeAll("topic") == nil {
+		t.Fail()
+	}
+}
+
+func TestReset(t *testing.T) {
+	bus := New(WithSeparator("/"))
+	bus.Subscribe("a/b", func() {})
+	bus.Subscribe("a/#", func() {})
+	bus.SubscribeRegex("^c", func() {})
+	bus.SetParking("parked", 0, 0)
+	bus.Publish("parked")
+	bus.Reset()
+	if bus.HasCallback("a/b") || bus.HasCallback("a/c") || bus.HasCallback("cde") || bus.Parked("parked") != 0 {
+		t.Fail()
+	}
+	calls := 0
+	bus.Subscribe("a/+", func() { calls++ })
+	bus.Publish("a/b")
+	if calls != 1 {
+		t.Fail()
+	}
+}
+
+func TestSubscribeUntil(t *testing.T) {
+	bus := New()
+	var states []string
+	bus.SubscribeUntil("job:state", func(state string) {
+		states = append(states, state)
+	}, func(args ...interface{}) bool {
+		return args[0] == "done"
+	})
+	bus.Publish("job:state", "queued")
+	bus.Publish("job:state", "running")
+	bus.Publish("job:state", "done")
+	bus.Publish("job:state", "queued")
+	if len(states) != 3 || states[2] != "done" || bus.HasCallback("job:state") {
+		t.Fatal(states)
+	}
+}
+
+func TestWaitAsyncTimeout(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	bus.SubscribeAsync("stuck", func() { <-release }, false)
+	bus.SubscribeAsync("fast", func() {}, false)
+	bus.Publish("stuck")
+	bus.Publish("stuck")
+	bus.Publish("fast")
+
+	err := bus.WaitAsyncTimeout(20 * time.Millisecond)
+	var pending *PendingWorkError
+	if !errors.As(err, &pending) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	if len(pending.Pending) != 1 || pending.Pending["stuck"] != 2 {
+		t.Fatal(pending.Pending)
+	}
+	if !strings.Contains(err.Error(), "stuck (2)") {
+		t.Fatal(err)
+	}
+
+	close(release)
+	if bus.WaitAsyncCtx(context.Background()) != nil {
+		t.Fail()
+	}
+}
+
+func TestReentrantSubscription(t *testing.T) {
+	bus := New()
+	var got []string
+	second := func() { got = append(got, "second") }
+	bus.Subscribe("topic", func() {
+		got = append(got, "first")
+		bus.Unsubscribe("topic", second)
+		bus.Subscribe("other", func() { got = append(got, "other") })
+		bus.Publish("other")
+	})
+	bus.Subscribe("topic", second)
+	bus.SubscribeOnce("topic", func() {
+		got = append(got, "once")
+		bus.Publish("topic")
+	})
+
+	bus.Publish("topic")
+	if strings.Join(got, ",") != "first,other,once,first,other,other" {
+		t.Fatal(got)
+	}
+}
+
+func TestPublishUnrelatedTopicWhileHandling(t *testing.T) {
+	bus := New()
+	entered, release := make(chan struct{}), make(chan struct{})
+	bus.Subscribe("slow", func() {
+		close(entered)
+		<-release
+	})
+	bus.Subscribe("fast", func() {})
+	go bus.Publish("slow")
+	<-entered
+	done := make(chan struct{})
+	go func() {
+		bus.Publish("fast")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked by a handler of another topic")
+	}
+	close(release)
+}
+
+func TestConcurrentPublishes(t *testing.T) {
+	bus := New()
+	entered, release := make(chan struct{}), make(chan struct{})
+	bus.SubscribeUntil("slow", func() {}, func(args ...interface{}) bool {
+		close(entered)
+		<-release // holds up the publish before the handler runs
+		return true
+	})
+	bus.Subscribe("fast", func() {})
+	go bus.Publish("slow")
+	<-entered
+	done := make(chan struct{})
+	go func() {
+		bus.Publish("fast")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked by a publish on another topic")
+	}
+	close(release)
+
+	var calls atomic.Int32
+	bus.SubscribeOnce("once", func() { calls.Add(1) })
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bus.Publish("once")
+		}()
+	}
+	wg.Wait()
+	if calls.Load() != 1 || bus.HasCallback("once") {
+		t.Fatal(calls.Load())
+	}
+}
@@ -0,0 +1,8 @@
+// Package eventbusotel traces an event bus with OpenTelemetry.
+//
+// It depends on go.opentelemetry.io/otel, so it is only built with the
+// eventbus_otel build tag (-tags eventbus_otel), keeping the dependency out of
+// the builds of users without OpenTelemetry:
+//
+//	bus := eventbus.New(eventbus.WithTracer(eventbusotel.New(nil)))
+package eventbusotel
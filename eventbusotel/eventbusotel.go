@@ -0,0 +1,94 @@
+//go:build eventbus_otel
+
+package eventbusotel
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	eventbus "github.com/asaskevich/EventBus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName - instrumentation scope of the spans
+const ScopeName = "github.com/asaskevich/EventBus"
+
+// LabelPrefix - prefix of the span attributes carrying the metric labels of
+// the event, see eventbus.WithMetricLabels
+const LabelPrefix = "eventbus.label."
+
+// Tracer - eventbus.Tracer implementation starting OpenTelemetry spans and
+// propagating their context through event headers
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+var _ eventbus.Tracer = (*Tracer)(nil)
+
+// New - create a Tracer using provider, or the global tracer provider and
+// propagator if provider is nil
+func New(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &Tracer{provider.Tracer(ScopeName), otel.GetTextMapPropagator()}
+}
+
+// StartPublish implements eventbus.Tracer
+func (t *Tracer) StartPublish(ctx context.Context, topic string) (context.Context, eventbus.Span) {
+	ctx, span := t.tracer.Start(ctx, "publish "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("messaging.destination.name", topic)),
+		trace.WithAttributes(labels(ctx)...))
+	return ctx, endSpan{span}
+}
+
+// StartHandler implements eventbus.Tracer
+func (t *Tracer) StartHandler(ctx context.Context, topic, handler string) (context.Context, eventbus.Span) {
+	ctx, span := t.tracer.Start(ctx, "process "+topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.destination.name", topic),
+			attribute.String("code.function", handler)),
+		trace.WithAttributes(labels(ctx)...))
+	return ctx, endSpan{span}
+}
+
+// labels returns the span attributes of the metric labels carried by ctx
+func labels(ctx context.Context) []attribute.KeyValue {
+	labels := eventbus.MetricLabelsFromContext(ctx)
+	attributes := make([]attribute.KeyValue, 0, len(labels))
+	for _, name := range slices.Sorted(maps.Keys(labels)) {
+		attributes = append(attributes, attribute.String(LabelPrefix+name, labels[name]))
+	}
+	return attributes
+}
+
+// Inject implements eventbus.Tracer
+func (t *Tracer) Inject(ctx context.Context, headers map[string]string) {
+	t.propagator.Inject(ctx, propagation.MapCarrier(headers))
+}
+
+// Extract implements eventbus.Tracer
+func (t *Tracer) Extract(ctx context.Context, headers map[string]string) context.Context {
+	return t.propagator.Extract(ctx, propagation.MapCarrier(headers))
+}
+
+// endSpan - eventbus.Span ending an OpenTelemetry span
+type endSpan struct {
+	span trace.Span
+}
+
+func (s endSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
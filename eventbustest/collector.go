@@ -0,0 +1,253 @@
+package eventbustest
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+// Event - event recorded by a Collector
+type Event struct {
+	Topic string
+	Args  []interface{}
+	Time  time.Time
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%s %v", e.Topic, e.Args)
+}
+
+// Expectation - assertion over the sequence of events recorded by a Collector
+type Expectation interface {
+	Satisfied(events []Event) bool
+	String() string
+}
+
+// Matcher - predicate over a single recorded event. As an Expectation it is
+// satisfied when any recorded event matches.
+type Matcher interface {
+	Expectation
+	Match(event Event) bool
+}
+
+// ArgMatcher - predicate usable in place of an expected argument in HavePayload
+type ArgMatcher func(arg interface{}) bool
+
+type eventMatcher struct {
+	description string
+	match       func(event Event) bool
+}
+
+func (m eventMatcher) Match(event Event) bool {
+	return m.match(event)
+}
+
+func (m eventMatcher) Satisfied(events []Event) bool {
+	for _, event := range events {
+		if m.match(event) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m eventMatcher) String() string {
+	return m.description
+}
+
+// OnTopic matches events published on a topic matching pattern, with the
+// path.Match syntax (e.g. "user.*")
+func OnTopic(pattern string) Matcher {
+	return eventMatcher{
+		description: fmt.Sprintf("event on %q", pattern),
+		match: func(event Event) bool {
+			ok, _ := path.Match(pattern, event.Topic)
+			return ok
+		},
+	}
+}
+
+// HavePayload matches events published with exactly the expected arguments.
+// Arguments are compared with reflect.DeepEqual, an ArgMatcher in place of an
+// argument checks it instead.
+func HavePayload(expected ...interface{}) Matcher {
+	return eventMatcher{
+		description: fmt.Sprintf("event with payload %v", expected),
+		match: func(event Event) bool {
+			if len(event.Args) != len(expected) {
+				return false
+			}
+			for i, arg := range event.Args {
+				if matcher, ok := expected[i].(ArgMatcher); ok {
+					if !matcher(arg) {
+						return false
+					}
+				} else if !reflect.DeepEqual(arg, expected[i]) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+}
+
+// AllOf matches events matched by every matcher
+func AllOf(matchers ...Matcher) Matcher {
+	descriptions := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		descriptions = append(descriptions, m.String())
+	}
+	return eventMatcher{
+		description: strings.Join(descriptions, " and "),
+		match: func(event Event) bool {
+			for _, m := range matchers {
+				if !m.Match(event) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+}
+
+type inOrder []Matcher
+
+// InOrder expects events matching the matchers in the given order. Other
+// events may be recorded in between.
+func InOrder(matchers ...Matcher) Expectation {
+	return inOrder(matchers)
+}
+
+func (o inOrder) Satisfied(events []Event) bool {
+	next := 0
+	for _, event := range events {
+		if next < len(o) && o[next].Match(event) {
+			next++
+		}
+	}
+	return next == len(o)
+}
+
+func (o inOrder) String() string {
+	descriptions := make([]string, 0, len(o))
+	for _, m := range o {
+		descriptions = append(descriptions, m.String())
+	}
+	return "in order: " + strings.Join(descriptions, ", then ")
+}
+
+type within struct {
+	timeout time.Duration
+	Expectation
+}
+
+// Within gives the events expected by expectation up to timeout to be
+// recorded, e.g. when they are published by async handlers
+func Within(timeout time.Duration, expectation Expectation) Expectation {
+	return within{timeout, expectation}
+}
+
+func (w within) String() string {
+	return fmt.Sprintf("%s within %s", w.Expectation, w.timeout)
+}
+
+// Collector - records the events published on topics of a bus for assertions.
+// It subscribes synchronously, so events are recorded in publishing order.
+type Collector struct {
+	t       testing.TB
+	lock    sync.Mutex
+	events  []Event
+	changed chan struct{}
+}
+
+// NewCollector returns a Collector recording the events published on topics.
+// Failed assertions are reported to t.
+func NewCollector(t testing.TB, bus eventbus.Subscriber, topics ...string) *Collector {
+	c := &Collector{t: t, changed: make(chan struct{})}
+	for _, topic := range topics {
+		topic := topic
+		if err := bus.Subscribe(topic, func(args ...interface{}) {
+			c.record(Event{Topic: topic, Args: args, Time: time.Now()})
+		}); err != nil {
+			t.Fatalf("eventbustest: subscribing collector to %s: %v", topic, err)
+		}
+	}
+	return c
+}
+
+func (c *Collector) record(event Event) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.events = append(c.events, event)
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// Events returns the events recorded so far
+func (c *Collector) Events() []Event {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]Event(nil), c.events...)
+}
+
+// Reset forgets the recorded events
+func (c *Collector) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.events = nil
+}
+
+// Assert checks the expectations against the recorded events, reporting the
+// unmet ones to the test. Returns true if all of them are met.
+func (c *Collector) Assert(expectations ...Expectation) bool {
+	c.t.Helper()
+	ok := true
+	for _, expectation := range expectations {
+		if !c.await(expectation) {
+			c.t.Errorf("eventbustest: expected %s, recorded:%s", expectation, c.describe())
+			ok = false
+		}
+	}
+	return ok
+}
+
+// await checks expectation, waiting for more events when it is a Within
+func (c *Collector) await(expectation Expectation) bool {
+	w, ok := expectation.(within)
+	if !ok {
+		return expectation.Satisfied(c.Events())
+	}
+	deadline := time.After(w.timeout)
+	for {
+		c.lock.Lock()
+		satisfied, changed := w.Satisfied(c.events), c.changed
+		c.lock.Unlock()
+		if satisfied {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			return false
+		}
+	}
+}
+
+func (c *Collector) describe() string {
+	events := c.Events()
+	if len(events) == 0 {
+		return " no events"
+	}
+	var s strings.Builder
+	for _, event := range events {
+		s.WriteString("\n\t")
+		s.WriteString(event.String())
+	}
+	return s.String()
+}
@@ -0,0 +1,76 @@
+package eventbustest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+// recorder captures the failures reported by a Collector
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestCollector(t *testing.T) {
+	bus := eventbus.New()
+	c := NewCollector(t, bus, "user.created", "user.deleted", "order.placed")
+	bus.Publish("user.created", "bob", 1)
+	bus.Publish("order.placed", 42)
+	bus.Publish("user.deleted", "bob")
+
+	c.Assert(
+		OnTopic("user.*"),
+		AllOf(OnTopic("user.created"), HavePayload("bob", 1)),
+		HavePayload(ArgMatcher(func(arg interface{}) bool { return arg.(int) > 40 })),
+		InOrder(OnTopic("user.created"), OnTopic("user.deleted")),
+	)
+	if len(c.Events()) != 3 {
+		t.Fail()
+	}
+	c.Reset()
+	if len(c.Events()) != 0 {
+		t.Fail()
+	}
+}
+
+func TestCollectorFailures(t *testing.T) {
+	bus := eventbus.New()
+	r := &recorder{TB: t}
+	c := NewCollector(r, bus, "a", "b")
+	bus.Publish("a", 1)
+	bus.Publish("b", 2)
+
+	if c.Assert(InOrder(OnTopic("b"), OnTopic("a"))) {
+		t.Fail()
+	}
+	if c.Assert(AllOf(OnTopic("a"), HavePayload(2))) {
+		t.Fail()
+	}
+	if c.Assert(Within(10*time.Millisecond, OnTopic("c"))) {
+		t.Fail()
+	}
+	if len(r.failures) != 3 {
+		t.Fatalf("got failures %v", r.failures)
+	}
+}
+
+func TestCollectorWithinAsync(t *testing.T) {
+	bus := eventbus.New()
+	c := NewCollector(t, bus, "done")
+	bus.SubscribeAsync("work", func(n int) {
+		time.Sleep(20 * time.Millisecond)
+		bus.Publish("done", n*2)
+	}, false)
+	bus.Publish("work", 21)
+	c.Assert(Within(time.Second, AllOf(OnTopic("done"), HavePayload(42))))
+	bus.WaitAsync()
+}
@@ -0,0 +1,284 @@
+// Package eventbustest provides utilities for testing code built on the event bus
+// and for testing alternative bus implementations.
+//
+// Built with the eventbus_rapid build tag (-tags eventbus_rapid),
+// CheckProperties generates operation sequences with pgregory.net/rapid,
+// shrinking failing ones to a minimal sequence; without it, it falls back on
+// testing/quick, keeping the dependency out of other builds.
+package eventbustest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+// Bus - behavior a bus implementation must provide to run the conformance suite
+type Bus interface {
+	eventbus.Subscriber
+	eventbus.Publisher
+	eventbus.Controller
+}
+
+// Closer - implemented by buses that can be shut down; the conformance suite
+// checks their Close semantics too
+type Closer interface {
+	Close(ctx context.Context) error
+}
+
+// asyncTimeout bounds how long the suite waits for async deliveries
+const asyncTimeout = 5 * time.Second
+
+// RunConformance runs the conformance suite as subtests of t, checking that the
+// buses returned by factory behave like the in-memory bus. factory is called
+// once per subtest.
+func RunConformance(t *testing.T, factory func() Bus) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, bus Bus)
+	}{
+		{"SubscribeRejectsNonFunc", testSubscribeRejectsNonFunc},
+		{"HasCallback", testHasCallback},
+		{"SyncOrdering", testSyncOrdering},
+		{"SubscribeOnce", testSubscribeOnce},
+		{"SubscribeOnceAsync", testSubscribeOnceAsync},
+		{"Unsubscribe", testUnsubscribe},
+		{"UnsubscribeDuringDelivery", testUnsubscribeDuringDelivery},
+		{"TransactionalOrdering", testTransactionalOrdering},
+		{"WaitAsync", testWaitAsync},
+		{"SubscribeExclusive", testSubscribeExclusive},
+		{"Close", testClose},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, factory())
+		})
+	}
+}
+
+func testSubscribeRejectsNonFunc(t *testing.T, bus Bus) {
+	if bus.Subscribe("topic", "String") == nil {
+		t.Error("Subscribe accepted a non-function handler")
+	}
+	if bus.SubscribeAsync("topic", 42, false) == nil {
+		t.Error("SubscribeAsync accepted a non-function handler")
+	}
+	if bus.HasCallback("topic") {
+		t.Error("rejected handler was registered")
+	}
+}
+
+func testHasCallback(t *testing.T, bus Bus) {
+	if bus.HasCallback("topic") {
+		t.Error("HasCallback is true before subscribing")
+	}
+	bus.Subscribe("topic", func() {})
+	if !bus.HasCallback("topic") || bus.HasCallback("other") {
+		t.Error("HasCallback doesn't reflect subscriptions")
+	}
+}
+
+func testSyncOrdering(t *testing.T, bus Bus) {
+	calls := make([]int, 0)
+	for i := 0; i < 3; i++ {
+		i := i
+		bus.Subscribe("topic", func(n int) {
+			calls = append(calls, n*10+i)
+		})
+	}
+	bus.Publish("topic", 1)
+	bus.Publish("topic", 2)
+	expected := []int{10, 11, 12, 20, 21, 22}
+	if len(calls) != len(expected) {
+		t.Fatalf("got %v, want %v", calls, expected)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Fatalf("got %v, want %v", calls, expected)
+		}
+	}
+}
+
+func testSubscribeOnce(t *testing.T, bus Bus) {
+	calls := 0
+	bus.SubscribeOnce("topic", func() { calls++ })
+	bus.Publish("topic")
+	bus.Publish("topic")
+	if calls != 1 {
+		t.Errorf("once handler called %d times", calls)
+	}
+	if bus.HasCallback("topic") {
+		t.Error("once handler still subscribed after delivery")
+	}
+}
+
+func testSubscribeOnceAsync(t *testing.T, bus Bus) {
+	lock := sync.Mutex{}
+	calls := 0
+	bus.SubscribeOnceAsync("topic", func() {
+		lock.Lock()
+		calls++
+		lock.Unlock()
+	})
+	bus.Publish("topic")
+	bus.Publish("topic")
+	waitAsync(t, bus)
+	if calls != 1 {
+		t.Errorf("once handler called %d times", calls)
+	}
+}
+
+func testUnsubscribe(t *testing.T, bus Bus) {
+	calls := 0
+	handler := func() { calls++ }
+	bus.Subscribe("topic", handler)
+	if err := bus.Unsubscribe("topic", handler); err != nil {
+		t.Errorf("Unsubscribe failed: %v", err)
+	}
+	if bus.Unsubscribe("topic", handler) == nil {
+		t.Error("Unsubscribe of an unsubscribed handler didn't fail")
+	}
+	bus.Publish("topic")
+	if calls != 0 || bus.HasCallback("topic") {
+		t.Error("unsubscribed handler still receives events")
+	}
+}
+
+func testUnsubscribeDuringDelivery(t *testing.T, bus Bus) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	lock := sync.Mutex{}
+	calls := 0
+	handler := func() {
+		lock.Lock()
+		calls++
+		first := calls == 1
+		lock.Unlock()
+		if first {
+			close(started)
+			<-release
+		}
+	}
+	bus.SubscribeAsync("topic", handler, false)
+	bus.Publish("topic")
+	select {
+	case <-started:
+	case <-time.After(asyncTimeout):
+		t.Fatal("async handler not started")
+	}
+	if err := bus.Unsubscribe("topic", handler); err != nil {
+		t.Fatalf("Unsubscribe during delivery failed: %v", err)
+	}
+	close(release)
+	waitAsync(t, bus)
+	bus.Publish("topic")
+	waitAsync(t, bus)
+	if calls != 1 {
+		t.Errorf("handler called %d times, want the in-flight delivery only", calls)
+	}
+}
+
+func testTransactionalOrdering(t *testing.T, bus Bus) {
+	lock := sync.Mutex{}
+	calls := make([]int, 0)
+	bus.SubscribeAsync("topic", func(n int, delay time.Duration) {
+		time.Sleep(delay)
+		lock.Lock()
+		calls = append(calls, n)
+		lock.Unlock()
+	}, true)
+	bus.Publish("topic", 1, 20*time.Millisecond)
+	bus.Publish("topic", 2, time.Duration(0))
+	bus.Publish("topic", 3, time.Duration(0))
+	waitAsync(t, bus)
+	if len(calls) != 3 || calls[0] != 1 || calls[1] != 2 || calls[2] != 3 {
+		t.Errorf("transactional deliveries ran as %v", calls)
+	}
+}
+
+func testWaitAsync(t *testing.T, bus Bus) {
+	lock := sync.Mutex{}
+	calls := 0
+	bus.SubscribeAsync("topic", func() {
+		time.Sleep(10 * time.Millisecond)
+		lock.Lock()
+		calls++
+		lock.Unlock()
+	}, false)
+	for i := 0; i < 5; i++ {
+		bus.Publish("topic")
+	}
+	waitAsync(t, bus)
+	if calls != 5 {
+		t.Errorf("WaitAsync returned after %d of 5 deliveries", calls)
+	}
+}
+
+func testSubscribeExclusive(t *testing.T, bus Bus) {
+	exclusive, ok := bus.(eventbus.ExclusiveSubscriber)
+	if !ok {
+		t.Skip("bus doesn't implement SubscribeExclusive")
+	}
+	calls := make([]string, 0)
+	active := func() { calls = append(calls, "active") }
+	standby := func() { calls = append(calls, "standby") }
+	exclusive.SubscribeExclusive("topic", active)
+	exclusive.SubscribeExclusive("topic", standby)
+	bus.Publish("topic")
+	bus.Unsubscribe("topic", active)
+	bus.Publish("topic")
+	if len(calls) != 2 || calls[0] != "active" || calls[1] != "standby" {
+		t.Errorf("exclusive deliveries went to %v", calls)
+	}
+}
+
+// testClose checks that Close drains the async deliveries, that the closed bus
+// rejects publishes and subscriptions, and that closing it again fails
+func testClose(t *testing.T, bus Bus) {
+	closer, ok := bus.(Closer)
+	if !ok {
+		t.Skip("bus doesn't implement Close")
+	}
+	done := make(chan struct{}, 1)
+	bus.SubscribeAsync("topic", func() {
+		time.Sleep(10 * time.Millisecond)
+		done <- struct{}{}
+	}, false)
+	bus.Publish("topic")
+	ctx, cancel := context.WithTimeout(context.Background(), asyncTimeout)
+	defer cancel()
+	if err := closer.Close(ctx); err != nil {
+		t.Fatalf("Close returned %v", err)
+	}
+	if len(done) != 1 {
+		t.Error("Close returned before the async delivery finished")
+	}
+	if err := bus.Publish("topic"); !errors.Is(err, eventbus.ErrBusClosed) {
+		t.Errorf("Publish after Close returned %v", err)
+	}
+	if err := bus.Subscribe("topic", func() {}); !errors.Is(err, eventbus.ErrBusClosed) {
+		t.Errorf("Subscribe after Close returned %v", err)
+	}
+	if err := closer.Close(ctx); !errors.Is(err, eventbus.ErrBusClosed) {
+		t.Errorf("second Close returned %v", err)
+	}
+}
+
+// waitAsync fails the test if WaitAsync doesn't return in time
+func waitAsync(t *testing.T, bus Bus) {
+	done := make(chan struct{})
+	go func() {
+		bus.WaitAsync()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(asyncTimeout):
+		t.Fatal("WaitAsync didn't return")
+	}
+}
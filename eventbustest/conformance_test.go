@@ -0,0 +1,19 @@
+package eventbustest
+
+import (
+	"testing"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+func TestConformance(t *testing.T) {
+	RunConformance(t, func() Bus {
+		return eventbus.New()
+	})
+}
+
+func TestConformanceWithCopyPayloads(t *testing.T) {
+	RunConformance(t, func() Bus {
+		return eventbus.New(eventbus.WithCopyPayloads())
+	})
+}
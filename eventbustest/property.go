@@ -0,0 +1,162 @@
+package eventbustest
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OpKind - kind of a generated bus operation
+type OpKind int
+
+// Kinds of generated bus operations
+const (
+	OpSubscribe OpKind = iota
+	OpSubscribeOnce
+	OpUnsubscribe
+	OpPublish
+)
+
+var opNames = [...]string{"Subscribe", "SubscribeOnce", "Unsubscribe", "Publish"}
+
+func (k OpKind) String() string {
+	if k < 0 || int(k) >= len(opNames) {
+		return fmt.Sprintf("OpKind(%d)", int(k))
+	}
+	return opNames[k]
+}
+
+// Op - bus operation on a topic. Handler selects one of the suite's handlers
+// for subscribe and unsubscribe operations.
+type Op struct {
+	Kind    OpKind
+	Topic   string
+	Handler int
+}
+
+func (op Op) String() string {
+	if op.Kind == OpPublish {
+		return fmt.Sprintf("Publish(%q)", op.Topic)
+	}
+	return fmt.Sprintf("%s(%q, h%d)", op.Kind, op.Topic, op.Handler)
+}
+
+// Ops - sequence of bus operations, generated randomly by CheckProperties
+type Ops []Op
+
+// opTopics are the topics used by generated operations
+var opTopics = []string{"a", "b"}
+
+// deliveries records the handlers called by a publish, in order
+type deliveries struct {
+	handlers []int
+}
+
+// opHandlers are distinct functions: the bus tells handlers apart by function
+// pointer, so closures over an index would all look the same to it.
+var opHandlers = []func(d *deliveries){
+	func(d *deliveries) { d.handlers = append(d.handlers, 0) },
+	func(d *deliveries) { d.handlers = append(d.handlers, 1) },
+	func(d *deliveries) { d.handlers = append(d.handlers, 2) },
+	func(d *deliveries) { d.handlers = append(d.handlers, 3) },
+}
+
+// modelHandler - subscription in the reference model
+type modelHandler struct {
+	handler int
+	once    bool
+}
+
+// Model - reference implementation of the synchronous bus semantics:
+// handlers are called in subscription order, once handlers are removed after
+// their first delivery and Unsubscribe removes the earliest subscription of a
+// handler.
+type Model struct {
+	handlers map[string][]modelHandler
+}
+
+// NewModel returns an empty reference model
+func NewModel() *Model {
+	return &Model{handlers: make(map[string][]modelHandler)}
+}
+
+// Apply applies op to the model. Returns the handlers a publish delivers to,
+// and for unsubscribe whether the handler was subscribed.
+func (m *Model) Apply(op Op) (delivered []int, ok bool) {
+	switch op.Kind {
+	case OpSubscribe, OpSubscribeOnce:
+		m.handlers[op.Topic] = append(m.handlers[op.Topic], modelHandler{op.Handler, op.Kind == OpSubscribeOnce})
+		return nil, true
+	case OpUnsubscribe:
+		for i, h := range m.handlers[op.Topic] {
+			if h.handler == op.Handler {
+				m.handlers[op.Topic] = append(m.handlers[op.Topic][:i:i], m.handlers[op.Topic][i+1:]...)
+				return nil, true
+			}
+		}
+		return nil, false
+	}
+	remaining := make([]modelHandler, 0, len(m.handlers[op.Topic]))
+	delivered = make([]int, 0)
+	for _, h := range m.handlers[op.Topic] {
+		delivered = append(delivered, h.handler)
+		if !h.once {
+			remaining = append(remaining, h)
+		}
+	}
+	m.handlers[op.Topic] = remaining
+	return delivered, true
+}
+
+// HasCallback reports whether the model has handlers for topic
+func (m *Model) HasCallback(topic string) bool {
+	return len(m.handlers[topic]) > 0
+}
+
+// CheckOps runs ops against bus and the reference model, returning an error
+// describing the first divergence
+func CheckOps(bus Bus, ops Ops) error {
+	model := NewModel()
+	for i, op := range ops {
+		if err := checkOp(bus, model, i, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOp runs the i-th op against bus and model, returning an error if they
+// diverge or the bus panics
+func checkOp(bus Bus, model *Model, i int, op Op) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("op %d %s panicked: %v", i, op, r)
+		}
+	}()
+	expected, ok := model.Apply(op)
+	switch op.Kind {
+	case OpSubscribe:
+		if e := bus.Subscribe(op.Topic, opHandlers[op.Handler]); e != nil {
+			return fmt.Errorf("op %d %s: %v", i, op, e)
+		}
+	case OpSubscribeOnce:
+		if e := bus.SubscribeOnce(op.Topic, opHandlers[op.Handler]); e != nil {
+			return fmt.Errorf("op %d %s: %v", i, op, e)
+		}
+	case OpUnsubscribe:
+		if e := bus.Unsubscribe(op.Topic, opHandlers[op.Handler]); (e == nil) != ok {
+			return fmt.Errorf("op %d %s returned %v, handler subscribed: %t", i, op, e, ok)
+		}
+	case OpPublish:
+		d := &deliveries{handlers: make([]int, 0)}
+		bus.Publish(op.Topic, d)
+		if !reflect.DeepEqual(d.handlers, expected) {
+			return fmt.Errorf("op %d %s delivered to %v, want %v", i, op, d.handlers, expected)
+		}
+	}
+	for _, topic := range opTopics {
+		if bus.HasCallback(topic) != model.HasCallback(topic) {
+			return fmt.Errorf("after op %d %s HasCallback(%q) is %t", i, op, topic, !model.HasCallback(topic))
+		}
+	}
+	return nil
+}
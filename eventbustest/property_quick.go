@@ -0,0 +1,40 @@
+//go:build !eventbus_rapid
+
+package eventbustest
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+// Generate implements quick.Generator
+func (Ops) Generate(rand *rand.Rand, size int) reflect.Value {
+	ops := make(Ops, rand.Intn(size+1))
+	for i := range ops {
+		ops[i] = Op{
+			Kind:    OpKind(rand.Intn(len(opNames))),
+			Topic:   opTopics[rand.Intn(len(opTopics))],
+			Handler: rand.Intn(len(opHandlers)),
+		}
+	}
+	return reflect.ValueOf(ops)
+}
+
+// CheckProperties runs random operation sequences against buses returned by
+// factory, reporting to t the first sequence the bus and the reference model
+// disagree on, out of 500 sequences generated with testing/quick. This is the
+// fallback of builds without the eventbus_rapid tag: failing sequences aren't
+// shrunk.
+func CheckProperties(t *testing.T, factory func() Bus) {
+	t.Helper()
+	var failure error
+	property := func(ops Ops) bool {
+		failure = CheckOps(factory(), ops)
+		return failure == nil
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 500}); err != nil {
+		t.Errorf("%v\n%v", err, failure)
+	}
+}
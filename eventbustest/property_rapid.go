@@ -0,0 +1,40 @@
+//go:build eventbus_rapid
+
+package eventbustest
+
+import (
+	"testing"
+
+	"pgregory.net/rapid"
+)
+
+// opGenerator draws a single bus operation
+var opGenerator = rapid.Custom(func(t *rapid.T) Op {
+	return Op{
+		Kind:    OpKind(rapid.IntRange(0, len(opNames)-1).Draw(t, "kind")),
+		Topic:   rapid.SampledFrom(opTopics).Draw(t, "topic"),
+		Handler: rapid.IntRange(0, len(opHandlers)-1).Draw(t, "handler"),
+	}
+})
+
+// OpsGenerator returns a rapid generator of operation sequences, to check
+// other properties of a bus with CheckOps
+func OpsGenerator() *rapid.Generator[Ops] {
+	return rapid.Custom(func(t *rapid.T) Ops {
+		return Ops(rapid.SliceOf(opGenerator).Draw(t, "ops"))
+	})
+}
+
+// CheckProperties runs random operation sequences against buses returned by
+// factory, reporting to t the first sequence the bus and the reference model
+// disagree on, shrunk to a minimal one. The number of sequences is set by the
+// -rapid.checks flag.
+func CheckProperties(t *testing.T, factory func() Bus) {
+	t.Helper()
+	ops := OpsGenerator()
+	rapid.Check(t, func(t *rapid.T) {
+		if err := CheckOps(factory(), ops.Draw(t, "ops")); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package eventbustest
+
+import (
+	"testing"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+func TestProperties(t *testing.T) {
+	CheckProperties(t, func() Bus {
+		return eventbus.New()
+	})
+}
+
+func TestCheckOpsDetectsDivergence(t *testing.T) {
+	// a bus that ignores once semantics must be caught
+	ops := Ops{
+		{Kind: OpSubscribeOnce, Topic: "a", Handler: 0},
+		{Kind: OpPublish, Topic: "a"},
+		{Kind: OpPublish, Topic: "a"},
+	}
+	if CheckOps(onceIgnoringBus{eventbus.New()}, ops) == nil {
+		t.Fail()
+	}
+	if err := CheckOps(eventbus.New(), ops); err != nil {
+		t.Error(err)
+	}
+}
+
+type onceIgnoringBus struct {
+	*eventbus.Bus
+}
+
+func (b onceIgnoringBus) SubscribeOnce(topic string, fn interface{}) error {
+	return b.Subscribe(topic, fn)
+}
@@ -0,0 +1,70 @@
+package eventbustest
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Scheduler - deterministic single-threaded eventbus.Scheduler. Scheduled tasks
+// are queued and only run, one at a time on the calling goroutine, when the test
+// calls RunNext or RunUntilIdle, so async deliveries interleave the same way on
+// every run. Since nothing runs on its own, call RunUntilIdle before WaitAsync.
+type Scheduler struct {
+	lock  sync.Mutex
+	tasks []func()
+	rand  *rand.Rand
+}
+
+// NewScheduler returns a Scheduler running tasks in the order they were scheduled
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// NewSeededScheduler returns a Scheduler running pending tasks in a pseudo-random
+// order determined by seed, to explore interleavings reproducibly. Deliveries of
+// a transactional handler still run in publishing order.
+func NewSeededScheduler(seed int64) *Scheduler {
+	return &Scheduler{rand: rand.New(rand.NewSource(seed))}
+}
+
+// Schedule queues task
+func (s *Scheduler) Schedule(task func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.tasks = append(s.tasks, task)
+}
+
+// Pending returns the number of queued tasks
+func (s *Scheduler) Pending() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.tasks)
+}
+
+// RunNext runs the next queued task. Returns false if there was none.
+func (s *Scheduler) RunNext() bool {
+	s.lock.Lock()
+	if len(s.tasks) == 0 {
+		s.lock.Unlock()
+		return false
+	}
+	idx := 0
+	if s.rand != nil {
+		idx = s.rand.Intn(len(s.tasks))
+	}
+	task := s.tasks[idx]
+	s.tasks = append(s.tasks[:idx], s.tasks[idx+1:]...)
+	s.lock.Unlock()
+	task()
+	return true
+}
+
+// RunUntilIdle runs queued tasks, including the ones they schedule, until the
+// queue is empty. Returns the number of tasks run.
+func (s *Scheduler) RunUntilIdle() int {
+	n := 0
+	for s.RunNext() {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,81 @@
+package eventbustest
+
+import (
+	"testing"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+func TestSchedulerRunsOnDemand(t *testing.T) {
+	scheduler := NewScheduler()
+	bus := eventbus.New(eventbus.WithScheduler(scheduler))
+	calls := make([]string, 0)
+	bus.SubscribeAsync("topic", func(s string) { calls = append(calls, "a"+s) }, false)
+	bus.SubscribeAsync("topic", func(s string) { calls = append(calls, "b"+s) }, false)
+	bus.Publish("topic", "1")
+	bus.Publish("topic", "2")
+	if len(calls) != 0 || scheduler.Pending() != 4 {
+		t.Fail()
+	}
+	if !scheduler.RunNext() || len(calls) != 1 || calls[0] != "a1" {
+		t.Fail()
+	}
+	if scheduler.RunUntilIdle() != 3 {
+		t.Fail()
+	}
+	bus.WaitAsync()
+	expected := []string{"a1", "b1", "a2", "b2"}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Fatalf("got %v, want %v", calls, expected)
+		}
+	}
+	if scheduler.RunNext() {
+		t.Fail()
+	}
+}
+
+func TestSchedulerTransactional(t *testing.T) {
+	scheduler := NewSeededScheduler(7)
+	bus := eventbus.New(eventbus.WithScheduler(scheduler))
+	calls := make([]int, 0)
+	bus.SubscribeAsync("topic", func(n int) { calls = append(calls, n) }, true)
+	for i := 0; i < 5; i++ {
+		bus.Publish("topic", i)
+	}
+	if scheduler.Pending() != 1 {
+		t.Fail() // the next delivery is scheduled once the previous one completed
+	}
+	scheduler.RunUntilIdle()
+	for i := range calls {
+		if calls[i] != i {
+			t.Fatalf("transactional deliveries ran as %v", calls)
+		}
+	}
+	if len(calls) != 5 {
+		t.Fail()
+	}
+}
+
+func TestSeededSchedulerIsReproducible(t *testing.T) {
+	run := func(seed int64) []int {
+		scheduler := NewSeededScheduler(seed)
+		bus := eventbus.New(eventbus.WithScheduler(scheduler))
+		calls := make([]int, 0)
+		bus.SubscribeAsync("topic", func(n int) { calls = append(calls, n) }, false)
+		for i := 0; i < 10; i++ {
+			bus.Publish("topic", i)
+		}
+		scheduler.RunUntilIdle()
+		return calls
+	}
+	first, second := run(42), run(42)
+	if len(first) != 10 || len(second) != 10 {
+		t.FailNow()
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("%v and %v differ for the same seed", first, second)
+		}
+	}
+}
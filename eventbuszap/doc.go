@@ -0,0 +1,8 @@
+// Package eventbuszap logs the activity of an event bus to zap.
+//
+// It depends on go.uber.org/zap, so it is only built with the eventbus_zap
+// build tag (-tags eventbus_zap), keeping the dependency out of the builds of
+// users without zap:
+//
+//	bus := eventbus.New(eventbus.WithLogger(eventbuszap.New(logger), nil))
+package eventbuszap
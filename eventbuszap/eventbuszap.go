@@ -0,0 +1,36 @@
+//go:build eventbus_zap
+
+package eventbuszap
+
+import (
+	"log/slog"
+
+	eventbus "github.com/asaskevich/EventBus"
+	"go.uber.org/zap"
+)
+
+// Logger - eventbus.Logger implementation writing to a zap.Logger
+type Logger struct {
+	logger *zap.SugaredLogger
+}
+
+var _ eventbus.Logger = (*Logger)(nil)
+
+// New - create a Logger writing to logger
+func New(logger *zap.Logger) *Logger {
+	return &Logger{logger.Sugar()}
+}
+
+// Log implements eventbus.Logger
+func (l *Logger) Log(level slog.Level, msg string, attrs ...interface{}) {
+	switch {
+	case level >= slog.LevelError:
+		l.logger.Errorw(msg, attrs...)
+	case level >= slog.LevelWarn:
+		l.logger.Warnw(msg, attrs...)
+	case level >= slog.LevelInfo:
+		l.logger.Infow(msg, attrs...)
+	default:
+		l.logger.Debugw(msg, attrs...)
+	}
+}
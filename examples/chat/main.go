@@ -0,0 +1,85 @@
+// Command chat serves a chat room to browsers through the WebSocket bridge:
+// pages publish their messages on the room topic and receive the messages of
+// the others as events. The server logs the room with a subscriber of its own,
+// like any other part of the process could.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+// topicRoom - topic of the chat messages, published with the name of the
+// sender and the text as arguments
+const topicRoom = "chat:lobby"
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	flag.Parse()
+	log.Printf("chat on http://%s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, newHandler(eventbus.New(), os.Stdout)))
+}
+
+// newHandler serves the chat page on / and its WebSocket connections on /ws,
+// logging the messages of the room to out
+func newHandler(bus *eventbus.Bus, out io.Writer) http.Handler {
+	bus.Subscribe(topicRoom, func(ev eventbus.Event) {
+		if len(ev.Args) == 2 {
+			fmt.Fprintf(out, "%v: %v\n", ev.Args[0], ev.Args[1])
+		}
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/ws", bus.WebsocketHandler(&eventbus.WebsocketOptions{
+		Publish: true,
+		Allow: func(r *http.Request, topic string, publish bool) bool {
+			return topic == topicRoom
+		},
+	}))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, page)
+	})
+	return mux
+}
+
+// page - chat client, subscribing to the room when it connects
+const page = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>EventBus chat</title></head>
+<body>
+<ul id="messages"></ul>
+<form id="form">
+	<input id="name" placeholder="name" size="10">
+	<input id="text" placeholder="message" size="40" autofocus>
+	<button>Send</button>
+</form>
+<script>
+const topic = "` + topicRoom + `";
+const socket = new WebSocket("ws://" + location.host + "/ws?topic=" + encodeURIComponent(topic));
+socket.onmessage = (message) => {
+	const msg = JSON.parse(message.data);
+	const item = document.createElement("li");
+	item.textContent = msg.type === "event" ? msg.args[0] + ": " + msg.args[1] : "error: " + msg.error;
+	document.getElementById("messages").append(item);
+};
+document.getElementById("form").onsubmit = (e) => {
+	e.preventDefault();
+	const name = document.getElementById("name").value || "anonymous";
+	const text = document.getElementById("text");
+	socket.send(JSON.stringify({type: "publish", topic: topic, args: [name, text.value]}));
+	text.value = "";
+};
+</script>
+</body>
+</html>
+`
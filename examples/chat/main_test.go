@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+func TestChat(t *testing.T) {
+	out := &strings.Builder{}
+	bus := eventbus.New()
+	server := httptest.NewServer(newHandler(bus, out))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatal(resp, err)
+	}
+	resp.Body.Close()
+
+	alice := dial(t, server, "/ws?topic="+topicRoom)
+	bob := dial(t, server, "/ws?topic="+topicRoom)
+	alice.send(t, eventbus.WebsocketMessage{Type: "publish", Topic: topicRoom, Args: []interface{}{"alice", "hi bob"}})
+	for _, c := range []*client{alice, bob} {
+		if msg := c.receive(t); msg.Type != "event" || msg.Args[0] != "alice" || msg.Args[1] != "hi bob" {
+			t.Fatal(msg)
+		}
+	}
+	bob.send(t, eventbus.WebsocketMessage{Type: "publish", Topic: "admin", Args: []interface{}{"bob"}})
+	if msg := bob.receive(t); msg.Type != "error" || msg.Topic != "admin" {
+		t.Fatal(msg)
+	}
+	bus.WaitAsync()
+	if out.String() != "alice: hi bob\n" {
+		t.Fatal(out.String())
+	}
+}
+
+// client - minimal WebSocket client of the chat
+type client struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// dial opens a WebSocket connection to path on server
+func dial(t *testing.T, server *httptest.Server, path string) *client {
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil || resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatal(resp, err)
+	}
+	return &client{conn, reader}
+}
+
+// send writes a message as a masked text frame
+func (c *client) send(t *testing.T, msg eventbus.WebsocketMessage) {
+	payload, _ := json.Marshal(msg)
+	frame := []byte{0x81, 0x80 | 126}
+	frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// receive reads a message from an unfragmented text frame
+func (c *client) receive(t *testing.T) eventbus.WebsocketMessage {
+	c.conn.SetReadDeadline(time.Now().Add(time.Second))
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		t.Fatal(err)
+	}
+	length := int(header[1] & 0x7f)
+	if length == 126 {
+		if _, err := io.ReadFull(c.reader, header); err != nil {
+			t.Fatal(err)
+		}
+		length = int(binary.BigEndian.Uint16(header))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		t.Fatal(err)
+	}
+	msg := eventbus.WebsocketMessage{}
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		t.Fatal(string(payload), err)
+	}
+	return msg
+}
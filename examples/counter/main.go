@@ -0,0 +1,102 @@
+// Command counter is an event-sourced counter: commands publish events with
+// time-ordered IDs, an append-only log stores them and a View projects them
+// into per-counter totals. A second bus rebuilds the projection by replaying
+// the log, as a restarted service would.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+const topicChanged = "counter:changed"
+
+// eventLog - append-only store of the events published on a bus
+type eventLog struct {
+	events []eventbus.Event
+	lock   sync.Mutex
+}
+
+func (l *eventLog) append(ev eventbus.Event) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.events = append(l.events, ev)
+}
+
+// replay publishes the stored events again on bus, in their original order
+func (l *eventLog) replay(bus *eventbus.Bus) error {
+	l.lock.Lock()
+	events := slices.Clone(l.events)
+	l.lock.Unlock()
+	slices.SortFunc(events, func(a, b eventbus.Event) int { return strings.Compare(a.ID, b.ID) })
+	for _, ev := range events {
+		if err := bus.PublishEvent(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// totals projects the changes of the counters into their current values
+func totals(bus *eventbus.Bus) (*eventbus.View, error) {
+	lock := sync.Mutex{}
+	current := make(map[string]int)
+	return bus.View(topicChanged, func(args ...interface{}) string {
+		return args[0].(string)
+	}, func(args ...interface{}) interface{} {
+		lock.Lock()
+		defer lock.Unlock()
+		current[args[0].(string)] += args[1].(int)
+		return current[args[0].(string)]
+	})
+}
+
+func main() {
+	if err := run(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(out io.Writer) error {
+	log := &eventLog{}
+	bus := eventbus.New(eventbus.WithIDGenerator(eventbus.NewULIDGenerator()))
+	bus.Subscribe(topicChanged, func(ev eventbus.Event) { log.append(ev) })
+	view, err := totals(bus)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range []struct {
+		counter string
+		delta   int
+	}{{"visits", 1}, {"visits", 1}, {"likes", 1}, {"visits", 1}, {"likes", -1}, {"likes", 1}} {
+		if err := bus.PublishEvent(eventbus.Event{Topic: topicChanged, Args: []interface{}{change.counter, change.delta}}); err != nil {
+			return err
+		}
+	}
+	printTotals(out, "live", view)
+
+	rebuilt := eventbus.New()
+	replayed, err := totals(rebuilt)
+	if err != nil {
+		return err
+	}
+	if err := log.replay(rebuilt); err != nil {
+		return err
+	}
+	printTotals(out, "replayed", replayed)
+	return nil
+}
+
+func printTotals(out io.Writer, label string, view *eventbus.View) {
+	likes, _ := view.Get("likes")
+	visits, _ := view.Get("visits")
+	fmt.Fprintf(out, "%s: likes=%v visits=%v\n", label, likes, visits)
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	out := strings.Builder{}
+	if err := run(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := "live: likes=1 visits=3\nreplayed: likes=1 visits=3\n"
+	if out.String() != expected {
+		t.Fatal(out.String())
+	}
+}
@@ -0,0 +1,84 @@
+// Command jobs processes jobs on a pool of async workers: failing jobs are
+// retried with backoff and the ones still failing end up on a dead-letter
+// topic, where they are reported.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+// Job - unit of work published on the jobs topic
+type Job struct {
+	ID       int
+	Failures int // times the job fails before succeeding, -1 to always fail
+}
+
+const topicJobs = "jobs:submitted"
+
+func main() {
+	if err := run(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(out io.Writer) error {
+	bus := eventbus.New(eventbus.WithAsyncWorkers(4), eventbus.WithDeadLetters(nil))
+	bus.SetTopicConfig(topicJobs, eventbus.Config{BufferSize: 100})
+
+	lock := sync.Mutex{}
+	attempts := make(map[int]int)
+	var done, failed []int
+	sub, err := bus.SubscribeAsyncHandle(topicJobs, func(job Job) error {
+		lock.Lock()
+		defer lock.Unlock()
+		attempts[job.ID]++
+		if job.Failures < 0 || attempts[job.ID] <= job.Failures {
+			return errors.New("temporary failure")
+		}
+		done = append(done, job.ID)
+		return nil
+	}, false)
+	if err != nil {
+		return err
+	}
+	if err := sub.WithRetry(3, 5*time.Millisecond); err != nil {
+		return err
+	}
+	bus.Subscribe(eventbus.DeadLetterTopic(topicJobs), func(letter eventbus.DeadLetter) {
+		lock.Lock()
+		defer lock.Unlock()
+		failed = append(failed, letter.Args[0].(Job).ID)
+	})
+
+	for id := 1; id <= 6; id++ {
+		job := Job{ID: id}
+		switch id {
+		case 2, 4:
+			job.Failures = 2 // succeeds on the third attempt
+		case 5:
+			job.Failures = -1
+		}
+		if err := bus.Publish(topicJobs, job); err != nil {
+			return err
+		}
+	}
+	if err := bus.Close(context.Background()); err != nil {
+		return err
+	}
+
+	slices.Sort(done)
+	fmt.Fprintln(out, "done:", done)
+	fmt.Fprintln(out, "dead letters:", failed)
+	fmt.Fprintln(out, "attempts of job 2:", attempts[2])
+	return nil
+}
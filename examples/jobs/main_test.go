@@ -0,0 +1,17 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	out := strings.Builder{}
+	if err := run(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := "done: [1 2 3 4 6]\ndead letters: [5]\nattempts of job 2: 3\n"
+	if out.String() != expected {
+		t.Fatal(out.String())
+	}
+}
@@ -0,0 +1,283 @@
+package eventbus
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore - Store keeping the log of every topic in an append-only file of
+// a directory, synced to disk on every append. A record torn by a crash is
+// dropped when the log is opened again. Records are limited to
+// maxFileRecord bytes.
+type FileStore struct {
+	dir  string
+	logs map[string]*fileLog
+	lock sync.Mutex
+}
+
+// fileLog - log file of a topic: an 8 bytes header holding the offset of the
+// first record, then records framed by their length and CRC-32
+type fileLog struct {
+	path string
+	file *os.File
+	base uint64 // offset of the first record
+	next uint64 // offset of the next record
+	lock sync.Mutex
+}
+
+const (
+	fileLogHeader = 8        // size of the header of a log file
+	maxFileRecord = 64 << 20 // size limit of a record
+)
+
+// NewFileStore returns a store keeping its logs in dir, created if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir, logs: make(map[string]*fileLog)}, nil
+}
+
+// Append appends a record to the log of a topic
+func (store *FileStore) Append(topic string, data []byte) (uint64, error) {
+	log, err := store.log(topic)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) > maxFileRecord {
+		return 0, fmt.Errorf("record of %d bytes exceeds the limit of %d bytes", len(data), maxFileRecord)
+	}
+	log.lock.Lock()
+	defer log.lock.Unlock()
+	frame := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	binary.BigEndian.PutUint32(frame[4:], crc32.ChecksumIEEE(data))
+	if _, err := log.file.Write(append(frame, data...)); err != nil {
+		return 0, err
+	}
+	if err := log.file.Sync(); err != nil {
+		return 0, err
+	}
+	log.next++
+	return log.next - 1, nil
+}
+
+// ReadFrom calls fn with the records of the log of a topic from offset on.
+// Records appended while it runs may not be read.
+func (store *FileStore) ReadFrom(topic string, offset uint64, fn func(record StoreRecord) error) error {
+	log, err := store.log(topic)
+	if err != nil {
+		return err
+	}
+	log.lock.Lock()
+	file, err := os.Open(log.path) // a truncation replaces the file, not this one
+	next := log.next
+	log.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader, err := newLogReader(file)
+	if err != nil {
+		return err
+	}
+	base, err := reader.header()
+	if err != nil {
+		return err
+	}
+	for current := base; current < next; current++ {
+		data, err := reader.record()
+		if err != nil {
+			return err
+		}
+		if current < offset {
+			continue
+		}
+		if err := fn(StoreRecord{Offset: current, Data: data}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Truncate drops the records of the log of a topic before offset by
+// rewriting the file
+func (store *FileStore) Truncate(topic string, before uint64) error {
+	log, err := store.log(topic)
+	if err != nil {
+		return err
+	}
+	log.lock.Lock()
+	defer log.lock.Unlock()
+	before = min(before, log.next)
+	if before <= log.base {
+		return nil
+	}
+	if _, err := log.file.Seek(fileLogHeader, io.SeekStart); err != nil {
+		return err
+	}
+	reader, err := newLogReader(log.file)
+	if err != nil {
+		return err
+	}
+	for current := log.base; current < before; current++ {
+		if _, err := reader.record(); err != nil {
+			return err
+		}
+	}
+	rewritten, err := os.CreateTemp(store.dir, "truncate-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(rewritten.Name()) // once renamed, there is nothing to remove
+	header := binary.BigEndian.AppendUint64(nil, before)
+	if _, err := rewritten.Write(header); err != nil {
+		rewritten.Close()
+		return err
+	}
+	if _, err := io.Copy(rewritten, reader.reader); err != nil {
+		rewritten.Close()
+		return err
+	}
+	if err := rewritten.Sync(); err != nil {
+		rewritten.Close()
+		return err
+	}
+	if err := os.Rename(rewritten.Name(), log.path); err != nil {
+		rewritten.Close()
+		return err
+	}
+	log.file.Close()
+	log.file, log.base = rewritten, before
+	_, err = log.file.Seek(0, io.SeekEnd)
+	return err
+}
+
+// Close closes the log files
+func (store *FileStore) Close() error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	var errs []error
+	for topic, log := range store.logs {
+		errs = append(errs, log.file.Close())
+		delete(store.logs, topic)
+	}
+	return errors.Join(errs...)
+}
+
+// log returns the log of a topic, opened on first use
+func (store *FileStore) log(topic string) (*fileLog, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	if log, ok := store.logs[topic]; ok {
+		return log, nil
+	}
+	log, err := openFileLog(filepath.Join(store.dir, url.QueryEscape(topic)+".log"))
+	if err != nil {
+		return nil, fmt.Errorf("log of topic %s: %w", topic, err)
+	}
+	store.logs[topic] = log
+	return log, nil
+}
+
+// openFileLog opens or creates a log file, dropping a torn last record
+func openFileLog(path string) (*fileLog, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	log := &fileLog{path: path, file: file}
+	reader, err := newLogReader(file)
+	if err == nil {
+		log.base, err = reader.header()
+	}
+	if err == io.EOF {
+		_, err = file.Write(make([]byte, fileLogHeader)) // new log
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	size := int64(fileLogHeader)
+	for log.next = log.base; ; log.next++ {
+		data, err := reader.record()
+		if err != nil {
+			break // end of the log, or torn record
+		}
+		size += int64(8 + len(data))
+	}
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(size, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return log, nil
+}
+
+// logReader - reads a log file, bounding the length of its records by the
+// size of the file
+type logReader struct {
+	reader    *bufio.Reader
+	remaining int64 // bytes of the file not read yet
+}
+
+// newLogReader returns a reader of file from its current position
+func newLogReader(file *os.File) (*logReader, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	position, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	return &logReader{bufio.NewReader(file), info.Size() - position}, nil
+}
+
+// read fills data from the file
+func (reader *logReader) read(data []byte) error {
+	n, err := io.ReadFull(reader.reader, data)
+	reader.remaining -= int64(n)
+	return err
+}
+
+// header reads the offset of the first record of a log file
+func (reader *logReader) header() (uint64, error) {
+	header := make([]byte, fileLogHeader)
+	if err := reader.read(header); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(header), nil
+}
+
+// record reads the next record of a log file.
+// Returns error if it is torn or corrupted.
+func (reader *logReader) record() ([]byte, error) {
+	frame := make([]byte, 8)
+	if err := reader.read(frame); err != nil {
+		return nil, err
+	}
+	length := int64(binary.BigEndian.Uint32(frame))
+	if length > maxFileRecord || length > reader.remaining {
+		return nil, errors.New("torn record") // length not written in full, or corrupted
+	}
+	data := make([]byte, length)
+	if err := reader.read(data); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(frame[4:]) {
+		return nil, errors.New("corrupted record")
+	}
+	return data, nil
+}
@@ -0,0 +1,83 @@
+package eventbus
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, data := range []string{"a", "b", "c", "d"} {
+		if offset, err := store.Append("orders:placed", []byte(data)); err != nil || offset != uint64(i) {
+			t.Fatal(offset, err)
+		}
+	}
+	if got := readAll(t, store, "orders:placed", 2); !slices.Equal(got, []string{"c", "d"}) {
+		t.Fatal(got)
+	}
+	if err := store.Truncate("orders:placed", 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, store, "orders:placed", 0); !slices.Equal(got, []string{"c", "d"}) {
+		t.Fatal(got)
+	}
+	if offset, _ := store.Append("orders:placed", []byte("e")); offset != 4 {
+		t.Fatal(offset)
+	}
+	store.Close()
+
+	// a record torn by a crash is dropped on reopening
+	path := filepath.Join(dir, "orders%3Aplaced.log")
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	file.Write([]byte{0, 0, 0, 9, 1, 2})
+	file.Close()
+	store, _ = NewFileStore(dir)
+	defer store.Close()
+	if offset, err := store.Append("orders:placed", []byte("f")); err != nil || offset != 5 {
+		t.Fatal(offset, err)
+	}
+	if got := readAll(t, store, "orders:placed", 0); !slices.Equal(got, []string{"c", "d", "e", "f"}) {
+		t.Fatal(got)
+	}
+}
+
+func TestFileStoreOversizedRecord(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewFileStore(dir)
+	store.Append("topic", []byte("a"))
+	if _, err := store.Append("topic", make([]byte, maxFileRecord+1)); err == nil {
+		t.Fail()
+	}
+	store.Close()
+
+	// a length beyond the end of the file is a torn record, not an allocation
+	file, _ := os.OpenFile(filepath.Join(dir, "topic.log"), os.O_WRONLY|os.O_APPEND, 0)
+	file.Write([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 1})
+	file.Close()
+	store, _ = NewFileStore(dir)
+	defer store.Close()
+	if offset, err := store.Append("topic", []byte("b")); err != nil || offset != 1 {
+		t.Fatal(offset, err)
+	}
+	if got := readAll(t, store, "topic", 0); !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatal(got)
+	}
+}
+
+func readAll(t *testing.T, store Store, topic string, offset uint64) []string {
+	var records []string
+	err := store.ReadFrom(topic, offset, func(record StoreRecord) error {
+		records = append(records, string(record.Data))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
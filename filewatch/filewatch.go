@@ -0,0 +1,224 @@
+// Package filewatch publishes file system changes on an event bus.
+//
+// Changes are detected by polling modification times and sizes, so the package
+// has no dependency outside the standard library. Built with the
+// eventbus_fsnotify build tag (-tags eventbus_fsnotify), it is notified of the
+// changes by github.com/fsnotify/fsnotify instead, and only falls back to
+// polling if the paths can't be watched. Directories are watched one level
+// deep.
+package filewatch
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+const (
+	// DefaultTopic - topic change events are published on
+	DefaultTopic = "fs:change"
+	// DefaultInterval - default polling interval
+	DefaultInterval = 500 * time.Millisecond
+	// DefaultDebounce - default quiet period before a change is published
+	DefaultDebounce = 100 * time.Millisecond
+)
+
+// Op - kind of change
+type Op int
+
+const (
+	// Create - the file appeared
+	Create Op = iota
+	// Write - the file size or modification time changed
+	Write
+	// Remove - the file disappeared
+	Remove
+)
+
+func (op Op) String() string {
+	switch op {
+	case Create:
+		return "create"
+	case Write:
+		return "write"
+	case Remove:
+		return "remove"
+	}
+	return "unknown"
+}
+
+// Event - a debounced change to a single path, published as the only argument
+type Event struct {
+	Path string
+	Op   Op
+}
+
+// Options - configuration of a Watcher
+type Options struct {
+	// Topic returns the topic an event is published on; defaults to DefaultTopic
+	Topic func(event Event) string
+	// Interval between two polls; defaults to DefaultInterval. Unused when
+	// notified by fsnotify.
+	Interval time.Duration
+	// Debounce is how long a path must stay unchanged before its change is
+	// published; successive changes are coalesced. Defaults to DefaultDebounce
+	Debounce time.Duration
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+type pendingEvent struct {
+	event Event
+	at    time.Time
+}
+
+// Watcher - watches files and directories and publishes their changes
+type Watcher struct {
+	bus     *eventbus.Bus
+	opts    Options
+	paths   []string
+	state   map[string]fileState
+	pending map[string]*pendingEvent
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Watch starts watching the paths and publishing their changes on the bus
+func Watch(bus *eventbus.Bus, paths []string, opts Options) (*Watcher, error) {
+	if opts.Topic == nil {
+		opts.Topic = func(Event) string { return DefaultTopic }
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	watcher := &Watcher{
+		bus:     bus,
+		opts:    opts,
+		paths:   paths,
+		pending: make(map[string]*pendingEvent),
+		done:    make(chan struct{}),
+	}
+	state, err := watcher.scan()
+	if err != nil {
+		return nil, err
+	}
+	watcher.state = state
+	watcher.start()
+	return watcher, nil
+}
+
+// Close stops the watcher. Pending changes are discarded.
+func (watcher *Watcher) Close() {
+	watcher.once.Do(func() { close(watcher.done) })
+}
+
+// run polls the paths until the watcher is closed
+func (watcher *Watcher) run() {
+	ticker := time.NewTicker(watcher.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-watcher.done:
+			return
+		case now := <-ticker.C:
+			watcher.poll(now)
+		}
+	}
+}
+
+// poll compares the files with the last scan and publishes the changes
+// debounced long enough
+func (watcher *Watcher) poll(now time.Time) {
+	state, err := watcher.scan()
+	if err != nil {
+		return
+	}
+	for path, current := range state {
+		previous, ok := watcher.state[path]
+		switch {
+		case !ok:
+			watcher.change(path, Create, now)
+		case previous != current:
+			watcher.change(path, Write, now)
+		}
+	}
+	for path := range watcher.state {
+		if _, ok := state[path]; !ok {
+			watcher.change(path, Remove, now)
+		}
+	}
+	watcher.state = state
+	watcher.flush(now)
+}
+
+// flush publishes the pending changes debounced long enough, and returns when
+// the next one will be
+func (watcher *Watcher) flush(now time.Time) (next time.Time) {
+	for path, pending := range watcher.pending {
+		due := pending.at.Add(watcher.opts.Debounce)
+		if !now.Before(due) {
+			delete(watcher.pending, path)
+			watcher.bus.Publish(watcher.opts.Topic(pending.event), pending.event)
+		} else if next.IsZero() || due.Before(next) {
+			next = due
+		}
+	}
+	return next
+}
+
+// change records a change, keeping a create as such until it is published
+func (watcher *Watcher) change(path string, op Op, now time.Time) {
+	if pending, ok := watcher.pending[path]; ok {
+		if pending.event.Op == Create && op == Remove {
+			delete(watcher.pending, path) // never published, nothing to report
+			return
+		}
+		if !(pending.event.Op == Create && op == Write) {
+			pending.event.Op = op
+		}
+		pending.at = now
+		return
+	}
+	watcher.pending[path] = &pendingEvent{Event{path, op}, now}
+}
+
+func (watcher *Watcher) scan() (map[string]fileState, error) {
+	state := make(map[string]fileState)
+	for _, path := range watcher.paths {
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			state[path] = fileState{info.Size(), info.ModTime()}
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue // removed since listed
+			}
+			state[filepath.Join(path, entry.Name())] = fileState{info.Size(), info.ModTime()}
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,63 @@
+package filewatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	bus := eventbus.New()
+	events := make(chan Event, 10)
+	bus.Subscribe("fs:config", func(event Event) {
+		events <- event
+	})
+
+	watcher, err := Watch(bus, []string{dir}, Options{
+		Topic:    func(Event) string { return "fs:config" },
+		Interval: 10 * time.Millisecond,
+		Debounce: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	path := filepath.Join(dir, "app.conf")
+	os.WriteFile(path, []byte("a"), 0600)
+	os.WriteFile(path, []byte("ab"), 0600)
+
+	select {
+	case event := <-events:
+		if event.Path != path || event.Op != Create {
+			t.Log(event)
+			t.Fail()
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("create not published")
+	}
+
+	os.Remove(path)
+	select {
+	case event := <-events:
+		if event.Op != Remove {
+			t.Log(event)
+			t.Fail()
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("remove not published")
+	}
+}
+
+func TestWatchMissingPath(t *testing.T) {
+	watcher, err := Watch(eventbus.New(), []string{filepath.Join(t.TempDir(), "missing")}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher.Close()
+	watcher.Close()
+}
@@ -0,0 +1,104 @@
+//go:build eventbus_fsnotify
+
+package filewatch
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// start starts watching the paths with fsnotify, or polling them if they can't
+// be watched, e.g. once out of inotify watches
+func (watcher *Watcher) start() {
+	notifier, files, dirs, err := watcher.notifier()
+	if err != nil {
+		go watcher.run()
+		return
+	}
+	go watcher.notify(notifier, files, dirs)
+}
+
+// notifier returns an fsnotify watcher of the paths, along with the watched
+// files by cleaned name and the watched directories. Files are watched through
+// their directory so that they are still seen once replaced or recreated.
+func (watcher *Watcher) notifier() (*fsnotify.Watcher, map[string]string, map[string]bool, error) {
+	notifier, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	files, dirs := make(map[string]string), make(map[string]bool)
+	for _, path := range watcher.paths {
+		dir := filepath.Dir(path)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			dir = filepath.Clean(path)
+			dirs[dir] = true
+		} else {
+			files[filepath.Clean(path)] = path
+		}
+		if err := notifier.Add(dir); err != nil {
+			notifier.Close()
+			return nil, nil, nil, err
+		}
+	}
+	return notifier, files, dirs, nil
+}
+
+// notify records the changes notified until the watcher is closed, publishing
+// them once debounced. The paths are scanned again when notifications are
+// lost.
+func (watcher *Watcher) notify(notifier *fsnotify.Watcher, files map[string]string, dirs map[string]bool) {
+	defer notifier.Close()
+	var due <-chan time.Time
+	for {
+		select {
+		case <-watcher.done:
+			return
+		case ev, ok := <-notifier.Events:
+			if !ok {
+				return
+			}
+			if path, ok := files[ev.Name]; ok {
+				watcher.refresh(path, ev.Has(fsnotify.Write), time.Now())
+			} else if dirs[filepath.Dir(ev.Name)] {
+				watcher.refresh(ev.Name, ev.Has(fsnotify.Write), time.Now())
+			}
+		case _, ok := <-notifier.Errors:
+			if !ok {
+				return
+			}
+			watcher.poll(time.Now())
+		case <-due:
+		}
+		now := time.Now()
+		if next := watcher.flush(now); next.IsZero() {
+			due = nil
+		} else {
+			due = time.After(next.Sub(now))
+		}
+	}
+}
+
+// refresh records the change of a file since it was last seen. A write is
+// recorded when notified even if its size and modification time didn't change.
+func (watcher *Watcher) refresh(path string, written bool, now time.Time) {
+	previous, seen := watcher.state[path]
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		if seen {
+			delete(watcher.state, path)
+			watcher.change(path, Remove, now)
+		}
+		return
+	}
+	current := fileState{info.Size(), info.ModTime()}
+	watcher.state[path] = current
+	switch {
+	case !seen:
+		watcher.change(path, Create, now)
+	case written || previous != current:
+		watcher.change(path, Write, now)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !eventbus_fsnotify
+
+package filewatch
+
+// start starts polling the paths
+func (watcher *Watcher) start() {
+	go watcher.run()
+}
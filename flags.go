@@ -0,0 +1,59 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+)
+
+// FeatureFlags - evaluates the feature flags gating subscriptions, see
+// WithFeatureFlags
+type FeatureFlags interface {
+	// Enabled reports whether flag is on for an event published with ctx,
+	// which carries the tags of the event (see TagsFromContext), e.g. its tenant
+	Enabled(ctx context.Context, flag string) bool
+}
+
+// FeatureFlagsFunc - function implementing FeatureFlags
+type FeatureFlagsFunc func(ctx context.Context, flag string) bool
+
+// Enabled calls f
+func (f FeatureFlagsFunc) Enabled(ctx context.Context, flag string) bool {
+	return f(ctx, flag)
+}
+
+// WithFeatureFlags evaluates the flags of the subscriptions gated with
+// Subscription.WithFlag with flags, for every event delivered to them. flags
+// is called with the bus lock held: it must be fast (e.g. read flags cached
+// by the flag provider's SDK) and must not use the bus.
+func WithFeatureFlags(flags FeatureFlags) Option {
+	return func(bus *Bus) {
+		bus.flags = flags
+	}
+}
+
+// WithFlag delivers to the subscription only the events for which the
+// feature flag evaluates true (see WithFeatureFlags), so a new handler can be
+// rolled out gradually, e.g. tenant by tenant, without code toggles. Events
+// the flag filters out go to the next handlers as if the subscription didn't
+// exist, e.g. to a standby exclusive handler. Without WithFeatureFlags, the
+// flag is off. An empty flag removes the gate.
+// Returns error if the subscription is not active.
+func (sub *Subscription) WithFlag(flag string) error {
+	bus := sub.bus
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	if _, idx := bus.locate(sub.handler); idx < 0 {
+		return fmt.Errorf("subscription to topic %s is not active", sub.topic)
+	}
+	sub.handler.flag = flag
+	return nil
+}
+
+// flagEnabled returns true if the feature flag of a handler, if it has one,
+// is on for an event published with ctx; the bus lock must be held
+func (bus *Bus) flagEnabled(ctx context.Context, handler *eventHandler) bool {
+	if handler.flag == "" {
+		return true
+	}
+	return bus.flags != nil && bus.flags.Enabled(ctx, handler.flag)
+}
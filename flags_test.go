@@ -0,0 +1,47 @@
+package eventbus
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestSubscriptionWithFlag(t *testing.T) {
+	bus := New(WithFeatureFlags(FeatureFlagsFunc(func(ctx context.Context, flag string) bool {
+		return flag == "new-billing" && TagsFromContext(ctx)["tenant"] == "beta"
+	})))
+	var legacy, rolledOut []string
+	bus.Subscribe("order", func(ctx context.Context) { legacy = append(legacy, TagsFromContext(ctx)["tenant"]) })
+	sub, _ := bus.SubscribeHandle("order", func(ctx context.Context) { rolledOut = append(rolledOut, TagsFromContext(ctx)["tenant"]) })
+	if err := sub.WithFlag("new-billing"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tenant := range []string{"alpha", "beta"} {
+		bus.PublishCtx(WithTags(context.Background(), map[string]string{"tenant": tenant}), "order")
+	}
+	if !slices.Equal(legacy, []string{"alpha", "beta"}) || !slices.Equal(rolledOut, []string{"beta"}) {
+		t.Fatal(legacy, rolledOut)
+	}
+
+	sub.WithFlag("")
+	bus.Publish("order")
+	if len(rolledOut) != 2 {
+		t.Fatal(rolledOut)
+	}
+	sub.Unsubscribe()
+	if sub.WithFlag("new-billing") == nil {
+		t.Fatal("flag set on inactive subscription")
+	}
+}
+
+func TestWithFlagExclusiveStandby(t *testing.T) {
+	bus := New() // no evaluator, flags are off
+	var handled []string
+	bus.SubscribeExclusive("job", func() { handled = append(handled, "new") })
+	bus.SubscribeExclusive("job", func() { handled = append(handled, "old") })
+	bus.handlersOf("job")[0].flag = "new-worker"
+	bus.Publish("job")
+	if !slices.Equal(handled, []string{"old"}) {
+		t.Fatal(handled)
+	}
+}
@@ -0,0 +1,198 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+)
+
+// PressureLevel - load of a topic's async handlers relative to its flow limit
+type PressureLevel int
+
+const (
+	// PressureNone - nothing in flight, or the topic has no flow limit
+	PressureNone PressureLevel = iota
+	// PressureLow - less than half of the limit in use
+	PressureLow
+	// PressureHigh - at least half of the limit in use
+	PressureHigh
+	// PressureSaturated - the limit is reached, AcquireCredit blocks
+	PressureSaturated
+)
+
+func (level PressureLevel) String() string {
+	switch level {
+	case PressureNone:
+		return "none"
+	case PressureLow:
+		return "low"
+	case PressureHigh:
+		return "high"
+	case PressureSaturated:
+		return "saturated"
+	}
+	return "unknown"
+}
+
+// flowControl counts the async deliveries in flight per topic
+type flowControl struct {
+	inFlight map[string]int
+	reserved map[string]int // credits acquired but not yet used by a publish
+	limits   map[string]int
+	changed  chan struct{} // closed whenever capacity is freed
+	sync.Mutex
+}
+
+// SetFlowLimit sets the number of async deliveries of a topic that may be in
+// flight before publishers are asked to slow down. Zero removes the limit.
+func (bus *Bus) SetFlowLimit(topic string, limit int) {
+	flow := &bus.flow
+	flow.Lock()
+	defer flow.Unlock()
+	if flow.limits == nil {
+		flow.limits = make(map[string]int)
+	}
+	if limit <= 0 {
+		delete(flow.limits, topic)
+	} else {
+		flow.limits[topic] = limit
+	}
+	flow.notify()
+}
+
+// Pressure returns the current pressure level of a topic, so well-behaved
+// publishers can slow down before handlers fall behind
+func (bus *Bus) Pressure(topic string) PressureLevel {
+	flow := &bus.flow
+	flow.Lock()
+	defer flow.Unlock()
+	limit := flow.limits[topic]
+	used := flow.inFlight[topic] + flow.reserved[topic]
+	switch {
+	case limit == 0 || used == 0:
+		return PressureNone
+	case used >= limit:
+		return PressureSaturated
+	case 2*used >= limit:
+		return PressureHigh
+	}
+	return PressureLow
+}
+
+// AcquireCredit blocks until the topic is below its flow limit and reserves a
+// slot for the caller's next publish, or returns the context error. The
+// credit is consumed by the next Publish on the topic. Topics without a flow
+// limit never block.
+func (bus *Bus) AcquireCredit(ctx context.Context, topic string) error {
+	flow := &bus.flow
+	for {
+		flow.Lock()
+		limit := flow.limits[topic]
+		if limit == 0 {
+			flow.Unlock()
+			return ctx.Err()
+		}
+		if flow.inFlight[topic]+flow.reserved[topic] < limit {
+			if flow.reserved == nil {
+				flow.reserved = make(map[string]int)
+			}
+			flow.reserved[topic]++
+			flow.Unlock()
+			return nil
+		}
+		if flow.changed == nil {
+			flow.changed = make(chan struct{})
+		}
+		changed := flow.changed
+		flow.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// InFlight returns the number of async deliveries of events published on a
+// topic that are queued or running, i.e. how far its async handlers lag behind
+func (bus *Bus) InFlight(topic string) int {
+	flow := &bus.flow
+	flow.Lock()
+	defer flow.Unlock()
+	return flow.inFlight[topic]
+}
+
+// WaitTopic waits for the async deliveries of events published on a topic to
+// complete, or ctx to be done, independently of the other topics (see WaitAsync).
+// Returns a *PendingWorkError wrapping ctx.Err() if ctx is done first.
+func (bus *Bus) WaitTopic(ctx context.Context, topic string) error {
+	flow := &bus.flow
+	for {
+		flow.Lock()
+		n := flow.inFlight[topic]
+		if n == 0 {
+			flow.Unlock()
+			return nil
+		}
+		if flow.changed == nil {
+			flow.changed = make(chan struct{})
+		}
+		changed := flow.changed
+		flow.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return &PendingWorkError{map[string]int{topic: bus.InFlight(topic)}, ctx.Err()}
+		}
+	}
+}
+
+// consume uses up a credit reserved for a publish on topic
+func (flow *flowControl) consume(topic string) {
+	flow.Lock()
+	defer flow.Unlock()
+	if flow.reserved[topic] > 0 {
+		flow.reserved[topic]--
+	}
+}
+
+// started counts an async delivery in flight, returning the topic's new count
+func (flow *flowControl) started(topic string) int {
+	flow.Lock()
+	defer flow.Unlock()
+	if flow.inFlight == nil {
+		flow.inFlight = make(map[string]int)
+	}
+	flow.inFlight[topic]++
+	return flow.inFlight[topic]
+}
+
+// finished counts the end of an async delivery, returning the topic's new count
+func (flow *flowControl) finished(topic string) int {
+	flow.Lock()
+	defer flow.Unlock()
+	flow.notify()
+	if flow.inFlight[topic]--; flow.inFlight[topic] <= 0 {
+		delete(flow.inFlight, topic)
+		return 0
+	}
+	return flow.inFlight[topic]
+}
+
+// pending returns the number of async deliveries in flight per topic
+func (flow *flowControl) pending() map[string]int {
+	flow.Lock()
+	defer flow.Unlock()
+	pending := make(map[string]int, len(flow.inFlight))
+	for topic, n := range flow.inFlight {
+		pending[topic] = n
+	}
+	return pending
+}
+
+// notify wakes up AcquireCredit callers; the lock must be held
+func (flow *flowControl) notify() {
+	if flow.changed != nil {
+		close(flow.changed)
+		flow.changed = nil
+	}
+}
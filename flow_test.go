@@ -0,0 +1,99 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFlowControl(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	bus.SubscribeAsync("topic", func() {
+		<-release
+	}, false)
+
+	if bus.Pressure("topic") != PressureNone {
+		t.Fail()
+	}
+	if bus.AcquireCredit(context.Background(), "topic") != nil {
+		t.Fail() // no limit, never blocks
+	}
+
+	bus.SetFlowLimit("topic", 4)
+	bus.Publish("topic")
+	if bus.Pressure("topic") != PressureLow {
+		t.Fail()
+	}
+	bus.Publish("topic")
+	if bus.Pressure("topic") != PressureHigh {
+		t.Fail()
+	}
+	for i := 0; i < 2; i++ {
+		if bus.AcquireCredit(context.Background(), "topic") != nil {
+			t.Fail()
+		}
+		bus.Publish("topic")
+	}
+	if bus.Pressure("topic") != PressureSaturated || bus.Pressure("topic").String() != "saturated" {
+		t.Fail()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if bus.AcquireCredit(ctx, "topic") != context.DeadlineExceeded {
+		t.Fail()
+	}
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- bus.AcquireCredit(context.Background(), "topic")
+	}()
+	close(release)
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fail()
+		}
+	case <-time.After(time.Second):
+		t.Fatal("credit not granted after handlers finished")
+	}
+	bus.WaitAsync()
+	if bus.Pressure("topic") != PressureLow {
+		t.Fail() // the unused credit still counts
+	}
+	bus.Publish("topic")
+	bus.WaitAsync()
+	if bus.Pressure("topic") != PressureNone {
+		t.Fail()
+	}
+}
+
+func TestWaitTopic(t *testing.T) {
+	bus := New()
+	slow := make(chan struct{})
+	bus.SubscribeAsync("slow", func() { <-slow }, false)
+	bus.SubscribeAsync("fast", func() { time.Sleep(5 * time.Millisecond) }, false)
+	bus.Publish("slow")
+	bus.Publish("fast")
+	bus.Publish("fast")
+	if bus.InFlight("slow") != 1 || bus.InFlight("other") != 0 {
+		t.Fail()
+	}
+
+	if err := bus.WaitTopic(context.Background(), "fast"); err != nil || bus.InFlight("fast") != 0 {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := bus.WaitTopic(ctx, "slow")
+	pending, ok := err.(*PendingWorkError)
+	if !ok || pending.Pending["slow"] != 1 {
+		t.Fatal(err)
+	}
+	close(slow)
+	bus.WaitAsync()
+	if bus.InFlight("slow") != 0 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,196 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultGatewayBuffer - default number of events queued per subscriber of
+// HTTPGateway
+const DefaultGatewayBuffer = 256
+
+// gatewayKeepAlive - interval of the comments keeping idle event streams open
+// through proxies
+const gatewayKeepAlive = 15 * time.Second
+
+// HTTPGatewayOptions - configuration of HTTPGateway
+type HTTPGatewayOptions struct {
+	// Allow reports whether the client of a request may subscribe to, or
+	// publish on if publish is set, a topic; nil allows every topic but the
+	// bus: control topics, whose events are also left out of the patterns
+	// subscribed to
+	Allow func(r *http.Request, topic string, publish bool) bool
+	// CheckOrigin accepts the origin of a request; nil accepts requests
+	// without Origin header and those from the host they are sent to, so
+	// other sites can't publish through their visitors' browsers
+	CheckOrigin func(r *http.Request) bool
+	// Buffer is the number of events queued per subscriber,
+	// DefaultGatewayBuffer if zero. A subscriber falling further behind is
+	// disconnected rather than slowing the publishers down.
+	Buffer int
+	// MaxBodySize limits the size of published bodies, 1 MiB if zero
+	MaxBodySize int64
+}
+
+// HTTPGateway returns an http.Handler exposing the bus to clients without
+// client library: POST /publish/{topic} publishes the JSON body (an array of
+// arguments, or a single argument) and answers the event ID as JSON, GET
+// /subscribe/{topic} streams the events of a topic (possibly a wildcard
+// pattern) as Server-Sent Events with the topic as event type and the JSON
+// array of arguments as data. Published arguments are decoded from JSON as
+// interface{} values (float64, string, bool, []interface{},
+// map[string]interface{}).
+func (bus *Bus) HTTPGateway(opts *HTTPGatewayOptions) http.Handler {
+	options := HTTPGatewayOptions{}
+	if opts != nil {
+		options = *opts
+	}
+	if options.Buffer <= 0 {
+		options.Buffer = DefaultGatewayBuffer
+	}
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = 1 << 20
+	}
+	if options.CheckOrigin == nil {
+		options.CheckOrigin = sameOrigin
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !options.CheckOrigin(r) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		if topic, ok := strings.CutPrefix(r.URL.Path, "/publish/"); ok {
+			if r.Method != http.MethodPost {
+				w.Header().Set("Allow", "POST")
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			bus.gatewayPublish(w, r, topic, options)
+		} else if topic, ok := strings.CutPrefix(r.URL.Path, "/subscribe/"); ok {
+			if r.Method != http.MethodGet {
+				w.Header().Set("Allow", "GET")
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			bus.gatewaySubscribe(w, r, topic, options)
+		} else {
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func (bus *Bus) gatewayPublish(w http.ResponseWriter, r *http.Request, topic string, options HTTPGatewayOptions) {
+	if !allowTopic(options.Allow, r, topic, true) {
+		http.Error(w, fmt.Sprintf("publishing on topic %s is not allowed", topic), http.StatusForbidden)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, options.MaxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	ev := Event{Topic: topic, ID: bus.NewID()}
+	if len(body) > 0 {
+		var arg interface{}
+		if err := json.Unmarshal(body, &arg); err != nil {
+			http.Error(w, "body must be JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if args, ok := arg.([]interface{}); ok {
+			ev.Args = args
+		} else {
+			ev.Args = []interface{}{arg}
+		}
+	}
+	if err := bus.PublishEvent(ev); err != nil {
+		http.Error(w, err.Error(), gatewayStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"id": ev.ID})
+}
+
+// gatewayStatus returns the HTTP status of a rejected publish
+func gatewayStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidTopic):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrNoSubscribers):
+		return http.StatusNotFound
+	case errors.Is(err, ErrBusClosed):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+func (bus *Bus) gatewaySubscribe(w http.ResponseWriter, r *http.Request, topic string, options HTTPGatewayOptions) {
+	if !allowTopic(options.Allow, r, topic, false) {
+		http.Error(w, fmt.Sprintf("subscribing to topic %s is not allowed", topic), http.StatusForbidden)
+		return
+	}
+	events := make(chan Event, options.Buffer)
+	behind := make(chan struct{})
+	once := sync.Once{}
+	sub, err := bus.SubscribeHandle(topic, func(ev Event) {
+		if options.Allow == nil && strings.HasPrefix(ev.Topic, controlPrefix) {
+			return // control event matching a pattern
+		}
+		select {
+		case events <- ev:
+		default:
+			once.Do(func() { close(behind) })
+		}
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer sub.Unsubscribe()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher := http.NewResponseController(w)
+	flusher.Flush()
+	keepAlive := time.NewTicker(gatewayKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case ev := <-events:
+			writeServerSentEvent(w, ev)
+		case <-keepAlive.C:
+			io.WriteString(w, ": keep-alive\n\n")
+		case <-behind:
+			return
+		case <-r.Context().Done():
+			return
+		}
+		if flusher.Flush() != nil {
+			return
+		}
+	}
+}
+
+// sseField - strips the line breaks that would end a Server-Sent Events field
+var sseField = strings.NewReplacer("\r", "", "\n", "")
+
+// writeServerSentEvent writes an event as a Server-Sent Event, or an error
+// event if its arguments can't be encoded as JSON
+func writeServerSentEvent(w io.Writer, ev Event) {
+	if ev.ID != "" {
+		fmt.Fprintf(w, "id: %s\n", sseField.Replace(ev.ID))
+	}
+	data, err := json.Marshal(ev.Args)
+	if err != nil {
+		data, _ = json.Marshal(err.Error())
+		fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", sseField.Replace(ev.Topic), data)
+}
@@ -0,0 +1,100 @@
+package eventbus
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPGateway(t *testing.T) {
+	bus := New()
+	server := httptest.NewServer(bus.HTTPGateway(&HTTPGatewayOptions{
+		Allow: func(r *http.Request, topic string, publish bool) bool {
+			return !strings.HasPrefix(topic, "private")
+		},
+	}))
+	defer server.Close()
+
+	stream, err := http.Get(server.URL + "/subscribe/orders/created")
+	if err != nil || stream.StatusCode != http.StatusOK || stream.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatal(stream, err)
+	}
+	defer stream.Body.Close()
+
+	resp, err := http.Post(server.URL+"/publish/orders/created", "application/json", strings.NewReader(`["order 1", 2]`))
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatal(resp, err)
+	}
+	published := map[string]string{}
+	json.NewDecoder(resp.Body).Decode(&published)
+	resp.Body.Close()
+
+	reader := bufio.NewReader(stream.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	if lines[0] != "id: "+published["id"] || lines[1] != "event: orders/created" || lines[2] != `data: ["order 1",2]` {
+		t.Fatal(lines)
+	}
+
+	received := make(chan string, 1)
+	bus.Subscribe("greeting", func(text string) { received <- text })
+	if resp, err := http.Post(server.URL+"/publish/greeting", "application/json", strings.NewReader(`"hello"`)); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatal(resp, err)
+	}
+	if <-received != "hello" {
+		t.Fail()
+	}
+}
+
+func TestHTTPGatewayRejected(t *testing.T) {
+	bus := New(WithNoSubscriberPolicy(NoSubscriberError))
+	server := httptest.NewServer(bus.HTTPGateway(&HTTPGatewayOptions{
+		Allow: func(r *http.Request, topic string, publish bool) bool { return topic != "private" },
+	}))
+	defer server.Close()
+	for _, test := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodPost, "/publish/private", "", http.StatusForbidden},
+		{http.MethodGet, "/subscribe/private", "", http.StatusForbidden},
+		{http.MethodPost, "/publish/orders", "{", http.StatusBadRequest},
+		{http.MethodPost, "/publish/orders", "", http.StatusNotFound},
+		{http.MethodPost, "/publish/" + TopicPanic, "", http.StatusBadRequest},
+		{http.MethodGet, "/publish/orders", "", http.StatusMethodNotAllowed},
+	} {
+		req, _ := http.NewRequest(test.method, server.URL+test.path, strings.NewReader(test.body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil || resp.StatusCode != test.status {
+			t.Error(test, resp, err)
+		}
+	}
+}
+
+func TestHTTPGatewayDefaults(t *testing.T) {
+	server := httptest.NewServer(New().HTTPGateway(nil))
+	defer server.Close()
+	if resp, err := http.Get(server.URL + "/subscribe/" + TopicPanic); err != nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatal(resp, err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/publish/orders", strings.NewReader("order=1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Origin", "https://evil.example")
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatal(resp, err)
+	}
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/publish/orders", strings.NewReader("1"))
+	req.Header.Set("Origin", server.URL)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatal(resp, err)
+	}
+}
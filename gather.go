@@ -0,0 +1,141 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// gatherKey - context key of the gathering of a PublishGather call
+type gatherKey struct{}
+
+// gathering - return values and errors of the handlers of an event published
+// with PublishGather
+type gathering struct {
+	values   []interface{}
+	errs     []error
+	pending  int  // async deliveries not finished yet
+	sealed   bool // the publish returned, no more deliveries are added
+	finished bool // PublishGather returned, later results are ignored
+	done     chan struct{}
+	lock     sync.Mutex
+}
+
+// gatheringFrom returns the gathering of ctx, nil if it has none
+func gatheringFrom(ctx context.Context) *gathering {
+	g, _ := ctx.Value(gatherKey{}).(*gathering)
+	return g
+}
+
+// PublishGather runs PublishGather on package-level bus singleton
+func PublishGather(ctx context.Context, topic string, args ...interface{}) ([]interface{}, error) {
+	return b.Load().PublishGather(ctx, topic, args...)
+}
+
+// PublishGather works like PublishCtx, then waits for the async deliveries of
+// the event and returns the values returned by all its handlers, synchronous
+// ones in delivery order and async ones as they complete. A handler
+// returning a single value (besides a trailing error) adds it as is, one
+// returning several adds them as a []interface{} and one returning none adds
+// nothing. The events the handlers publish themselves are not gathered.
+// Returns the errors of the failed handlers joined, or ctx.Err() along with
+// the values gathered so far if ctx is done before all deliveries complete.
+func (bus *Bus) PublishGather(ctx context.Context, topic string, args ...interface{}) ([]interface{}, error) {
+	g := &gathering{done: make(chan struct{})}
+	if err := bus.PublishCtx(context.WithValue(ctx, gatherKey{}, g), topic, args...); err != nil {
+		g.finish()
+		return nil, err
+	}
+	g.seal()
+	select {
+	case <-g.done:
+	case <-ctx.Done():
+		values, _ := g.finish()
+		return values, ctx.Err()
+	}
+	values, errs := g.finish()
+	return values, errors.Join(errs...)
+}
+
+// add records an async delivery about to be scheduled
+func (g *gathering) add() {
+	if g == nil {
+		return
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.pending++
+}
+
+// release records the end of an async delivery
+func (g *gathering) release() {
+	if g == nil {
+		return
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.pending--
+	g.complete()
+}
+
+// seal records that the publish returned, all the deliveries being added
+func (g *gathering) seal() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.sealed = true
+	g.complete()
+}
+
+// complete closes done once all the deliveries finished; g.lock must be held
+func (g *gathering) complete() {
+	if g.sealed && g.pending == 0 && !g.finished {
+		g.finished = true
+		close(g.done)
+	}
+}
+
+// finish stops gathering and returns what was gathered
+func (g *gathering) finish() ([]interface{}, []error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if !g.finished {
+		g.finished = true
+		close(g.done)
+	}
+	return g.values, g.errs
+}
+
+// collect records the values returned by a handler, except a trailing error
+func (g *gathering) collect(results []reflect.Value) {
+	if g == nil {
+		return
+	}
+	if n := len(results); n > 0 && results[n-1].Type() == errorType {
+		results = results[:n-1]
+	}
+	if len(results) == 0 {
+		return
+	}
+	value := interface{}(interfaces(results))
+	if len(results) == 1 {
+		value = results[0].Interface()
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if !g.finished {
+		g.values = append(g.values, value)
+	}
+}
+
+// fail records the error of a handler
+func (g *gathering) fail(err error) {
+	if g == nil {
+		return
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if !g.finished {
+		g.errs = append(g.errs, err)
+	}
+}
@@ -0,0 +1,66 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPublishGather(t *testing.T) {
+	bus := New()
+	bus.Subscribe("quote", func(symbol string) float64 { return 1 })
+	bus.Subscribe("quote", func(symbol string) {})
+	bus.SubscribeAsync("quote", func(ctx context.Context, symbol string) (float64, error) {
+		time.Sleep(5 * time.Millisecond)
+		bus.PublishCtx(ctx, "quote:seen", symbol) // not gathered
+		return 2, nil
+	}, false)
+	bus.Subscribe("quote", func(symbol string) (string, int) { return symbol, 3 })
+	bus.Subscribe("quote:seen", func(symbol string) int { return 4 })
+
+	values, err := bus.PublishGather(context.Background(), "quote", "AAPL")
+	if err != nil || len(values) != 3 || values[0] != 1.0 || values[2] != 2.0 {
+		t.Fatal(values, err)
+	}
+	if pair, ok := values[1].([]interface{}); !ok || !slices.Equal(pair, []interface{}{"AAPL", 3}) {
+		t.Fatal(values[1])
+	}
+	bus.WaitAsync()
+}
+
+func TestPublishGatherErrors(t *testing.T) {
+	bus := New()
+	bus.Subscribe("job", func() (int, error) { return 0, errors.New("sync failure") })
+	bus.SubscribeAsync("job", func() (int, error) { return 0, errors.New("async failure") }, false)
+	bus.Subscribe("job", func() (int, error) { return 1, nil })
+
+	values, err := bus.PublishGather(context.Background(), "job")
+	var handlerErr *HandlerError
+	if !errors.As(err, &handlerErr) || len(values) != 1 || values[0] != 1 {
+		t.Fatal(values, err)
+	}
+	bus.Close(context.Background())
+	if _, err := bus.PublishGather(context.Background(), "job"); !errors.Is(err, ErrBusClosed) {
+		t.Fatal(err)
+	}
+}
+
+func TestPublishGatherTimeout(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	bus.Subscribe("slow", func() int { return 1 })
+	bus.SubscribeAsync("slow", func() int {
+		<-release
+		return 2
+	}, false)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	values, err := bus.PublishGather(ctx, "slow")
+	if !errors.Is(err, context.DeadlineExceeded) || len(values) != 1 || values[0] != 1 {
+		t.Fatal(values, err)
+	}
+	close(release)
+	bus.WaitAsync()
+}
@@ -0,0 +1,367 @@
+// Package gcppubsub emulates the Google Cloud Pub/Sub client
+// (cloud.google.com/go/pubsub) on an event bus, so code written against
+// Pub/Sub topics and subscriptions can run in-process, locally or in tests,
+// without the Pub/Sub emulator.
+//
+// Types and methods mirror the part of the client most code uses, without
+// depending on it: code calling the client through a small interface of its
+// own switches with an adapter of a few lines. Topics are bus topics named
+// after the topic resource name; every subscription receives each message
+// published after it was created, and redelivers the messages that are
+// nacked or not acked within the ack deadline.
+package gcppubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+// DefaultAckDeadline - ack deadline of the subscriptions configured without one
+const DefaultAckDeadline = 10 * time.Second
+
+var (
+	// ErrNotFound - error of the operations on a topic or subscription that
+	// doesn't exist
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists - error creating a topic or subscription that exists
+	ErrAlreadyExists = errors.New("already exists")
+)
+
+// Message - a Pub/Sub message
+type Message struct {
+	ID              string
+	Data            []byte
+	Attributes      map[string]string
+	PublishTime     time.Time
+	DeliveryAttempt *int // 1 on the first delivery to a subscription
+
+	done func(ack bool)
+}
+
+// Ack acknowledges the message, which is not redelivered
+func (m *Message) Ack() {
+	if m.done != nil {
+		m.done(true)
+	}
+}
+
+// Nack redelivers the message right away
+func (m *Message) Nack() {
+	if m.done != nil {
+		m.done(false)
+	}
+}
+
+// Client - Pub/Sub client of a project, backed by an event bus
+type Client struct {
+	bus     *eventbus.Bus
+	project string
+	topics  map[string]bool
+	subs    map[string]*subscription
+	lock    sync.Mutex
+}
+
+// NewClient returns a client of the project whose topics and subscriptions
+// live on bus
+func NewClient(bus *eventbus.Bus, projectID string) *Client {
+	return &Client{
+		bus:     bus,
+		project: projectID,
+		topics:  make(map[string]bool),
+		subs:    make(map[string]*subscription),
+	}
+}
+
+// Close deletes the subscriptions of the client
+func (c *Client) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for id, sub := range c.subs {
+		sub.handle.Unsubscribe()
+		delete(c.subs, id)
+	}
+	return nil
+}
+
+// CreateTopic creates a topic.
+// Returns ErrAlreadyExists if it exists.
+func (c *Client) CreateTopic(ctx context.Context, id string) (*Topic, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.topics[id] {
+		return nil, fmt.Errorf("topic %s: %w", id, ErrAlreadyExists)
+	}
+	c.topics[id] = true
+	return c.Topic(id), nil
+}
+
+// Topic returns a reference to a topic, which may not exist
+func (c *Client) Topic(id string) *Topic {
+	return &Topic{client: c, id: id}
+}
+
+// CreateSubscription creates a subscription to cfg.Topic.
+// Returns ErrAlreadyExists if it exists, ErrNotFound if the topic doesn't.
+func (c *Client) CreateSubscription(ctx context.Context, id string, cfg SubscriptionConfig) (*Subscription, error) {
+	if cfg.Topic == nil {
+		return nil, errors.New("subscription config without topic")
+	}
+	if cfg.AckDeadline <= 0 {
+		cfg.AckDeadline = DefaultAckDeadline
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.subs[id]; ok {
+		return nil, fmt.Errorf("subscription %s: %w", id, ErrAlreadyExists)
+	}
+	if !c.topics[cfg.Topic.id] {
+		return nil, fmt.Errorf("topic %s: %w", cfg.Topic.id, ErrNotFound)
+	}
+	sub := &subscription{config: cfg, ready: make(chan struct{}, 1)}
+	handle, err := c.bus.SubscribeHandle(cfg.Topic.String(), sub.push)
+	if err != nil {
+		return nil, err
+	}
+	sub.handle = handle
+	c.subs[id] = sub
+	return c.Subscription(id), nil
+}
+
+// Subscription returns a reference to a subscription, which may not exist
+func (c *Client) Subscription(id string) *Subscription {
+	return &Subscription{client: c, id: id}
+}
+
+// Topic - reference to a Pub/Sub topic
+type Topic struct {
+	client *Client
+	id     string
+}
+
+// ID returns the ID of the topic
+func (t *Topic) ID() string {
+	return t.id
+}
+
+// String returns the resource name of the topic, which is its bus topic
+func (t *Topic) String() string {
+	return fmt.Sprintf("projects/%s/topics/%s", t.client.project, t.id)
+}
+
+// Exists reports whether the topic exists
+func (t *Topic) Exists(ctx context.Context) (bool, error) {
+	t.client.lock.Lock()
+	defer t.client.lock.Unlock()
+	return t.client.topics[t.id], nil
+}
+
+// Delete deletes the topic; its subscriptions stop receiving messages
+func (t *Topic) Delete(ctx context.Context) error {
+	t.client.lock.Lock()
+	defer t.client.lock.Unlock()
+	if !t.client.topics[t.id] {
+		return fmt.Errorf("topic %s: %w", t.id, ErrNotFound)
+	}
+	delete(t.client.topics, t.id)
+	return nil
+}
+
+// Publish publishes msg on the topic, which its subscriptions receive
+// asynchronously. The returned result is ready right away.
+func (t *Topic) Publish(ctx context.Context, msg *Message) *PublishResult {
+	result := &PublishResult{ready: make(chan struct{})}
+	defer close(result.ready)
+	if exists, _ := t.Exists(ctx); !exists {
+		result.err = fmt.Errorf("topic %s: %w", t.id, ErrNotFound)
+		return result
+	}
+	published := Message{
+		ID:          t.client.bus.NewID(),
+		Data:        msg.Data,
+		Attributes:  msg.Attributes,
+		PublishTime: time.Now(),
+	}
+	result.id, result.err = published.ID, t.client.bus.PublishCtx(ctx, t.String(), published)
+	return result
+}
+
+// Stop sends the outstanding messages; publishing is synchronous, so there are none
+func (t *Topic) Stop() {}
+
+// PublishResult - result of Topic.Publish
+type PublishResult struct {
+	id    string
+	err   error
+	ready chan struct{}
+}
+
+// Ready returns a channel closed when the result is ready
+func (r *PublishResult) Ready() <-chan struct{} {
+	return r.ready
+}
+
+// Get returns the ID of the published message.
+// Returns error if the message wasn't published.
+func (r *PublishResult) Get(ctx context.Context) (serverID string, err error) {
+	select {
+	case <-r.ready:
+		return r.id, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// SubscriptionConfig - configuration of a subscription
+type SubscriptionConfig struct {
+	Topic *Topic
+	// AckDeadline - time after which a message delivered and not acked is
+	// redelivered; DefaultAckDeadline if zero
+	AckDeadline time.Duration
+}
+
+// Subscription - reference to a Pub/Sub subscription
+type Subscription struct {
+	client *Client
+	id     string
+}
+
+// subscription - messages of a subscription waiting for a delivery
+type subscription struct {
+	config  SubscriptionConfig
+	handle  *eventbus.Subscription
+	pending []*delivery
+	ready   chan struct{} // signaled when pending grows
+	lock    sync.Mutex
+}
+
+// delivery - message delivered to a subscription
+type delivery struct {
+	msg      Message
+	attempts int
+}
+
+// ID returns the ID of the subscription
+func (s *Subscription) ID() string {
+	return s.id
+}
+
+// Exists reports whether the subscription exists
+func (s *Subscription) Exists(ctx context.Context) (bool, error) {
+	return s.state() != nil, nil
+}
+
+// Config returns the configuration of the subscription
+func (s *Subscription) Config(ctx context.Context) (SubscriptionConfig, error) {
+	sub := s.state()
+	if sub == nil {
+		return SubscriptionConfig{}, fmt.Errorf("subscription %s: %w", s.id, ErrNotFound)
+	}
+	return sub.config, nil
+}
+
+// Delete deletes the subscription and drops its messages
+func (s *Subscription) Delete(ctx context.Context) error {
+	s.client.lock.Lock()
+	defer s.client.lock.Unlock()
+	sub, ok := s.client.subs[s.id]
+	if !ok {
+		return fmt.Errorf("subscription %s: %w", s.id, ErrNotFound)
+	}
+	sub.handle.Unsubscribe()
+	delete(s.client.subs, s.id)
+	return nil
+}
+
+// Receive calls f, each time in a new goroutine, with the messages of the
+// subscription until ctx is done, then waits for the calls to return. f must
+// ack or nack the message; messages not acked within the ack deadline are
+// redelivered.
+// Returns ErrNotFound if the subscription doesn't exist.
+func (s *Subscription) Receive(ctx context.Context, f func(context.Context, *Message)) error {
+	sub := s.state()
+	if sub == nil {
+		return fmt.Errorf("subscription %s: %w", s.id, ErrNotFound)
+	}
+	wg := sync.WaitGroup{}
+	defer wg.Wait()
+	for ctx.Err() == nil {
+		d := sub.pop()
+		if d == nil {
+			select {
+			case <-sub.ready:
+			case <-ctx.Done():
+			}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f(ctx, sub.deliver(d))
+		}()
+	}
+	return nil
+}
+
+// state returns the state of an existing subscription, nil if it doesn't exist
+func (s *Subscription) state() *subscription {
+	s.client.lock.Lock()
+	defer s.client.lock.Unlock()
+	return s.client.subs[s.id]
+}
+
+// push queues a message published on the topic of the subscription
+func (sub *subscription) push(msg Message) {
+	sub.requeue(&delivery{msg: msg})
+}
+
+// requeue queues a delivery for Receive
+func (sub *subscription) requeue(d *delivery) {
+	sub.lock.Lock()
+	sub.pending = append(sub.pending, d)
+	sub.lock.Unlock()
+	select {
+	case sub.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop returns the next delivery, nil if there is none
+func (sub *subscription) pop() *delivery {
+	sub.lock.Lock()
+	defer sub.lock.Unlock()
+	if len(sub.pending) == 0 {
+		return nil
+	}
+	d := sub.pending[0]
+	sub.pending[0] = nil
+	sub.pending = sub.pending[1:]
+	return d
+}
+
+// deliver returns the message of a delivery, requeued if it is nacked or not
+// acked within the ack deadline
+func (sub *subscription) deliver(d *delivery) *Message {
+	d.attempts++
+	attempts := d.attempts
+	msg := d.msg
+	msg.DeliveryAttempt = &attempts
+	once := sync.Once{}
+	var expiry *time.Timer
+	msg.done = func(ack bool) {
+		once.Do(func() {
+			expiry.Stop()
+			if !ack {
+				sub.requeue(d)
+			}
+		})
+	}
+	expiry = time.AfterFunc(sub.config.AckDeadline, func() {
+		once.Do(func() { sub.requeue(d) })
+	})
+	return &msg
+}
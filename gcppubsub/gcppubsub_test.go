@@ -0,0 +1,92 @@
+package gcppubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+func TestPublishReceive(t *testing.T) {
+	ctx := context.Background()
+	client := NewClient(eventbus.New(), "test")
+	defer client.Close()
+	if _, err := client.Topic("orders").Publish(ctx, &Message{}).Get(ctx); !errors.Is(err, ErrNotFound) {
+		t.Fatal(err)
+	}
+	topic, err := client.CreateTopic(ctx, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateTopic(ctx, "orders"); !errors.Is(err, ErrAlreadyExists) {
+		t.Fatal(err)
+	}
+	billing, err := client.CreateSubscription(ctx, "billing", SubscriptionConfig{Topic: topic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shipping, err := client.CreateSubscription(ctx, "shipping", SubscriptionConfig{Topic: topic})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := topic.Publish(ctx, &Message{Data: []byte("order 1"), Attributes: map[string]string{"tenant": "a"}}).Get(ctx)
+	if err != nil || id == "" {
+		t.Fatal(id, err)
+	}
+	for _, sub := range []*Subscription{billing, shipping} {
+		received := receiveOne(t, sub, func(msg *Message) { msg.Ack() })
+		if received.ID != id || string(received.Data) != "order 1" || received.Attributes["tenant"] != "a" || *received.DeliveryAttempt != 1 {
+			t.Fatal(received)
+		}
+	}
+
+	if billing.Delete(ctx) != nil || billing.Delete(ctx) == nil {
+		t.Fail()
+	}
+	if exists, _ := billing.Exists(ctx); exists {
+		t.Fail()
+	}
+}
+
+func TestRedelivery(t *testing.T) {
+	ctx := context.Background()
+	client := NewClient(eventbus.New(), "test")
+	topic, _ := client.CreateTopic(ctx, "jobs")
+	sub, _ := client.CreateSubscription(ctx, "workers", SubscriptionConfig{Topic: topic, AckDeadline: 10 * time.Millisecond})
+	topic.Publish(ctx, &Message{Data: []byte("job")})
+
+	receiveOne(t, sub, func(msg *Message) { msg.Nack() })
+	receiveOne(t, sub, func(msg *Message) {}) // ack deadline expires
+	received := receiveOne(t, sub, func(msg *Message) { msg.Ack() })
+	if *received.DeliveryAttempt != 3 {
+		t.Fatal(*received.DeliveryAttempt)
+	}
+	receiveCtx, cancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	defer cancel()
+	sub.Receive(receiveCtx, func(ctx context.Context, msg *Message) {
+		t.Fatal("acked message redelivered")
+	})
+}
+
+// receiveOne receives a message of sub, handled with handle
+func receiveOne(t *testing.T, sub *Subscription, handle func(msg *Message)) *Message {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var received *Message
+	once := sync.Once{}
+	err := sub.Receive(ctx, func(_ context.Context, msg *Message) {
+		once.Do(func() {
+			received = msg
+			cancel() // before handle redelivers msg
+			handle(msg)
+		})
+	})
+	if err != nil || received == nil {
+		t.Fatal(err, received)
+	}
+	return received
+}
@@ -0,0 +1,108 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// TopicGroupFailure - control topic receiving GroupFailure events
+const TopicGroupFailure = "bus:group"
+
+// GroupStep - member of a handler group
+type GroupStep struct {
+	// Do handles the event. The step fails if it panics or returns a non-nil
+	// error as its last result.
+	Do interface{}
+	// Compensate undoes Do and is called with the same arguments when a later
+	// step of the group fails. Optional.
+	Compensate interface{}
+}
+
+// GroupFailure - published when a step of a handler group failed and the
+// previous steps were compensated
+type GroupFailure struct {
+	Topic string
+	Step  int // index of the failed step
+	Err   error
+	Args  []interface{}
+}
+
+// Group - handle of the subscriptions of a handler group to its topics, see
+// SubscribeGroup
+type Group struct {
+	subs []*Subscription
+}
+
+// SubscribeGroup subscribes a group of steps to one or more topics. An event on
+// any of the topics runs the steps in order; if one fails, the Compensate
+// callbacks of the steps that already succeeded run in reverse order and a
+// GroupFailure is published on TopicGroupFailure.
+// Returns error if a step is not a function or the subscription to a topic
+// fails; the group is then subscribed to none of them.
+func (bus *Bus) SubscribeGroup(topics []string, steps ...GroupStep) (*Group, error) {
+	if len(steps) == 0 {
+		return nil, errors.New("handler group has no steps")
+	}
+	for i, step := range steps {
+		if reflect.TypeOf(step.Do) == nil || reflect.TypeOf(step.Do).Kind() != reflect.Func {
+			return nil, fmt.Errorf("step %d: Do is not of type reflect.Func", i)
+		}
+		if step.Compensate != nil && reflect.TypeOf(step.Compensate).Kind() != reflect.Func {
+			return nil, fmt.Errorf("step %d: Compensate is not of type reflect.Func", i)
+		}
+	}
+	group := &Group{}
+	for _, topic := range topics {
+		topic := topic
+		sub, err := bus.SubscribeHandle(topic, func(args ...interface{}) {
+			bus.runGroup(topic, steps, args)
+		})
+		if err != nil {
+			group.Unsubscribe()
+			return nil, err
+		}
+		group.subs = append(group.subs, sub)
+	}
+	return group, nil
+}
+
+// Unsubscribe removes the subscriptions of the group to all its topics.
+// Returns error if some of them were no longer active.
+func (group *Group) Unsubscribe() error {
+	var errs []error
+	for _, sub := range group.subs {
+		if err := sub.Unsubscribe(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (bus *Bus) runGroup(topic string, steps []GroupStep, args []interface{}) {
+	for i, step := range steps {
+		err := bus.callStep(topic, step.Do, args)
+		if err == nil {
+			continue
+		}
+		for j := i - 1; j >= 0; j-- {
+			if steps[j].Compensate != nil {
+				bus.callStep(topic, steps[j].Compensate, args)
+			}
+		}
+		bus.publishControl(TopicGroupFailure, GroupFailure{topic, i, err, args})
+		return
+	}
+}
+
+// callStep calls fn with args and returns its error result, or the report of
+// its panic
+func (bus *Bus) callStep(topic string, fn interface{}, args []interface{}) error {
+	handler := &eventHandler{callBack: reflect.ValueOf(fn)}
+	results, report := callRecovered(handler.callBack.Call, bus.setUpPublish(handler, "", args...))
+	if report != nil {
+		bus.reportPanic(report.describe(topic, handler.callBack, args))
+		return report
+	}
+	return resultError(results)
+}
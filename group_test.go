@@ -0,0 +1,100 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSubscribeGroup(t *testing.T) {
+	bus := New()
+	failures := make(chan GroupFailure, 1)
+	bus.Subscribe(TopicGroupFailure, func(failure GroupFailure) {
+		failures <- failure
+	})
+
+	stock, charged, shipped := 10, 0, 0
+	reserve := GroupStep{
+		Do:         func(qty int) { stock -= qty },
+		Compensate: func(qty int) { stock += qty },
+	}
+	charge := GroupStep{
+		Do:         func(qty int) error { charged += qty; return nil },
+		Compensate: func(qty int) { charged -= qty },
+	}
+	ship := GroupStep{
+		Do: func(qty int) error {
+			if qty > 5 {
+				return errors.New("too heavy")
+			}
+			shipped += qty
+			return nil
+		},
+	}
+	if _, err := bus.SubscribeGroup([]string{"order:placed", "order:replayed"}, reserve, charge, ship); err != nil {
+		t.Fail()
+	}
+	if _, err := bus.SubscribeGroup([]string{"x"}, GroupStep{Do: "String"}); err == nil {
+		t.Fail()
+	}
+	if _, err := bus.SubscribeGroup([]string{"x"}); err == nil {
+		t.Fail()
+	}
+
+	bus.Publish("order:placed", 2)
+	bus.Publish("order:replayed", 1)
+	if stock != 7 || charged != 3 || shipped != 3 {
+		t.Fail()
+	}
+
+	bus.Publish("order:placed", 6)
+	bus.WaitAsync()
+	if stock != 7 || charged != 3 || shipped != 3 {
+		t.Log(stock, charged, shipped)
+		t.Fail()
+	}
+	failure := <-failures
+	if failure.Step != 2 || failure.Topic != "order:placed" || failure.Err.Error() != "too heavy" {
+		t.Log(failure)
+		t.Fail()
+	}
+}
+
+func TestSubscribeGroupPanic(t *testing.T) {
+	bus := New()
+	compensated := false
+	bus.SubscribeGroup([]string{"topic"}, GroupStep{
+		Do:         func() {},
+		Compensate: func() { compensated = true },
+	}, GroupStep{
+		Do: func() { panic("boom") },
+	})
+	bus.Publish("topic")
+	if !compensated {
+		t.Fail()
+	}
+}
+
+func TestSubscribeGroupUnsubscribe(t *testing.T) {
+	bus := New(WithSeparator("/"))
+	step := GroupStep{Do: func() {}}
+	if _, err := bus.SubscribeGroup([]string{"a", "b", "c/#/d"}, step); err == nil {
+		t.Fatal("invalid pattern accepted")
+	}
+	if bus.HasCallback("a") || bus.HasCallback("b") {
+		t.Fatal("failed group left subscribed")
+	}
+
+	group, err := bus.SubscribeGroup([]string{"a", "b"}, step)
+	if err != nil || !bus.HasCallback("a") || !bus.HasCallback("b") {
+		t.Fatal(err)
+	}
+	if err := group.Unsubscribe(); err != nil {
+		t.Fatal(err)
+	}
+	if bus.HasCallback("a") || bus.HasCallback("b") {
+		t.Fatal("group still subscribed")
+	}
+	if group.Unsubscribe() == nil {
+		t.Fatal("second Unsubscribe succeeded")
+	}
+}
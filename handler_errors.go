@@ -0,0 +1,64 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// HandlerError - error returned by a handler for an event
+type HandlerError struct {
+	Topic   string
+	Handler string // name of the handler function
+	Err     error
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("handler %s for topic %s: %v", e.Handler, e.Topic, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// WithErrorSink sends the errors returned by async handlers to sink, which
+// runs on the handler's goroutine. Without a sink they are discarded.
+func WithErrorSink(sink func(err *HandlerError)) Option {
+	return func(bus *Bus) {
+		bus.errorSink = sink
+	}
+}
+
+// PublishWithResult runs PublishWithResult on package-level bus singleton
+func PublishWithResult(topic string, args ...interface{}) []error {
+	return b.Load().PublishWithResult(topic, args...)
+}
+
+// resultKey - context key of the errors collected for PublishWithResult
+type resultKey struct{}
+
+// PublishWithResult publishes like Publish and returns the errors returned by
+// the synchronous handlers, as *HandlerError values in delivery order, or the
+// error rejecting the publish. Handlers return an error as their last result.
+func (bus *Bus) PublishWithResult(topic string, args ...interface{}) []error {
+	var errs []error
+	ctx := context.WithValue(context.Background(), resultKey{}, &errs)
+	if err := bus.intercept(ctx, topic, args, bus.publishChecked); err != nil && !slices.Contains(errs, err) {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// handlerError wraps the error among the results of handler, or the report of
+// its recovered panic, if any
+func handlerError(topic string, handler *eventHandler, results []reflect.Value, report *PanicReport) error {
+	err := resultError(results)
+	if report != nil {
+		err = report
+	}
+	if err == nil {
+		return nil
+	}
+	return &HandlerError{topic, handlerName(handler.callBack.Pointer()), err}
+}
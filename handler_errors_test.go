@@ -0,0 +1,92 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var errTest = errors.New("test error")
+
+func failingHandler(n int) error {
+	if n < 0 {
+		return errTest
+	}
+	return nil
+}
+
+func TestPublishWithResult(t *testing.T) {
+	bus := New()
+	bus.Subscribe("topic", failingHandler)
+	bus.Subscribe("topic", func(n int) {})
+	bus.Subscribe("topic", func(n int) (int, error) { return n, errors.New("second") })
+	bus.SubscribeAsync("topic", failingHandler, false)
+
+	errs := bus.PublishWithResult("topic", -1)
+	bus.WaitAsync()
+	if len(errs) != 2 {
+		t.Fatal(errs)
+	}
+	var handlerErr *HandlerError
+	if !errors.As(errs[0], &handlerErr) || !errors.Is(errs[0], errTest) {
+		t.Fail()
+	}
+	if handlerErr.Topic != "topic" || !strings.HasSuffix(handlerErr.Handler, "failingHandler") {
+		t.Error(handlerErr)
+	}
+	if errs[1].Error() == "" || !strings.Contains(errs[1].Error(), "second") {
+		t.Error(errs[1])
+	}
+
+	if errs := bus.PublishWithResult("other", 1); errs != nil {
+		t.Fail()
+	}
+	bus.SetTopicRules(&TopicRules{Reserved: []string{"internal."}})
+	if errs := bus.PublishWithResult("internal.x"); len(errs) != 1 || !errors.Is(errs[0], ErrInvalidTopic) {
+		t.Fail()
+	}
+}
+
+func TestWithErrorSink(t *testing.T) {
+	lock := sync.Mutex{}
+	sunk := make([]*HandlerError, 0)
+	bus := New(WithErrorSink(func(err *HandlerError) {
+		lock.Lock()
+		defer lock.Unlock()
+		sunk = append(sunk, err)
+	}))
+	bus.SubscribeAsync("topic", failingHandler, false)
+	bus.Subscribe("topic", failingHandler)
+	bus.Publish("topic", -1)
+	bus.Publish("topic", 1)
+	bus.WaitAsync()
+	if len(sunk) != 1 || !errors.Is(sunk[0], errTest) {
+		t.Fail()
+	}
+}
+
+func TestPublishWithResultIntercepted(t *testing.T) {
+	bus := New()
+	bus.Subscribe("topic", failingHandler)
+	var seen []string
+	bus.Use(func(next PublishFunc) PublishFunc {
+		return func(ctx context.Context, topic string, args []interface{}) error {
+			seen = append(seen, topic)
+			if topic == "blocked" {
+				return errTest
+			}
+			return next(ctx, topic, args)
+		}
+	})
+	if errs := bus.PublishWithResult("topic", -1); len(errs) != 1 || !errors.Is(errs[0], errTest) {
+		t.Fatal(errs)
+	}
+	if errs := bus.PublishWithResult("blocked"); len(errs) != 1 || errs[0] != errTest {
+		t.Fatal(errs)
+	}
+	if len(seen) != 2 {
+		t.Fatal(seen)
+	}
+}
@@ -0,0 +1,164 @@
+// Package hashring maps partition keys to nodes or partitions with consistent
+// hashing, so that adding or removing a node moves as few keys as possible.
+//
+// Ring hashes nodes to many points on a ring and maps a key to the node of the
+// next point; Partition maps a key to one of n numbered partitions with jump
+// consistent hashing. Both take a pluggable HashFunc, so applications can
+// align the sharding of external systems with their own keys.
+package hashring
+
+import (
+	"cmp"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// HashFunc - hashes a key or a node's virtual point to 64 bits
+type HashFunc func(data []byte) uint64
+
+// FNV1a - default HashFunc, 64 bits FNV-1a
+func FNV1a(data []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64()
+}
+
+// DefaultReplicas - default number of points per node on a Ring
+const DefaultReplicas = 128
+
+// Options - configuration of a Ring
+type Options struct {
+	// Replicas is the number of points per node, more points spread keys more evenly;
+	// defaults to DefaultReplicas
+	Replicas int
+	// Hash hashes the keys and points; defaults to FNV1a
+	Hash HashFunc
+}
+
+// Ring - consistent hash ring of nodes, safe for concurrent use
+type Ring struct {
+	opts   Options
+	points []point // sorted by hash
+	nodes  map[string]bool
+	lock   sync.RWMutex
+}
+
+type point struct {
+	hash uint64
+	node string
+}
+
+// New - create an empty Ring
+func New(opts *Options) *Ring {
+	r := &Ring{nodes: make(map[string]bool)}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.Replicas <= 0 {
+		r.opts.Replicas = DefaultReplicas
+	}
+	if r.opts.Hash == nil {
+		r.opts.Hash = FNV1a
+	}
+	return r
+}
+
+// Add adds nodes to the ring, ignoring the ones already on it
+func (r *Ring) Add(nodes ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, node := range nodes {
+		if r.nodes[node] {
+			continue
+		}
+		r.nodes[node] = true
+		for i := 0; i < r.opts.Replicas; i++ {
+			r.points = append(r.points, point{r.opts.Hash([]byte(strconv.Itoa(i) + "#" + node)), node})
+		}
+	}
+	slices.SortFunc(r.points, func(a, b point) int {
+		if a.hash != b.hash {
+			return cmp.Compare(a.hash, b.hash)
+		}
+		return cmp.Compare(a.node, b.node) // same hash: deterministic order
+	})
+}
+
+// Remove removes nodes from the ring; their keys move to the next nodes
+func (r *Ring) Remove(nodes ...string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, node := range nodes {
+		delete(r.nodes, node)
+	}
+	r.points = slices.DeleteFunc(r.points, func(p point) bool { return !r.nodes[p.node] })
+}
+
+// Nodes returns the nodes on the ring, sorted
+func (r *Ring) Nodes() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	nodes := make([]string, 0, len(r.nodes))
+	for node := range r.nodes {
+		nodes = append(nodes, node)
+	}
+	slices.Sort(nodes)
+	return nodes
+}
+
+// Get returns the node of a key, false if the ring is empty
+func (r *Ring) Get(key string) (string, bool) {
+	nodes := r.GetN(key, 1)
+	if len(nodes) == 0 {
+		return "", false
+	}
+	return nodes[0], true
+}
+
+// GetN returns up to n distinct nodes for a key, the node of the key first,
+// e.g. to place replicas
+func (r *Ring) GetN(key string, n int) []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if len(r.points) == 0 || n <= 0 {
+		return nil
+	}
+	hash := r.opts.Hash([]byte(key))
+	i, _ := slices.BinarySearchFunc(r.points, hash, func(p point, hash uint64) int { return cmp.Compare(p.hash, hash) })
+	nodes := make([]string, 0, min(n, len(r.nodes)))
+	for j := 0; j < len(r.points) && len(nodes) < cap(nodes); j++ {
+		node := r.points[(i+j)%len(r.points)].node
+		if !slices.Contains(nodes, node) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Partition returns the partition of a key among n partitions numbered from
+// 0, with jump consistent hashing: growing n to n+1 only moves the keys
+// landing on the new partition. hash may be nil to use FNV1a.
+// Returns -1 if n <= 0.
+func Partition(key string, n int, hash HashFunc) int {
+	if hash == nil {
+		hash = FNV1a
+	}
+	return Jump(hash([]byte(key)), n)
+}
+
+// Jump maps a 64 bits key hash to one of n buckets (Lamping and Veach, "A
+// Fast, Minimal Memory, Consistent Hash Algorithm"). Returns -1 if n <= 0.
+func Jump(key uint64, n int) int {
+	if n <= 0 {
+		return -1
+	}
+	b, j := int64(-1), int64(0)
+	for j < int64(n) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
@@ -0,0 +1,86 @@
+package hashring
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	ring := New(nil)
+	if _, ok := ring.Get("key"); ok {
+		t.Fail()
+	}
+	ring.Add("a", "b", "c", "a")
+	if fmt.Sprint(ring.Nodes()) != "[a b c]" {
+		t.Fatal(ring.Nodes())
+	}
+
+	before := make(map[string]string)
+	counts := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		key := fmt.Sprint("key", i)
+		node, _ := ring.Get(key)
+		before[key] = node
+		counts[node]++
+	}
+	for node, count := range counts {
+		if count < 500 {
+			t.Fatal(node, count) // badly balanced
+		}
+	}
+
+	ring.Remove("b")
+	for key, node := range before {
+		moved, _ := ring.Get(key)
+		if node != "b" && moved != node {
+			t.Fatal(key, "moved from", node, "to", moved)
+		}
+		if moved == "b" {
+			t.Fatal(key, "still on the removed node")
+		}
+	}
+
+	replicas := ring.GetN("key", 5)
+	if len(replicas) != 2 || replicas[0] == replicas[1] {
+		t.Fatal(replicas)
+	}
+}
+
+func TestRingHash(t *testing.T) {
+	ring := New(&Options{Replicas: 1, Hash: func(data []byte) uint64 {
+		switch string(data) {
+		case "0#a":
+			return 10
+		case "0#b":
+			return 20
+		}
+		return 15
+	}})
+	ring.Add("a", "b")
+	if node, _ := ring.Get("key"); node != "b" {
+		t.Fatal(node)
+	}
+}
+
+func TestPartition(t *testing.T) {
+	if Partition("key", 0, nil) != -1 {
+		t.Fail()
+	}
+	moved := 0
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprint("key", i)
+		p := Partition(key, 10, nil)
+		if p < 0 || p >= 10 {
+			t.Fatal(p)
+		}
+		if grown := Partition(key, 11, nil); grown != p {
+			if grown != 10 {
+				t.Fatal(key, p, grown)
+			}
+			moved++
+		}
+	}
+	if moved == 0 || moved > 200 {
+		t.Fatal(moved)
+	}
+}
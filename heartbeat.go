@@ -0,0 +1,61 @@
+package eventbus
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// MinEmitInterval - shortest interval between the ticks of an emitter, see
+// EmitEvery
+const MinEmitInterval = time.Millisecond
+
+// EmitEvery runs EmitEvery on package-level bus singleton
+func EmitEvery(topic string, interval time.Duration, payload func() []interface{}) (stop func()) {
+	return b.Load().EmitEvery(topic, interval, payload)
+}
+
+// EmitEvery publishes on a topic every interval, with the arguments returned by
+// payload (no arguments if payload is nil). Intervals shorter than
+// MinEmitInterval, zero or negative ones included, are raised to it. The
+// returned function stops the emitter and is safe to call more than once.
+func (bus *Bus) EmitEvery(topic string, interval time.Duration, payload func() []interface{}) (stop func()) {
+	return bus.EmitEveryJitter(topic, interval, 0, payload)
+}
+
+// EmitEveryJitter works like EmitEvery but shifts every tick by a random
+// amount in [-jitter, jitter], so emitters started together don't stay in step.
+// Jittered ticks are still at least MinEmitInterval apart.
+func (bus *Bus) EmitEveryJitter(topic string, interval, jitter time.Duration, payload func() []interface{}) (stop func()) {
+	interval = max(interval, MinEmitInterval)
+	done := make(chan struct{})
+	once := sync.Once{}
+	go func() {
+		timer := time.NewTimer(jittered(interval, jitter))
+		defer timer.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-timer.C:
+				var args []interface{}
+				if payload != nil {
+					args = payload()
+				}
+				bus.Publish(topic, args...)
+				timer.Reset(jittered(interval, jitter))
+			}
+		}
+	}()
+	return bus.track(func() {
+		once.Do(func() { close(done) })
+	})
+}
+
+func jittered(interval, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	delay := interval + time.Duration(rand.Int63n(int64(2*jitter)+1)) - jitter
+	return max(delay, MinEmitInterval)
+}
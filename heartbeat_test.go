@@ -0,0 +1,72 @@
+package eventbus
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEmitEvery(t *testing.T) {
+	bus := New()
+	ticks := make(chan int, 100)
+	bus.Subscribe("heartbeat", func(n int) {
+		ticks <- n
+	})
+
+	n := 0
+	stop := bus.EmitEvery("heartbeat", 5*time.Millisecond, func() []interface{} {
+		n++
+		return []interface{}{n}
+	})
+	for i := 1; i <= 3; i++ {
+		select {
+		case tick := <-ticks:
+			if tick != i {
+				t.Fail()
+			}
+		case <-time.After(time.Second):
+			t.Fatal("heartbeat not published")
+		}
+	}
+	stop()
+	stop()
+
+	time.Sleep(20 * time.Millisecond)
+	for len(ticks) > 0 {
+		<-ticks // a tick may have raced with stop
+	}
+	time.Sleep(20 * time.Millisecond)
+	if len(ticks) != 0 {
+		t.Fail()
+	}
+}
+
+func TestJittered(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		delay := jittered(10*time.Millisecond, 5*time.Millisecond)
+		if delay < 5*time.Millisecond || delay > 15*time.Millisecond {
+			t.Fatal(delay)
+		}
+	}
+	if jittered(time.Millisecond, 0) != time.Millisecond {
+		t.Fail()
+	}
+}
+
+func TestEmitEveryClampsInterval(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if delay := jittered(MinEmitInterval, time.Second); delay < MinEmitInterval {
+			t.Fatal(delay)
+		}
+	}
+
+	bus := New()
+	ticks := atomic.Int32{}
+	bus.Subscribe("heartbeat", func() { ticks.Add(1) })
+	stop := bus.EmitEvery("heartbeat", 0, nil)
+	time.Sleep(20 * time.Millisecond)
+	stop()
+	if n := ticks.Load(); n == 0 || n > 25 {
+		t.Fatal(n)
+	}
+}
@@ -0,0 +1,131 @@
+package eventbus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Wildcard segments of hierarchical topic patterns
+const (
+	// WildcardSingle matches exactly one level: "a/+/c" matches "a/b/c"
+	WildcardSingle = "+"
+	// WildcardMulti matches any number of trailing levels, including none:
+	// "a/#" matches "a", "a/b" and "a/b/c". It must be the last segment.
+	WildcardMulti = "#"
+)
+
+// WithSeparator makes topics hierarchical, with levels separated by separator
+// (e.g. "/" or "."). Handlers subscribed to a topic pattern containing
+// wildcard segments receive the events published on every matching topic,
+// after the handlers subscribed to the exact topic.
+func WithSeparator(separator string) Option {
+	return func(bus *Bus) {
+		bus.hierarchy = topicTree{separator: separator, root: &topicNode{}}
+	}
+}
+
+// topicTree indexes the subscribed topic patterns by level
+type topicTree struct {
+	separator string // hierarchy disabled if empty
+	root      *topicNode
+	added     int // number of patterns added so far, orders the matches
+}
+
+type topicNode struct {
+	children map[string]*topicNode
+	pattern  string // pattern ending at this node, if any
+	order    int
+}
+
+// isPattern reports whether topic contains wildcard segments
+func (tree *topicTree) isPattern(topic string) bool {
+	if tree.separator == "" {
+		return false
+	}
+	for _, segment := range strings.Split(topic, tree.separator) {
+		if segment == WildcardSingle || segment == WildcardMulti {
+			return true
+		}
+	}
+	return false
+}
+
+// validate returns error if pattern is malformed
+func (tree *topicTree) validate(pattern string) error {
+	segments := strings.Split(pattern, tree.separator)
+	for i, segment := range segments {
+		if segment == WildcardMulti && i != len(segments)-1 {
+			return fmt.Errorf("wildcard %s must be the last level of topic %s", WildcardMulti, pattern)
+		}
+	}
+	return nil
+}
+
+// add indexes pattern, adding it more than once has no effect
+func (tree *topicTree) add(pattern string) {
+	node := tree.root
+	for _, segment := range strings.Split(pattern, tree.separator) {
+		child, ok := node.children[segment]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[string]*topicNode)
+			}
+			child = &topicNode{}
+			node.children[segment] = child
+		}
+		node = child
+	}
+	if node.pattern == "" {
+		tree.added++
+		node.pattern, node.order = pattern, tree.added
+	}
+}
+
+// remove drops pattern from the index
+func (tree *topicTree) remove(pattern string) {
+	tree.root.remove(strings.Split(pattern, tree.separator))
+}
+
+// remove drops the pattern at the end of segments, returns true if the node became empty
+func (node *topicNode) remove(segments []string) bool {
+	if len(segments) == 0 {
+		node.pattern = ""
+	} else if child, ok := node.children[segments[0]]; ok && child.remove(segments[1:]) {
+		delete(node.children, segments[0])
+	}
+	return node.pattern == "" && len(node.children) == 0
+}
+
+// match returns the patterns matching topic, in the order they were added
+func (tree *topicTree) match(topic string) []string {
+	if tree.separator == "" || len(tree.root.children) == 0 {
+		return nil
+	}
+	var matches []*topicNode
+	var walk func(node *topicNode, segments []string)
+	walk = func(node *topicNode, segments []string) {
+		if multi, ok := node.children[WildcardMulti]; ok && multi.pattern != "" {
+			matches = append(matches, multi)
+		}
+		if len(segments) == 0 {
+			if node.pattern != "" {
+				matches = append(matches, node)
+			}
+			return
+		}
+		if child, ok := node.children[segments[0]]; ok {
+			walk(child, segments[1:])
+		}
+		if single, ok := node.children[WildcardSingle]; ok {
+			walk(single, segments[1:])
+		}
+	}
+	walk(tree.root, strings.Split(topic, tree.separator))
+	sort.Slice(matches, func(i, j int) bool { return matches[i].order < matches[j].order })
+	patterns := make([]string, 0, len(matches))
+	for _, node := range matches {
+		patterns = append(patterns, node.pattern)
+	}
+	return patterns
+}
@@ -0,0 +1,77 @@
+package eventbus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHierarchyWildcards(t *testing.T) {
+	bus := New(WithSeparator("/"))
+	calls := make([]string, 0)
+	record := func(name string) func() {
+		return func() { calls = append(calls, name) }
+	}
+	bus.Subscribe("sensors/+/temperature", record("single"))
+	bus.Subscribe("sensors/#", record("multi"))
+	bus.Subscribe("#", record("all"))
+	bus.Subscribe("sensors/kitchen/temperature", record("exact"))
+
+	bus.Publish("sensors/kitchen/temperature")
+	expected := "exact single multi all"
+	if strings.Join(calls, " ") != expected {
+		t.Errorf("got %v, want %s", calls, expected)
+	}
+
+	calls = calls[:0]
+	bus.Publish("sensors")
+	bus.Publish("sensors/kitchen/humidity")
+	bus.Publish("lights/kitchen")
+	expected = "multi all multi all all"
+	if strings.Join(calls, " ") != expected {
+		t.Errorf("got %v, want %s", calls, expected)
+	}
+}
+
+func TestHierarchySubscriptions(t *testing.T) {
+	bus := New(WithSeparator("."))
+	calls := 0
+	handler := func() { calls++ }
+	if bus.Subscribe("a.#.c", handler) == nil {
+		t.Fail()
+	}
+	if bus.Subscribe("a.+", handler) != nil {
+		t.Fail()
+	}
+	if !bus.HasCallback("a.b") || !bus.HasCallback("a.+") || bus.HasCallback("a.b.c") || bus.HasCallback("a.#") {
+		t.Fail()
+	}
+	if bus.Publish("a.+") == nil {
+		t.Fail()
+	}
+	if bus.Unsubscribe("a.+", handler) != nil {
+		t.Fail()
+	}
+	if bus.HasCallback("a.b") || len(bus.hierarchy.root.children) != 0 {
+		t.Fail()
+	}
+	bus.Publish("a.b")
+
+	bus.SubscribeOnce("a.+", handler)
+	bus.Publish("a.b")
+	bus.Publish("a.b")
+	if calls != 1 || bus.HasCallback("a.b") {
+		t.Fail()
+	}
+}
+
+func TestFlatTopicsByDefault(t *testing.T) {
+	bus := New()
+	calls := 0
+	bus.Subscribe("a/+", func() { calls++ })
+	if bus.Publish("a/b") != nil || bus.Publish("a/+") != nil {
+		t.Fail()
+	}
+	if calls != 1 || bus.HasCallback("a/b") {
+		t.Fail()
+	}
+}
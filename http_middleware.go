@@ -0,0 +1,96 @@
+package eventbus
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultHTTPStartedTopic - default topic of request started events
+	DefaultHTTPStartedTopic = "http:request:started"
+	// DefaultHTTPFinishedTopic - default topic of request finished events
+	DefaultHTTPFinishedTopic = "http:request:finished"
+)
+
+// HTTPRequestEvent - request lifecycle event published by HTTPMiddleware
+type HTTPRequestEvent struct {
+	Method     string
+	Path       string
+	RemoteAddr string
+	// Status, Duration and BytesWritten are only set on finished events
+	Status       int
+	Duration     time.Duration
+	BytesWritten int64
+	// ContentType and ContentLength describe the request body when
+	// HTTPMiddlewareOptions.BodyMetadata is set
+	ContentType   string
+	ContentLength int64
+}
+
+// HTTPMiddlewareOptions - configuration of HTTPMiddleware
+type HTTPMiddlewareOptions struct {
+	StartedTopic  string // defaults to DefaultHTTPStartedTopic
+	FinishedTopic string // defaults to DefaultHTTPFinishedTopic
+	BodyMetadata  bool   // include request body content type and length
+}
+
+// HTTPMiddleware returns net/http middleware publishing an HTTPRequestEvent when
+// a request starts and when it finishes.
+func (bus *Bus) HTTPMiddleware(opts *HTTPMiddlewareOptions) func(http.Handler) http.Handler {
+	options := HTTPMiddlewareOptions{}
+	if opts != nil {
+		options = *opts
+	}
+	if options.StartedTopic == "" {
+		options.StartedTopic = DefaultHTTPStartedTopic
+	}
+	if options.FinishedTopic == "" {
+		options.FinishedTopic = DefaultHTTPFinishedTopic
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			event := HTTPRequestEvent{
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				RemoteAddr: r.RemoteAddr,
+			}
+			if options.BodyMetadata {
+				event.ContentType = r.Header.Get("Content-Type")
+				event.ContentLength = r.ContentLength
+			}
+			bus.Publish(options.StartedTopic, event)
+
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			event.Status = recorder.status
+			event.Duration = time.Since(start)
+			event.BytesWritten = recorder.written
+			bus.Publish(options.FinishedTopic, event)
+		})
+	}
+}
+
+// statusRecorder captures the status code and size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status  int
+	written int64
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) Write(data []byte) (int, error) {
+	n, err := recorder.ResponseWriter.Write(data)
+	recorder.written += int64(n)
+	return n, err
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
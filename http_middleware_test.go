@@ -0,0 +1,44 @@
+package eventbus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	bus := New()
+	var started, finished []HTTPRequestEvent
+	bus.Subscribe(DefaultHTTPStartedTopic, func(event HTTPRequestEvent) {
+		started = append(started, event)
+	})
+	bus.Subscribe("audit:http", func(event HTTPRequestEvent) {
+		finished = append(finished, event)
+	})
+
+	handler := bus.HTTPMiddleware(&HTTPMiddlewareOptions{
+		FinishedTopic: "audit:http",
+		BodyMetadata:  true,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("done"))
+	}))
+
+	request := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"x"}`))
+	request.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	if len(started) != 1 || started[0].Method != "POST" || started[0].Path != "/users" || started[0].Status != 0 {
+		t.Log(started)
+		t.Fail()
+	}
+	if len(finished) != 1 {
+		t.Fatal(finished)
+	}
+	event := finished[0]
+	if event.Status != http.StatusCreated || event.BytesWritten != 4 || event.ContentType != "application/json" || event.ContentLength != 12 {
+		t.Log(event)
+		t.Fail()
+	}
+}
@@ -0,0 +1,169 @@
+package eventbus
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// IDGenerator - generates the IDs of a bus, see WithIDGenerator.
+// Implementations must be safe for concurrent use.
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGeneratorFunc - function implementing IDGenerator
+type IDGeneratorFunc func() string
+
+// NewID implements IDGenerator
+func (f IDGeneratorFunc) NewID() string {
+	return f()
+}
+
+// WithIDGenerator makes the bus generate the IDs of the events published
+// with PublishEvent, and those returned by NewID, with gen instead of random
+// hexadecimal strings, e.g. NewULIDGenerator so IDs sort by time in
+// downstream databases and logs.
+func WithIDGenerator(gen IDGenerator) Option {
+	return func(bus *Bus) {
+		bus.ids = gen
+	}
+}
+
+// NewID runs NewID on package-level bus singleton
+func NewID() string {
+	return b.Load().NewID()
+}
+
+// NewID returns a new ID from the generator of the bus, e.g. to use as the
+// correlation ID of the events caused by a request
+func (bus *Bus) NewID() string {
+	if bus.ids == nil {
+		return randomID()
+	}
+	return bus.ids.NewID()
+}
+
+// randomID returns a random 128 bits ID in hexadecimal
+func randomID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// monotonicClock - millisecond timestamps with a sequence number counting
+// the IDs generated in the same millisecond, so IDs are strictly increasing
+type monotonicClock struct {
+	last     int64 // unix milliseconds of the last ID
+	sequence uint64
+	seed     uint64 // random, drawn with the first sequence of a millisecond
+	lock     sync.Mutex
+}
+
+// next returns the millisecond timestamp, sequence number and seed of a new
+// ID. The sequence starts at a value from start at each millisecond; the
+// timestamp moves ahead of the clock once the sequence would exceed limit.
+func (c *monotonicClock) next(start func() uint64, limit uint64) (ms int64, sequence, seed uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now().UnixMilli()
+	if now > c.last || c.sequence >= limit {
+		c.last, c.sequence, c.seed = max(now, c.last+1), start(), randomUint64()
+	} else {
+		c.sequence++
+	}
+	return c.last, c.sequence, c.seed
+}
+
+func randomUint64() uint64 {
+	var buf [8]byte
+	rand.Read(buf[:])
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+// crockford - alphabet of the ULID base32 encoding
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidGenerator - see NewULIDGenerator
+type ulidGenerator struct {
+	clock monotonicClock
+}
+
+// NewULIDGenerator returns an IDGenerator of ULIDs: 26 characters sorting by
+// generation time, strictly increasing within a process
+func NewULIDGenerator() IDGenerator {
+	return &ulidGenerator{}
+}
+
+func (g *ulidGenerator) NewID() string {
+	// the 80 random bits are 16 bits drawn for the millisecond above a 64
+	// bits sequence, so incrementing it keeps the IDs random and ordered
+	ms, sequence, seed := g.clock.next(func() uint64 { return randomUint64() >> 1 }, 1<<64-1)
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[0:8], uint64(ms)<<16|seed&0xffff)
+	binary.BigEndian.PutUint64(id[8:16], sequence)
+	return encodeCrockford(id)
+}
+
+// encodeCrockford encodes 128 bits as 26 base32 characters, most significant first
+func encodeCrockford(id [16]byte) string {
+	high, low := binary.BigEndian.Uint64(id[0:8]), binary.BigEndian.Uint64(id[8:16])
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[low&31]
+		low = low>>5 | high<<59
+		high >>= 5
+	}
+	return string(out)
+}
+
+// uuidV7Generator - see NewUUIDv7Generator
+type uuidV7Generator struct {
+	clock monotonicClock
+}
+
+// NewUUIDv7Generator returns an IDGenerator of version 7 UUIDs (RFC 9562),
+// which sort by generation time, strictly increasing within a process
+func NewUUIDv7Generator() IDGenerator {
+	return &uuidV7Generator{}
+}
+
+func (g *uuidV7Generator) NewID() string {
+	// 12 bits counter in rand_a, seeded below 2048 to leave room to count
+	ms, counter, _ := g.clock.next(func() uint64 { return randomUint64() & 0x7ff }, 0xfff)
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[0:8], uint64(ms)<<16|0x7000|counter)
+	rand.Read(id[8:])
+	id[8] = id[8]&0x3f | 0x80 // variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
+
+// SnowflakeEpoch - start of the timestamps of snowflake IDs (2020-01-01 UTC)
+var SnowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// snowflakeGenerator - see NewSnowflakeGenerator
+type snowflakeGenerator struct {
+	node  int64
+	clock monotonicClock
+}
+
+// NewSnowflakeGenerator returns an IDGenerator of snowflake IDs: 63 bits
+// numbers made of milliseconds since SnowflakeEpoch, the node (10 bits, so
+// processes sharing an ID space must use distinct nodes) and a sequence,
+// formatted with 19 zero-padded digits so they also sort as strings.
+// Returns error if node doesn't fit in 10 bits.
+func NewSnowflakeGenerator(node int64) (IDGenerator, error) {
+	if node < 0 || node >= 1<<10 {
+		return nil, fmt.Errorf("snowflake node %d out of range [0, 1024)", node)
+	}
+	return &snowflakeGenerator{node: node}, nil
+}
+
+func (g *snowflakeGenerator) NewID() string {
+	ms, sequence, _ := g.clock.next(func() uint64 { return 0 }, 1<<12-1)
+	id := (ms-SnowflakeEpoch.UnixMilli())<<22 | g.node<<12 | int64(sequence)
+	return fmt.Sprintf("%019d", id)
+}
@@ -0,0 +1,53 @@
+package eventbus
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestIDGenerators(t *testing.T) {
+	snowflake, err := NewSnowflakeGenerator(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		gen    IDGenerator
+		format *regexp.Regexp
+	}{
+		{NewULIDGenerator(), regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)},
+		{NewUUIDv7Generator(), regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)},
+		{snowflake, regexp.MustCompile(`^[0-9]{19}$`)},
+	} {
+		ids := make([]string, 10000)
+		for i := range ids {
+			ids[i] = test.gen.NewID()
+			if !test.format.MatchString(ids[i]) {
+				t.Fatal(ids[i])
+			}
+		}
+		if !slices.IsSorted(ids) || len(slices.Compact(slices.Clone(ids))) != len(ids) {
+			t.Fatal("IDs not strictly increasing", ids[0])
+		}
+	}
+	if _, err := NewSnowflakeGenerator(1024); err == nil {
+		t.Fail()
+	}
+}
+
+func TestWithIDGenerator(t *testing.T) {
+	next := 0
+	bus := New(WithIDGenerator(IDGeneratorFunc(func() string {
+		next++
+		return string(rune('a' + next))
+	})))
+	var got Event
+	bus.Subscribe("topic", func(ev Event) { got = ev })
+	bus.PublishEvent(Event{Topic: "topic"})
+	if got.ID != "b" || bus.NewID() != "c" {
+		t.Fatal(got.ID)
+	}
+	if len(New().NewID()) != 32 {
+		t.Fail()
+	}
+}
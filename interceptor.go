@@ -0,0 +1,90 @@
+package eventbus
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// PublishFunc - publishes an event on a topic, see Bus.Use
+type PublishFunc func(ctx context.Context, topic string, args []interface{}) error
+
+// PublishMiddleware - wraps the publishes on a bus. It may change the topic
+// or the arguments, reject the publish by returning an error without calling
+// next or observe its outcome.
+type PublishMiddleware func(next PublishFunc) PublishFunc
+
+// interceptors - middleware added with Use and UseDelivery
+type interceptors struct {
+	publish atomic.Pointer[[]PublishMiddleware]
+	deliver atomic.Pointer[[]DeliveryMiddleware]
+}
+
+// Use runs Use on package-level bus singleton
+func Use(middleware ...PublishMiddleware) {
+	b.Load().Use(middleware...)
+}
+
+// Use applies middleware to the later calls to Publish and PublishCtx on the
+// bus, e.g. to log, validate or enrich every event. Middleware runs in the
+// order it was added, the first added being the outermost; the topic it
+// receives is the one given by the publisher, before topic rules and aliases.
+// Control events published by the bus itself are not intercepted.
+func (bus *Bus) Use(middleware ...PublishMiddleware) {
+	appendMiddleware(&bus.interceptors.publish, middleware)
+}
+
+// UseDelivery runs UseDelivery on package-level bus singleton
+func UseDelivery(middleware ...DeliveryMiddleware) {
+	b.Load().UseDelivery(middleware...)
+}
+
+// UseDelivery applies middleware to the later deliveries to every
+// subscription of the bus, outside the middleware added to single
+// subscriptions with WrapSubscription or Subscription.Use. The Delivery
+// carries the topic, arguments and name of the handler.
+func (bus *Bus) UseDelivery(middleware ...DeliveryMiddleware) {
+	appendMiddleware(&bus.interceptors.deliver, middleware)
+}
+
+// appendMiddleware appends middleware to a chain without disturbing the
+// publishes and deliveries running through it
+func appendMiddleware[M any](chain *atomic.Pointer[[]M], middleware []M) {
+	for {
+		current := chain.Load()
+		var wrapped []M
+		if current != nil {
+			wrapped = append(wrapped, *current...)
+		}
+		wrapped = append(wrapped, middleware...)
+		if chain.CompareAndSwap(current, &wrapped) {
+			return
+		}
+	}
+}
+
+// intercept runs publish through the publish middleware of the bus
+func (bus *Bus) intercept(ctx context.Context, topic string, args []interface{}, publish PublishFunc) error {
+	middleware := bus.interceptors.publish.Load()
+	if middleware == nil {
+		return publish(ctx, topic, args)
+	}
+	for i := len(*middleware) - 1; i >= 0; i-- {
+		publish = (*middleware)[i](publish)
+	}
+	return publish(ctx, topic, args)
+}
+
+// deliveryMiddleware returns the bus delivery middleware followed by the
+// delivery middleware of a handler, nil if there is none
+func (bus *Bus) deliveryMiddleware(handler *eventHandler) []DeliveryMiddleware {
+	shared, own := bus.interceptors.deliver.Load(), handler.middleware.Load()
+	switch {
+	case shared == nil && own == nil:
+		return nil
+	case shared == nil:
+		return *own
+	case own == nil:
+		return *shared
+	}
+	return append(append([]DeliveryMiddleware(nil), *shared...), *own...)
+}
@@ -0,0 +1,74 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUse(t *testing.T) {
+	bus := New()
+	var calls []string
+	bus.Use(func(next PublishFunc) PublishFunc {
+		return func(ctx context.Context, topic string, args []interface{}) error {
+			calls = append(calls, "outer "+topic)
+			return next(ctx, topic, args)
+		}
+	}, func(next PublishFunc) PublishFunc {
+		return func(ctx context.Context, topic string, args []interface{}) error {
+			calls = append(calls, "inner "+topic)
+			if topic == "invalid" {
+				return errors.New("invalid topic")
+			}
+			return next(ctx, topic, append(args, "enriched"))
+		}
+	})
+	var received []interface{}
+	bus.Subscribe("topic", func(args ...interface{}) {
+		received = args
+	})
+
+	if err := bus.Publish("topic", 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2 || received[0] != 1 || received[1] != "enriched" {
+		t.Fatal(received)
+	}
+	if err := bus.Publish("invalid", 1); err == nil {
+		t.Fail()
+	}
+	if strings.Join(calls, ",") != "outer topic,inner topic,outer invalid,inner invalid" {
+		t.Fatal(calls)
+	}
+}
+
+func TestUseDelivery(t *testing.T) {
+	bus := New()
+	var calls []string
+	bus.UseDelivery(func(next DeliverFunc) DeliverFunc {
+		return func(delivery *Delivery) error {
+			calls = append(calls, "bus "+delivery.Topic)
+			if !strings.Contains(delivery.Handler, "TestUseDelivery") {
+				t.Error(delivery.Handler)
+			}
+			return next(delivery)
+		}
+	})
+	sub, _ := bus.SubscribeHandle("topic", func(n int) {
+		calls = append(calls, "handler")
+	})
+	sub.Use(func(next DeliverFunc) DeliverFunc {
+		return func(delivery *Delivery) error {
+			calls = append(calls, "subscription")
+			return next(delivery)
+		}
+	})
+	bus.SubscribeAsync("topic", func(n int) {}, false)
+
+	bus.Publish("topic", 1)
+	bus.WaitAsync()
+	if len(calls) != 4 || strings.Join(calls[:3], ",") != "bus topic,subscription,handler" || calls[3] != "bus topic" {
+		t.Fatal(calls)
+	}
+}
@@ -0,0 +1,144 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// JournalEntry - an event recorded by a topic journal
+type JournalEntry struct {
+	ID   string // generated with Bus.NewID, see WithIDGenerator
+	Args []interface{}
+	Time time.Time // publish time
+}
+
+// journal - ring buffer of the last events published on a topic. Publishes
+// only queue their events, which a scheduled flush writes to the buffer.
+type journal struct {
+	entries   []JournalEntry
+	next      int
+	full      bool
+	pending   []JournalEntry // published, not written yet
+	scheduled bool           // a flush of pending is scheduled
+	lock      sync.Mutex
+}
+
+// journals - journals of the topics, see EnableJournal
+type journals struct {
+	topics map[string]*journal
+	sync.Mutex
+}
+
+// EnableJournal starts recording the last `size` events published on a topic,
+// for Replay. Writing the journal is scheduled off the publishing goroutine.
+// Enabling an already journaled topic resets its journal.
+func (bus *Bus) EnableJournal(topic string, size int) {
+	if size <= 0 {
+		return
+	}
+	topic = bus.canonicalTopic(topic)
+	bus.journals.Lock()
+	defer bus.journals.Unlock()
+	if bus.journals.topics == nil {
+		bus.journals.topics = make(map[string]*journal)
+	}
+	bus.journals.topics[topic] = &journal{entries: make([]JournalEntry, size)}
+}
+
+// DisableJournal stops recording the events of a topic and drops its journal.
+func (bus *Bus) DisableJournal(topic string) {
+	topic = bus.canonicalTopic(topic)
+	bus.journals.Lock()
+	defer bus.journals.Unlock()
+	delete(bus.journals.topics, topic)
+}
+
+// Journal returns the events recorded by the journal of a topic, oldest first.
+func (bus *Bus) Journal(topic string) []JournalEntry {
+	j := bus.journal(topic)
+	if j == nil {
+		return nil
+	}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.write(bus)
+	if !j.full {
+		return slices.Clone(j.entries[:j.next])
+	}
+	entries := make([]JournalEntry, 0, len(j.entries))
+	entries = append(entries, j.entries[j.next:]...)
+	return append(entries, j.entries[:j.next]...)
+}
+
+// Replay calls fn, on the calling goroutine, with the arguments of the events
+// of the journal of a topic published at or after since, oldest first, so a
+// new component can catch up before or after subscribing. fn takes the
+// arguments like a handler of the topic, optionally after a context.Context,
+// or an Event carrying the ID and time of the entry.
+// Returns error if fn is not a function or the topic has no journal.
+func (bus *Bus) Replay(topic string, since time.Time, fn interface{}) error {
+	if reflect.TypeOf(fn) == nil || reflect.TypeOf(fn).Kind() != reflect.Func {
+		return fmt.Errorf("%v is not of type reflect.Func", reflect.TypeOf(fn))
+	}
+	topic = bus.canonicalTopic(topic)
+	if bus.journal(topic) == nil {
+		return fmt.Errorf("topic %s has no journal", topic)
+	}
+	handler := &eventHandler{callBack: reflect.ValueOf(fn)}
+	for _, entry := range bus.Journal(topic) {
+		if entry.Time.Before(since) {
+			continue
+		}
+		ctx := context.WithValue(context.Background(), eventKey{}, &Event{ID: entry.ID, Time: entry.Time})
+		args := bus.withEvent(ctx, handler, topic, entry.Time, withContext(ctx, handler, entry.Args))
+		results, report := bus.invoke(handler, topic, args)
+		bus.logHandled(topic, handler, handlerError(topic, handler, results, report), 0)
+	}
+	return nil
+}
+
+// journal returns the journal of a topic, nil if it has none
+func (bus *Bus) journal(topic string) *journal {
+	bus.journals.Lock()
+	defer bus.journals.Unlock()
+	return bus.journals.topics[topic]
+}
+
+// record queues an event published on topic for its journal, if it has one
+func (bus *Bus) record(topic string, published time.Time, args []interface{}) {
+	j := bus.journal(topic)
+	if j == nil {
+		return
+	}
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.pending = append(j.pending, JournalEntry{Args: slices.Clone(args), Time: published})
+	if !j.scheduled {
+		j.scheduled = true
+		bus.scheduler.Schedule(func() {
+			j.lock.Lock()
+			defer j.lock.Unlock()
+			j.write(bus)
+		})
+	}
+}
+
+// write moves the pending events to the ring buffer; j.lock must be held
+func (j *journal) write(bus *Bus) {
+	for _, entry := range j.pending {
+		entry.ID = bus.NewID()
+		j.entries[j.next] = entry
+		j.next++
+		if j.next == len(j.entries) {
+			j.next = 0
+			j.full = true
+		}
+	}
+	clear(j.pending)
+	j.pending = j.pending[:0]
+	j.scheduled = false
+}
@@ -0,0 +1,60 @@
+package eventbus
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	bus := New(WithIDGenerator(NewULIDGenerator()))
+	if bus.Replay("orders", time.Time{}, func(int) {}) == nil {
+		t.Fatal("replayed a topic without journal")
+	}
+	bus.EnableJournal("orders", 3)
+	for id := 1; id <= 4; id++ {
+		bus.Publish("orders", id) // journaled without subscribers
+	}
+	since := time.Now()
+	bus.Publish("orders", 5)
+
+	entries := bus.Journal("orders")
+	if len(entries) != 3 || entries[0].Args[0] != 3 || entries[2].Args[0] != 5 {
+		t.Fatal(entries)
+	}
+	if entries[0].ID == "" || entries[0].ID >= entries[1].ID {
+		t.Fatal(entries)
+	}
+
+	var replayed []int
+	if err := bus.Replay("orders", time.Time{}, func(id int) { replayed = append(replayed, id) }); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(replayed, []int{3, 4, 5}) {
+		t.Fatal(replayed)
+	}
+	var recent []Event
+	bus.Replay("orders", since, func(ev Event) { recent = append(recent, ev) })
+	if len(recent) != 1 || recent[0].Args[0] != 5 || recent[0].ID != entries[2].ID || recent[0].Topic != "orders" {
+		t.Fatal(recent)
+	}
+
+	bus.DisableJournal("orders")
+	if bus.Journal("orders") != nil {
+		t.Fail()
+	}
+}
+
+func TestJournalWrittenAsync(t *testing.T) {
+	bus := New()
+	bus.EnableJournal("topic", 10)
+	bus.Publish("topic", 1)
+	bus.Publish("topic", 2)
+	time.Sleep(10 * time.Millisecond) // the scheduled flush writes the events
+	j := bus.journal("topic")
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if len(j.pending) != 0 || j.next != 2 {
+		t.Fatal(j.pending, j.next)
+	}
+}
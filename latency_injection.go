@@ -0,0 +1,64 @@
+package eventbus
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// injectedLatency - artificial latency of the async deliveries of a topic
+type injectedLatency struct {
+	base, jitter time.Duration
+}
+
+// latencyInjection - latencies set with InjectLatency, by topic
+type latencyInjection struct {
+	topics map[string]injectedLatency
+	sync.RWMutex
+}
+
+// InjectLatency delays every async delivery of the events published on topic
+// by latency plus a random jitter in [0, jitter), to reproduce the timing of
+// production deliveries (network hops, busy workers) in local development and
+// tests, so handlers racing each other fail early. An empty topic sets the
+// latency of the topics without their own; zero latency and jitter remove the
+// setting. Synchronous deliveries are not delayed. Meant for development: don't
+// use it in production.
+func (bus *Bus) InjectLatency(topic string, latency, jitter time.Duration) {
+	injection := &bus.injected
+	injection.Lock()
+	defer injection.Unlock()
+	if latency <= 0 && jitter <= 0 {
+		delete(injection.topics, topic)
+		return
+	}
+	if injection.topics == nil {
+		injection.topics = make(map[string]injectedLatency)
+	}
+	injection.topics[topic] = injectedLatency{max(latency, 0), max(jitter, 0)}
+}
+
+// delayDelivery waits for the latency injected into the deliveries of topic,
+// returning false if ctx is done first
+func (bus *Bus) delayDelivery(ctx context.Context, topic string) bool {
+	injection := &bus.injected
+	injection.RLock()
+	if len(injection.topics) == 0 {
+		injection.RUnlock()
+		return true
+	}
+	latency, ok := injection.topics[topic]
+	if !ok {
+		latency, ok = injection.topics[""]
+	}
+	injection.RUnlock()
+	if !ok {
+		return true
+	}
+	delay := latency.base
+	if latency.jitter > 0 {
+		delay += rand.N(latency.jitter)
+	}
+	return sleepCtx(ctx, delay)
+}
@@ -0,0 +1,41 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInjectLatency(t *testing.T) {
+	bus := New()
+	bus.InjectLatency("slow", 20*time.Millisecond, 10*time.Millisecond)
+	bus.InjectLatency("", 5*time.Millisecond, 0)
+	delivered := make(chan string, 3)
+	for _, topic := range []string{"slow", "other"} {
+		topic := topic
+		bus.SubscribeAsync(topic, func() { delivered <- topic }, false)
+	}
+	bus.Subscribe("sync", func() { delivered <- "sync" })
+
+	start := time.Now()
+	bus.Publish("slow")
+	bus.Publish("other")
+	bus.Publish("sync")
+	if <-delivered != "sync" || time.Since(start) > 15*time.Millisecond {
+		t.Fatal("synchronous delivery delayed")
+	}
+	if <-delivered != "other" || <-delivered != "slow" {
+		t.Fatal("deliveries not delayed by topic")
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Fatal(elapsed)
+	}
+
+	bus.InjectLatency("slow", 0, 0)
+	bus.InjectLatency("", 0, 0)
+	start = time.Now()
+	bus.Publish("slow")
+	bus.WaitAsync()
+	if time.Since(start) >= 20*time.Millisecond {
+		t.Fatal(time.Since(start))
+	}
+}
@@ -0,0 +1,103 @@
+package eventbus
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultLatencyWindow - default number of recent deliveries latency stats are computed on
+const DefaultLatencyWindow = 1000
+
+// LatencyDistribution - quantiles of the latencies of recent deliveries
+type LatencyDistribution struct {
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+// LatencyStats - delivery latencies of a topic
+type LatencyStats struct {
+	Deliveries int64               // deliveries measured since the stats were enabled
+	Queue      LatencyDistribution // time from Publish to handler start (event age)
+	Completion LatencyDistribution // time from Publish to handler completion
+}
+
+type latencyTracker struct {
+	deliveries int64
+	queue      *latencyWindow
+	completion *latencyWindow
+}
+
+type latencyRegistry struct {
+	trackers map[string]*latencyTracker
+	sync.Mutex
+}
+
+// EnableLatencyStats starts measuring the queue latency and completion time of
+// every delivery on a topic, keeping the last `window` deliveries
+// (DefaultLatencyWindow if zero or negative). A growing queue latency is the
+// first sign of an async backlog. Enabling an already measured topic resets its stats.
+func (bus *Bus) EnableLatencyStats(topic string, window int) {
+	if window <= 0 {
+		window = DefaultLatencyWindow
+	}
+	bus.latencies.Lock()
+	defer bus.latencies.Unlock()
+	if bus.latencies.trackers == nil {
+		bus.latencies.trackers = make(map[string]*latencyTracker)
+	}
+	bus.latencies.trackers[topic] = &latencyTracker{
+		queue:      newLatencyWindow(window),
+		completion: newLatencyWindow(window),
+	}
+}
+
+// DisableLatencyStats stops measuring the deliveries of a topic and drops its stats
+func (bus *Bus) DisableLatencyStats(topic string) {
+	bus.latencies.Lock()
+	defer bus.latencies.Unlock()
+	delete(bus.latencies.trackers, topic)
+}
+
+// LatencyStats returns the latency distributions of a topic over its recent
+// deliveries, false if the topic is not measured.
+func (bus *Bus) LatencyStats(topic string) (LatencyStats, bool) {
+	bus.latencies.Lock()
+	defer bus.latencies.Unlock()
+	tracker, ok := bus.latencies.trackers[topic]
+	if !ok {
+		return LatencyStats{}, false
+	}
+	return LatencyStats{
+		Deliveries: tracker.deliveries,
+		Queue:      tracker.queue.distribution(),
+		Completion: tracker.completion.distribution(),
+	}, true
+}
+
+func (bus *Bus) hasLatencyStats(topic string) bool {
+	bus.latencies.Lock()
+	defer bus.latencies.Unlock()
+	_, ok := bus.latencies.trackers[topic]
+	return ok
+}
+
+func (bus *Bus) observeLatency(topic string, queue, completion time.Duration) {
+	bus.latencies.Lock()
+	defer bus.latencies.Unlock()
+	if tracker, ok := bus.latencies.trackers[topic]; ok {
+		tracker.deliveries++
+		tracker.queue.add(queue)
+		tracker.completion.add(completion)
+	}
+}
+
+func (window *latencyWindow) distribution() LatencyDistribution {
+	return LatencyDistribution{
+		P50: window.quantile(0.5),
+		P90: window.quantile(0.9),
+		P99: window.quantile(0.99),
+		Max: window.quantile(1),
+	}
+}
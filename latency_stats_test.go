@@ -0,0 +1,42 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyStats(t *testing.T) {
+	bus := New()
+	if _, ok := bus.LatencyStats("topic"); ok {
+		t.Fail()
+	}
+	release := make(chan struct{})
+	bus.SubscribeAsync("topic", func() {
+		<-release
+	}, true)
+	bus.EnableLatencyStats("topic", 10)
+
+	bus.Publish("topic")
+	bus.Publish("topic") // waits behind the first delivery
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	bus.WaitAsync()
+
+	stats, ok := bus.LatencyStats("topic")
+	if !ok || stats.Deliveries != 2 {
+		t.Fatal(stats)
+	}
+	if stats.Queue.Max < 20*time.Millisecond || stats.Queue.P50 > stats.Queue.Max {
+		t.Log(stats.Queue)
+		t.Fail()
+	}
+	if stats.Completion.Max < stats.Queue.Max || stats.Completion.P50 < 20*time.Millisecond {
+		t.Log(stats.Completion)
+		t.Fail()
+	}
+
+	bus.DisableLatencyStats("topic")
+	if _, ok := bus.LatencyStats("topic"); ok {
+		t.Fail()
+	}
+}
@@ -0,0 +1,84 @@
+package eventbus
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+const (
+	// TopicSignal - reserved topic receiving OS signals as an os.Signal argument
+	TopicSignal = "bus:signal"
+	// TopicLifecycle - reserved topic receiving process lifecycle phases as a LifecyclePhase argument
+	TopicLifecycle = "bus:lifecycle"
+)
+
+// LifecyclePhase - phase of the process lifecycle
+type LifecyclePhase int
+
+const (
+	// LifecycleStarted - the process started and components are initializing
+	LifecycleStarted LifecyclePhase = iota
+	// LifecycleReady - the process is ready to serve
+	LifecycleReady
+	// LifecycleStopping - the process is shutting down
+	LifecycleStopping
+)
+
+func (phase LifecyclePhase) String() string {
+	switch phase {
+	case LifecycleStarted:
+		return "started"
+	case LifecycleReady:
+		return "ready"
+	case LifecycleStopping:
+		return "stopping"
+	}
+	return "unknown"
+}
+
+// NotifySignals runs NotifySignals on package-level bus singleton
+func NotifySignals(sigs ...os.Signal) (stop func()) {
+	return b.Load().NotifySignals(sigs...)
+}
+
+// NotifySignals publishes the given OS signals on TopicSignal (SIGTERM, SIGHUP
+// and SIGINT if none are given) so components don't each install their own
+// signal.Notify. The returned function stops the relay and is safe to call
+// more than once.
+func (bus *Bus) NotifySignals(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGTERM, syscall.SIGHUP, os.Interrupt}
+	}
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, sigs...)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				bus.publishSystem(TopicSignal, sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+	once := sync.Once{}
+	return bus.track(func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	})
+}
+
+// PublishLifecycle runs PublishLifecycle on package-level bus singleton
+func PublishLifecycle(phase LifecyclePhase) {
+	b.Load().PublishLifecycle(phase)
+}
+
+// PublishLifecycle announces a process lifecycle phase on TopicLifecycle
+func (bus *Bus) PublishLifecycle(phase LifecyclePhase) {
+	bus.publishSystem(TopicLifecycle, phase)
+}
@@ -0,0 +1,43 @@
+package eventbus
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNotifySignals(t *testing.T) {
+	bus := New()
+	received := make(chan os.Signal, 1)
+	bus.Subscribe(TopicSignal, func(sig os.Signal) {
+		received <- sig
+	})
+
+	stop := bus.NotifySignals(syscall.SIGHUP)
+	defer stop()
+	syscall.Kill(os.Getpid(), syscall.SIGHUP)
+
+	select {
+	case sig := <-received:
+		if sig != syscall.SIGHUP {
+			t.Fail()
+		}
+	case <-time.After(time.Second):
+		t.Fatal("signal not published")
+	}
+}
+
+func TestPublishLifecycle(t *testing.T) {
+	bus := New()
+	phases := make([]LifecyclePhase, 0)
+	bus.Subscribe(TopicLifecycle, func(phase LifecyclePhase) {
+		phases = append(phases, phase)
+	})
+	bus.PublishLifecycle(LifecycleStarted)
+	bus.PublishLifecycle(LifecycleReady)
+	bus.PublishLifecycle(LifecycleStopping)
+	if len(phases) != 3 || phases[2] != LifecycleStopping || phases[1].String() != "ready" {
+		t.Fail()
+	}
+}
@@ -0,0 +1,100 @@
+package eventbus
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"time"
+)
+
+// Logger - receives the logs of a bus, see WithLogger. attrs are alternating
+// keys and values, as with slog.
+type Logger interface {
+	Log(level slog.Level, msg string, attrs ...interface{})
+}
+
+// LogOptions - configuration of the logs of a bus
+type LogOptions struct {
+	// Level is the minimum level logged; defaults to slog.LevelInfo. Publishes
+	// and handler executions are logged at debug level, subscriptions at info
+	// level, slow handlers, deprecated topics and dropped events at warn level
+	// and handler errors at error level.
+	Level slog.Level
+	// SlowHandler logs a warning for every handler execution taking longer,
+	// if set
+	SlowHandler time.Duration
+}
+
+// logging - logger of a bus and its options
+type logging struct {
+	logger Logger // nil without WithLogger
+	LogOptions
+}
+
+// WithLogger logs the activity of the bus to logger: subscriptions,
+// publishes, handler executions, slow handlers and errors, see LogOptions.
+// Without it, the bus doesn't log, but for deprecated topics and the
+// NoSubscriberLog policy, which use the standard log package.
+func WithLogger(logger Logger, opts *LogOptions) Option {
+	return func(bus *Bus) {
+		bus.logging = logging{logger: logger}
+		if opts != nil {
+			bus.logging.LogOptions = *opts
+		}
+	}
+}
+
+// NewSlogLogger - create a Logger writing to a slog.Logger
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return slogLogger{logger}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) Log(level slog.Level, msg string, attrs ...interface{}) {
+	l.logger.Log(context.Background(), level, msg, attrs...)
+}
+
+// enabled returns true if logs of level are logged
+func (l *logging) enabled(level slog.Level) bool {
+	return l.logger != nil && level >= l.Level
+}
+
+// timed returns true if handler executions must be timed for the logs
+func (l *logging) timed() bool {
+	return l.enabled(slog.LevelDebug) || l.SlowHandler > 0
+}
+
+// log logs msg if its level is enabled
+func (bus *Bus) log(level slog.Level, msg string, attrs ...interface{}) {
+	if bus.logging.enabled(level) {
+		bus.logging.logger.Log(level, msg, attrs...)
+	}
+}
+
+// warn logs a warning the bus always reported, with the standard log package
+// if it has no logger
+func (bus *Bus) warn(msg string, attrs ...interface{}) {
+	if bus.logging.logger == nil {
+		log.Printf("eventbus: %s", msg)
+		return
+	}
+	bus.log(slog.LevelWarn, msg, attrs...)
+}
+
+// logHandled logs the end of a handler execution that took duration (0 if
+// not timed) and returned err
+func (bus *Bus) logHandled(topic string, handler *eventHandler, err error, duration time.Duration) error {
+	if err != nil && bus.logging.enabled(slog.LevelError) {
+		bus.log(slog.LevelError, "handler failed", "topic", topic, "handler", handlerName(handler.callBack.Pointer()), "error", err)
+	}
+	if slow := bus.logging.SlowHandler; slow > 0 && duration > slow && bus.logging.enabled(slog.LevelWarn) {
+		bus.log(slog.LevelWarn, "slow handler", "topic", topic, "handler", handlerName(handler.callBack.Pointer()), "duration", duration)
+	}
+	if bus.logging.enabled(slog.LevelDebug) {
+		bus.log(slog.LevelDebug, "handler finished", "topic", topic, "handler", handlerName(handler.callBack.Pointer()), "duration", duration)
+	}
+	return err
+}
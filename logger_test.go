@@ -0,0 +1,90 @@
+package eventbus
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingLogger struct {
+	lines []string
+	sync.Mutex
+}
+
+func (l *recordingLogger) Log(level slog.Level, msg string, attrs ...interface{}) {
+	l.Lock()
+	defer l.Unlock()
+	l.lines = append(l.lines, level.String()+" "+msg)
+}
+
+func TestWithLogger(t *testing.T) {
+	logger := &recordingLogger{}
+	bus := New(WithLogger(logger, &LogOptions{Level: slog.LevelDebug, SlowHandler: 5 * time.Millisecond}))
+	handler := func(n int) error {
+		if n < 0 {
+			return errors.New("negative")
+		}
+		time.Sleep(time.Duration(n) * time.Millisecond)
+		return nil
+	}
+	bus.Subscribe("topic", handler)
+	bus.Publish("topic", 10)
+	bus.Publish("topic", -1)
+	bus.Unsubscribe("topic", handler)
+	bus.Publish("topic", 1)
+
+	expected := []string{
+		"INFO subscribed",
+		"DEBUG published", "DEBUG handler started", "WARN slow handler", "DEBUG handler finished",
+		"DEBUG published", "DEBUG handler started", "ERROR handler failed", "DEBUG handler finished",
+		"INFO unsubscribed",
+		"DEBUG published",
+	}
+	if strings.Join(logger.lines, ",") != strings.Join(expected, ",") {
+		t.Fatal(logger.lines)
+	}
+}
+
+func TestWithLoggerLevel(t *testing.T) {
+	logger := &recordingLogger{}
+	bus := New(WithLogger(logger, &LogOptions{Level: slog.LevelError}))
+	bus.Subscribe("topic", func() error { return errors.New("failed") })
+	bus.Publish("topic")
+	bus.SetNoSubscriberPolicy("other", NoSubscriberLog)
+	bus.Publish("other")
+	if len(logger.lines) != 1 || logger.lines[0] != "ERROR handler failed" {
+		t.Fatal(logger.lines)
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	var output bytes.Buffer
+	bus := New(WithLogger(NewSlogLogger(slog.New(slog.NewTextHandler(&output, nil))), nil))
+	bus.Subscribe("topic", func() {})
+	if !strings.Contains(output.String(), "msg=subscribed topic=topic") {
+		t.Fatal(output.String())
+	}
+}
+
+func TestWithoutLogger(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+	bus := New()
+	bus.Subscribe("topic", func() error { return errors.New("failed") })
+	bus.Publish("topic")
+	if output.Len() != 0 {
+		t.Fatal(output.String())
+	}
+	bus.SetNoSubscriberPolicy("other", NoSubscriberLog)
+	bus.Publish("other")
+	if !strings.Contains(output.String(), "eventbus: no subscribers for topic other, event dropped") {
+		t.Fatal(output.String())
+	}
+}
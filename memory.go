@@ -0,0 +1,231 @@
+package eventbus
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// MemoryPolicy - what a topic does with new async deliveries once its memory cap is reached
+type MemoryPolicy int
+
+const (
+	// DropNewest - new async deliveries are dropped while the cap is reached
+	DropNewest MemoryPolicy = iota
+	// DropOldest - queued async deliveries that didn't start yet are dropped,
+	// oldest first, to make room for new ones
+	DropOldest
+)
+
+// MemoryUsage - approximate memory retained by a topic
+type MemoryUsage struct {
+	Queued   int64  // bytes of arguments held by pending async deliveries
+	Retained int64  // bytes held by the topic's trace buffer
+	Cap      int64  // zero if unlimited
+	Dropped  uint64 // async deliveries dropped to stay under the cap
+}
+
+// memoryAccounting tracks the memory retained by the topics with a memory cap
+type memoryAccounting struct {
+	topics map[string]*topicMemory
+	sync.Mutex
+}
+
+type topicMemory struct {
+	usage   MemoryUsage
+	policy  MemoryPolicy
+	pending []*queuedDelivery // queued deliveries that didn't start, oldest first
+}
+
+// queuedDelivery - accounted async delivery
+type queuedDelivery struct {
+	size  int64
+	state int32 // queuedPending, queuedStarted or queuedDropped
+}
+
+const (
+	queuedPending int32 = iota
+	queuedStarted
+	queuedDropped
+)
+
+// SetMemoryCap starts accounting the memory retained by a topic: arguments of
+// pending async deliveries and trace buffer entries. Once they reach limit
+// bytes, new async deliveries are handled according to policy. A zero limit
+// only accounts, without a cap. Sizes are estimates of the reachable data.
+func (bus *Bus) SetMemoryCap(topic string, limit int64, policy MemoryPolicy) {
+	retained := bus.traceBytes(topic)
+	memory := &bus.memory
+	memory.Lock()
+	defer memory.Unlock()
+	if memory.topics == nil {
+		memory.topics = make(map[string]*topicMemory)
+	}
+	tm, ok := memory.topics[topic]
+	if !ok {
+		tm = &topicMemory{}
+		memory.topics[topic] = tm
+		tm.usage.Retained = retained
+	}
+	tm.usage.Cap, tm.policy = limit, policy
+}
+
+// RemoveMemoryCap stops accounting the memory retained by a topic
+func (bus *Bus) RemoveMemoryCap(topic string) {
+	memory := &bus.memory
+	memory.Lock()
+	defer memory.Unlock()
+	delete(memory.topics, topic)
+}
+
+// MemoryUsage returns the memory retained by a topic, zero if it isn't accounted
+func (bus *Bus) MemoryUsage(topic string) MemoryUsage {
+	memory := &bus.memory
+	memory.Lock()
+	defer memory.Unlock()
+	if tm, ok := memory.topics[topic]; ok {
+		return tm.usage
+	}
+	return MemoryUsage{}
+}
+
+// admit accounts an async delivery of args on topic. Returns nil if the topic
+// isn't accounted, and ok false if the delivery must be dropped.
+func (memory *memoryAccounting) admit(topic string, args []interface{}) (delivery *queuedDelivery, ok bool) {
+	memory.Lock()
+	defer memory.Unlock()
+	tm, accounted := memory.topics[topic]
+	if !accounted {
+		return nil, true
+	}
+	size := argsSize(args)
+	if limit := tm.usage.Cap; limit > 0 {
+		if tm.policy == DropOldest {
+			for len(tm.pending) > 0 && tm.usage.Queued+tm.usage.Retained+size > limit {
+				oldest := tm.pending[0]
+				tm.pending = tm.pending[1:]
+				if atomic.CompareAndSwapInt32(&oldest.state, queuedPending, queuedDropped) {
+					tm.usage.Queued -= oldest.size
+					tm.usage.Dropped++
+				}
+			}
+		}
+		if tm.usage.Queued+tm.usage.Retained+size > limit {
+			tm.usage.Dropped++
+			return nil, false
+		}
+	}
+	delivery = &queuedDelivery{size: size}
+	tm.usage.Queued += size
+	tm.pending = append(tm.pending, delivery)
+	return delivery, true
+}
+
+// start reports whether an accounted delivery may run, false if it was dropped
+func (memory *memoryAccounting) start(topic string, delivery *queuedDelivery) bool {
+	if delivery == nil {
+		return true
+	}
+	if !atomic.CompareAndSwapInt32(&delivery.state, queuedPending, queuedStarted) {
+		return false
+	}
+	memory.Lock()
+	defer memory.Unlock()
+	if tm, ok := memory.topics[topic]; ok {
+		if idx := indexOf(tm.pending, delivery); idx >= 0 {
+			tm.pending = append(tm.pending[:idx], tm.pending[idx+1:]...)
+		}
+	}
+	return true
+}
+
+// done releases the memory of a completed delivery
+func (memory *memoryAccounting) done(topic string, delivery *queuedDelivery) {
+	if delivery == nil {
+		return
+	}
+	memory.Lock()
+	defer memory.Unlock()
+	if tm, ok := memory.topics[topic]; ok {
+		tm.usage.Queued -= delivery.size
+	}
+}
+
+// retain adds delta bytes to the memory retained by a topic
+func (memory *memoryAccounting) retain(topic string, delta int64) {
+	memory.Lock()
+	defer memory.Unlock()
+	if tm, ok := memory.topics[topic]; ok {
+		tm.usage.Retained += delta
+	}
+}
+
+func indexOf(deliveries []*queuedDelivery, delivery *queuedDelivery) int {
+	for i, d := range deliveries {
+		if d == delivery {
+			return i
+		}
+	}
+	return -1
+}
+
+// argsSize estimates the memory reachable from event arguments
+func argsSize(args []interface{}) int64 {
+	seen := make(map[uintptr]bool)
+	size := int64(0)
+	for _, arg := range args {
+		size += valueSize(reflect.ValueOf(arg), seen)
+	}
+	return size
+}
+
+// valueSize estimates the memory held by v, counting the data behind pointers once
+func valueSize(v reflect.Value, seen map[uintptr]bool) int64 {
+	if !v.IsValid() {
+		return 0
+	}
+	size := int64(v.Type().Size())
+	switch v.Kind() {
+	case reflect.String:
+		size += int64(v.Len())
+	case reflect.Ptr:
+		if !v.IsNil() && !seen[v.Pointer()] {
+			seen[v.Pointer()] = true
+			size += valueSize(v.Elem(), seen)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			size += valueSize(v.Elem(), seen)
+		}
+	case reflect.Slice:
+		if !v.IsNil() && !seen[v.Pointer()] {
+			seen[v.Pointer()] = true
+			for i := 0; i < v.Len(); i++ {
+				size += valueSize(v.Index(i), seen)
+			}
+			size += int64(v.Cap()-v.Len()) * int64(v.Type().Elem().Size())
+		}
+	case reflect.Map:
+		if !v.IsNil() && !seen[v.Pointer()] {
+			seen[v.Pointer()] = true
+			iter := v.MapRange()
+			for iter.Next() {
+				size += valueSize(iter.Key(), seen) + valueSize(iter.Value(), seen)
+			}
+		}
+	case reflect.Struct:
+		size = 0
+		for i := 0; i < v.NumField(); i++ {
+			size += valueSize(v.Field(i), seen)
+		}
+		if size < int64(v.Type().Size()) {
+			size = int64(v.Type().Size()) // padding
+		}
+	case reflect.Array:
+		size = 0
+		for i := 0; i < v.Len(); i++ {
+			size += valueSize(v.Index(i), seen)
+		}
+	}
+	return size
+}
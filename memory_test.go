@@ -0,0 +1,100 @@
+package eventbus
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestArgsSize(t *testing.T) {
+	small := argsSize([]interface{}{1})
+	large := argsSize([]interface{}{strings.Repeat("x", 1000)})
+	if small <= 0 || large < 1000 {
+		t.Fail()
+	}
+	type node struct {
+		next *node
+		data []byte
+	}
+	cyclic := &node{data: make([]byte, 100)}
+	cyclic.next = cyclic
+	if size := argsSize([]interface{}{cyclic, map[string]int{"a": 1}}); size < 100 || size > 1000 {
+		t.Error(size)
+	}
+}
+
+func TestMemoryCapDropNewest(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	lock := sync.Mutex{}
+	calls := 0
+	bus.SubscribeAsync("topic", func(payload string) {
+		<-release
+		lock.Lock()
+		calls++
+		lock.Unlock()
+	}, false)
+	bus.SetMemoryCap("topic", 2500, DropNewest)
+	payload := strings.Repeat("x", 1000)
+	for i := 0; i < 5; i++ {
+		bus.Publish("topic", payload)
+	}
+	usage := bus.MemoryUsage("topic")
+	if usage.Dropped != 3 || usage.Queued < 2000 || usage.Queued > 2500 || usage.Cap != 2500 {
+		t.Error(usage)
+	}
+	close(release)
+	bus.WaitAsync()
+	if calls != 2 || bus.MemoryUsage("topic").Queued != 0 {
+		t.Fail()
+	}
+}
+
+func TestMemoryCapDropOldest(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	delivered := make([]int, 0)
+	bus.SubscribeAsync("topic", func(n int, payload string) {
+		<-release
+		delivered = append(delivered, n)
+	}, true)
+	bus.SetMemoryCap("topic", 2500, DropOldest)
+	payload := strings.Repeat("x", 1000)
+	for i := 0; i < 5; i++ {
+		bus.Publish("topic", i, payload)
+	}
+	close(release)
+	bus.WaitAsync()
+	// the first delivery may have started before being dropped
+	if len(delivered) < 2 || delivered[len(delivered)-1] != 4 || delivered[len(delivered)-2] != 3 {
+		t.Error(delivered)
+	}
+	if usage := bus.MemoryUsage("topic"); usage.Queued != 0 || int(usage.Dropped)+len(delivered) != 5 {
+		t.Error(usage)
+	}
+}
+
+func TestMemoryUsageTraces(t *testing.T) {
+	bus := New()
+	bus.Subscribe("topic", func(string) {})
+	bus.EnableTrace("topic", 2, 0)
+	bus.Publish("topic", "before")
+	bus.SetMemoryCap("topic", 0, DropNewest)
+	retained := bus.MemoryUsage("topic").Retained
+	if retained <= 0 {
+		t.Fail()
+	}
+	bus.Publish("topic", "after")
+	bus.Publish("topic", "after")
+	if bus.MemoryUsage("topic").Retained <= retained {
+		t.Fail()
+	}
+	bus.DisableTrace("topic")
+	if bus.MemoryUsage("topic").Retained != 0 {
+		t.Fail()
+	}
+	bus.RemoveMemoryCap("topic")
+	if bus.MemoryUsage("topic") != (MemoryUsage{}) {
+		t.Fail()
+	}
+}
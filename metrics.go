@@ -0,0 +1,135 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// Metrics - receives the activity of a bus, labeled by topic, see WithMetrics.
+// Implementations must be safe for concurrent use and fast: they are called
+// on the publish and delivery paths.
+type Metrics interface {
+	// Published is called for every event published on a topic, including
+	// control events and events without subscribers
+	Published(topic string)
+	// Handled is called after every handler invocation with its duration and
+	// the *HandlerError it returned or panicked with, if any
+	Handled(topic string, latency time.Duration, err error)
+	// QueueDepth is called whenever the number of async deliveries of a
+	// topic queued or running changes
+	QueueDepth(topic string, depth int)
+}
+
+// LabeledMetrics - Metrics also receiving the labels of the events and of the
+// subscriptions handling them, see WithMetricLabels, so that they can be
+// broken down by business dimension, e.g. tenant or component. When the
+// metrics implement it, its methods are called instead of Published and
+// Handled. labels is never nil and must not be modified.
+type LabeledMetrics interface {
+	Metrics
+	// PublishedLabeled is called instead of Published with the labels of the event
+	PublishedLabeled(topic string, labels map[string]string)
+	// HandledLabeled is called instead of Handled with the labels of the
+	// event, overridden by the labels of the subscription
+	HandledLabeled(topic string, labels map[string]string, latency time.Duration, err error)
+}
+
+// metricLabelsKey - context key of the metric labels of an event
+type metricLabelsKey struct{}
+
+// WithMetricLabels returns a copy of ctx carrying metric labels in addition to
+// the labels ctx already carries, to publish an event with PublishCtx. They
+// are reported to LabeledMetrics along with the event and its handlers.
+func WithMetricLabels(ctx context.Context, labels map[string]string) context.Context {
+	if len(labels) == 0 {
+		return ctx
+	}
+	merged := maps.Clone(MetricLabelsFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(labels))
+	}
+	maps.Copy(merged, labels)
+	return context.WithValue(ctx, metricLabelsKey{}, merged)
+}
+
+// MetricLabelsFromContext returns the metric labels carried by ctx, nil if
+// there are none. The map must not be modified.
+func MetricLabelsFromContext(ctx context.Context) map[string]string {
+	labels, _ := ctx.Value(metricLabelsKey{}).(map[string]string)
+	return labels
+}
+
+// WithMetricLabels reports the invocations of the subscription's handler to
+// LabeledMetrics with labels, e.g. {"component": "billing"}, replacing the
+// labels given before. They override the labels of the event.
+// Returns error if the subscription is not active.
+func (sub *Subscription) WithMetricLabels(labels map[string]string) error {
+	if !sub.IsActive() {
+		return fmt.Errorf("subscription to topic %s is not active", sub.topic)
+	}
+	labels = maps.Clone(labels)
+	sub.handler.labels.Store(&labels)
+	return nil
+}
+
+// WithMetrics reports the publishes, handler invocations, handler latencies,
+// errors and async queue depths of the bus to metrics, e.g. a
+// prommetrics.Collector exposing them to Prometheus.
+func WithMetrics(metrics Metrics) Option {
+	return func(bus *Bus) {
+		bus.metrics = metrics
+	}
+}
+
+// published reports an event published on topic with ctx to the metrics
+func (bus *Bus) published(ctx context.Context, topic string) {
+	if metrics, ok := bus.metrics.(LabeledMetrics); ok {
+		labels := MetricLabelsFromContext(ctx)
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		metrics.PublishedLabeled(topic, labels)
+		return
+	}
+	bus.metrics.Published(topic)
+}
+
+// handled reports the invocation of a handler with an event published on
+// topic with ctx to the metrics
+func (bus *Bus) handled(ctx context.Context, topic string, handler *eventHandler, latency time.Duration, err error) {
+	metrics, ok := bus.metrics.(LabeledMetrics)
+	if !ok {
+		bus.metrics.Handled(topic, latency, err)
+		return
+	}
+	labels := MetricLabelsFromContext(ctx)
+	if own := handler.labels.Load(); own != nil && len(*own) > 0 {
+		labels = maps.Clone(labels)
+		if labels == nil {
+			labels = make(map[string]string, len(*own))
+		}
+		maps.Copy(labels, *own)
+	}
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	metrics.HandledLabeled(topic, labels, latency, err)
+}
+
+// started counts an async delivery of an event published on topic
+func (bus *Bus) started(topic string) {
+	depth := bus.flow.started(topic)
+	if bus.metrics != nil {
+		bus.metrics.QueueDepth(topic, depth)
+	}
+}
+
+// finished counts the end of an async delivery of an event published on topic
+func (bus *Bus) finished(topic string) {
+	depth := bus.flow.finished(topic)
+	if bus.metrics != nil {
+		bus.metrics.QueueDepth(topic, depth)
+	}
+}
@@ -0,0 +1,107 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	published, handled, errors map[string]int
+	depths                     []int
+	sync.Mutex
+}
+
+func (m *recordingMetrics) Published(topic string) {
+	m.Lock()
+	defer m.Unlock()
+	m.published[topic]++
+}
+
+func (m *recordingMetrics) Handled(topic string, latency time.Duration, err error) {
+	m.Lock()
+	defer m.Unlock()
+	m.handled[topic]++
+	if err != nil {
+		m.errors[topic]++
+	}
+}
+
+func (m *recordingMetrics) QueueDepth(topic string, depth int) {
+	m.Lock()
+	defer m.Unlock()
+	m.depths = append(m.depths, depth)
+}
+
+func TestWithMetrics(t *testing.T) {
+	metrics := &recordingMetrics{published: map[string]int{}, handled: map[string]int{}, errors: map[string]int{}}
+	bus := New(WithMetrics(metrics))
+	bus.Subscribe("topic", func() error { return errors.New("failed") })
+	bus.SubscribeAsync("async", func() {}, true)
+	bus.Publish("topic")
+	bus.Publish("async")
+	bus.Publish("async")
+	bus.WaitAsync()
+
+	metrics.Lock()
+	defer metrics.Unlock()
+	if metrics.published["topic"] != 1 || metrics.published["async"] != 2 {
+		t.Fatal(metrics.published)
+	}
+	if metrics.handled["topic"] != 1 || metrics.errors["topic"] != 1 || metrics.handled["async"] != 2 || metrics.errors["async"] != 0 {
+		t.Fatal(metrics.handled, metrics.errors)
+	}
+	if len(metrics.depths) != 4 || metrics.depths[0] != 1 || metrics.depths[3] != 0 {
+		t.Fatal(metrics.depths)
+	}
+}
+
+type labeledMetrics struct {
+	recordingMetrics
+	labels []map[string]string
+}
+
+func (m *labeledMetrics) PublishedLabeled(topic string, labels map[string]string) {
+	m.Lock()
+	defer m.Unlock()
+	m.labels = append(m.labels, labels)
+}
+
+func (m *labeledMetrics) HandledLabeled(topic string, labels map[string]string, latency time.Duration, err error) {
+	m.Lock()
+	defer m.Unlock()
+	m.labels = append(m.labels, labels)
+}
+
+func TestWithMetricLabels(t *testing.T) {
+	metrics := &labeledMetrics{}
+	bus := New(WithMetrics(metrics))
+	sub, _ := bus.SubscribeHandle("topic", func() {})
+	if err := sub.WithMetricLabels(map[string]string{"component": "billing", "tenant": "sub"}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithMetricLabels(context.Background(), map[string]string{"tenant": "acme"})
+	ctx = WithMetricLabels(ctx, map[string]string{"region": "eu"})
+	bus.PublishCtx(ctx, "topic")
+	bus.Publish("topic")
+
+	expected := []map[string]string{
+		{"tenant": "acme", "region": "eu"},
+		{"tenant": "sub", "region": "eu", "component": "billing"},
+		{},
+		{"tenant": "sub", "component": "billing"},
+	}
+	if !reflect.DeepEqual(metrics.labels, expected) {
+		t.Fatal(metrics.labels)
+	}
+	if len(metrics.published) != 0 || len(metrics.handled) != 0 {
+		t.Fatal("unlabeled methods called")
+	}
+	sub.Unsubscribe()
+	if sub.WithMetricLabels(nil) == nil {
+		t.Fatal("labels set on an inactive subscription")
+	}
+}
@@ -0,0 +1,154 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MigrateOptions - options of Migrate
+type MigrateOptions struct {
+	// Verify runs once the subscriptions are mirrored on the new bus, before
+	// the traffic switches to it, e.g. to publish test events on it. An error
+	// aborts the migration.
+	Verify func(old, new *Bus) error
+	// Drain, if positive, closes the old bus once the traffic switched,
+	// waiting up to Drain for its async deliveries in flight.
+	Drain time.Duration
+}
+
+// Migrate moves a live service from the old bus to a newly configured one,
+// e.g. to enable a worker pool without restarting it: the subscriptions of
+// old are mirrored on new and checked for parity (new must accept every topic
+// and pattern of old the same way), opts.Verify runs, then the publishes on
+// old are forwarded to new and new atomically becomes the package-level bus
+// if old was. Subscriptions made on old after the switch get no events.
+// PublishWithResult, which bypasses Use middleware, still delivers on old.
+// Returns error, leaving old untouched, if the subscriptions don't match or
+// Verify failed, or the error closing old after opts.Drain.
+func Migrate(old, new *Bus, opts MigrateOptions) error {
+	if old == new {
+		return errors.New("can't migrate a bus to itself")
+	}
+	m := &migration{old: old, new: new, mirrored: make(map[*eventHandler]*eventHandler)}
+	if err := m.sync(nil); err != nil {
+		return m.rollback(err)
+	}
+	if opts.Verify != nil {
+		if err := opts.Verify(old, new); err != nil {
+			return m.rollback(err)
+		}
+	}
+	forward := func(PublishFunc) PublishFunc {
+		return func(ctx context.Context, topic string, args []interface{}) error {
+			return new.PublishCtx(ctx, topic, args...)
+		}
+	}
+	// picks up the subscriptions changed during Verify and switches the
+	// traffic while they can't change
+	if err := m.sync(func() { old.Use(forward) }); err != nil {
+		return m.rollback(err)
+	}
+	b.CompareAndSwap(old, new)
+	if opts.Drain <= 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Drain)
+	defer cancel()
+	return old.Close(ctx)
+}
+
+// migration - subscriptions of a bus mirrored on another one by Migrate
+type migration struct {
+	old, new *Bus
+	mirrored map[*eventHandler]*eventHandler // handlers of old to their copy on new
+}
+
+// sync mirrors the subscriptions of the old bus on the new one, then calls
+// switched (may be nil), holding both bus locks
+func (m *migration) sync(switched func()) error {
+	m.old.lock.Lock()
+	defer m.old.lock.Unlock()
+	m.new.lock.Lock()
+	defer m.new.lock.Unlock()
+	if m.new.closed {
+		return ErrBusClosed
+	}
+	current := make(map[*eventHandler]bool)
+	for _, key := range m.old.keys() {
+		handlers := m.old.handlersOf(key)
+		if err := m.compatible(key); err != nil {
+			return err
+		}
+		for _, handler := range handlers {
+			current[handler] = true
+			if _, ok := m.mirrored[handler]; !ok {
+				m.mirrored[handler] = handler.clone()
+				if err := m.new.attach(key, m.mirrored[handler]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for handler, clone := range m.mirrored {
+		if !current[handler] {
+			m.detach(clone)
+			delete(m.mirrored, handler)
+		}
+	}
+	m.new.unpark()
+	if switched != nil {
+		switched()
+	}
+	return nil
+}
+
+// compatible returns an error if the new bus doesn't handle a handler map key
+// of the old one the same way; both bus locks must be held
+func (m *migration) compatible(key string) error {
+	if strings.HasPrefix(key, regexKeyPrefix) {
+		return nil
+	}
+	if topic, err := m.new.checkTopic(key, false); err != nil || topic != key {
+		return fmt.Errorf("topic %s of the old bus is not accepted as is by the new bus: %v", key, err)
+	}
+	if m.old.hierarchy.isPattern(key) != m.new.hierarchy.isPattern(key) {
+		return fmt.Errorf("topic %s is a pattern on only one of the buses", key)
+	}
+	return nil
+}
+
+// detach unsubscribes a mirrored handler from the new bus; its lock must be held
+func (m *migration) detach(clone *eventHandler) {
+	if topic, idx := m.new.locate(clone); idx >= 0 {
+		m.new.removeHandler(topic, idx)
+	}
+}
+
+// rollback unsubscribes the mirrored handlers from the new bus and returns err
+func (m *migration) rollback(err error) error {
+	m.new.lock.Lock()
+	defer m.new.lock.Unlock()
+	for _, clone := range m.mirrored {
+		m.detach(clone)
+	}
+	return err
+}
+
+// clone returns a copy of the subscription of a handler, without its
+// delivery state
+func (handler *eventHandler) clone() *eventHandler {
+	clone := &eventHandler{
+		callBack: handler.callBack, subscribed: handler.subscribed,
+		flagOnce: handler.flagOnce, until: handler.until, priority: handler.priority,
+		async: handler.async, transactional: handler.transactional,
+		exclusive: handler.exclusive, shadow: handler.shadow,
+		checkpoint: handler.checkpoint, config: handler.config,
+		name: handler.name, after: handler.after, tags: handler.tags,
+	}
+	clone.middleware.Store(handler.middleware.Load())
+	clone.retry.Store(handler.retry.Load())
+	return clone
+}
@@ -0,0 +1,78 @@
+package eventbus
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMigrate(t *testing.T) {
+	old, next := New(WithSeparator(".")), New(WithSeparator("."), WithCopyPayloads())
+	var direct, async, pattern atomic.Int32
+	old.Subscribe("orders.created", func(int) { direct.Add(1) })
+	old.SubscribeAsync("orders.created", func(int) { async.Add(1) }, false)
+	old.Subscribe("orders.+", func(int) { pattern.Add(1) })
+
+	defaultBus := b.Load()
+	b.Store(old)
+	defer b.Store(defaultBus)
+
+	err := Migrate(old, next, MigrateOptions{
+		Verify: func(old, next *Bus) error {
+			if !next.HasCallback("orders.created") {
+				return errors.New("not mirrored")
+			}
+			return next.Publish("orders.created", 0)
+		},
+		Drain: time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next.WaitAsync()
+	if direct.Load() != 1 || async.Load() != 1 || pattern.Load() != 1 {
+		t.Fatal(direct.Load(), async.Load(), pattern.Load())
+	}
+	if b.Load() != next {
+		t.Fatal("default bus not switched")
+	}
+
+	old.Publish("orders.created", 1) // forwarded
+	Publish("orders.created", 2)
+	next.WaitAsync()
+	if direct.Load() != 3 || async.Load() != 3 || pattern.Load() != 3 {
+		t.Fatal(direct.Load(), async.Load(), pattern.Load())
+	}
+	if !errors.Is(old.Subscribe("orders.created", func() {}), ErrBusClosed) {
+		t.Fatal("old bus not closed")
+	}
+}
+
+func TestMigrateVerifyFails(t *testing.T) {
+	old, next := New(), New()
+	var calls atomic.Int32
+	old.Subscribe("topic", func() { calls.Add(1) })
+	fail := errors.New("fail")
+	if err := Migrate(old, next, MigrateOptions{Verify: func(*Bus, *Bus) error { return fail }}); err != fail {
+		t.Fatal(err)
+	}
+	if next.HasCallback("topic") {
+		t.Fatal("mirrored subscription not rolled back")
+	}
+	old.Publish("topic")
+	if calls.Load() != 1 {
+		t.Fail()
+	}
+}
+
+func TestMigrateIncompatibleTopics(t *testing.T) {
+	old, next := New(WithSeparator(".")), New(WithSeparator("/"))
+	old.Subscribe("a.+", func() {})
+	if err := Migrate(old, next, MigrateOptions{}); err == nil {
+		t.Fail()
+	}
+	if next.HasCallback("a.+") {
+		t.Fail()
+	}
+}
@@ -0,0 +1,177 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/rpc"
+	"sync"
+	"sync/atomic"
+)
+
+var errMirrorClosed = errors.New("mirror closed")
+
+// MirrorOptions - configuration of a Mirror
+type MirrorOptions struct {
+	// Topics mirrored; wildcard patterns are allowed on hierarchical buses
+	Topics []string
+	// SampleRate is the fraction of events forwarded, between 0 and 1;
+	// defaults to 1
+	SampleRate float64
+	// Scrub returns the arguments forwarded for an event, e.g. a copy with
+	// personal data redacted (see RedactKeys); it must not modify args.
+	// Defaults to forwarding the arguments as is.
+	Scrub func(topic string, args []interface{}) []interface{}
+}
+
+// MirrorStats - counters of a Mirror
+type MirrorStats struct {
+	Forwarded int64
+	Sampled   int64 // events skipped by sampling
+	Failed    int64 // events the remote bus couldn't be reached for
+}
+
+// Mirror - forwards sampled, scrubbed copies of the events of a bus to a
+// remote bus, see Bus.Mirror
+type Mirror struct {
+	bus       *Bus
+	address   string
+	path      string
+	opts      MirrorOptions
+	subs      []*Subscription
+	conn      *rpc.Client
+	closed    bool
+	connLock  sync.Mutex
+	stop      func()
+	forwarded atomic.Int64
+	sampled   atomic.Int64
+	failed    atomic.Int64
+}
+
+// Mirror forwards a sampled, scrubbed copy of the events published on the
+// given topics to the Client listening at address and path (see
+// NewClient and Client.Start), e.g. to feed a staging environment realistic
+// traffic. Events are forwarded asynchronously, so a slow or unreachable
+// remote bus doesn't slow publishers down; arguments must be gob-encodable.
+// Returns error if a topic can't be subscribed to.
+func (bus *Bus) Mirror(address, path string, opts MirrorOptions) (*Mirror, error) {
+	if opts.SampleRate <= 0 || opts.SampleRate > 1 {
+		opts.SampleRate = 1
+	}
+	m := &Mirror{bus: bus, address: address, path: path, opts: opts}
+	for _, topic := range opts.Topics {
+		sub, err := bus.SubscribeAsyncHandle(topic, m.forward, false)
+		if err != nil {
+			m.shutdown()
+			return nil, err
+		}
+		m.subs = append(m.subs, sub)
+	}
+	m.stop = bus.track(m.shutdown)
+	return m, nil
+}
+
+// Stats returns the counters of the mirror
+func (m *Mirror) Stats() MirrorStats {
+	return MirrorStats{m.forwarded.Load(), m.sampled.Load(), m.failed.Load()}
+}
+
+// Close stops mirroring and drops the events not forwarded yet. Closing the
+// bus closes its mirrors.
+func (m *Mirror) Close() {
+	m.stop()
+}
+
+// shutdown unsubscribes the mirror and closes its connection; it is safe to
+// call more than once
+func (m *Mirror) shutdown() {
+	for _, sub := range m.subs {
+		sub.Unsubscribe()
+	}
+	m.connLock.Lock()
+	defer m.connLock.Unlock()
+	m.closed = true
+	if m.conn != nil {
+		m.conn.Close()
+		m.conn = nil
+	}
+}
+
+// forward sends an event to the remote bus
+func (m *Mirror) forward(ev Event) {
+	if m.opts.SampleRate < 1 && rand.Float64() >= m.opts.SampleRate {
+		m.sampled.Add(1)
+		return
+	}
+	args := ev.Args
+	if m.opts.Scrub != nil {
+		args = m.opts.Scrub(ev.Topic, args)
+	}
+	if err := m.call(&ClientArg{args, ev.Topic, ev.Deadline, ev.forwarded("mirror")}); err != nil {
+		m.failed.Add(1)
+		return
+	}
+	m.forwarded.Add(1)
+}
+
+// call pushes an event to the remote client, dialing it if needed
+func (m *Mirror) call(arg *ClientArg) error {
+	m.connLock.Lock()
+	defer m.connLock.Unlock()
+	if m.closed {
+		return errMirrorClosed
+	}
+	if m.conn == nil {
+		conn, err := rpc.DialHTTPPath("tcp", m.address, m.path)
+		if err != nil {
+			return fmt.Errorf("dialing: %v", err)
+		}
+		m.conn = conn
+	}
+	var reply bool
+	if err := m.conn.Call(PublishService, arg, &reply); err != nil {
+		if err == rpc.ErrShutdown {
+			m.conn = nil // redial for the next event
+		}
+		return err
+	}
+	return nil
+}
+
+// RedactKeys returns a MirrorOptions.Scrub function replacing the values of
+// the given keys with "[REDACTED]" in the map[string]interface{} and
+// map[string]string arguments of events. Other arguments are forwarded as is.
+func RedactKeys(keys ...string) func(topic string, args []interface{}) []interface{} {
+	return func(topic string, args []interface{}) []interface{} {
+		scrubbed := make([]interface{}, len(args))
+		for i, arg := range args {
+			switch arg := arg.(type) {
+			case map[string]interface{}:
+				copied := make(map[string]interface{}, len(arg))
+				for key, value := range arg {
+					copied[key] = value
+				}
+				for _, key := range keys {
+					if _, ok := copied[key]; ok {
+						copied[key] = "[REDACTED]"
+					}
+				}
+				scrubbed[i] = copied
+			case map[string]string:
+				copied := make(map[string]string, len(arg))
+				for key, value := range arg {
+					copied[key] = value
+				}
+				for _, key := range keys {
+					if _, ok := copied[key]; ok {
+						copied[key] = "[REDACTED]"
+					}
+				}
+				scrubbed[i] = copied
+			default:
+				scrubbed[i] = arg
+			}
+		}
+		return scrubbed
+	}
+}
@@ -0,0 +1,76 @@
+package eventbus
+
+import (
+	"encoding/gob"
+	"sync"
+	"testing"
+)
+
+func TestMirror(t *testing.T) {
+	gob.Register(map[string]interface{}{}) // sent as interface{} arguments
+	staging := New()
+	lock := sync.Mutex{}
+	var received []map[string]interface{}
+	staging.Subscribe("users:created", func(user map[string]interface{}) {
+		lock.Lock()
+		defer lock.Unlock()
+		received = append(received, user)
+	})
+	var hops []Hop
+	staging.Subscribe("users:created", func(ev Event) {
+		lock.Lock()
+		defer lock.Unlock()
+		hops = ev.Provenance
+	})
+	client := NewClient("localhost:2045", "/_mirror_staging_", staging)
+	if err := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Stop()
+
+	production := New()
+	mirror, err := production.Mirror("localhost:2045", "/_mirror_staging_", MirrorOptions{
+		Topics: []string{"users:created"},
+		Scrub:  RedactKeys("email"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := map[string]interface{}{"name": "ann", "email": "ann@example.com"}
+	production.Publish("users:created", user)
+	production.Publish("users:deleted", user)
+	production.WaitAsync()
+
+	lock.Lock()
+	if len(received) != 1 || received[0]["name"] != "ann" || received[0]["email"] != "[REDACTED]" {
+		t.Fatal(received)
+	}
+	if len(hops) != 1 || hops[0].Topic != "users:created" || hops[0].Via != "mirror" {
+		t.Fatal(hops)
+	}
+	lock.Unlock()
+	if user["email"] != "ann@example.com" {
+		t.Fatal(user) // the published payload isn't scrubbed
+	}
+	if stats := mirror.Stats(); stats.Forwarded != 1 || stats.Failed != 0 {
+		t.Fatal(stats)
+	}
+
+	mirror.Close()
+	if production.HasCallback("users:created") {
+		t.Fail()
+	}
+}
+
+func TestMirrorSampling(t *testing.T) {
+	bus := New()
+	mirror, _ := bus.Mirror("localhost:1", "/_nowhere_", MirrorOptions{Topics: []string{"topic"}, SampleRate: 0.5})
+	for i := 0; i < 200; i++ {
+		bus.Publish("topic", i)
+	}
+	bus.WaitAsync()
+	stats := mirror.Stats()
+	if stats.Sampled == 0 || stats.Failed == 0 || stats.Sampled+stats.Failed != 200 || stats.Forwarded != 0 {
+		t.Fatal(stats)
+	}
+}
@@ -0,0 +1,68 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type namespaces struct {
+	owners map[string]string // prefix -> owner
+	sync.Mutex
+}
+
+// ClaimNamespace runs ClaimNamespace on package-level bus singleton
+func ClaimNamespace(prefix, owner string) error {
+	return b.Load().ClaimNamespace(prefix, owner)
+}
+
+// ClaimNamespace reserves the topics starting with prefix for owner, so
+// components embedding the bus detect collisions instead of silently sharing
+// topics. Claiming again with the same owner is a no-op.
+// Returns error if the prefix overlaps a namespace claimed by another owner.
+func (bus *Bus) ClaimNamespace(prefix, owner string) error {
+	if prefix == "" {
+		return errors.New("namespace prefix must not be empty")
+	}
+	bus.namespaces.Lock()
+	defer bus.namespaces.Unlock()
+	for claimed, claimedBy := range bus.namespaces.owners {
+		if claimedBy == owner {
+			continue
+		}
+		if strings.HasPrefix(prefix, claimed) || strings.HasPrefix(claimed, prefix) {
+			return fmt.Errorf("namespace %s overlaps %s owned by %s", prefix, claimed, claimedBy)
+		}
+	}
+	if bus.namespaces.owners == nil {
+		bus.namespaces.owners = make(map[string]string)
+	}
+	bus.namespaces.owners[prefix] = owner
+	return nil
+}
+
+// ReleaseNamespace gives up a namespace claimed by owner.
+// Returns error if the prefix isn't claimed by owner.
+func (bus *Bus) ReleaseNamespace(prefix, owner string) error {
+	bus.namespaces.Lock()
+	defer bus.namespaces.Unlock()
+	if bus.namespaces.owners[prefix] != owner || owner == "" {
+		return fmt.Errorf("namespace %s is not owned by %s", prefix, owner)
+	}
+	delete(bus.namespaces.owners, prefix)
+	return nil
+}
+
+// NamespaceOwner returns the owner of the longest claimed prefix of topic
+func (bus *Bus) NamespaceOwner(topic string) (owner string, ok bool) {
+	bus.namespaces.Lock()
+	defer bus.namespaces.Unlock()
+	longest := ""
+	for claimed, claimedBy := range bus.namespaces.owners {
+		if strings.HasPrefix(topic, claimed) && len(claimed) > len(longest) {
+			longest, owner, ok = claimed, claimedBy, true
+		}
+	}
+	return owner, ok
+}
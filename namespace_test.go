@@ -0,0 +1,46 @@
+package eventbus
+
+import (
+	"testing"
+)
+
+func TestClaimNamespace(t *testing.T) {
+	bus := New()
+	if bus.ClaimNamespace("billing:", "billing") != nil {
+		t.Fail()
+	}
+	if bus.ClaimNamespace("billing:", "billing") != nil {
+		t.Fail()
+	}
+	if bus.ClaimNamespace("billing:invoices:", "billing") != nil {
+		t.Fail()
+	}
+	if bus.ClaimNamespace("billing:refunds:", "payments") == nil {
+		t.Fail()
+	}
+	if bus.ClaimNamespace("bill", "payments") == nil {
+		t.Fail()
+	}
+	if bus.ClaimNamespace("", "payments") == nil {
+		t.Fail()
+	}
+	if bus.ClaimNamespace("payments:", "payments") != nil {
+		t.Fail()
+	}
+
+	if owner, ok := bus.NamespaceOwner("billing:invoices:paid"); !ok || owner != "billing" {
+		t.Fail()
+	}
+	if _, ok := bus.NamespaceOwner("users:created"); ok {
+		t.Fail()
+	}
+
+	if bus.ReleaseNamespace("billing:", "payments") == nil {
+		t.Fail()
+	}
+	bus.ReleaseNamespace("billing:", "billing")
+	bus.ReleaseNamespace("billing:invoices:", "billing")
+	if bus.ClaimNamespace("billing:refunds:", "payments") != nil {
+		t.Fail()
+	}
+}
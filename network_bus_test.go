@@ -2,6 +2,7 @@ package eventbus
 
 import (
 	"testing"
+	"time"
 )
 
 func TestNewServer(t *testing.T) {
@@ -47,7 +48,7 @@ func TestPushEvent(t *testing.T) {
 	eventArgs := make([]interface{}, 1)
 	eventArgs[0] = 10
 
-	clientArg := &ClientArg{eventArgs, "topic"}
+	clientArg := &ClientArg{Args: eventArgs, Topic: "topic"}
 	reply := new(bool)
 
 	fn := func(a int) {
@@ -110,3 +111,17 @@ func TestNetworkBus(t *testing.T) {
 	networkBusA.Stop()
 	networkBusB.Stop()
 }
+
+func TestPushEventExpired(t *testing.T) {
+	clientBus := NewClient("localhost:2015", "/_client_bus_", New())
+	delivered := 0
+	clientBus.eventBus.Subscribe("topic", func(a int) {
+		delivered++
+	})
+	reply := new(bool)
+	clientBus.service.PushEvent(&ClientArg{Args: []interface{}{1}, Topic: "topic", Deadline: time.Now().Add(time.Hour)}, reply)
+	clientBus.service.PushEvent(&ClientArg{Args: []interface{}{2}, Topic: "topic", Deadline: time.Now().Add(-time.Second)}, reply)
+	if delivered != 1 {
+		t.Fatal("expired event delivered")
+	}
+}
@@ -0,0 +1,25 @@
+// Package networkbus exposes an event bus over gRPC: a Server serving the
+// publishes and streaming subscriptions of remote processes, and a Client
+// forwarding Publish and Subscribe to a remote bus.
+//
+// Events travel as gob encoded envelopes (see eventbus.Event), so no
+// generated code is needed on either side; the types of the arguments other
+// than the basic ones must be registered with gob.Register in both processes.
+//
+// UnaryServerInterceptor and StreamServerInterceptor publish the lifecycle of
+// the calls of any gRPC server on a bus, like eventbus.Bus.HTTPMiddleware does
+// for HTTP requests.
+//
+// It depends on google.golang.org/grpc, so it is only built with the
+// eventbus_grpc build tag (-tags eventbus_grpc), keeping the dependency out of
+// the builds of users without gRPC:
+//
+//	server := grpc.NewServer()
+//	networkbus.NewServer(bus).Register(server)
+//	go server.Serve(listener)
+//
+//	conn, _ := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	client := networkbus.NewClient(conn)
+//	client.Subscribe("orders", func(order Order) { ... })
+//	client.Publish("orders", Order{ID: 1})
+package networkbus
@@ -0,0 +1,98 @@
+//go:build eventbus_grpc
+
+package networkbus
+
+import (
+	"context"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultRPCStartedTopic - default topic of call started events
+	DefaultRPCStartedTopic = "grpc:call:started"
+	// DefaultRPCFinishedTopic - default topic of call finished events
+	DefaultRPCFinishedTopic = "grpc:call:finished"
+)
+
+// RPCEvent - call lifecycle event published by the server interceptors
+type RPCEvent struct {
+	FullMethod string // /package.Service/Method
+	Peer       string // address of the client, if known
+	Stream     bool
+	// Code, Message and Duration are only set on finished events
+	Code     codes.Code
+	Message  string
+	Duration time.Duration
+}
+
+// InterceptorOptions - configuration of UnaryServerInterceptor and
+// StreamServerInterceptor
+type InterceptorOptions struct {
+	StartedTopic  string // defaults to DefaultRPCStartedTopic
+	FinishedTopic string // defaults to DefaultRPCFinishedTopic
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor publishing an RPCEvent on
+// bus when a unary call starts and when it finishes
+func UnaryServerInterceptor(bus *eventbus.Bus, opts *InterceptorOptions) grpc.UnaryServerInterceptor {
+	options := interceptorOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		event := rpcEvent(ctx, info.FullMethod, false)
+		bus.Publish(options.StartedTopic, event)
+		start := time.Now()
+		reply, err := handler(ctx, req)
+		finish(bus, options, event, start, err)
+		return reply, err
+	}
+}
+
+// StreamServerInterceptor returns a gRPC interceptor publishing an RPCEvent on
+// bus when a streaming call starts and when it finishes
+func StreamServerInterceptor(bus *eventbus.Bus, opts *InterceptorOptions) grpc.StreamServerInterceptor {
+	options := interceptorOptions(opts)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		event := rpcEvent(stream.Context(), info.FullMethod, true)
+		bus.Publish(options.StartedTopic, event)
+		start := time.Now()
+		err := handler(srv, stream)
+		finish(bus, options, event, start, err)
+		return err
+	}
+}
+
+func interceptorOptions(opts *InterceptorOptions) InterceptorOptions {
+	options := InterceptorOptions{}
+	if opts != nil {
+		options = *opts
+	}
+	if options.StartedTopic == "" {
+		options.StartedTopic = DefaultRPCStartedTopic
+	}
+	if options.FinishedTopic == "" {
+		options.FinishedTopic = DefaultRPCFinishedTopic
+	}
+	return options
+}
+
+func rpcEvent(ctx context.Context, method string, stream bool) RPCEvent {
+	event := RPCEvent{FullMethod: method, Stream: stream}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		event.Peer = p.Addr.String()
+	}
+	return event
+}
+
+// finish publishes the finished event of a call with the status of err
+func finish(bus *eventbus.Bus, options InterceptorOptions, event RPCEvent, start time.Time, err error) {
+	s := status.Convert(err)
+	event.Code = s.Code()
+	event.Message = s.Message()
+	event.Duration = time.Since(start)
+	bus.Publish(options.FinishedTopic, event)
+}
@@ -0,0 +1,364 @@
+//go:build eventbus_grpc
+
+package networkbus
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	eventbus "github.com/asaskevich/EventBus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceName - name of the gRPC service
+const ServiceName = "eventbus.NetworkBus"
+
+const (
+	publishMethod   = "/" + ServiceName + "/Publish"
+	subscribeMethod = "/" + ServiceName + "/Subscribe"
+	codecName       = "eventbus-gob"
+	subscribedKey   = "eventbus-subscribed" // header sent once a subscription is active
+	buffer          = 64                    // events queued per remote subscription
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// codec - gRPC codec encoding messages with gob
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	data := bytes.Buffer{}
+	err := gob.NewEncoder(&data).Encode(v)
+	return data.Bytes(), err
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// subscribeRequest - first and only message of a client on a Subscribe stream
+type subscribeRequest struct {
+	Topic string
+}
+
+// publishReply - reply to Publish
+type publishReply struct {
+	Published bool
+}
+
+// serviceDesc - hand written description of the service, the messages being
+// Go values encoded by codec rather than protocol buffers
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods:     []grpc.MethodDesc{{MethodName: "Publish", Handler: publishHandler}},
+	Streams:     []grpc.StreamDesc{{StreamName: "Subscribe", Handler: subscribeHandler, ServerStreams: true}},
+}
+
+// Server - gRPC service publishing the events of remote clients on a bus and
+// streaming the events of its topics to them
+type Server struct {
+	bus *eventbus.Bus
+}
+
+// NewServer - create a Server for bus
+func NewServer(bus *eventbus.Bus) *Server {
+	return &Server{bus}
+}
+
+// Register registers the service on a gRPC server
+func (server *Server) Register(registrar grpc.ServiceRegistrar) {
+	registrar.RegisterService(&serviceDesc, server)
+}
+
+func publishHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	ev := &eventbus.Event{}
+	if err := dec(ev); err != nil {
+		return nil, err
+	}
+	publish := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if err := srv.(*Server).bus.PublishEvent(*req.(*eventbus.Event)); err != nil {
+			return nil, statusError(err)
+		}
+		return &publishReply{true}, nil
+	}
+	if interceptor == nil {
+		return publish(ctx, ev)
+	}
+	return interceptor(ctx, ev, &grpc.UnaryServerInfo{Server: srv, FullMethod: publishMethod}, publish)
+}
+
+func subscribeHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := &subscribeRequest{}
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(*Server).subscribe(req.Topic, stream)
+}
+
+// subscribe streams the events of a topic until the client goes away. Like
+// SubscribeChannel, publishes block while the stream is behind by more than
+// buffer events.
+func (server *Server) subscribe(topic string, stream grpc.ServerStream) error {
+	ctx := stream.Context()
+	events := make(chan eventbus.Event, buffer)
+	sub, err := server.bus.SubscribeHandle(topic, func(ev eventbus.Event) {
+		select {
+		case events <- ev:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		return statusError(err)
+	}
+	defer sub.Unsubscribe()
+	if err := stream.SendHeader(metadata.Pairs(subscribedKey, topic)); err != nil {
+		return err
+	}
+	for {
+		select {
+		case ev := <-events:
+			ev.Provenance = append(ev.Provenance[:len(ev.Provenance):len(ev.Provenance)], eventbus.Hop{Topic: ev.Topic, Time: ev.Time, Via: "grpc"})
+			if err := stream.SendMsg(&ev); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// statusError returns the gRPC status of an error of the bus
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, eventbus.ErrInvalidTopic):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, eventbus.ErrBusClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, eventbus.ErrNoSubscribers):
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
+
+// Client - forwards publishes and subscriptions to the bus of a remote Server.
+// Every subscription streams the events of its topic, delivered to its
+// handler in order on a goroutine of its own.
+type Client struct {
+	conn          grpc.ClientConnInterface
+	subscriptions map[string][]*subscription
+	onError       func(ev eventbus.Event, err error)
+	lock          sync.Mutex
+}
+
+// subscription - handler subscribed to a topic of the remote bus
+type subscription struct {
+	client  *Client
+	handler reflect.Value
+	cancel  context.CancelFunc
+}
+
+var eventType = reflect.TypeOf(eventbus.Event{})
+
+// NewClient - create a Client of the Server reached through conn
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn, subscriptions: make(map[string][]*subscription)}
+}
+
+// OnError sets the function called with the remote events a handler couldn't
+// take (arguments not matching its parameters) or panicked on, and with the
+// events received once expired, with an error wrapping
+// context.DeadlineExceeded. Such events are dropped; the subscription goes on
+// with the next one.
+func (client *Client) OnError(fn func(ev eventbus.Event, err error)) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	client.onError = fn
+}
+
+// Publish publishes an event on a topic of the remote bus
+func (client *Client) Publish(topic string, args ...interface{}) error {
+	return client.PublishEvent(context.Background(), eventbus.Event{Topic: topic, Args: args})
+}
+
+// PublishEvent publishes an envelope on the remote bus, see
+// eventbus.Bus.PublishEvent. The deadline of ctx, if earlier than the
+// deadline of ev, becomes the deadline of the event on the remote bus.
+func (client *Client) PublishEvent(ctx context.Context, ev eventbus.Event) error {
+	if deadline, ok := ctx.Deadline(); ok && (ev.Deadline.IsZero() || deadline.Before(ev.Deadline)) {
+		ev.Deadline = deadline
+	}
+	return client.conn.Invoke(ctx, publishMethod, &ev, &publishReply{}, grpc.CallContentSubtype(codecName))
+}
+
+// Subscribe subscribes to a topic (possibly a wildcard pattern) of the remote
+// bus. fn takes the arguments of the events, or their eventbus.Event envelope.
+// Returns once the subscription is active on the server, or an error if it
+// can't be. Control topics of the remote bus can't be subscribed to.
+func (client *Client) Subscribe(topic string, fn interface{}) error {
+	handler := reflect.ValueOf(fn)
+	if handler.Kind() != reflect.Func {
+		return fmt.Errorf("%s is not of type reflect.Func", handler.Kind())
+	}
+	if eventbus.IsControlTopic(topic) {
+		return fmt.Errorf("%w %q: control topics of the remote bus can't be subscribed to", eventbus.ErrReservedTopic, topic)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.conn.NewStream(ctx, &serviceDesc.Streams[0], subscribeMethod, grpc.CallContentSubtype(codecName))
+	if err == nil {
+		err = stream.SendMsg(&subscribeRequest{topic})
+	}
+	if err == nil {
+		err = stream.CloseSend()
+	}
+	if err == nil {
+		err = subscribed(stream)
+	}
+	if err != nil {
+		cancel()
+		return err
+	}
+	sub := &subscription{client, handler, cancel}
+	client.lock.Lock()
+	client.subscriptions[topic] = append(client.subscriptions[topic], sub)
+	client.lock.Unlock()
+	go sub.receive(stream)
+	return nil
+}
+
+// subscribed waits for the server to confirm a subscription
+func subscribed(stream grpc.ClientStream) error {
+	header, err := stream.Header()
+	if err != nil {
+		return err
+	}
+	if len(header.Get(subscribedKey)) > 0 {
+		return nil
+	}
+	if err := stream.RecvMsg(&eventbus.Event{}); err != nil {
+		return err // why the stream ended without subscribing
+	}
+	return errors.New("subscription not confirmed by the server")
+}
+
+// Unsubscribe removes a handler subscribed to a topic with Subscribe
+func (client *Client) Unsubscribe(topic string, fn interface{}) error {
+	pointer := reflect.ValueOf(fn).Pointer()
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	for i, sub := range client.subscriptions[topic] {
+		if sub.handler.Pointer() == pointer {
+			sub.cancel()
+			client.subscriptions[topic] = append(client.subscriptions[topic][:i:i], client.subscriptions[topic][i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("handler is not subscribed to topic %s", topic)
+}
+
+// Close cancels every subscription; conn is left open
+func (client *Client) Close() {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	for topic, subs := range client.subscriptions {
+		for _, sub := range subs {
+			sub.cancel()
+		}
+		delete(client.subscriptions, topic)
+	}
+}
+
+// receive delivers the events of the stream to the handler until the stream
+// ends: once cancelled, or when the connection to the server is lost
+func (sub *subscription) receive(stream grpc.ClientStream) {
+	defer sub.cancel()
+	for {
+		ev := eventbus.Event{}
+		if err := stream.RecvMsg(&ev); err != nil {
+			return
+		}
+		if err := sub.deliver(ev); err != nil {
+			sub.client.lock.Lock()
+			onError := sub.client.onError
+			sub.client.lock.Unlock()
+			if onError != nil {
+				onError(ev, err)
+			}
+		}
+	}
+}
+
+// deliver calls the handler with an event, returning an error if the event
+// expired, the arguments of the event don't match its parameters or it
+// panicked
+func (sub *subscription) deliver(ev eventbus.Event) (err error) {
+	if ev.Expired() {
+		return fmt.Errorf("event of topic %s expired at %s: %w", ev.Topic, ev.Deadline, context.DeadlineExceeded)
+	}
+	args, err := sub.arguments(ev)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("handler of topic %s panicked: %v", ev.Topic, recovered)
+		}
+	}()
+	sub.handler.Call(args)
+	return nil
+}
+
+// arguments returns the arguments of the handler for an event, or an error if
+// the event doesn't match its parameters
+func (sub *subscription) arguments(ev eventbus.Event) ([]reflect.Value, error) {
+	handlerType := sub.handler.Type()
+	if handlerType.NumIn() == 1 && handlerType.In(0) == eventType {
+		return []reflect.Value{reflect.ValueOf(ev)}, nil
+	}
+	n, variadic := handlerType.NumIn(), handlerType.IsVariadic()
+	if (!variadic && len(ev.Args) != n) || (variadic && len(ev.Args) < n-1) {
+		return nil, fmt.Errorf("event of topic %s has %d arguments, handler takes %d", ev.Topic, len(ev.Args), n)
+	}
+	args := make([]reflect.Value, len(ev.Args))
+	for i, arg := range ev.Args {
+		var param reflect.Type
+		if variadic && i >= n-1 {
+			param = handlerType.In(n - 1).Elem()
+		} else {
+			param = handlerType.In(i)
+		}
+		switch {
+		case arg == nil && nillable(param):
+			args[i] = reflect.Zero(param)
+		case arg != nil && reflect.TypeOf(arg).AssignableTo(param):
+			args[i] = reflect.ValueOf(arg)
+		default:
+			return nil, fmt.Errorf("argument %d of event of topic %s is %T, handler takes %s", i, ev.Topic, arg, param)
+		}
+	}
+	return args, nil
+}
+
+// nillable reports whether nil is a value of a type
+func nillable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+//go:build eventbus_grpc
+
+package networkbus
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dial starts a Server for bus and returns a Client connected to it
+func dial(t *testing.T, bus *eventbus.Bus) *Client {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	NewServer(bus).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := NewClient(conn)
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestRoundTrip(t *testing.T) {
+	bus := eventbus.New()
+	client := dial(t, bus)
+	received := make(chan string, 1)
+	if err := client.Subscribe("orders", func(id string, quantity int) {
+		received <- id + strings.Repeat("+", quantity)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish("orders", "order", 2)
+	select {
+	case got := <-received:
+		if got != "order++" {
+			t.Fatal(got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+
+	local := make(chan string, 1)
+	bus.Subscribe("payments", func(id string) { local <- id })
+	if err := client.Publish("payments", "payment"); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-local; got != "payment" {
+		t.Fatal(got)
+	}
+	if err := client.Subscribe(eventbus.TopicPanic, func(eventbus.Event) {}); err == nil {
+		t.Fail()
+	}
+}
+
+func TestMismatchedEvents(t *testing.T) {
+	bus := eventbus.New()
+	client := dial(t, bus)
+	errs := make(chan error, 3)
+	client.OnError(func(ev eventbus.Event, err error) { errs <- err })
+	received := make(chan int, 1)
+	if err := client.Subscribe("numbers", func(n int) {
+		if n < 0 {
+			panic("negative")
+		}
+		received <- n
+	}); err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish("numbers", 1, 2)  // arity
+	bus.Publish("numbers", "one") // type
+	bus.Publish("numbers", -1)    // panic
+	bus.Publish("numbers", 1)
+	for _, want := range []string{"2 arguments", "string", "panicked"} {
+		select {
+		case err := <-errs:
+			if !strings.Contains(err.Error(), want) {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("error not reported:", want)
+		}
+	}
+	select {
+	case n := <-received:
+		if n != 1 {
+			t.Fatal(n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscription stopped after a mismatched event")
+	}
+}
+
+func TestServerInterceptors(t *testing.T) {
+	bus := eventbus.New()
+	calls := eventbus.New()
+	events := make(chan RPCEvent, 4)
+	calls.Subscribe(DefaultRPCStartedTopic, func(ev RPCEvent) { events <- ev })
+	calls.Subscribe(DefaultRPCFinishedTopic, func(ev RPCEvent) { events <- ev })
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(calls, nil)),
+		grpc.StreamInterceptor(StreamServerInterceptor(calls, nil)))
+	NewServer(bus).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if err := NewClient(conn).Publish("bus:closed"); err == nil {
+		t.Fatal("control topic published")
+	}
+	started, finished := <-events, <-events
+	if started.FullMethod != publishMethod || started.Stream || started.Code != codes.OK {
+		t.Fatal(started)
+	}
+	if finished.FullMethod != publishMethod || finished.Code != codes.InvalidArgument || finished.Duration <= 0 {
+		t.Fatal(finished)
+	}
+
+	client := NewClient(conn)
+	if err := client.Subscribe("orders", func(string) {}); err != nil {
+		t.Fatal(err)
+	}
+	if started := <-events; started.FullMethod != subscribeMethod || !started.Stream {
+		t.Fatal(started)
+	}
+	client.Close()
+	select {
+	case finished := <-events:
+		if finished.FullMethod != subscribeMethod || !finished.Stream {
+			t.Fatal(finished)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream finished event not published")
+	}
+}
@@ -0,0 +1,100 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NoSubscriberPolicy - what the bus does with an event published on a topic
+// nobody is subscribed to
+type NoSubscriberPolicy int
+
+const (
+	// NoSubscriberDrop - drop the event silently
+	NoSubscriberDrop NoSubscriberPolicy = iota
+	// NoSubscriberLog - drop the event and log it
+	NoSubscriberLog
+	// NoSubscriberError - drop the event and return ErrNoSubscribers to the publisher
+	NoSubscriberError
+	// NoSubscriberBuffer - park the event until a handler subscribes to the topic,
+	// for the parking TTL at most, see SetParking
+	NoSubscriberBuffer
+	// NoSubscriberDeadLetter - publish the event as a DeadLetter on DeadLetterTopic(topic)
+	NoSubscriberDeadLetter
+)
+
+// DeadLetterPrefix - prefix of the dead-letter topic of a topic
+const DeadLetterPrefix = "_dlq."
+
+// ErrNoSubscribers - error returned by Publish under NoSubscriberError
+var ErrNoSubscribers = errors.New("no subscribers")
+
+// DeadLetter - event that couldn't be delivered, published on the dead-letter
+// topic of the topic it was published on
+type DeadLetter struct {
+	Topic   string
+	Args    []interface{}
+	Reason  error // ErrNoSubscribers, ErrCascadeTooDeep, or the *HandlerError of a failed handler
+	Time    time.Time
+	Handler string // name of the failed handler, see WithDeadLetters
+}
+
+// DeadLetterTopic returns the dead-letter topic of a topic
+func DeadLetterTopic(topic string) string {
+	return DeadLetterPrefix + topic
+}
+
+type noSubscribers struct {
+	policy NoSubscriberPolicy
+	topics map[string]NoSubscriberPolicy
+	sync.Mutex
+}
+
+// WithNoSubscriberPolicy sets what the bus does with events published on
+// topics without subscribers, NoSubscriberDrop by default. Control topics and
+// dead-letter topics always drop them.
+func WithNoSubscriberPolicy(policy NoSubscriberPolicy) Option {
+	return func(bus *Bus) {
+		bus.noSubscribers.policy = policy
+	}
+}
+
+// SetNoSubscriberPolicy overrides the no subscriber policy of a topic
+func (bus *Bus) SetNoSubscriberPolicy(topic string, policy NoSubscriberPolicy) {
+	ns := &bus.noSubscribers
+	ns.Lock()
+	defer ns.Unlock()
+	if ns.topics == nil {
+		ns.topics = make(map[string]NoSubscriberPolicy)
+	}
+	ns.topics[topic] = policy
+}
+
+// dropUnsubscribed applies the no subscriber policy to an event published on
+// topic; the bus lock must be held
+func (bus *Bus) dropUnsubscribed(topic string, args []interface{}) error {
+	if strings.HasPrefix(topic, controlPrefix) || strings.HasPrefix(topic, DeadLetterPrefix) {
+		return nil
+	}
+	ns := &bus.noSubscribers
+	ns.Lock()
+	defer ns.Unlock()
+	policy, ok := ns.topics[topic]
+	if !ok {
+		policy = ns.policy
+	}
+	switch policy {
+	case NoSubscriberLog:
+		bus.warn(fmt.Sprintf("no subscribers for topic %s, event dropped", topic), "topic", topic)
+	case NoSubscriberError:
+		return fmt.Errorf("%w for topic %s", ErrNoSubscribers, topic)
+	case NoSubscriberBuffer:
+		bus.parking.park(topic, args)
+	case NoSubscriberDeadLetter:
+		bus.publishControl(DeadLetterTopic(topic), DeadLetter{topic, args, fmt.Errorf("%w for topic %s", ErrNoSubscribers, topic), time.Now(), ""})
+	}
+	return nil
+}
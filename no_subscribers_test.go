@@ -0,0 +1,79 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNoSubscriberDrop(t *testing.T) {
+	bus := New()
+	if bus.Publish("topic", 1) != nil {
+		t.Fail()
+	}
+	bus.Subscribe("topic", func(a int) {
+		t.Fail()
+	})
+	bus.WaitAsync()
+}
+
+func TestNoSubscriberError(t *testing.T) {
+	bus := New(WithNoSubscriberPolicy(NoSubscriberError))
+	if err := bus.Publish("topic"); !errors.Is(err, ErrNoSubscribers) {
+		t.Fatal(err)
+	}
+	if errs := bus.PublishWithResult("topic"); len(errs) != 1 || !errors.Is(errs[0], ErrNoSubscribers) {
+		t.Fatal(errs)
+	}
+	if bus.publish(context.Background(), TopicPanic) != nil {
+		t.Fail() // control topics are exempt
+	}
+	bus.SetNoSubscriberPolicy("other", NoSubscriberDrop)
+	if bus.Publish("other") != nil {
+		t.Fail()
+	}
+	bus.Subscribe("topic", func() {})
+	if bus.Publish("topic") != nil {
+		t.Fail()
+	}
+}
+
+func TestNoSubscriberBuffer(t *testing.T) {
+	bus := New(WithNoSubscriberPolicy(NoSubscriberBuffer), WithParkingTTL(50*time.Millisecond))
+	bus.Publish("expired", 0)
+	time.Sleep(60 * time.Millisecond)
+	bus.Publish("topic", 1)
+	bus.Publish("topic", 2)
+	bus.Publish("expired", 3)
+
+	var received, expired []int
+	bus.Subscribe("topic", func(a int) {
+		received = append(received, a)
+	})
+	bus.Subscribe("expired", func(a int) {
+		expired = append(expired, a)
+	})
+	bus.WaitAsync()
+	if len(received) != 2 || received[0] != 1 || received[1] != 2 {
+		t.Fatal(received)
+	}
+	if len(expired) != 1 || expired[0] != 3 {
+		t.Fatal(expired)
+	}
+}
+
+func TestNoSubscriberDeadLetter(t *testing.T) {
+	bus := New()
+	bus.SetNoSubscriberPolicy("topic", NoSubscriberDeadLetter)
+	letters := make(chan DeadLetter, 1)
+	bus.Subscribe(DeadLetterTopic("topic"), func(letter DeadLetter) {
+		letters <- letter
+	})
+	bus.Publish("topic", 1, "a")
+	bus.WaitAsync()
+	letter := <-letters
+	if letter.Topic != "topic" || len(letter.Args) != 2 || letter.Args[1] != "a" || !errors.Is(letter.Reason, ErrNoSubscribers) {
+		t.Fatal(letter)
+	}
+}
@@ -0,0 +1,59 @@
+package eventbus
+
+import (
+	"context"
+)
+
+// OrderedPublisher - publishes events whose async deliveries run one at a
+// time in publishing order, across all the topics it publishes on
+type OrderedPublisher struct {
+	bus   *Bus
+	queue serialQueue
+}
+
+// publisherKey - context key of the OrderedPublisher of an event
+type publisherKey struct{}
+
+// NewOrderedPublisher returns a publisher whose events reach every async
+// subscriber (transactional or not) in the exact order it published them,
+// even across different topics sharing a subscriber, e.g. a wildcard handler
+// or a state machine consuming several related topics. Synchronous handlers
+// are called in publishing order anyway. Use one per publishing goroutine:
+// the async deliveries of a publisher's events never run concurrently.
+func (bus *Bus) NewOrderedPublisher() *OrderedPublisher {
+	return &OrderedPublisher{bus: bus}
+}
+
+// Publish works like Bus.Publish, keeping the publisher's order
+func (p *OrderedPublisher) Publish(topic string, args ...interface{}) error {
+	return p.PublishCtx(context.Background(), topic, args...)
+}
+
+// PublishCtx works like Bus.PublishCtx, keeping the publisher's order
+func (p *OrderedPublisher) PublishCtx(ctx context.Context, topic string, args ...interface{}) error {
+	return p.bus.PublishCtx(context.WithValue(ctx, publisherKey{}, p), topic, args...)
+}
+
+// scheduleAsync schedules an async delivery to a handler, in the order of the
+// OrderedPublisher of the event if it has one
+func (bus *Bus) scheduleAsync(ctx context.Context, handler *eventHandler, transactional bool, deliver func()) {
+	p, ok := ctx.Value(publisherKey{}).(*OrderedPublisher)
+	ordered := ok && p.bus == bus
+	switch {
+	case ordered && transactional:
+		// queued behind the handler's other deliveries once its turn comes,
+		// holding back the publisher's later deliveries until it ran
+		p.queue.pushAsync(bus.scheduler, func(done func()) {
+			handler.serial.push(bus.scheduler, func() {
+				defer done()
+				deliver()
+			})
+		})
+	case ordered:
+		p.queue.push(bus.scheduler, deliver)
+	case transactional:
+		handler.serial.push(bus.scheduler, deliver)
+	default:
+		bus.schedulePriority(EventPriority(ctx), deliver)
+	}
+}
@@ -0,0 +1,75 @@
+package eventbus
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOrderedPublisher(t *testing.T) {
+	bus := New(WithSeparator("/"))
+	lock := sync.Mutex{}
+	var received []string
+	record := func(topic string) func(n int) {
+		return func(n int) {
+			time.Sleep(time.Duration(10-n) * time.Millisecond) // earlier events take longer
+			lock.Lock()
+			defer lock.Unlock()
+			received = append(received, fmt.Sprint(topic, n))
+		}
+	}
+	bus.SubscribeAsync("machine/+", record("machine/+"), false)
+	bus.SubscribeAsync("machine/start", record("start"), false)
+	bus.SubscribeAsync("machine/stop", record("stop"), true)
+
+	publisher := bus.NewOrderedPublisher()
+	for n := 0; n < 6; n++ {
+		topic := "machine/start"
+		if n%2 == 1 {
+			topic = "machine/stop"
+		}
+		if err := publisher.Publish(topic, n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bus.WaitAsync()
+
+	var wildcard, direct []string
+	for _, event := range received {
+		if event[0] == 'm' {
+			wildcard = append(wildcard, event)
+		} else {
+			direct = append(direct, event)
+		}
+	}
+	if fmt.Sprint(wildcard) != "[machine/+0 machine/+1 machine/+2 machine/+3 machine/+4 machine/+5]" {
+		t.Fatal(wildcard)
+	}
+	if fmt.Sprint(direct) != "[start0 stop1 start2 stop3 start4 stop5]" {
+		t.Fatal(direct)
+	}
+}
+
+func TestOrderedPublisherTransactional(t *testing.T) {
+	bus := New()
+	var running, overlapped atomic.Int32
+	bus.SubscribeAsync("topic", func(n int) {
+		if running.Add(1) > 1 {
+			overlapped.Add(1)
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+	}, true)
+
+	publisher := bus.NewOrderedPublisher()
+	for n := 0; n < 20; n++ {
+		publisher.Publish("topic", n)
+		bus.Publish("topic", n)
+	}
+	bus.WaitAsync()
+	if overlapped.Load() != 0 {
+		t.Fatal("transactional handler ran concurrently with itself", overlapped.Load())
+	}
+}
@@ -0,0 +1,113 @@
+package eventbus
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// Named names the subscription, so other subscriptions on its topic can be
+// delivered after it, see After.
+// Returns error if the subscription is not active, another subscription on
+// the topic has the same name or naming it creates a dependency cycle.
+func (sub *Subscription) Named(name string) error {
+	bus := sub.bus
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	topic, idx := bus.locate(sub.handler)
+	if idx < 0 {
+		return fmt.Errorf("subscription to topic %s is not active", sub.topic)
+	}
+	for _, handler := range bus.handlersOf(topic) {
+		if handler != sub.handler && handler.name == name {
+			return fmt.Errorf("topic %s already has a subscription named %s", sub.topic, name)
+		}
+	}
+	previous := sub.handler.name
+	sub.handler.name = name
+	if err := bus.orderHandlers(topic); err != nil {
+		sub.handler.name = previous
+		return err
+	}
+	return nil
+}
+
+// After delivers events to the subscription after the subscriptions of its
+// topic with the given names. Names nobody subscribed with yet are allowed;
+// they take effect when such a subscription is named. Dependencies take
+// precedence over priorities (see SubscribeWithPriority).
+// Returns error if the subscription is not active or the dependencies have a cycle.
+func (sub *Subscription) After(names ...string) error {
+	bus := sub.bus
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	topic, idx := bus.locate(sub.handler)
+	if idx < 0 {
+		return fmt.Errorf("subscription to topic %s is not active", sub.topic)
+	}
+	previous := sub.handler.after
+	sub.handler.after = append(append([]string(nil), previous...), names...)
+	if err := bus.orderHandlers(topic); err != nil {
+		sub.handler.after = previous
+		return err
+	}
+	return nil
+}
+
+// ordered returns true if the handlers of a topic have priorities or
+// dependencies; the topic must be locked (see lockTopic)
+func (bus *Bus) ordered(topic string) bool {
+	for _, handler := range bus.handlersOf(topic) {
+		if handler.priority != 0 || handler.name != "" || len(handler.after) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// orderHandlers sorts the handlers of a topic so every handler comes after the
+// handlers it depends on, by decreasing priority otherwise, keeping the
+// subscription order among equals; the topic must be locked (see lockTopic)
+func (bus *Bus) orderHandlers(topic string) error {
+	handlers := slices.Clone(bus.handlersOf(topic))
+	slices.SortStableFunc(handlers, func(a, b *eventHandler) int {
+		return cmp.Compare(b.priority, a.priority)
+	})
+	named := make(map[string]int)
+	for i, handler := range handlers {
+		if handler.name != "" {
+			named[handler.name] = i
+		}
+	}
+	dependents := make([][]int, len(handlers))
+	pending := make([]int, len(handlers)) // number of dependencies not delivered yet
+	for i, handler := range handlers {
+		for _, name := range handler.after {
+			if j, ok := named[name]; ok {
+				dependents[j] = append(dependents[j], i)
+				pending[i]++
+			}
+		}
+	}
+	ordered := make([]*eventHandler, 0, len(handlers))
+	done := make([]bool, len(handlers))
+	for len(ordered) < len(handlers) {
+		next := -1
+		for i := range handlers {
+			if !done[i] && pending[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return fmt.Errorf("subscriptions to topic %s have a dependency cycle", topic)
+		}
+		done[next] = true
+		ordered = append(ordered, handlers[next])
+		for _, i := range dependents[next] {
+			pending[i]--
+		}
+	}
+	bus.setHandlers(topic, ordered)
+	return nil
+}
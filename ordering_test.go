@@ -0,0 +1,48 @@
+package eventbus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubscriptionAfter(t *testing.T) {
+	bus := New()
+	order := ""
+	notify, _ := bus.SubscribeHandle("topic", func() { order += "n" })
+	audit, _ := bus.SubscribeHandle("topic", func() { order += "a" })
+	bus.SubscribeHandle("topic", func() { order += "x" })
+	store, _ := bus.SubscribeHandle("topic", func() { order += "s" })
+
+	if notify.After("audit-logger", "store") != nil || audit.After("store") != nil {
+		t.Fail()
+	}
+	if audit.Named("audit-logger") != nil || store.Named("store") != nil {
+		t.Fail()
+	}
+	bus.Publish("topic")
+	if order != "xsan" {
+		t.Fatal(order)
+	}
+
+	if err := store.After("audit-logger"); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatal(err)
+	}
+	if notify.Named("store") == nil {
+		t.Fail()
+	}
+	order = ""
+	bus.Publish("topic")
+	if order != "xsan" {
+		t.Fatal(order)
+	}
+
+	store.Unsubscribe()
+	if store.Named("store") == nil {
+		t.Fail()
+	}
+	order = ""
+	bus.Publish("topic")
+	if order != "xan" {
+		t.Fatal(order)
+	}
+}
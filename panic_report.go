@@ -0,0 +1,54 @@
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"time"
+)
+
+// TopicPanic - control topic receiving a PanicReport for every handler panic
+// recovered by the bus
+const TopicPanic = "bus:panic"
+
+// PanicReport - context of a recovered handler panic
+type PanicReport struct {
+	Topic     string
+	Handler   string      // name of the function that panicked
+	Args      string      // bounded snapshot of the event arguments
+	Recovered interface{} // value the handler panicked with
+	Stack     string      // stack of the panicking goroutine
+	Time      time.Time
+}
+
+func (report *PanicReport) Error() string {
+	return fmt.Sprintf("panic: %v", report.Recovered)
+}
+
+// callRecovered calls fn (reflect.Value.Call or CallSlice) and returns a
+// report of the panic it raised, if any. Only Recovered, Stack and Time are
+// set, see describe for the rest.
+func callRecovered(call func([]reflect.Value) []reflect.Value, args []reflect.Value) (results []reflect.Value, report *PanicReport) {
+	defer func() {
+		if r := recover(); r != nil {
+			report = &PanicReport{Recovered: r, Stack: string(debug.Stack()), Time: time.Now()}
+		}
+	}()
+	return call(args), nil
+}
+
+// reportPanic publishes a described report on TopicPanic. Panics of TopicPanic
+// handlers are not reported, so a failing hook can't feed itself.
+func (bus *Bus) reportPanic(report *PanicReport) {
+	if report.Topic != TopicPanic {
+		bus.publishControl(TopicPanic, report)
+	}
+}
+
+// describe fills in the event and handler of a report
+func (report *PanicReport) describe(topic string, fn reflect.Value, args []interface{}) *PanicReport {
+	report.Topic = topic
+	report.Handler = handlerName(fn.Pointer())
+	report.Args = snapshot(args, DefaultSnapshotSize)
+	return report
+}
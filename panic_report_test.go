@@ -0,0 +1,92 @@
+package eventbus
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func panickingShadow(a int, s string) {
+	panic("shadow failure")
+}
+
+func TestPanicReportShadow(t *testing.T) {
+	bus := New()
+	reports := make(chan *PanicReport, 1)
+	bus.Subscribe(TopicPanic, func(report *PanicReport) {
+		reports <- report
+	})
+	bus.SubscribeShadow("topic", panickingShadow)
+	bus.Publish("topic", 42, strings.Repeat("x", 2*DefaultSnapshotSize))
+
+	select {
+	case report := <-reports:
+		if report.Topic != "topic" || report.Recovered != "shadow failure" {
+			t.Log(report)
+			t.Fail()
+		}
+		if !strings.HasSuffix(report.Handler, "panickingShadow") {
+			t.Log(report.Handler)
+			t.Fail()
+		}
+		if !strings.HasPrefix(report.Args, "[42 xxx") || len(report.Args) > DefaultSnapshotSize+3 {
+			t.Log(report.Args)
+			t.Fail()
+		}
+		if !strings.Contains(report.Stack, "panickingShadow") || report.Time.IsZero() {
+			t.Log(report.Stack)
+			t.Fail()
+		}
+		if report.Error() != "panic: shadow failure" {
+			t.Fail()
+		}
+	case <-time.After(time.Second):
+		t.Fatal("panic not reported")
+	}
+}
+
+func TestPanicReportGroup(t *testing.T) {
+	bus := New()
+	var reports []*PanicReport
+	bus.Subscribe(TopicPanic, func(report *PanicReport) {
+		reports = append(reports, report)
+	})
+	failures := make(chan GroupFailure, 1)
+	bus.Subscribe(TopicGroupFailure, func(failure GroupFailure) {
+		failures <- failure
+	})
+	bus.SubscribeGroup([]string{"topic"}, GroupStep{
+		Do: func(a int) { panic("boom") },
+	})
+	bus.Publish("topic", 1)
+	bus.WaitAsync()
+
+	failure := <-failures
+	report, ok := failure.Err.(*PanicReport)
+	if !ok || report.Topic != "topic" || report.Args != "[1]" {
+		t.Fatal(failure)
+	}
+	if len(reports) != 1 || reports[0] != report {
+		t.Fail()
+	}
+}
+
+func TestPanicReportHookPanics(t *testing.T) {
+	bus := New()
+	var calls atomic.Int32
+	bus.SubscribeShadow(TopicPanic, func(report *PanicReport) {
+		calls.Add(1)
+		panic("hook failure")
+	})
+	bus.SubscribeGroup([]string{"topic"}, GroupStep{
+		Do: func() { panic("boom") },
+	})
+	bus.Publish("topic")
+	bus.WaitAsync()
+	time.Sleep(10 * time.Millisecond) // the shadow hook runs outside WaitAsync
+
+	if calls.Load() != 1 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,165 @@
+package eventbus
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultParkingTTL - default time a parked event waits for a subscriber
+	DefaultParkingTTL = 10 * time.Second
+	// DefaultParkingCapacity - default number of events parked per topic
+	DefaultParkingCapacity = 1000
+)
+
+type parkedEvent struct {
+	args    []interface{}
+	expires time.Time
+}
+
+type parkingLimits struct {
+	capacity int
+	ttl      time.Duration
+}
+
+// parking holds the events of topics without subscribers under NoSubscriberBuffer
+type parking struct {
+	ttl     time.Duration // default TTL, see WithParkingTTL
+	limits  map[string]parkingLimits
+	parked  map[string][]parkedEvent
+	flushes map[string][]parkedEvent // events left to deliver by a flush in progress
+	sync.Mutex
+}
+
+// WithParkingTTL sets how long a parked event waits for a subscriber,
+// DefaultParkingTTL by default
+func WithParkingTTL(ttl time.Duration) Option {
+	return func(bus *Bus) {
+		bus.parking.ttl = ttl
+	}
+}
+
+// SetParking parks the events published on a topic while nobody is subscribed
+// to it: at most capacity events (DefaultParkingCapacity if zero or negative,
+// the oldest are dropped first), each for ttl at most (the parking TTL if zero
+// or negative). Once a matching subscription is registered, including a
+// wildcard or regex one, the parked events are delivered in publishing order,
+// before the events published in the meantime.
+// Sets the no subscriber policy of the topic to NoSubscriberBuffer.
+func (bus *Bus) SetParking(topic string, capacity int, ttl time.Duration) {
+	bus.SetNoSubscriberPolicy(topic, NoSubscriberBuffer)
+	p := &bus.parking
+	p.Lock()
+	defer p.Unlock()
+	if p.limits == nil {
+		p.limits = make(map[string]parkingLimits)
+	}
+	p.limits[topic] = parkingLimits{capacity, ttl}
+}
+
+// Parked returns the number of events of a topic waiting for a subscriber or
+// for their delivery
+func (bus *Bus) Parked(topic string) int {
+	p := &bus.parking
+	p.Lock()
+	defer p.Unlock()
+	return len(unexpired(p.parked[topic], time.Now())) + len(p.flushes[topic])
+}
+
+func (p *parking) park(topic string, args []interface{}) {
+	p.Lock()
+	defer p.Unlock()
+	limits := p.limits[topic]
+	if limits.capacity <= 0 {
+		limits.capacity = DefaultParkingCapacity
+	}
+	if limits.ttl <= 0 {
+		limits.ttl = p.ttl
+	}
+	if limits.ttl <= 0 {
+		limits.ttl = DefaultParkingTTL
+	}
+	if p.parked == nil {
+		p.parked = make(map[string][]parkedEvent)
+	}
+	now := time.Now()
+	events := append(unexpired(p.parked[topic], now), parkedEvent{args, now.Add(limits.ttl)})
+	if len(events) > limits.capacity {
+		events = events[len(events)-limits.capacity:]
+	}
+	p.parked[topic] = events
+}
+
+// behindFlush queues an event published on a topic whose parked events are
+// being delivered, so it doesn't overtake them. Returns false if there is no
+// such flush.
+func (p *parking) behindFlush(topic string, args []interface{}) bool {
+	p.Lock()
+	defer p.Unlock()
+	if _, ok := p.flushes[topic]; !ok {
+		return false
+	}
+	p.flushes[topic] = append(p.flushes[topic], parkedEvent{args: args})
+	return true
+}
+
+// unpark starts delivering the parked events of topics, or of every topic
+// without topics, that have a subscriber now; the topics must be locked (see
+// lockTopic), the bus lock held exclusively without topics
+func (bus *Bus) unpark(topics ...string) {
+	p := &bus.parking
+	p.Lock()
+	defer p.Unlock()
+	now := time.Now()
+	for topic, events := range p.parked {
+		if (len(topics) > 0 && !slices.Contains(topics, topic)) || !bus.hasCallback(topic) {
+			continue
+		}
+		delete(p.parked, topic)
+		events = unexpired(events, now)
+		if len(events) == 0 {
+			continue
+		}
+		if p.flushes == nil {
+			p.flushes = make(map[string][]parkedEvent)
+		}
+		_, flushing := p.flushes[topic]
+		p.flushes[topic] = append(p.flushes[topic], events...)
+		if !flushing {
+			bus.wg.Add(1)
+			bus.scheduler.Schedule(func() { bus.flush(topic) })
+		}
+	}
+}
+
+// flush delivers the queued events of a topic until there are none left
+func (bus *Bus) flush(topic string) {
+	defer bus.wg.Done()
+	p := &bus.parking
+	for {
+		p.Lock()
+		events := p.flushes[topic]
+		if len(events) == 0 {
+			delete(p.flushes, topic)
+			p.Unlock()
+			return
+		}
+		p.flushes[topic] = []parkedEvent{}
+		p.Unlock()
+		for _, event := range events {
+			bus.lock.RLock()
+			bus.publishLocked(context.Background(), topic, false, event.args...)
+			bus.lock.RUnlock()
+		}
+	}
+}
+
+// unexpired drops the expired events at the head of events
+func unexpired(events []parkedEvent, now time.Time) []parkedEvent {
+	for len(events) > 0 && !events[0].expires.After(now) {
+		events = events[1:]
+	}
+	return events
+}
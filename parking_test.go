@@ -0,0 +1,54 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetParking(t *testing.T) {
+	scheduler := &queueScheduler{}
+	bus := New(WithScheduler(scheduler))
+	bus.SetParking("orders:created", 2, time.Minute)
+	bus.Publish("orders:created", 1)
+	bus.Publish("orders:created", 2)
+	bus.Publish("orders:created", 3)
+	if bus.Parked("orders:created") != 2 {
+		t.Fatal(bus.Parked("orders:created"))
+	}
+
+	var received []int
+	bus.SubscribeRegex("^orders:", func(a int) {
+		received = append(received, a)
+	})
+	bus.Publish("orders:created", 4) // must not overtake the parked events
+	if len(received) != 0 || bus.Parked("orders:created") != 3 {
+		t.Fatal(received)
+	}
+	scheduler.run()
+	bus.WaitAsync()
+	if len(received) != 3 || received[0] != 2 || received[1] != 3 || received[2] != 4 {
+		t.Fatal(received)
+	}
+	if bus.Parked("orders:created") != 0 {
+		t.Fail()
+	}
+
+	bus.Publish("orders:created", 5) // delivered directly once flushed
+	if len(received) != 4 {
+		t.Fail()
+	}
+}
+
+func TestParkingTTL(t *testing.T) {
+	bus := New()
+	bus.SetParking("topic", 0, 20*time.Millisecond)
+	bus.Publish("topic", 1)
+	time.Sleep(30 * time.Millisecond)
+	if bus.Parked("topic") != 0 {
+		t.Fail()
+	}
+	bus.Subscribe("topic", func(a int) {
+		t.Fail()
+	})
+	bus.WaitAsync()
+}
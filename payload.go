@@ -0,0 +1,160 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+const (
+	// TopicPayloadMutation - control topic receiving PayloadMutation reports in debug builds
+	TopicPayloadMutation = "bus:mutation"
+	// TopicConcurrentAccess - control topic receiving ConcurrentAccess reports in debug builds
+	TopicConcurrentAccess = "bus:race"
+)
+
+// PayloadMutation - reported by debug builds (-tags eventbus_debug) when a
+// handler modified an argument it shares with the publisher and the other
+// subscribers. When handlers held the argument at the same time, the change
+// is only seen once the last of them returns, and reported for it.
+type PayloadMutation struct {
+	Topic   string
+	Handler string
+	Arg     int // index of the modified argument
+}
+
+// ConcurrentAccess - reported by debug builds (-tags eventbus_debug) along
+// with a PayloadMutation when other handlers were running with the same map,
+// slice or pointer while it was modified
+type ConcurrentAccess struct {
+	Topic   string
+	Handler string
+	Arg     int
+	Peers   []HandlerRef // handlers holding the payload at the same time
+}
+
+// HandlerRef - identifies a handler subscribed to a topic
+type HandlerRef struct {
+	Topic   string
+	Handler string
+}
+
+// payloadHold - handler calls holding a mutable payload. The payload is
+// fingerprinted before the first call starts and after the last one ends,
+// never while a handler may be writing to it.
+type payloadHold struct {
+	calls       int
+	refs        []HandlerRef // handlers of the calls since the first one started
+	fingerprint uint64
+}
+
+// payloadTracker records which handler calls currently hold which payloads
+type payloadTracker struct {
+	active map[uintptr]*payloadHold
+	sync.Mutex
+}
+
+// WithCopyPayloads makes every subscriber receive its own deep copy of the
+// event arguments, so concurrent async handlers can't race on shared maps,
+// slices or pointers. Ref arguments and contexts are shared on purpose and
+// never copied.
+func WithCopyPayloads() Option {
+	return func(bus *Bus) {
+		bus.copyPayloads = true
+	}
+}
+
+func copyPayload(arg interface{}) interface{} {
+	if _, ok := arg.(*Ref); ok {
+		return arg
+	}
+	return deepCopy(arg)
+}
+
+// mutable returns true if a handler can modify arg through a shared
+// reference. Refs and contexts are shared on purpose.
+func mutable(arg interface{}) bool {
+	switch arg.(type) {
+	case *Ref, context.Context:
+		return false
+	}
+	switch reflect.ValueOf(arg).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Ptr:
+		return true
+	}
+	return false
+}
+
+// instrument watches the arguments of a handler call in debug builds and
+// returns the function reporting mutations once the call is over
+func (bus *Bus) instrument(topic string, handler *eventHandler, args []interface{}) (done func()) {
+	ref := HandlerRef{topic, handlerName(handler.callBack.Pointer())}
+	bus.payloads.enter(ref, args)
+	return func() {
+		for i, peers := range bus.payloads.exit(ref, args) {
+			if peers == nil {
+				continue
+			}
+			bus.publishControl(TopicPayloadMutation, PayloadMutation{topic, ref.Handler, i})
+			if len(peers) > 0 {
+				bus.publishControl(TopicConcurrentAccess, ConcurrentAccess{topic, ref.Handler, i, peers})
+			}
+		}
+	}
+}
+
+// enter records a handler call holding the mutable arguments of an event
+func (tracker *payloadTracker) enter(ref HandlerRef, args []interface{}) {
+	tracker.Lock()
+	defer tracker.Unlock()
+	if tracker.active == nil {
+		tracker.active = make(map[uintptr]*payloadHold)
+	}
+	entered := make(map[uintptr]bool)
+	for _, arg := range args {
+		if !mutable(arg) || entered[reflect.ValueOf(arg).Pointer()] {
+			continue // an argument passed twice is held once
+		}
+		id := reflect.ValueOf(arg).Pointer()
+		entered[id] = true
+		hold, ok := tracker.active[id]
+		if !ok {
+			hold = &payloadHold{fingerprint: fingerprint(arg)}
+			tracker.active[id] = hold
+		}
+		hold.calls++
+		hold.refs = append(hold.refs, ref)
+	}
+}
+
+// exit removes a handler call and returns, per argument it modified along
+// with the calls holding it at the same time, the handlers of those calls
+// (empty, not nil, if there were none); nil for the other arguments
+func (tracker *payloadTracker) exit(ref HandlerRef, args []interface{}) [][]HandlerRef {
+	tracker.Lock()
+	defer tracker.Unlock()
+	peers := make([][]HandlerRef, len(args))
+	exited := make(map[uintptr]bool)
+	for i, arg := range args {
+		if !mutable(arg) || exited[reflect.ValueOf(arg).Pointer()] {
+			continue
+		}
+		id := reflect.ValueOf(arg).Pointer()
+		exited[id] = true
+		hold := tracker.active[id]
+		if hold.calls--; hold.calls > 0 {
+			continue // the payload is still held
+		}
+		delete(tracker.active, id)
+		if fingerprint(arg) == hold.fingerprint {
+			continue
+		}
+		peers[i] = []HandlerRef{}
+		if j := slices.Index(hold.refs, ref); j >= 0 {
+			hold.refs = slices.Delete(hold.refs, j, j+1)
+		}
+		peers[i] = append(peers[i], hold.refs...)
+	}
+	return peers
+}
@@ -0,0 +1,91 @@
+//go:build eventbus_debug
+
+package eventbus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPayloadMutationReported(t *testing.T) {
+	bus := New()
+	reports := make(chan PayloadMutation, 1)
+	bus.Subscribe(TopicPayloadMutation, func(report PayloadMutation) {
+		reports <- report
+	})
+	bus.Subscribe("topic", func(id int, values []int) {
+		values[0] = id
+	})
+	bus.Publish("topic", 7, []int{1})
+	bus.WaitAsync()
+
+	select {
+	case report := <-reports:
+		if report.Topic != "topic" || report.Arg != 1 || !strings.Contains(report.Handler, "TestPayloadMutationReported") {
+			t.Log(report)
+			t.Fail()
+		}
+	default:
+		t.Fatal("mutation not reported")
+	}
+}
+
+func TestConcurrentAccessReported(t *testing.T) {
+	bus := New()
+	reports := make(chan ConcurrentAccess, 2)
+	bus.Subscribe(TopicConcurrentAccess, func(report ConcurrentAccess) {
+		reports <- report
+	})
+
+	reading := make(chan struct{})
+	written := make(chan struct{})
+	bus.SubscribeAsync("topic", func(values map[string]int) {
+		close(reading)
+		<-written
+	}, false)
+	bus.SubscribeAsync("topic", func(values map[string]int) {
+		<-reading
+		values["key"] = 2
+		close(written)
+	}, false)
+	bus.Publish("topic", map[string]int{"key": 1})
+	bus.WaitAsync()
+
+	select {
+	case report := <-reports:
+		if report.Topic != "topic" || len(report.Peers) != 1 || report.Peers[0].Topic != "topic" {
+			t.Log(report)
+			t.Fail()
+		}
+	default:
+		t.Fatal("concurrent access not reported")
+	}
+}
+
+func TestNestedPayloadMutationReported(t *testing.T) {
+	type node struct {
+		value int
+		next  *node
+	}
+	bus := New()
+	reports := make(chan PayloadMutation, 1)
+	bus.Subscribe(TopicPayloadMutation, func(report PayloadMutation) {
+		reports <- report
+	})
+	bus.Subscribe("topic", func(list *node) {
+		list.next.value = 3
+	})
+	list := &node{1, &node{2, nil}}
+	list.next.next = list // cycle
+	bus.Publish("topic", list)
+	bus.WaitAsync()
+
+	select {
+	case report := <-reports:
+		if report.Arg != 0 {
+			t.Fatal(report)
+		}
+	default:
+		t.Fatal("mutation behind a pointer not reported")
+	}
+}
@@ -0,0 +1,51 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithCopyPayloads(t *testing.T) {
+	bus := New(WithCopyPayloads())
+	payload := map[string][]int{"values": {1, 2}}
+	ref := NewRef(payload)
+
+	bus.SubscribeAsync("topic", func(m map[string][]int, r *Ref) {
+		m["values"][0] = 100
+		m["added"] = nil
+		if r != ref {
+			t.Fail()
+		}
+	}, false)
+	seen := 0
+	bus.Subscribe("topic", func(m map[string][]int, r *Ref) {
+		seen = m["values"][0]
+	})
+	bus.Publish("topic", payload, ref)
+	bus.WaitAsync()
+
+	if seen != 1 || payload["values"][0] != 1 || len(payload) != 1 {
+		t.Fail()
+	}
+}
+
+func TestWithCopyPayloadsSharesContexts(t *testing.T) {
+	type request struct {
+		ctx  context.Context
+		Ctx  context.Context
+		Name string
+	}
+	bus := New(WithCopyPayloads())
+	ctx, cancel := context.WithCancel(context.Background())
+	var got context.Context
+	var req request
+	bus.Subscribe("topic", func(c context.Context, r request) {
+		got, req = c, r
+	})
+	bus.Publish("topic", ctx, request{ctx, ctx, "a"})
+	cancel()
+
+	if got.Err() == nil || req.Ctx.Err() == nil || req.ctx.Err() == nil {
+		t.Fatal("copied context not cancelled along with the published one")
+	}
+}
@@ -0,0 +1,23 @@
+package eventbus
+
+import "runtime/pprof"
+
+// Profiler label keys set on handler executions by WithProfilerLabels
+const (
+	LabelTopic   = "eventbus.topic"
+	LabelHandler = "eventbus.handler"
+)
+
+// WithProfilerLabels runs every sync and async handler with pprof labels
+// naming the topic (LabelTopic) and handler function (LabelHandler), so CPU
+// and goroutine profiles attribute the time spent to subscriptions. Labels of
+// the publisher's context are kept.
+func WithProfilerLabels() Option {
+	return func(bus *Bus) {
+		bus.profilerLabels = true
+	}
+}
+
+func handlerLabels(topic string, handler *eventHandler) pprof.LabelSet {
+	return pprof.Labels(LabelTopic, topic, LabelHandler, handlerName(handler.callBack.Pointer()))
+}
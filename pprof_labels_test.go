@@ -0,0 +1,32 @@
+package eventbus
+
+import (
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+func labelsOf(ctx context.Context) map[string]string {
+	labels := make(map[string]string)
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		labels[key] = value
+		return true
+	})
+	return labels
+}
+
+func TestWithProfilerLabels(t *testing.T) {
+	bus := New(WithProfilerLabels())
+	var sync, async map[string]string
+	bus.Subscribe("topic", func(ctx context.Context) { sync = labelsOf(ctx) })
+	bus.SubscribeAsync("topic", func(ctx context.Context) { async = labelsOf(ctx) }, false)
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("request", "42"))
+	bus.PublishCtx(ctx, "topic")
+	bus.WaitAsync()
+	for _, labels := range []map[string]string{sync, async} {
+		if labels[LabelTopic] != "topic" || !strings.Contains(labels[LabelHandler], "TestWithProfilerLabels") || labels["request"] != "42" {
+			t.Error(labels)
+		}
+	}
+}
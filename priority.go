@@ -0,0 +1,79 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// ErrStopPropagation - returned by a synchronous handler as its last result to
+// keep the event from the handlers after it
+var ErrStopPropagation = errors.New("stop propagation")
+
+// SubscribeWithPriority runs SubscribeWithPriority on package-level bus singleton
+func SubscribeWithPriority(topic string, fn interface{}, priority int) error {
+	return b.Load().SubscribeWithPriority(topic, fn, priority)
+}
+
+// SubscribeWithPriority subscribes to a topic with a priority: handlers are
+// delivered by decreasing priority, in subscription order among equal
+// priorities (Subscribe uses priority 0). A synchronous handler returning
+// ErrStopPropagation (possibly wrapped) stops the delivery of the event to the
+// handlers after it, including those of matching wildcard and regex
+// subscriptions; it is not reported as a handler error.
+// Returns error if `fn` is not a function.
+func (bus *Bus) SubscribeWithPriority(topic string, fn interface{}, priority int) error {
+	return bus.doSubscribe(topic, fn, &eventHandler{
+		callBack: reflect.ValueOf(fn), priority: priority,
+	})
+}
+
+// eventPriorityKey - context key of the priority of an event
+type eventPriorityKey struct{}
+
+// WithEventPriority returns a copy of ctx publishing events with a priority,
+// to publish an event with PublishCtx. With a PriorityScheduler, e.g.
+// WithAsyncWorkers, queued async deliveries to non-transactional handlers run
+// by decreasing event priority (events without one have priority 0), so
+// urgent events overtake a backlog. Transactional handlers and
+// OrderedPublisher keep publishing order.
+func WithEventPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, eventPriorityKey{}, priority)
+}
+
+// EventPriority returns the priority of the events published with ctx, see
+// WithEventPriority
+func EventPriority(ctx context.Context) int {
+	priority, _ := ctx.Value(eventPriorityKey{}).(int)
+	return priority
+}
+
+// WithPriorityInheritance makes the events published by handlers with the
+// context they receive (see PublishCtx) inherit the priority of the event they
+// handle, so follow-up work keeps its urgency without every handler
+// forwarding it. The deadline of the event is always inherited, along with
+// the cancellation of its context. Without the option, handler contexts carry
+// no priority.
+func WithPriorityInheritance() Option {
+	return func(bus *Bus) {
+		bus.inherit = true
+	}
+}
+
+// inherited returns the context of the handlers of an event published with
+// ctx, without its priority unless inherited
+func (bus *Bus) inherited(ctx context.Context) context.Context {
+	if bus.inherit || EventPriority(ctx) == 0 {
+		return ctx
+	}
+	return WithEventPriority(ctx, 0)
+}
+
+// schedulePriority schedules an async delivery of an event with a priority
+func (bus *Bus) schedulePriority(priority int, deliver func()) {
+	if scheduler, ok := bus.scheduler.(PriorityScheduler); ok && priority != 0 {
+		scheduler.SchedulePriority(priority, deliver)
+		return
+	}
+	bus.scheduler.Schedule(deliver)
+}
@@ -0,0 +1,104 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSubscribeWithPriority(t *testing.T) {
+	bus := New()
+	order := ""
+	bus.Subscribe("topic", func() { order += "d" })
+	bus.SubscribeWithPriority("topic", func() { order += "b" }, 10)
+	bus.SubscribeWithPriority("topic", func() { order += "e" }, -1)
+	bus.SubscribeWithPriority("topic", func() { order += "a" }, 20)
+	bus.SubscribeWithPriority("topic", func() { order += "c" }, 10)
+	bus.Publish("topic")
+	if order != "abcde" {
+		t.Fatal(order)
+	}
+}
+
+func TestStopPropagation(t *testing.T) {
+	bus := New(WithSeparator("/"))
+	calls := 0
+	bus.Subscribe("a/b", func() { calls++ })
+	bus.Subscribe("a/#", func() { calls++ })
+	stop := true
+	bus.SubscribeWithPriority("a/b", func() error {
+		if stop {
+			return fmt.Errorf("validation failed: %w", ErrStopPropagation)
+		}
+		return nil
+	}, 1)
+
+	if errs := bus.PublishWithResult("a/b"); len(errs) != 0 || calls != 0 {
+		t.Fatal(errs, calls)
+	}
+	stop = false
+	bus.Publish("a/b")
+	if calls != 2 {
+		t.Fail()
+	}
+}
+
+func TestEventPriorityScheduling(t *testing.T) {
+	bus := New(WithAsyncWorkers(1))
+	defer bus.Close(context.Background())
+	started, release := make(chan struct{}), make(chan struct{})
+	bus.SubscribeAsync("block", func() {
+		close(started)
+		<-release
+	}, false)
+	var order []int
+	bus.SubscribeAsync("work", func(n int) {
+		order = append(order, n)
+	}, false)
+
+	bus.Publish("block")
+	<-started
+	bus.Publish("work", 1)
+	bus.PublishCtx(WithEventPriority(context.Background(), 5), "work", 2)
+	bus.PublishCtx(WithEventPriority(context.Background(), 10), "work", 3)
+	bus.PublishCtx(WithEventPriority(context.Background(), 5), "work", 4)
+	close(release)
+	bus.WaitAsync()
+
+	if !slices.Equal(order, []int{3, 2, 4, 1}) {
+		t.Fatal(order)
+	}
+}
+
+func TestWithPriorityInheritance(t *testing.T) {
+	for _, inherit := range []bool{false, true} {
+		var bus *Bus
+		if inherit {
+			bus = New(WithPriorityInheritance())
+		} else {
+			bus = New()
+		}
+		priority := -1
+		var deadline time.Time
+		bus.Subscribe("order", func(ctx context.Context) {
+			bus.PublishCtx(ctx, "invoice")
+		})
+		bus.Subscribe("invoice", func(ctx context.Context) {
+			priority = EventPriority(ctx)
+			deadline, _ = ctx.Deadline()
+		})
+		expected := time.Now().Add(time.Hour)
+		ctx, cancel := context.WithDeadline(WithEventPriority(context.Background(), 7), expected)
+		bus.PublishCtx(ctx, "order")
+		cancel()
+
+		if inherit && priority != 7 || !inherit && priority != 0 {
+			t.Fatal(inherit, priority)
+		}
+		if !deadline.Equal(expected) {
+			t.Fatal("deadline not inherited", deadline)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+//go:build eventbus_prometheus
+
+package prommetrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// descs - descriptions of the metrics of a Collector
+type descs struct {
+	published, handled, errors, duration, depth, dropped *prometheus.Desc
+}
+
+// descs returns the descriptions of the metrics, named and labeled as served
+// by ServeHTTP
+func (c *Collector) descs() descs {
+	labels := append([]string{"topic"}, c.opts.Labels...)
+	desc := func(metric, help string, labels []string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(c.opts.Namespace, "", metric), help, labels, nil)
+	}
+	return descs{
+		published: desc("published_total", "Events published.", labels),
+		handled:   desc("handled_total", "Handler invocations.", labels),
+		errors:    desc("handler_errors_total", "Handler invocations returning an error or panicking.", labels),
+		duration:  desc("handler_duration_seconds", "Handler latency in seconds.", labels),
+		depth:     desc("async_queue_depth", "Async deliveries queued or running.", []string{"topic"}),
+		dropped:   desc("async_dropped_total", "Async deliveries dropped by a full queue.", []string{"topic"}),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	d := c.descs()
+	for _, desc := range []*prometheus.Desc{d.published, d.handled, d.errors, d.duration, d.depth, d.dropped} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	d := c.descs()
+	send := func(desc *prometheus.Desc, metric prometheus.Metric, err error) {
+		if err != nil {
+			metric = prometheus.NewInvalidMetric(desc, err) // e.g. a topic that isn't valid UTF-8
+		}
+		ch <- metric
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, m := range c.series {
+		values := append([]string{m.topic}, m.values...)
+		metric, err := prometheus.NewConstMetric(d.published, prometheus.CounterValue, float64(m.published), values...)
+		send(d.published, metric, err)
+		metric, err = prometheus.NewConstMetric(d.handled, prometheus.CounterValue, float64(m.handled), values...)
+		send(d.handled, metric, err)
+		metric, err = prometheus.NewConstMetric(d.errors, prometheus.CounterValue, float64(m.errors), values...)
+		send(d.errors, metric, err)
+		buckets, cumulative := make(map[float64]uint64, len(c.opts.Buckets)), uint64(0)
+		for i, bound := range c.opts.Buckets {
+			cumulative += m.buckets[i]
+			buckets[bound] = cumulative
+		}
+		metric, err = prometheus.NewConstHistogram(d.duration, m.handled, m.sum, buckets, values...)
+		send(d.duration, metric, err)
+	}
+	for topic, q := range c.queues {
+		metric, err := prometheus.NewConstMetric(d.depth, prometheus.GaugeValue, float64(q.depth), topic)
+		send(d.depth, metric, err)
+		metric, err = prometheus.NewConstMetric(d.dropped, prometheus.CounterValue, float64(q.dropped), topic)
+		send(d.dropped, metric, err)
+	}
+}
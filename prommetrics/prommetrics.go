@@ -0,0 +1,248 @@
+// Package prommetrics exposes the metrics of an event bus to Prometheus.
+//
+// Metrics are rendered in the Prometheus text exposition format by the
+// package itself, so it has no dependency outside the standard library:
+// serve a Collector on the scrape endpoint.
+//
+// Built with the eventbus_prometheus build tag (-tags eventbus_prometheus), a
+// Collector also implements prometheus.Collector of
+// github.com/prometheus/client_golang, to register it along with the other
+// metrics of the process instead:
+//
+//	prometheus.MustRegister(collector)
+package prommetrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+// DefaultNamespace - prefix of the metric names
+const DefaultNamespace = "eventbus"
+
+// DefaultBuckets - upper bounds, in seconds, of the handler latency histogram
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Options - configuration of a Collector
+type Options struct {
+	// Namespace prefixes the metric names; defaults to DefaultNamespace
+	Namespace string
+	// Buckets of the handler latency histogram in seconds; defaults to DefaultBuckets
+	Buckets []float64
+	// Labels - names of the metric labels (see eventbus.WithMetricLabels)
+	// breaking down the publish and handler metrics along with the topic, e.g.
+	// "tenant". Other labels are ignored to bound the number of series.
+	Labels []string
+}
+
+// Collector - eventbus.Metrics implementation serving the metrics it
+// collected to Prometheus:
+//
+//	<namespace>_published_total{topic,labels}          events published
+//	<namespace>_handled_total{topic,labels}            handler invocations
+//	<namespace>_handler_errors_total{topic,labels}     invocations returning an error or panicking
+//	<namespace>_handler_duration_seconds{topic,labels} handler latency histogram
+//	<namespace>_async_queue_depth{topic}               async deliveries queued or running
+//	<namespace>_async_dropped_total{topic}             async deliveries dropped by a full queue
+//
+// where labels are the labels named in Options.Labels, empty if unset.
+type Collector struct {
+	opts   Options
+	series map[string]*seriesMetrics
+	queues map[string]*queueMetrics
+	lock   sync.Mutex
+}
+
+// seriesMetrics - publish and handler metrics of a topic and label values
+type seriesMetrics struct {
+	topic                      string
+	values                     []string // values of Options.Labels
+	published, handled, errors uint64
+	buckets                    []uint64 // observations per bucket, not cumulative
+	sum                        float64
+}
+
+// queueMetrics - async queue metrics of a topic
+type queueMetrics struct {
+	dropped uint64
+	depth   int
+}
+
+var (
+	_ eventbus.LabeledMetrics = (*Collector)(nil)
+	_ eventbus.QueueMetrics   = (*Collector)(nil)
+)
+
+// New - create a Collector, to pass to eventbus.WithMetrics
+func New(opts *Options) *Collector {
+	c := &Collector{series: make(map[string]*seriesMetrics), queues: make(map[string]*queueMetrics)}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.Namespace == "" {
+		c.opts.Namespace = DefaultNamespace
+	}
+	if c.opts.Buckets == nil {
+		c.opts.Buckets = DefaultBuckets
+	}
+	c.opts.Buckets = slices.Sorted(slices.Values(c.opts.Buckets))
+	return c
+}
+
+// seriesOf returns the metrics of a topic and labels; the lock must be held
+func (c *Collector) seriesOf(topic string, labels map[string]string) *seriesMetrics {
+	values := make([]string, len(c.opts.Labels))
+	for i, name := range c.opts.Labels {
+		values[i] = labels[name]
+	}
+	key := strings.Join(append([]string{topic}, values...), "\xff")
+	m, ok := c.series[key]
+	if !ok {
+		m = &seriesMetrics{topic: topic, values: values, buckets: make([]uint64, len(c.opts.Buckets)+1)}
+		c.series[key] = m
+	}
+	return m
+}
+
+// queue returns the queue metrics of a topic; the lock must be held
+func (c *Collector) queue(topic string) *queueMetrics {
+	m, ok := c.queues[topic]
+	if !ok {
+		m = &queueMetrics{}
+		c.queues[topic] = m
+	}
+	return m
+}
+
+// Published implements eventbus.Metrics
+func (c *Collector) Published(topic string) {
+	c.PublishedLabeled(topic, nil)
+}
+
+// PublishedLabeled implements eventbus.LabeledMetrics
+func (c *Collector) PublishedLabeled(topic string, labels map[string]string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.seriesOf(topic, labels).published++
+}
+
+// Handled implements eventbus.Metrics
+func (c *Collector) Handled(topic string, latency time.Duration, err error) {
+	c.HandledLabeled(topic, nil, latency, err)
+}
+
+// HandledLabeled implements eventbus.LabeledMetrics
+func (c *Collector) HandledLabeled(topic string, labels map[string]string, latency time.Duration, err error) {
+	seconds := latency.Seconds()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	m := c.seriesOf(topic, labels)
+	m.handled++
+	if err != nil {
+		m.errors++
+	}
+	i, _ := slices.BinarySearch(c.opts.Buckets, seconds)
+	m.buckets[i]++
+	m.sum += seconds
+}
+
+// QueueDepth implements eventbus.Metrics
+func (c *Collector) QueueDepth(topic string, depth int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.queue(topic).depth = depth
+}
+
+// Dropped implements eventbus.QueueMetrics
+func (c *Collector) Dropped(topic string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.queue(topic).dropped++
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	keys := make([]string, 0, len(c.series))
+	for key := range c.series {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	topics := make([]string, 0, len(c.queues))
+	for topic := range c.queues {
+		topics = append(topics, topic)
+	}
+	slices.Sort(topics)
+
+	out := strings.Builder{}
+	name := func(metric, kind, help string) string {
+		full := c.opts.Namespace + "_" + metric
+		fmt.Fprintf(&out, "# HELP %s %s\n# TYPE %s %s\n", full, help, full, kind)
+		return full
+	}
+	labels := func(m *seriesMetrics) string {
+		pairs := "topic=" + quote(m.topic)
+		for i, label := range c.opts.Labels {
+			pairs += "," + label + "=" + quote(m.values[i])
+		}
+		return pairs
+	}
+	counter := func(metric, help string, value func(m *seriesMetrics) uint64) {
+		full := name(metric, "counter", help)
+		for _, key := range keys {
+			fmt.Fprintf(&out, "%s{%s} %d\n", full, labels(c.series[key]), value(c.series[key]))
+		}
+	}
+	counter("published_total", "Events published.", func(m *seriesMetrics) uint64 { return m.published })
+	counter("handled_total", "Handler invocations.", func(m *seriesMetrics) uint64 { return m.handled })
+	counter("handler_errors_total", "Handler invocations returning an error or panicking.", func(m *seriesMetrics) uint64 { return m.errors })
+
+	full := name("handler_duration_seconds", "histogram", "Handler latency in seconds.")
+	for _, key := range keys {
+		m := c.series[key]
+		cumulative := uint64(0)
+		for i, bound := range c.opts.Buckets {
+			cumulative += m.buckets[i]
+			fmt.Fprintf(&out, "%s_bucket{%s,le=\"%s\"} %d\n", full, labels(m), formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(&out, "%s_bucket{%s,le=\"+Inf\"} %d\n", full, labels(m), m.handled)
+		fmt.Fprintf(&out, "%s_sum{%s} %s\n", full, labels(m), formatFloat(m.sum))
+		fmt.Fprintf(&out, "%s_count{%s} %d\n", full, labels(m), m.handled)
+	}
+
+	full = name("async_queue_depth", "gauge", "Async deliveries queued or running.")
+	for _, topic := range topics {
+		fmt.Fprintf(&out, "%s{topic=%s} %d\n", full, quote(topic), c.queues[topic].depth)
+	}
+	full = name("async_dropped_total", "counter", "Async deliveries dropped by a full queue.")
+	for _, topic := range topics {
+		fmt.Fprintf(&out, "%s{topic=%s} %d\n", full, quote(topic), c.queues[topic].dropped)
+	}
+	n, err := io.WriteString(w, out.String())
+	return int64(n), err
+}
+
+// quote returns a label value escaped for the text exposition format
+func quote(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+	return `"` + value + `"`
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
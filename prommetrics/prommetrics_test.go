@@ -0,0 +1,98 @@
+package prommetrics
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+func TestCollector(t *testing.T) {
+	collector := New(&Options{Buckets: []float64{0.01, 1}})
+	bus := eventbus.New(eventbus.WithMetrics(collector))
+	bus.Subscribe("orders", func(n int) error {
+		if n < 0 {
+			return errors.New("negative")
+		}
+		return nil
+	})
+	release := make(chan struct{})
+	bus.SubscribeAsync("jobs", func() { <-release }, false)
+	bus.SetTopicConfig("jobs", eventbus.Config{BufferSize: 2, Overflow: eventbus.OverflowDropNewest})
+
+	bus.Publish("orders", 1)
+	bus.Publish("orders", -1)
+	bus.Publish("jobs")
+	bus.Publish("jobs")
+	bus.Publish("jobs") // dropped
+	bus.Publish("nobody")
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		collector.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+		if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain") {
+			t.Error(recorder.Header())
+		}
+		return recorder.Body.String()
+	}
+	time.Sleep(10 * time.Millisecond)
+	body := scrape()
+	for _, line := range []string{
+		"# TYPE eventbus_published_total counter",
+		`eventbus_published_total{topic="orders"} 2`,
+		`eventbus_published_total{topic="nobody"} 1`,
+		`eventbus_handled_total{topic="orders"} 2`,
+		`eventbus_handler_errors_total{topic="orders"} 1`,
+		`eventbus_handler_duration_seconds_bucket{topic="orders",le="0.01"} 2`,
+		`eventbus_handler_duration_seconds_bucket{topic="orders",le="+Inf"} 2`,
+		`eventbus_handler_duration_seconds_count{topic="orders"} 2`,
+		`eventbus_async_queue_depth{topic="jobs"} 2`,
+		`eventbus_async_dropped_total{topic="jobs"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatal(line, "\n", body)
+		}
+	}
+
+	close(release)
+	bus.WaitAsync()
+	body = scrape()
+	if !strings.Contains(body, `eventbus_async_queue_depth{topic="jobs"} 0`) || !strings.Contains(body, `eventbus_handled_total{topic="jobs"} 2`) {
+		t.Fatal(body)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	if quote("a\"b\\c\nd") != `"a\"b\\c\nd"` {
+		t.Fatal(quote("a\"b\\c\nd"))
+	}
+}
+
+func TestCollectorLabels(t *testing.T) {
+	collector := New(&Options{Labels: []string{"tenant"}})
+	bus := eventbus.New(eventbus.WithMetrics(collector))
+	bus.Subscribe("orders", func() {})
+	bus.PublishCtx(eventbus.WithMetricLabels(context.Background(), map[string]string{"tenant": "acme", "region": "eu"}), "orders")
+	bus.PublishCtx(eventbus.WithMetricLabels(context.Background(), map[string]string{"tenant": "acme"}), "orders")
+	bus.Publish("orders")
+
+	out := strings.Builder{}
+	collector.WriteTo(&out)
+	for _, line := range []string{
+		`eventbus_published_total{topic="orders",tenant="acme"} 2`,
+		`eventbus_published_total{topic="orders",tenant=""} 1`,
+		`eventbus_handled_total{topic="orders",tenant="acme"} 2`,
+		`eventbus_handler_duration_seconds_count{topic="orders",tenant=""} 1`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Fatal(line, "\n", out.String())
+		}
+	}
+	if strings.Contains(out.String(), "region") {
+		t.Fatal(out.String())
+	}
+}
@@ -0,0 +1,66 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+)
+
+// Hop - step of the provenance of an event, see Event.Provenance
+type Hop struct {
+	Topic string
+	Time  time.Time // when the event was published on Topic
+	// Via - what carried the event on from Topic: "handler" for an event
+	// published by a handler with its context, "alias" for a deprecated
+	// topic, "rpc", "mirror" or "grpc" for a bridge to another bus
+	Via string
+}
+
+// hopLink - provenance carried by a context, newest hop first
+type hopLink struct {
+	hop  Hop
+	prev *hopLink
+}
+
+// provenanceKey - context key of the provenance of the events published
+// with a context
+type provenanceKey struct{}
+
+// withHop returns a copy of ctx publishing events with hop appended to their
+// provenance
+func withHop(ctx context.Context, hop Hop) context.Context {
+	prev, _ := ctx.Value(provenanceKey{}).(*hopLink)
+	return context.WithValue(ctx, provenanceKey{}, &hopLink{hop, prev})
+}
+
+// withProvenance returns a copy of ctx publishing events with a provenance
+func withProvenance(ctx context.Context, hops []Hop) context.Context {
+	for _, hop := range hops {
+		ctx = withHop(ctx, hop)
+	}
+	return ctx
+}
+
+// Provenance returns the provenance of the events published with ctx, e.g.
+// the context passed to a handler, oldest hop first; nil if there is none
+func Provenance(ctx context.Context) []Hop {
+	link, _ := ctx.Value(provenanceKey{}).(*hopLink)
+	n := 0
+	for l := link; l != nil; l = l.prev {
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	hops := make([]Hop, n)
+	for l := link; l != nil; l = l.prev {
+		n--
+		hops[n] = l.hop
+	}
+	return hops
+}
+
+// forwarded returns the provenance of an event carried on from its topic by
+// via, e.g. a bridge
+func (ev Event) forwarded(via string) []Hop {
+	return append(ev.Provenance[:len(ev.Provenance):len(ev.Provenance)], Hop{ev.Topic, ev.Time, via})
+}
@@ -0,0 +1,39 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEventProvenance(t *testing.T) {
+	bus := New()
+	bus.Alias("orders:new", "orders:created")
+	bus.EnableTrace("invoices", 10, 0)
+	bus.Subscribe("orders:created", func(ctx context.Context, id int) {
+		bus.PublishCtx(ctx, "invoices", id)
+	})
+	var received Event
+	bus.Subscribe("invoices", func(ev Event) {
+		received = ev
+	})
+	bus.Publish("orders:new", 1)
+
+	hops := received.Provenance
+	if len(hops) != 2 || hops[0].Topic != "orders:new" || hops[0].Via != "alias" ||
+		hops[1].Topic != "orders:created" || hops[1].Via != "handler" || hops[1].Time.IsZero() {
+		t.Fatal(hops)
+	}
+	if traces := bus.Traces("invoices"); len(traces) != 1 || len(traces[0].Provenance) != 2 {
+		t.Fatal(traces)
+	}
+
+	received = Event{}
+	bus.PublishEvent(Event{Topic: "invoices", Args: []interface{}{2}, Provenance: []Hop{{Topic: "remote", Via: "rpc"}}})
+	if len(received.Provenance) != 1 || received.Provenance[0].Topic != "remote" {
+		t.Fatal(received.Provenance)
+	}
+	bus.Publish("invoices", 3)
+	if received.Provenance != nil {
+		t.Fatal(received.Provenance)
+	}
+}
@@ -0,0 +1,281 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotQuarantined - returned by TestFire and Reinstate for an id no handler
+// is quarantined with
+var ErrNotQuarantined = errors.New("not quarantined")
+
+// Quarantined - handler unsubscribed by the bus because it misbehaved, see
+// Quarantine
+type Quarantined struct {
+	ID        int
+	Topic     string
+	Handler   string
+	Reason    string // e.g. "panic limit" or "slow consumer"
+	LastError error  // the error that disabled it, or returned by the last TestFire
+	Time      time.Time
+}
+
+// quarantine - handlers unsubscribed by WithPanicLimit and WithSlowConsumerEviction
+type quarantine struct {
+	next    int
+	entries map[int]*quarantineEntry
+	sync.Mutex
+}
+
+type quarantineEntry struct {
+	Quarantined
+	handler *eventHandler
+}
+
+// Quarantine returns the handlers the bus unsubscribed because they reached
+// the panic limit (see WithPanicLimit) or were slow consumers (see
+// WithSlowConsumerEviction), oldest first. They can be inspected with
+// TestFire and subscribed again with Reinstate.
+func (bus *Bus) Quarantine() []Quarantined {
+	q := &bus.quarantine
+	q.Lock()
+	defer q.Unlock()
+	list := make([]Quarantined, 0, len(q.entries))
+	for _, entry := range q.entries {
+		list = append(list, entry.Quarantined)
+	}
+	slices.SortFunc(list, func(a, b Quarantined) int { return a.ID - b.ID })
+	return list
+}
+
+// Reinstate subscribes a quarantined handler again to its topic, with its
+// panic and slow consumer counters reset, and removes it from the quarantine.
+// Returns ErrBusClosed, an error wrapping ErrNotQuarantined if no handler is
+// quarantined with this id, or the error keeping it from being subscribed,
+// e.g. a dependency cycle with the subscriptions made since (see
+// Subscription.After); the handler then stays quarantined.
+func (bus *Bus) Reinstate(id int) error {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	if bus.closed {
+		return ErrBusClosed
+	}
+	entry, err := bus.quarantined(id, false)
+	if err != nil {
+		return err
+	}
+	handler, topic := entry.handler, entry.Topic
+	handler.panics.Store(0)
+	handler.overSince.Store(0)
+	handler.evicted.Store(false)
+	if err := bus.attach(topic, handler); err != nil {
+		return fmt.Errorf("can't reinstate handler %d: %w", id, err)
+	}
+	bus.quarantined(id, true)
+	bus.unpark()
+	return nil
+}
+
+// attach adds a handler that was subscribed before to a handler map key, be
+// it a topic, a wildcard pattern or a regex; the bus lock must be held.
+// Returns the error ordering the handlers of the key, which are then left
+// unchanged.
+func (bus *Bus) attach(topic string, handler *eventHandler) error {
+	previous := bus.handlersOf(topic)
+	bus.setHandlers(topic, append(previous, handler))
+	if bus.ordered(topic) {
+		if err := bus.orderHandlers(topic); err != nil {
+			bus.setHandlers(topic, previous)
+			return err
+		}
+	}
+	if bus.hierarchy.isPattern(topic) {
+		bus.hierarchy.add(topic)
+	}
+	if strings.HasPrefix(topic, regexKeyPrefix) && len(previous) == 0 {
+		bus.regexes.patterns = append(bus.regexes.patterns, regexp.MustCompile(strings.TrimPrefix(topic, regexKeyPrefix)))
+		bus.regexes.cache = nil
+	}
+	return nil
+}
+
+// TestFire calls a quarantined handler, and only it, synchronously with a
+// synthetic event, e.g. to check that a fix worked before reinstating it.
+// Handlers taking a context receive context.Background(). The outcome is
+// recorded as the handler's LastError.
+// Returns the *HandlerError of the handler, or an error wrapping
+// ErrNotQuarantined if no handler is quarantined with this id. Panics are only
+// recovered with WithRecovery.
+func (bus *Bus) TestFire(id int, args ...interface{}) error {
+	entry, err := bus.quarantined(id, false)
+	if err != nil {
+		return err
+	}
+	handler := entry.handler
+	results, report := bus.invoke(handler, entry.Topic, withContext(context.Background(), handler, args))
+	err = handlerError(entry.Topic, handler, results, report)
+	bus.quarantine.Lock()
+	entry.LastError = err
+	bus.quarantine.Unlock()
+	return err
+}
+
+// quarantined returns the quarantine entry with id, removing it if remove is true
+func (bus *Bus) quarantined(id int, remove bool) (*quarantineEntry, error) {
+	q := &bus.quarantine
+	q.Lock()
+	defer q.Unlock()
+	entry, ok := q.entries[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: no handler with id %d", ErrNotQuarantined, id)
+	}
+	if remove {
+		delete(q.entries, id)
+	}
+	return entry, nil
+}
+
+// quarantineLocked records an unsubscribed handler; the bus lock must be held
+func (bus *Bus) quarantineLocked(topic string, handler *eventHandler, reason string, lastErr error) {
+	q := &bus.quarantine
+	q.Lock()
+	defer q.Unlock()
+	if q.entries == nil {
+		q.entries = make(map[int]*quarantineEntry)
+	}
+	q.next++
+	q.entries[q.next] = &quarantineEntry{
+		Quarantined{q.next, topic, handlerName(handler.callBack.Pointer()), reason, lastErr, time.Now()},
+		handler,
+	}
+}
+
+// QuarantineHandler returns an admin HTTP handler for the quarantine: GET
+// lists the quarantined handlers as JSON, POST with a reinstate=<id> form
+// value reinstates one, and POST with a test_fire=<id> query value test-fires
+// one with the arguments of the JSON array in the body, decoded into the
+// types of its parameters, answering the handler's error, if any, as JSON.
+// Unknown ids are answered with 404, reinstating on a closed bus with 503 and
+// other reinstate failures with 409.
+func (bus *Bus) QuarantineHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			type item struct {
+				ID        int       `json:"id"`
+				Topic     string    `json:"topic"`
+				Handler   string    `json:"handler"`
+				Reason    string    `json:"reason"`
+				LastError string    `json:"last_error,omitempty"`
+				Time      time.Time `json:"time"`
+			}
+			items := []item{}
+			for _, q := range bus.Quarantine() {
+				it := item{ID: q.ID, Topic: q.Topic, Handler: q.Handler, Reason: q.Reason, Time: q.Time}
+				if q.LastError != nil {
+					it.LastError = q.LastError.Error()
+				}
+				items = append(items, it)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(items)
+		case http.MethodPost:
+			if r.URL.Query().Has("test_fire") {
+				bus.serveTestFire(w, r)
+				return
+			}
+			id, err := strconv.Atoi(r.FormValue("reinstate"))
+			if err != nil {
+				http.Error(w, "reinstate must be a quarantine id", http.StatusBadRequest)
+				return
+			}
+			if err := bus.Reinstate(id); err != nil {
+				http.Error(w, err.Error(), reinstateStatus(err))
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// reinstateStatus returns the HTTP status of an error of Reinstate
+func reinstateStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrNotQuarantined):
+		return http.StatusNotFound
+	case errors.Is(err, ErrBusClosed):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusConflict
+}
+
+// serveTestFire test-fires the quarantined handler of the test_fire query
+// value of r, see QuarantineHandler
+func (bus *Bus) serveTestFire(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("test_fire"))
+	if err != nil {
+		http.Error(w, "test_fire must be a quarantine id", http.StatusBadRequest)
+		return
+	}
+	entry, err := bus.quarantined(id, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	var raw []json.RawMessage
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			http.Error(w, "body must be a JSON array of arguments: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	args, err := decodeArguments(entry.handler, raw)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result := struct {
+		ID    int    `json:"id"`
+		Error string `json:"error,omitempty"`
+	}{ID: id}
+	if err := bus.TestFire(id, args...); err != nil {
+		if errors.Is(err, ErrNotQuarantined) {
+			http.Error(w, err.Error(), http.StatusNotFound) // reinstated meanwhile
+			return
+		}
+		result.Error = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// decodeArguments decodes JSON arguments into the types of the parameters of
+// a handler, after the context it may take
+func decodeArguments(handler *eventHandler, raw []json.RawMessage) ([]interface{}, error) {
+	fnType, first := handler.callBack.Type(), 0
+	if takesContext(handler) {
+		first = 1
+	}
+	args := make([]interface{}, len(raw))
+	for i, data := range raw {
+		value := reflect.New(parameterType(fnType, first+i))
+		if err := json.Unmarshal(data, value.Interface()); err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		args[i] = value.Elem().Interface()
+	}
+	return args, nil
+}
@@ -0,0 +1,141 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestQuarantine(t *testing.T) {
+	bus := New(WithRecovery(nil), WithPanicLimit(2))
+	broken := true
+	calls := 0
+	bus.Subscribe("topic", func(n int) {
+		calls++
+		if broken {
+			panic("broken")
+		}
+	})
+	bus.Publish("topic", 1)
+	bus.Publish("topic", 2)
+	bus.WaitAsync()
+	if bus.HasCallback("topic") {
+		t.Fatal("handler not evicted")
+	}
+
+	list := bus.Quarantine()
+	if len(list) != 1 || list[0].Topic != "topic" || list[0].Reason != "panic limit" || !strings.Contains(list[0].Handler, "TestQuarantine") {
+		t.Fatal(list)
+	}
+	var report *PanicReport
+	if !errors.As(list[0].LastError, &report) {
+		t.Fatal(list[0].LastError)
+	}
+
+	id := list[0].ID
+	if err := bus.TestFire(id, 3); err == nil || calls != 3 {
+		t.Fatal(err, calls)
+	}
+	broken = false
+	if err := bus.TestFire(id, 4); err != nil || calls != 4 || bus.Quarantine()[0].LastError != nil {
+		t.Fatal(err, calls)
+	}
+
+	if err := bus.Reinstate(id); err != nil {
+		t.Fatal(err)
+	}
+	if bus.Reinstate(id) == nil || bus.TestFire(id) == nil || len(bus.Quarantine()) != 0 {
+		t.Fail()
+	}
+	bus.Publish("topic", 5)
+	if calls != 5 {
+		t.Fatal(calls)
+	}
+}
+
+func TestQuarantineHandler(t *testing.T) {
+	bus := New(WithRecovery(nil), WithPanicLimit(1))
+	bus.Subscribe("topic", func() { panic("broken") })
+	bus.Publish("topic")
+	bus.WaitAsync()
+
+	recorder := httptest.NewRecorder()
+	bus.QuarantineHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/quarantine", nil))
+	var items []struct {
+		ID        int    `json:"id"`
+		Topic     string `json:"topic"`
+		LastError string `json:"last_error"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Topic != "topic" || items[0].LastError != "panic: broken" {
+		t.Fatal(items)
+	}
+
+	recorder = httptest.NewRecorder()
+	bus.QuarantineHandler().ServeHTTP(recorder, httptest.NewRequest("POST", "/quarantine?reinstate=42", nil))
+	if recorder.Code != 404 {
+		t.Fatal(recorder.Code)
+	}
+	recorder = httptest.NewRecorder()
+	bus.QuarantineHandler().ServeHTTP(recorder, httptest.NewRequest("POST", "/quarantine?reinstate=1", nil))
+	if recorder.Code != 204 || !bus.HasCallback("topic") {
+		t.Fatal(recorder.Code)
+	}
+}
+
+func TestQuarantineHandlerTestFire(t *testing.T) {
+	bus := New(WithRecovery(nil), WithPanicLimit(1))
+	bus.Subscribe("topic", func(n int) {
+		if n < 0 {
+			panic("negative")
+		}
+	})
+	bus.Publish("topic", -1)
+	bus.WaitAsync()
+
+	fire := func(target, body string) (int, string) {
+		recorder := httptest.NewRecorder()
+		bus.QuarantineHandler().ServeHTTP(recorder, httptest.NewRequest("POST", target, strings.NewReader(body)))
+		var result struct {
+			Error string `json:"error"`
+		}
+		if recorder.Code == 200 {
+			json.NewDecoder(recorder.Body).Decode(&result)
+		}
+		return recorder.Code, result.Error
+	}
+	if code, err := fire("/quarantine?test_fire=1", "[2]"); code != 200 || err != "" {
+		t.Fatal(code, err)
+	}
+	if code, err := fire("/quarantine?test_fire=1", "[-2]"); code != 200 || !strings.HasSuffix(err, "panic: negative") {
+		t.Fatal(code, err)
+	}
+	if code, _ := fire("/quarantine?test_fire=1", `["two"]`); code != 400 {
+		t.Fatal(code)
+	}
+	if code, _ := fire("/quarantine?test_fire=42", "[2]"); code != 404 {
+		t.Fatal(code)
+	}
+}
+
+func TestReinstateClosedBus(t *testing.T) {
+	bus := New(WithRecovery(nil), WithPanicLimit(1))
+	bus.Subscribe("topic", func() { panic("broken") })
+	bus.Publish("topic")
+	bus.WaitAsync()
+	bus.Close(context.Background())
+
+	recorder := httptest.NewRecorder()
+	bus.QuarantineHandler().ServeHTTP(recorder, httptest.NewRequest("POST", "/quarantine?reinstate=1", nil))
+	if recorder.Code != 503 {
+		t.Fatal(recorder.Code)
+	}
+	if q := bus.Quarantine(); len(q) != 1 {
+		t.Fatal("entry dropped", q)
+	}
+}
@@ -0,0 +1,80 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// activity - publishes and async deliveries in progress, see AwaitQuiescence
+type activity struct {
+	busy    int
+	last    time.Time     // when a publish or delivery last started or ended
+	changed chan struct{} // closed once busy drops to zero
+	sync.Mutex
+}
+
+// AwaitQuiescence runs AwaitQuiescence on package-level bus singleton
+func AwaitQuiescence(ctx context.Context, settle time.Duration) error {
+	return b.Load().AwaitQuiescence(ctx, settle)
+}
+
+// AwaitQuiescence waits until the bus has been idle for settle: no publish,
+// async delivery or control event in progress, and none started or ended
+// during the settle window. Unlike WaitAsync it outlasts cascades whose
+// handlers publish after a delay, e.g. from timers, as long as the delay is
+// shorter than settle. Returns a *PendingWorkError wrapping ctx.Err() if ctx
+// is done first.
+func (bus *Bus) AwaitQuiescence(ctx context.Context, settle time.Duration) error {
+	activity := &bus.activity
+	for {
+		activity.Lock()
+		busy, quiet := activity.busy > 0, time.Since(activity.last)
+		var changed chan struct{}
+		if busy {
+			if activity.changed == nil {
+				activity.changed = make(chan struct{})
+			}
+			changed = activity.changed
+		}
+		activity.Unlock()
+		if !busy && quiet >= settle {
+			return nil
+		}
+		if busy {
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return &PendingWorkError{bus.flow.pending(), ctx.Err()}
+			}
+			continue
+		}
+		timer := time.NewTimer(settle - quiet)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return &PendingWorkError{bus.flow.pending(), ctx.Err()}
+		}
+	}
+}
+
+// begin counts a publish or async delivery starting
+func (activity *activity) begin() {
+	activity.Lock()
+	defer activity.Unlock()
+	activity.busy++
+	activity.last = time.Now()
+}
+
+// end counts a publish or async delivery ending
+func (activity *activity) end() {
+	activity.Lock()
+	defer activity.Unlock()
+	activity.busy--
+	activity.last = time.Now()
+	if activity.busy == 0 && activity.changed != nil {
+		close(activity.changed)
+		activity.changed = nil
+	}
+}
@@ -0,0 +1,45 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAwaitQuiescence(t *testing.T) {
+	bus := New()
+	var handled atomic.Int32
+	bus.SubscribeAsync("step", func(n int) {
+		handled.Add(1)
+		if n > 0 {
+			time.AfterFunc(5*time.Millisecond, func() { bus.Publish("step", n-1) }) // outlives WaitAsync
+		}
+	}, false)
+	bus.Publish("step", 3)
+	start := time.Now()
+	if err := bus.AwaitQuiescence(context.Background(), 30*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if handled.Load() != 4 || time.Since(start) < 30*time.Millisecond {
+		t.Fatal(handled.Load(), time.Since(start))
+	}
+}
+
+func TestAwaitQuiescenceCancelled(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	bus.SubscribeAsync("slow", func() { <-release }, false)
+	bus.Publish("slow")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var pending *PendingWorkError
+	if err := bus.AwaitQuiescence(ctx, time.Millisecond); !errors.As(err, &pending) || pending.Pending["slow"] != 1 {
+		t.Fatal(err)
+	}
+	close(release)
+	if err := bus.AwaitQuiescence(context.Background(), time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+}
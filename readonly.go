@@ -0,0 +1,50 @@
+package eventbus
+
+// ReadOnlyBus - view of a bus that can subscribe and wait for handlers but can't publish
+type ReadOnlyBus interface {
+	Subscriber
+	Controller
+}
+
+// readOnlyBus forwards to a Bus without embedding it, so neither Publish nor the
+// underlying *Bus can be reached through a type assertion
+type readOnlyBus struct {
+	bus *Bus
+}
+
+// ReadOnly returns a view of the bus for consumers that must never emit events
+func (bus *Bus) ReadOnly() ReadOnlyBus {
+	return readOnlyBus{bus}
+}
+
+func (view readOnlyBus) Subscribe(topic string, fn interface{}) error {
+	return view.bus.Subscribe(topic, fn)
+}
+
+func (view readOnlyBus) SubscribeAsync(topic string, fn interface{}, transactional bool) error {
+	return view.bus.SubscribeAsync(topic, fn, transactional)
+}
+
+func (view readOnlyBus) SubscribeOnce(topic string, fn interface{}) error {
+	return view.bus.SubscribeOnce(topic, fn)
+}
+
+func (view readOnlyBus) SubscribeOnceAsync(topic string, fn interface{}) error {
+	return view.bus.SubscribeOnceAsync(topic, fn)
+}
+
+func (view readOnlyBus) SubscribeExclusive(topic string, fn interface{}) error {
+	return view.bus.SubscribeExclusive(topic, fn)
+}
+
+func (view readOnlyBus) Unsubscribe(topic string, handler interface{}) error {
+	return view.bus.Unsubscribe(topic, handler)
+}
+
+func (view readOnlyBus) HasCallback(topic string) bool {
+	return view.bus.HasCallback(topic)
+}
+
+func (view readOnlyBus) WaitAsync() {
+	view.bus.WaitAsync()
+}
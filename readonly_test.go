@@ -0,0 +1,32 @@
+package eventbus
+
+import (
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	bus := New()
+	view := bus.ReadOnly()
+
+	received := 0
+	handler := func(a int) { received += a }
+	if view.Subscribe("topic", handler) != nil || !view.HasCallback("topic") {
+		t.Fail()
+	}
+	bus.Publish("topic", 2)
+	view.WaitAsync()
+	if received != 2 {
+		t.Fail()
+	}
+
+	var escaped interface{} = view
+	if _, ok := escaped.(Publisher); ok {
+		t.Fail()
+	}
+	if _, ok := escaped.(*Bus); ok {
+		t.Fail()
+	}
+	if view.Unsubscribe("topic", handler) != nil || bus.HasCallback("topic") {
+		t.Fail()
+	}
+}
@@ -0,0 +1,75 @@
+package eventbus
+
+import (
+	"reflect"
+)
+
+// WithRecovery recovers the panics of handlers instead of letting them crash the
+// process. hook (may be nil) is called with the topic, the subscribed function
+// and the recovered value on the delivering goroutine, and a PanicReport is
+// published on TopicPanic. A recovered synchronous handler reports its panic to
+// the publisher as a *HandlerError wrapping the PanicReport.
+func WithRecovery(hook func(topic string, handler interface{}, recovered interface{})) Option {
+	return func(bus *Bus) {
+		if hook == nil {
+			hook = func(string, interface{}, interface{}) {}
+		}
+		bus.recovery = hook
+	}
+}
+
+// WithPanicLimit unsubscribes a handler once it panicked limit times in a row,
+// putting it in quarantine (see Quarantine).
+// Only recovered panics count, see WithRecovery.
+func WithPanicLimit(limit int) Option {
+	return func(bus *Bus) {
+		bus.panicLimit = limit
+	}
+}
+
+// invoke calls a handler, recovering its panic if the bus has a recovery hook
+func (bus *Bus) invoke(handler *eventHandler, topic string, args []interface{}) ([]reflect.Value, *PanicReport) {
+	if bus.recovery == nil {
+		return bus.call(handler, topic, args), nil
+	}
+	results, report := callRecovered(func([]reflect.Value) []reflect.Value {
+		return bus.call(handler, topic, args)
+	}, nil)
+	if report == nil {
+		if bus.panicLimit > 0 {
+			handler.panics.Store(0)
+		}
+		return results, nil
+	}
+	fn := handler.callBack
+	if handler.subscribed.IsValid() {
+		fn = handler.subscribed
+	}
+	bus.recovery(topic, fn.Interface(), report.Recovered)
+	bus.reportPanic(report.describe(topic, fn, args))
+	if bus.panicLimit > 0 && int(handler.panics.Add(1)) == bus.panicLimit {
+		bus.evict(handler, func(topic string) {
+			bus.quarantineLocked(topic, handler, "panic limit", report)
+		})
+	}
+	return nil, report
+}
+
+// evict unsubscribes a handler from whatever topic it is subscribed to, then
+// calls removed (may be nil) with the topic and the bus lock held. The
+// removal is asynchronous so it is safe to call while the bus lock is held;
+// WaitAsync waits for it.
+func (bus *Bus) evict(handler *eventHandler, removed func(topic string)) {
+	bus.wg.Add(1)
+	bus.scheduler.Schedule(func() {
+		defer bus.wg.Done()
+		bus.lock.Lock()
+		defer bus.lock.Unlock()
+		if topic, idx := bus.locate(handler); idx >= 0 {
+			bus.removeHandler(topic, idx)
+			if removed != nil {
+				removed(topic)
+			}
+		}
+	})
+}
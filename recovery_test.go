@@ -0,0 +1,79 @@
+package eventbus
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestWithRecovery(t *testing.T) {
+	lock := sync.Mutex{}
+	var recovered []interface{}
+	var handlers []interface{}
+	bus := New(WithRecovery(func(topic string, handler interface{}, r interface{}) {
+		lock.Lock()
+		defer lock.Unlock()
+		if topic != "topic" {
+			t.Fail()
+		}
+		recovered = append(recovered, r)
+		handlers = append(handlers, handler)
+	}))
+	reports := make(chan *PanicReport, 2)
+	bus.Subscribe(TopicPanic, func(report *PanicReport) {
+		reports <- report
+	})
+	asyncFn := func(a int) { panic("async") }
+	bus.SubscribeAsync("topic", asyncFn, false)
+	bus.Subscribe("topic", func(a int) error { panic("sync") })
+
+	errs := bus.PublishWithResult("topic", 1)
+	bus.WaitAsync()
+
+	var report *PanicReport
+	if len(errs) != 1 || !errors.As(errs[0], &report) || report.Recovered != "sync" {
+		t.Fatal(errs)
+	}
+	if len(recovered) != 2 || len(reports) != 2 {
+		t.Fatal(recovered)
+	}
+	for i, r := range recovered {
+		if r == "async" && reflect.ValueOf(handlers[i]).Pointer() != reflect.ValueOf(asyncFn).Pointer() {
+			t.Fail()
+		}
+	}
+	if !bus.HasCallback("topic") {
+		t.Fail()
+	}
+}
+
+func TestWithPanicLimit(t *testing.T) {
+	bus := New(WithRecovery(nil), WithPanicLimit(2))
+	calls := 0
+	fn := func(fail bool) {
+		calls++
+		if fail {
+			panic("boom")
+		}
+	}
+	bus.Subscribe("topic", fn)
+
+	bus.Publish("topic", true)
+	bus.Publish("topic", false) // resets the count
+	bus.Publish("topic", true)
+	bus.WaitAsync()
+	if !bus.HasCallback("topic") {
+		t.Fatal("unsubscribed before the limit")
+	}
+
+	bus.Publish("topic", true)
+	bus.WaitAsync()
+	if bus.HasCallback("topic") {
+		t.Fatal("still subscribed after the limit")
+	}
+	bus.Publish("topic", true)
+	if calls != 4 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,222 @@
+package eventbus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sync"
+)
+
+// Ref - reference to a large payload shared by every subscriber of an event
+// instead of being copied. Handlers Borrow the payload, must treat it as
+// read-only, and Release it when done; a handler that needs to modify the
+// payload works on its own Mutable copy. Debug builds (-tags eventbus_debug)
+// panic when a payload was modified while borrowed.
+type Ref struct {
+	value       interface{}
+	borrowed    int
+	fingerprint uint64
+	sync.Mutex
+}
+
+// NewRef - wrap a payload for by-reference delivery
+func NewRef(value interface{}) *Ref {
+	ref := &Ref{value: value}
+	if debugMode {
+		ref.fingerprint = fingerprint(value)
+	}
+	return ref
+}
+
+// Borrow returns the shared payload. It must not be modified and must be
+// handed back with Release.
+func (ref *Ref) Borrow() interface{} {
+	ref.Lock()
+	defer ref.Unlock()
+	ref.borrowed++
+	return ref.value
+}
+
+// Release ends a Borrow. It panics if there is no outstanding borrow.
+func (ref *Ref) Release() {
+	ref.Lock()
+	defer ref.Unlock()
+	if ref.borrowed == 0 {
+		panic("eventbus: Ref released more often than borrowed")
+	}
+	ref.borrowed--
+	if debugMode && fingerprint(ref.value) != ref.fingerprint {
+		panic(fmt.Sprintf("eventbus: shared payload %T was modified while borrowed", ref.value))
+	}
+}
+
+// Borrowed returns the number of outstanding borrows
+func (ref *Ref) Borrowed() int {
+	ref.Lock()
+	defer ref.Unlock()
+	return ref.borrowed
+}
+
+// Mutable returns a deep copy of the payload that the caller owns and may modify
+func (ref *Ref) Mutable() interface{} {
+	return deepCopy(ref.value)
+}
+
+// fingerprint hashes the content of a value, following pointers, interfaces,
+// maps, slices and struct fields (unexported ones included) all the way down
+func fingerprint(value interface{}) uint64 {
+	h := fnv.New64a()
+	hashValue(h, reflect.ValueOf(value), make(map[uintptr]bool))
+	return h.Sum64()
+}
+
+// hashValue writes the content of v to h; seen holds the pointers, maps and
+// slices being hashed, so cycles end
+func hashValue(h hash.Hash64, v reflect.Value, seen map[uintptr]bool) {
+	if !v.IsValid() {
+		h.Write([]byte{0})
+		return
+	}
+	h.Write([]byte{byte(v.Kind())})
+	number := make([]byte, 8)
+	writeUint := func(n uint64) {
+		binary.LittleEndian.PutUint64(number, n)
+		h.Write(number)
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		id := v.Pointer()
+		if seen[id] {
+			writeUint(uint64(id)) // cycle
+			return
+		}
+		seen[id] = true
+		defer delete(seen, id)
+		switch v.Kind() {
+		case reflect.Ptr:
+			hashValue(h, v.Elem(), seen)
+		case reflect.Map:
+			// the iteration order is random: combine the entries commutatively
+			var sum uint64
+			iter := v.MapRange()
+			for iter.Next() {
+				entry := fnv.New64a()
+				hashValue(entry, iter.Key(), seen)
+				hashValue(entry, iter.Value(), seen)
+				sum += entry.Sum64()
+			}
+			writeUint(uint64(v.Len()))
+			writeUint(sum)
+		case reflect.Slice:
+			writeUint(uint64(v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				hashValue(h, v.Index(i), seen)
+			}
+		}
+	case reflect.Interface:
+		hashValue(h, v.Elem(), seen)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			hashValue(h, v.Index(i), seen)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			hashValue(h, v.Field(i), seen)
+		}
+	case reflect.String:
+		writeUint(uint64(v.Len()))
+		h.Write([]byte(v.String()))
+	case reflect.Bool:
+		if v.Bool() {
+			h.Write([]byte{1})
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		writeUint(math.Float64bits(real(v.Complex())))
+		writeUint(math.Float64bits(imag(v.Complex())))
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		writeUint(uint64(v.Pointer()))
+	}
+}
+
+// deepCopy copies maps, slices, arrays, pointers and structs recursively.
+// Unexported struct fields, channels and functions are copied shallowly, and
+// contexts are shared so that their cancellation still reaches the copy.
+func deepCopy(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return copyValue(reflect.ValueOf(value), make(map[uintptr]reflect.Value)).Interface()
+}
+
+func copyValue(v reflect.Value, seen map[uintptr]reflect.Value) reflect.Value {
+	if v.Kind() != reflect.Interface && v.Type().Implements(contextType) {
+		return v
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if copied, ok := seen[v.Pointer()]; ok {
+			return copied
+		}
+		copied := reflect.New(v.Type().Elem())
+		seen[v.Pointer()] = copied
+		copied.Elem().Set(copyValue(v.Elem(), seen))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(copyValue(v.Elem(), seen))
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(copyValue(iter.Key(), seen), copyValue(iter.Value(), seen))
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(copyValue(v.Index(i), seen))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(copyValue(v.Index(i), seen))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := copied.Field(i); field.CanSet() {
+				field.Set(copyValue(v.Field(i), seen))
+			}
+		}
+		return copied
+	}
+	return v
+}
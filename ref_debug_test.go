@@ -0,0 +1,20 @@
+//go:build eventbus_debug
+
+package eventbus
+
+import (
+	"testing"
+)
+
+func TestRefMutationDetected(t *testing.T) {
+	ref := NewRef(map[string]int{"count": 1})
+	payload := ref.Borrow().(map[string]int)
+	payload["count"] = 2
+
+	defer func() {
+		if recover() == nil {
+			t.Fail()
+		}
+	}()
+	ref.Release()
+}
@@ -0,0 +1,67 @@
+package eventbus
+
+import (
+	"reflect"
+	"testing"
+)
+
+type document struct {
+	Title  string
+	Tags   []string
+	Meta   map[string]interface{}
+	Parent *document
+	secret []byte
+}
+
+func TestRef(t *testing.T) {
+	bus := New()
+	payload := &document{Title: "report", Tags: []string{"a"}}
+	ref := NewRef(payload)
+
+	bus.Subscribe("topic", func(r *Ref) {
+		doc := r.Borrow().(*document)
+		defer r.Release()
+		if doc != payload {
+			t.Fail()
+		}
+	})
+	bus.Subscribe("topic", func(r *Ref) {
+		doc := r.Mutable().(*document)
+		doc.Tags[0] = "changed"
+	})
+	bus.Publish("topic", ref)
+
+	if ref.Borrowed() != 0 || payload.Tags[0] != "a" {
+		t.Fail()
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fail()
+		}
+	}()
+	ref.Release()
+}
+
+func TestDeepCopy(t *testing.T) {
+	original := &document{
+		Title:  "child",
+		Tags:   []string{"x", "y"},
+		Meta:   map[string]interface{}{"sizes": []int{1, 2}},
+		secret: []byte("s"),
+	}
+	original.Parent = original // cycle
+
+	copied := deepCopy(original).(*document)
+	if !reflect.DeepEqual(copied.Tags, original.Tags) || copied.Parent != copied {
+		t.Fail()
+	}
+	copied.Tags[0] = "changed"
+	copied.Meta["sizes"].([]int)[0] = 100
+	if original.Tags[0] != "x" || original.Meta["sizes"].([]int)[0] != 1 {
+		t.Fail()
+	}
+	if deepCopy(nil) != nil || deepCopy(5) != 5 {
+		t.Fail()
+	}
+}
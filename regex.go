@@ -0,0 +1,101 @@
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// regexKeyPrefix prefixes the handler map keys of regex subscriptions, so
+// they can't collide with topics
+const regexKeyPrefix = "\x00regex:"
+
+// maxCachedTopics bounds the number of topics whose matching regexes are cached
+const maxCachedTopics = 4096
+
+// regexIndex holds the subscribed regexes and caches the ones matching each topic
+type regexIndex struct {
+	patterns  []*regexp.Regexp    // in subscription order
+	cache     map[string][]string // guarded by cacheLock, or the bus lock held exclusively
+	cacheLock sync.Mutex
+}
+
+// SubscribeRegex subscribes to every topic matching the regular expression
+// pattern. Handlers of the matching regexes receive an event after the
+// handlers subscribed to its topic, in the order the regexes were subscribed.
+// Returns error if `fn` is not a function or pattern doesn't compile.
+func (bus *Bus) SubscribeRegex(pattern string, fn interface{}) error {
+	if !(reflect.TypeOf(fn).Kind() == reflect.Func) {
+		return fmt.Errorf("%s is not of type reflect.Func", reflect.TypeOf(fn).Kind())
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	if bus.closed {
+		return ErrBusClosed
+	}
+	key := regexKeyPrefix + pattern
+	if len(bus.handlersOf(key)) == 0 {
+		bus.regexes.patterns = append(bus.regexes.patterns, re)
+		bus.regexes.cache = nil
+	}
+	bus.setHandlers(key, append(bus.handlersOf(key), &eventHandler{
+		callBack: reflect.ValueOf(fn),
+	}))
+	bus.unpark()
+	return nil
+}
+
+// UnsubscribeRegex removes a handler subscribed with SubscribeRegex.
+// Returns error if the handler is not subscribed to pattern.
+func (bus *Bus) UnsubscribeRegex(pattern string, handler interface{}) error {
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	key := regexKeyPrefix + pattern
+	idx := bus.findHandlerIdx(key, reflect.ValueOf(handler))
+	if idx < 0 {
+		return fmt.Errorf("handler is not subscribed to regex %s", pattern)
+	}
+	bus.removeHandler(key, idx)
+	return nil
+}
+
+// remove drops the regex of a handler map key once it has no handlers
+func (index *regexIndex) remove(key string) {
+	pattern := strings.TrimPrefix(key, regexKeyPrefix)
+	for i, re := range index.patterns {
+		if re.String() == pattern {
+			index.patterns = append(index.patterns[:i:i], index.patterns[i+1:]...)
+			index.cache = nil
+			return
+		}
+	}
+}
+
+// match returns the handler map keys of the regexes matching topic
+func (index *regexIndex) match(topic string) []string {
+	if len(index.patterns) == 0 {
+		return nil
+	}
+	index.cacheLock.Lock()
+	defer index.cacheLock.Unlock()
+	if keys, ok := index.cache[topic]; ok {
+		return keys
+	}
+	var keys []string
+	for _, re := range index.patterns {
+		if re.MatchString(topic) {
+			keys = append(keys, regexKeyPrefix+re.String())
+		}
+	}
+	if index.cache == nil || len(index.cache) >= maxCachedTopics {
+		index.cache = make(map[string][]string)
+	}
+	index.cache[topic] = keys
+	return keys
+}
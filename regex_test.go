@@ -0,0 +1,61 @@
+package eventbus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubscribeRegex(t *testing.T) {
+	bus := New()
+	calls := make([]string, 0)
+	users := func(id int) { calls = append(calls, "users") }
+	created := func(id int) { calls = append(calls, "created") }
+	if bus.SubscribeRegex(`^user\.`, users) != nil {
+		t.Fail()
+	}
+	if bus.SubscribeRegex(`\.created$`, created) != nil {
+		t.Fail()
+	}
+	if bus.SubscribeRegex(`(`, users) == nil || bus.SubscribeRegex(`.*`, "String") == nil {
+		t.Fail()
+	}
+	bus.Subscribe("user.created", func(id int) { calls = append(calls, "exact") })
+
+	bus.Publish("user.created", 1)
+	bus.Publish("user.deleted", 2)
+	bus.Publish("order.created", 3)
+	bus.Publish(`^user\.`, 4) // the pattern itself is not a topic
+	if strings.Join(calls, " ") != "exact users created users created" {
+		t.Error(calls)
+	}
+	if !bus.HasCallback("user.updated") || bus.HasCallback("order.deleted") {
+		t.Fail()
+	}
+
+	if bus.UnsubscribeRegex(`^user\.`, created) == nil {
+		t.Fail()
+	}
+	if bus.UnsubscribeRegex(`^user\.`, users) != nil {
+		t.Fail()
+	}
+	if bus.HasCallback("user.updated") || len(bus.regexes.patterns) != 1 {
+		t.Fail()
+	}
+	calls = calls[:0]
+	bus.Publish("user.deleted", 5)
+	bus.Publish("order.created", 6)
+	if strings.Join(calls, " ") != "created" {
+		t.Error(calls)
+	}
+}
+
+func TestRegexCacheBounded(t *testing.T) {
+	bus := New()
+	bus.SubscribeRegex(`^t`, func() {})
+	for i := 0; i < maxCachedTopics+10; i++ {
+		bus.Publish("t" + strings.Repeat("x", i%50) + string(rune('a'+i%26)) + string(rune(i)))
+	}
+	if len(bus.regexes.cache) > maxCachedTopics {
+		t.Fail()
+	}
+}
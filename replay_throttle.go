@@ -0,0 +1,90 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	minReplayDelay = time.Millisecond // first pause of a throttled replay
+	maxReplayDelay = time.Second      // longest pause between replayed events
+)
+
+// replayThrottle - paces replays on the queue latency of async deliveries,
+// see WithReplayThrottle
+type replayThrottle struct {
+	target  time.Duration
+	latency time.Duration // moving average of the queue latency
+	waiting int           // async deliveries scheduled but not started
+	delay   time.Duration // current pause between replayed events
+	sync.Mutex
+}
+
+// WithReplayThrottle slows replays (ReplayFromStore and Backfill) down while
+// async deliveries wait in the queue for longer than target, so a replay
+// doesn't starve the live traffic sharing its subscribers and workers. The
+// pause between replayed events doubles (up to a second) as long as the
+// queue latency stays over target, and halves once it is back under.
+func WithReplayThrottle(target time.Duration) Option {
+	return func(bus *Bus) {
+		bus.throttle.target = target
+	}
+}
+
+// ReplayDelay returns the current pause between replayed events, zero if
+// replays run at full speed
+func (bus *Bus) ReplayDelay() time.Duration {
+	throttle := &bus.throttle
+	throttle.Lock()
+	defer throttle.Unlock()
+	return throttle.delay
+}
+
+// queued counts an async delivery scheduled
+func (throttle *replayThrottle) queued() {
+	if throttle.target <= 0 {
+		return
+	}
+	throttle.Lock()
+	defer throttle.Unlock()
+	throttle.waiting++
+}
+
+// dequeued counts an async delivery leaving the queue after waiting for latency
+func (throttle *replayThrottle) dequeued(latency time.Duration) {
+	if throttle.target <= 0 {
+		return
+	}
+	throttle.Lock()
+	defer throttle.Unlock()
+	throttle.waiting--
+	throttle.latency += (latency - throttle.latency) / 8
+}
+
+// wait pauses a replay before its next event, adapting the pause to the
+// queue latency. Returns ctx.Err() if ctx is done first.
+func (throttle *replayThrottle) wait(ctx context.Context) error {
+	if throttle.target <= 0 {
+		return ctx.Err()
+	}
+	throttle.Lock()
+	if throttle.waiting > 0 && throttle.latency > throttle.target {
+		throttle.delay = min(max(2*throttle.delay, minReplayDelay), maxReplayDelay)
+	} else if throttle.delay /= 2; throttle.delay < minReplayDelay {
+		throttle.delay = 0
+	}
+	delay := throttle.delay
+	throttle.Unlock()
+	if delay == 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
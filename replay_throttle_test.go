@@ -0,0 +1,48 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReplayThrottle(t *testing.T) {
+	bus := New(WithReplayThrottle(time.Millisecond), WithAsyncWorkers(1))
+	bus.SubscribeAsync("live", func() { time.Sleep(5 * time.Millisecond) }, false)
+	for i := 0; i < 20; i++ {
+		bus.Publish("live")
+	}
+	time.Sleep(50 * time.Millisecond) // deliveries start later and later
+	ctx := context.Background()
+	bus.throttle.wait(ctx)
+	bus.throttle.wait(ctx)
+	if bus.ReplayDelay() != 2*minReplayDelay {
+		t.Fatal(bus.ReplayDelay())
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if bus.throttle.wait(cancelled) != context.Canceled {
+		t.Fail()
+	}
+
+	bus.WaitAsync()
+	bus.throttle.wait(ctx)
+	bus.throttle.wait(ctx)
+	bus.throttle.wait(ctx)
+	if bus.ReplayDelay() != 0 {
+		t.Fatal(bus.ReplayDelay())
+	}
+}
+
+func TestReplayUnthrottled(t *testing.T) {
+	bus := New()
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if bus.throttle.wait(context.Background()) != nil {
+			t.Fatal()
+		}
+	}
+	if bus.ReplayDelay() != 0 || time.Since(start) > 10*time.Millisecond {
+		t.Fail()
+	}
+}
@@ -0,0 +1,144 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrNoResponder - error returned by Request when no reply handler is
+// subscribed to the topic
+var ErrNoResponder = errors.New("no reply handler")
+
+// replies - reply handlers subscribed with SubscribeReply, by topic
+type replies struct {
+	handlers map[string]*eventHandler
+	sync.Mutex
+}
+
+// SubscribeReply runs SubscribeReply on package-level bus singleton
+func SubscribeReply(topic string, fn interface{}) error {
+	return b.Load().SubscribeReply(topic, fn)
+}
+
+// SubscribeReply subscribes the handler of the requests sent to a topic with
+// Request. fn takes the request, optionally after a context.Context, and
+// returns the response and an error. A topic has at most one reply handler;
+// it doesn't receive the events published on the topic.
+// Returns error if fn doesn't have this signature or the topic already has a
+// reply handler.
+func (bus *Bus) SubscribeReply(topic string, fn interface{}) error {
+	fnType := reflect.TypeOf(fn)
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return fmt.Errorf("%v is not of type reflect.Func", fnType)
+	}
+	handler := &eventHandler{callBack: reflect.ValueOf(fn)}
+	params := 1
+	if takesContext(handler) {
+		params = 2
+	}
+	if fnType.NumIn() != params || fnType.IsVariadic() || fnType.NumOut() != 2 || fnType.Out(1) != errorType {
+		return fmt.Errorf("reply handler of topic %s must be func([context.Context, ]Request) (Response, error), not %s", topic, fnType)
+	}
+	topic, err := bus.checkTopic(topic, false)
+	if err != nil {
+		return err
+	}
+	bus.lock.RLock()
+	closed := bus.closed
+	bus.lock.RUnlock()
+	if closed {
+		return ErrBusClosed
+	}
+	bus.replies.Lock()
+	defer bus.replies.Unlock()
+	if _, ok := bus.replies.handlers[topic]; ok {
+		return fmt.Errorf("topic %s already has a reply handler", topic)
+	}
+	if bus.replies.handlers == nil {
+		bus.replies.handlers = make(map[string]*eventHandler)
+	}
+	bus.replies.handlers[topic] = handler
+	return nil
+}
+
+// UnsubscribeReply runs UnsubscribeReply on package-level bus singleton
+func UnsubscribeReply(topic string) error {
+	return b.Load().UnsubscribeReply(topic)
+}
+
+// UnsubscribeReply removes the reply handler of a topic.
+// Returns error if the topic has none.
+func (bus *Bus) UnsubscribeReply(topic string) error {
+	topic = bus.canonicalTopic(topic)
+	bus.replies.Lock()
+	defer bus.replies.Unlock()
+	if _, ok := bus.replies.handlers[topic]; !ok {
+		return fmt.Errorf("topic %s has no reply handler", topic)
+	}
+	delete(bus.replies.handlers, topic)
+	return nil
+}
+
+// Request runs Request on package-level bus singleton
+func Request(ctx context.Context, topic string, req interface{}) (interface{}, error) {
+	return b.Load().Request(ctx, topic, req)
+}
+
+// Request sends req to the reply handler of a topic (see SubscribeReply) and
+// returns its response. The handler runs in its own goroutine, which
+// WaitAsync waits for, with ctx if it takes one; delivery middleware, topic
+// configs and WithRecovery apply to it as to other handlers.
+// Returns the *HandlerError of the handler, ErrNoResponder if the topic has
+// no reply handler, ctx.Err() if ctx is done before the response arrives, or
+// error if req doesn't match the handler's parameter.
+func (bus *Bus) Request(ctx context.Context, topic string, req interface{}) (interface{}, error) {
+	topic, err := bus.checkPublish(topic)
+	if err != nil {
+		return nil, err
+	}
+	bus.lock.RLock()
+	closed := bus.closed
+	bus.lock.RUnlock()
+	if closed {
+		return nil, ErrBusClosed
+	}
+	bus.replies.Lock()
+	handler, ok := bus.replies.handlers[topic]
+	bus.replies.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w for topic %s", ErrNoResponder, topic)
+	}
+	args := withContext(ctx, handler, []interface{}{req})
+	if param := parameterType(handler.callBack.Type(), len(args)-1); req != nil && !reflect.TypeOf(req).AssignableTo(param) {
+		return nil, fmt.Errorf("request of type %T can't be passed to the reply handler of topic %s taking %s", req, topic, param)
+	}
+
+	type reply struct {
+		resp interface{}
+		err  error
+	}
+	replied := make(chan reply, 1)
+	bus.wg.Add(1)
+	go func() {
+		defer bus.wg.Done()
+		results, report := bus.invoke(handler, topic, args)
+		if err := handlerError(topic, handler, results, report); err != nil {
+			replied <- reply{nil, err}
+			return
+		}
+		if len(results) == 0 {
+			replied <- reply{} // short-circuited by a delivery middleware
+			return
+		}
+		replied <- reply{results[0].Interface(), nil}
+	}()
+	select {
+	case r := <-replied:
+		return r.resp, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
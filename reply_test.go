@@ -0,0 +1,64 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRequest(t *testing.T) {
+	bus := New()
+	if _, err := bus.Request(context.Background(), "price", "AAPL"); !errors.Is(err, ErrNoResponder) {
+		t.Fatal(err)
+	}
+	if bus.SubscribeReply("price", func(symbol string) float64 { return 0 }) == nil {
+		t.Fatal("signature not checked")
+	}
+	err := bus.SubscribeReply("price", func(ctx context.Context, symbol string) (float64, error) {
+		if symbol == "" {
+			return 0, errors.New("no symbol")
+		}
+		return 42, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bus.SubscribeReply("price", func(string) (float64, error) { return 0, nil }) == nil {
+		t.Fatal("second reply handler accepted")
+	}
+	calls := 0
+	bus.Subscribe("price", func(string) { calls++ })
+
+	resp, err := bus.Request(context.Background(), "price", "AAPL")
+	if err != nil || resp != 42.0 || calls != 0 {
+		t.Fatal(resp, err, calls)
+	}
+	var handlerErr *HandlerError
+	if _, err := bus.Request(context.Background(), "price", ""); !errors.As(err, &handlerErr) {
+		t.Fatal(err)
+	}
+	if _, err := bus.Request(context.Background(), "price", 1); err == nil {
+		t.Fatal("mismatched request accepted")
+	}
+
+	if bus.UnsubscribeReply("price") != nil || bus.UnsubscribeReply("price") == nil {
+		t.Fail()
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	bus.SubscribeReply("slow", func(struct{}) (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := bus.Request(ctx, "slow", struct{}{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	close(release)
+	bus.WaitAsync()
+}
@@ -0,0 +1,110 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// retainKey - context key marking the events to retain, see PublishRetained
+type retainKey struct{}
+
+// retainedEvents - retained events by topic, see PublishRetained
+type retainedEvents struct {
+	topics map[string]retainedEvent
+	sync.Mutex
+}
+
+// retainedEvent - last event published on a topic with PublishRetained
+type retainedEvent struct {
+	args      []interface{}
+	published time.Time
+}
+
+// PublishRetained runs PublishRetained on package-level bus singleton
+func PublishRetained(topic string, args ...interface{}) error {
+	return b.Load().PublishRetained(topic, args...)
+}
+
+// PublishRetained works like Publish and keeps the event as the retained
+// event of the topic, replacing the previous one, which handlers subscribing
+// with SubscribeWithRetained receive right away. The event is retained even if
+// the topic has no subscribers yet.
+func (bus *Bus) PublishRetained(topic string, args ...interface{}) error {
+	return bus.PublishCtx(context.WithValue(context.Background(), retainKey{}, true), topic, args...)
+}
+
+// Retained returns the arguments of the retained event of a topic, false if
+// it has none
+func (bus *Bus) Retained(topic string) ([]interface{}, bool) {
+	topic = bus.canonicalTopic(topic)
+	bus.retained.Lock()
+	defer bus.retained.Unlock()
+	retained, ok := bus.retained.topics[topic]
+	if !ok {
+		return nil, false
+	}
+	return slices.Clone(retained.args), true
+}
+
+// ClearRetained drops the retained event of a topic
+func (bus *Bus) ClearRetained(topic string) {
+	topic = bus.canonicalTopic(topic)
+	bus.retained.Lock()
+	defer bus.retained.Unlock()
+	delete(bus.retained.topics, topic)
+}
+
+// SubscribeWithRetained runs SubscribeWithRetained on package-level bus singleton
+func SubscribeWithRetained(topic string, fn interface{}) error {
+	return b.Load().SubscribeWithRetained(topic, fn)
+}
+
+// SubscribeWithRetained works like Subscribe, then delivers the retained
+// event of the topic (of every matching topic for a pattern) to the handler
+// on the calling goroutine. The handler doesn't miss an event published
+// while it subscribes, but one published concurrently may reach it before
+// the retained event.
+// Returns error if `fn` is not a function.
+func (bus *Bus) SubscribeWithRetained(topic string, fn interface{}) error {
+	handler := &eventHandler{callBack: reflect.ValueOf(fn)}
+	bus.lock.Lock()
+	topic, err := bus.subscribeLocked(topic, fn, handler)
+	if err != nil {
+		bus.lock.Unlock()
+		return err
+	}
+	bus.retained.Lock()
+	var topics []string
+	for retainedTopic := range bus.retained.topics {
+		if retainedTopic == topic || slices.Contains(bus.hierarchy.match(retainedTopic), topic) {
+			topics = append(topics, retainedTopic)
+		}
+	}
+	slices.Sort(topics)
+	events := make([]retainedEvent, 0, len(topics))
+	for _, retainedTopic := range topics {
+		events = append(events, bus.retained.topics[retainedTopic])
+	}
+	bus.retained.Unlock()
+	bus.lock.Unlock()
+
+	for i, retained := range events {
+		args := bus.withEvent(context.Background(), handler, topics[i], retained.published, withContext(context.Background(), handler, retained.args))
+		results, report := bus.invoke(handler, topics[i], args)
+		bus.logHandled(topics[i], handler, handlerError(topics[i], handler, results, report), 0)
+	}
+	return nil
+}
+
+// retain keeps the event published on a topic as its retained event
+func (bus *Bus) retain(topic string, args []interface{}) {
+	bus.retained.Lock()
+	defer bus.retained.Unlock()
+	if bus.retained.topics == nil {
+		bus.retained.topics = make(map[string]retainedEvent)
+	}
+	bus.retained.topics[topic] = retainedEvent{slices.Clone(args), time.Now()}
+}
@@ -0,0 +1,59 @@
+package eventbus
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestPublishRetained(t *testing.T) {
+	bus := New()
+	if err := bus.SubscribeWithRetained("config", func(string) { t.Fatal("nothing retained") }); err != nil {
+		t.Fatal(err)
+	}
+	bus.UnsubscribeAll("config")
+
+	bus.PublishRetained("config", "v1")
+	bus.PublishRetained("config", "v2")
+	bus.Publish("config", "not retained")
+	if args, ok := bus.Retained("config"); !ok || !slices.Equal(args, []interface{}{"v2"}) {
+		t.Fatal(args, ok)
+	}
+
+	var received []string
+	if err := bus.SubscribeWithRetained("config", func(version string) { received = append(received, version) }); err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish("config", "v3")
+	if !slices.Equal(received, []string{"v2", "v3"}) {
+		t.Fatal(received)
+	}
+
+	bus.ClearRetained("config")
+	if _, ok := bus.Retained("config"); ok {
+		t.Fail()
+	}
+}
+
+func TestPublishRetainedFromHandler(t *testing.T) {
+	bus := New()
+	bus.Subscribe("a", func(ctx context.Context) { bus.PublishCtx(ctx, "b") })
+	bus.PublishRetained("a")
+	if _, ok := bus.Retained("b"); ok {
+		t.Fatal("event published by a handler retained")
+	}
+}
+
+func TestSubscribeWithRetainedPattern(t *testing.T) {
+	bus := New(WithSeparator("/"))
+	bus.PublishRetained("sensors/kitchen", 21)
+	bus.PublishRetained("sensors/garage", 12)
+	bus.PublishRetained("alarms/garage", true)
+	var received []Event
+	if err := bus.SubscribeWithRetained("sensors/+", func(ev Event) { received = append(received, ev) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2 || received[0].Topic != "sensors/garage" || received[1].Args[0] != 21 {
+		t.Fatal(received)
+	}
+}
@@ -0,0 +1,70 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// retryPolicy - retries of the failed deliveries of a subscription, see
+// Subscription.WithRetry
+type retryPolicy struct {
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// delay returns the backoff before the attempt following attempt
+func (retry *retryPolicy) delay(attempt int) time.Duration {
+	return retry.backoff << min(attempt-1, 16)
+}
+
+// WithRetry retries the deliveries the subscription's handler fails, by
+// returning an error or panicking (with WithRecovery), up to maxAttempts
+// attempts in all, before the event is dropped or dead-lettered (see
+// WithDeadLetters). Async deliveries wait backoff before the second attempt,
+// doubling the delay for every further attempt, unless the publish context is
+// done first; synchronous deliveries are retried immediately. It replaces
+// Config.MaxAttempts for the subscription.
+// Returns error if the subscription is not active.
+func (sub *Subscription) WithRetry(maxAttempts int, backoff time.Duration) error {
+	bus := sub.bus
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	if _, idx := bus.locate(sub.handler); idx < 0 {
+		return fmt.Errorf("subscription to topic %s is not active", sub.topic)
+	}
+	sub.handler.retry.Store(&retryPolicy{maxAttempts, backoff})
+	return nil
+}
+
+// doPublishRetried calls a handler like doPublish, retrying the failed
+// delivery according to the retry policy of the handler
+func (bus *Bus) doPublishRetried(ctx context.Context, handler *eventHandler, topic string, published time.Time, async bool, args ...interface{}) error {
+	err := bus.doPublish(ctx, handler, topic, published, args...)
+	retry := handler.retry.Load()
+	if retry == nil {
+		return err
+	}
+	for attempt := 1; err != nil && attempt < retry.maxAttempts && !errors.Is(err, ErrStopPropagation); attempt++ {
+		bus.log(slog.LevelWarn, "retrying handler", "topic", topic, "handler", handlerName(handler.callBack.Pointer()), "attempt", attempt+1, "error", err)
+		if async && !sleepCtx(ctx, retry.delay(attempt)) {
+			break // the publisher gave up
+		}
+		err = bus.doPublish(ctx, handler, topic, published, args...)
+	}
+	return err
+}
+
+// sleepCtx waits for d, returning false if ctx is done first
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,87 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRetry(t *testing.T) {
+	bus := New(WithRecovery(nil), WithDeadLetters(nil))
+	var attempts atomic.Int32
+	var times []time.Time
+	sub, _ := bus.SubscribeAsyncHandle("topic", func(n int) {
+		times = append(times, time.Now())
+		if attempts.Add(1) < 3 {
+			panic("flaky")
+		}
+	}, true)
+	if err := sub.WithRetry(3, 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	letters := make(chan DeadLetter, 1)
+	bus.Subscribe(DeadLetterTopic("topic"), func(letter DeadLetter) { letters <- letter })
+
+	bus.Publish("topic", 1)
+	bus.WaitAsync()
+	if attempts.Load() != 3 {
+		t.Fatal(attempts.Load())
+	}
+	if times[1].Sub(times[0]) < 10*time.Millisecond || times[2].Sub(times[1]) < 20*time.Millisecond {
+		t.Fatal(times) // exponential backoff
+	}
+	select {
+	case letter := <-letters:
+		t.Fatal("dead-lettered a successful retry", letter)
+	default:
+	}
+
+	attempts.Store(-10)
+	bus.Publish("topic", 2)
+	bus.WaitAsync()
+	select {
+	case letter := <-letters:
+		if letter.Args[0] != 2 {
+			t.Fatal(letter)
+		}
+	default:
+		t.Fatal("not dead-lettered after the last attempt")
+	}
+}
+
+func TestWithRetrySync(t *testing.T) {
+	bus := New()
+	attempts := 0
+	sub, _ := bus.SubscribeHandle("topic", func() error {
+		attempts++
+		return errors.New("failed")
+	})
+	sub.WithRetry(4, time.Hour) // synchronous deliveries don't wait
+	if errs := bus.PublishWithResult("topic"); len(errs) != 1 || attempts != 4 {
+		t.Fatal(errs, attempts)
+	}
+	sub.Unsubscribe()
+	if sub.WithRetry(1, 0) == nil {
+		t.Fail()
+	}
+}
+
+func TestWithRetryCanceled(t *testing.T) {
+	bus := New()
+	var attempts atomic.Int32
+	sub, _ := bus.SubscribeAsyncHandle("topic", func() error {
+		attempts.Add(1)
+		return errors.New("failed")
+	}, false)
+	sub.WithRetry(5, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	bus.PublishCtx(ctx, "topic")
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	bus.WaitAsync()
+	if attempts.Load() != 1 {
+		t.Fatal(attempts.Load())
+	}
+}
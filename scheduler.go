@@ -0,0 +1,171 @@
+package eventbus
+
+import (
+	"slices"
+	"sync"
+)
+
+// Scheduler - runs the asynchronous work of a bus: async and shadow handler
+// deliveries and the bus' own control events. Schedule must not block.
+type Scheduler interface {
+	Schedule(task func())
+}
+
+// PriorityScheduler - Scheduler queueing tasks by priority, e.g. the workers
+// of WithAsyncWorkers. The async deliveries of events with a priority (see
+// WithEventPriority) to non-transactional handlers are scheduled with it.
+type PriorityScheduler interface {
+	Scheduler
+	// SchedulePriority works like Schedule, running task before the queued
+	// tasks of lower priority; Schedule uses priority 0
+	SchedulePriority(priority int, task func())
+}
+
+// goroutineScheduler - default scheduler, runs every task in its own goroutine
+type goroutineScheduler struct{}
+
+func (goroutineScheduler) Schedule(task func()) {
+	go task()
+}
+
+// WithScheduler makes the bus dispatch asynchronous work through scheduler
+// instead of starting a goroutine per delivery. A single-threaded scheduler
+// (see eventbustest.Scheduler) makes async interleavings reproducible in tests.
+func WithScheduler(scheduler Scheduler) Option {
+	return func(bus *Bus) {
+		bus.scheduler = scheduler
+	}
+}
+
+// WithAsyncWorkers runs the asynchronous work of the bus on n goroutines
+// instead of a goroutine per delivery, so bursts of publishes queue up rather
+// than start thousands of goroutines. Tasks wait in an unbounded queue, see
+// Config.BufferSize to bound it, run by decreasing priority (see
+// PriorityScheduler), in scheduling order among equal priorities. Handlers
+// blocking on the deliveries of other async handlers need enough workers to
+// avoid deadlocks. Close stops the workers once the queue is empty.
+func WithAsyncWorkers(n int) Option {
+	return func(bus *Bus) {
+		pool := &workerPool{}
+		pool.wake = sync.NewCond(&pool.lock)
+		for i := 0; i < max(n, 1); i++ {
+			go pool.work()
+		}
+		bus.scheduler = pool
+		bus.track(pool.stop)
+	}
+}
+
+// workerPool - Scheduler running tasks on a fixed set of goroutines
+type workerPool struct {
+	tasks   []poolTask // by decreasing priority
+	stopped bool
+	lock    sync.Mutex
+	wake    *sync.Cond
+}
+
+// poolTask - task queued in a workerPool
+type poolTask struct {
+	priority int
+	run      func()
+}
+
+var _ PriorityScheduler = (*workerPool)(nil)
+
+func (pool *workerPool) Schedule(task func()) {
+	pool.SchedulePriority(0, task)
+}
+
+func (pool *workerPool) SchedulePriority(priority int, task func()) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	if pool.stopped {
+		go task() // e.g. control events emitted while the bus closes
+		return
+	}
+	// after the queued tasks of the same or a higher priority
+	i := len(pool.tasks)
+	for i > 0 && pool.tasks[i-1].priority < priority {
+		i--
+	}
+	pool.tasks = slices.Insert(pool.tasks, i, poolTask{priority, task})
+	pool.wake.Signal()
+}
+
+func (pool *workerPool) work() {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	for {
+		for len(pool.tasks) == 0 {
+			if pool.stopped {
+				return
+			}
+			pool.wake.Wait()
+		}
+		task := pool.tasks[0]
+		pool.tasks[0] = poolTask{}
+		pool.tasks = pool.tasks[1:]
+		pool.lock.Unlock()
+		task.run()
+		pool.lock.Lock()
+	}
+}
+
+// stop lets the workers exit once the queue is empty
+func (pool *workerPool) stop() {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	pool.stopped = true
+	pool.wake.Broadcast()
+}
+
+// serialQueue - runs the deliveries of a transactional handler one at a time,
+// in publishing order, scheduling the next only once the previous completed
+type serialQueue struct {
+	lock    sync.Mutex
+	pending []func(done func())
+	running bool
+}
+
+// push queues task and schedules it when no other task of the queue is running
+func (q *serialQueue) push(scheduler Scheduler, task func()) {
+	q.pushAsync(scheduler, func(done func()) {
+		defer done()
+		task()
+	})
+}
+
+// pushAsync works like push for a task that completes once it calls done,
+// which it may do after returning
+func (q *serialQueue) pushAsync(scheduler Scheduler, task func(done func())) {
+	q.lock.Lock()
+	q.pending = append(q.pending, task)
+	if q.running {
+		q.lock.Unlock()
+		return
+	}
+	q.running = true
+	q.lock.Unlock()
+	scheduler.Schedule(func() { q.runNext(scheduler) })
+}
+
+func (q *serialQueue) runNext(scheduler Scheduler) {
+	q.lock.Lock()
+	task := q.pending[0]
+	q.pending[0] = nil
+	q.pending = q.pending[1:]
+	q.lock.Unlock()
+	task(func() { q.done(scheduler) })
+}
+
+// done schedules the next task once the running one completed
+func (q *serialQueue) done(scheduler Scheduler) {
+	q.lock.Lock()
+	if len(q.pending) == 0 {
+		q.running = false
+		q.lock.Unlock()
+		return
+	}
+	q.lock.Unlock()
+	scheduler.Schedule(func() { q.runNext(scheduler) })
+}
@@ -0,0 +1,94 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type queueScheduler struct {
+	lock  sync.Mutex
+	tasks []func()
+}
+
+func (s *queueScheduler) Schedule(task func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.tasks = append(s.tasks, task)
+}
+
+func (s *queueScheduler) run() {
+	for {
+		s.lock.Lock()
+		if len(s.tasks) == 0 {
+			s.lock.Unlock()
+			return
+		}
+		task := s.tasks[0]
+		s.tasks = s.tasks[1:]
+		s.lock.Unlock()
+		task()
+	}
+}
+
+func TestWithScheduler(t *testing.T) {
+	scheduler := &queueScheduler{}
+	bus := New(WithScheduler(scheduler))
+	calls := 0
+	bus.SubscribeAsync("topic", func() { calls++ }, false)
+	bus.SubscribeShadow("topic", func() { calls++ })
+	bus.Subscribe("topic", func() { calls++ })
+	bus.Publish("topic")
+	if calls != 1 || len(scheduler.tasks) != 2 {
+		t.Fail()
+	}
+	scheduler.run()
+	bus.WaitAsync()
+	if calls != 3 {
+		t.Fail()
+	}
+}
+
+func TestTransactionalSingleThreaded(t *testing.T) {
+	scheduler := &queueScheduler{}
+	bus := New(WithScheduler(scheduler))
+	results := make([]int, 0)
+	bus.SubscribeAsync("topic", func(n int) { results = append(results, n) }, true)
+	bus.Publish("topic", 1)
+	bus.Publish("topic", 2) // must not block until the first delivery ran
+	bus.Publish("topic", 3)
+	scheduler.run()
+	bus.WaitAsync()
+	if len(results) != 3 || results[0] != 1 || results[1] != 2 || results[2] != 3 {
+		t.Fail()
+	}
+}
+
+func TestWithAsyncWorkers(t *testing.T) {
+	bus := New(WithAsyncWorkers(2))
+	var running, peak, calls atomic.Int32
+	bus.SubscribeAsync("topic", func() {
+		n := running.Add(1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		calls.Add(1)
+	}, false)
+	for i := 0; i < 20; i++ {
+		bus.Publish("topic")
+	}
+	bus.WaitAsync()
+	if calls.Load() != 20 || peak.Load() > 2 {
+		t.Fatal(calls.Load(), peak.Load())
+	}
+
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	bus.scheduler.Schedule(func() { close(done) })
+	<-done // runs after the workers stopped
+}
@@ -0,0 +1,133 @@
+package eventbus
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+)
+
+// Upcaster - converts the arguments of an event stored with an older payload
+// schema to the next schema
+type Upcaster func(args []interface{}) ([]interface{}, error)
+
+// SchemaMigration - proposed change of the payload schema of a stored topic,
+// see DryRunSchemaMigration
+type SchemaMigration struct {
+	// Handler - function taking the new payload types, like the handlers
+	// subscribed once the migration is deployed; it is not called
+	Handler interface{}
+	// Upcasters convert the stored arguments, in order
+	Upcasters []Upcaster
+	// Validate, if set, checks the upcast arguments
+	Validate func(args []interface{}) error
+	// From - offset of the first stored event checked
+	From uint64
+	// Last - number of the most recent events checked, 0 for every event
+	// from From on
+	Last int
+}
+
+// SchemaReport - result of DryRunSchemaMigration
+type SchemaReport struct {
+	Topic    string
+	Checked  int // events checked
+	Failures []SchemaFailure
+}
+
+// SchemaFailure - stored event the migration would fail on
+type SchemaFailure struct {
+	Offset uint64
+	ID     string // empty if the event couldn't be decoded
+	Err    error
+}
+
+// DryRunSchemaMigration checks a proposed payload schema change of a stored
+// topic (see WithStore) against its recent events before it is deployed:
+// every stored event is decoded, upcast and validated, and its arguments
+// checked against the parameters of migration.Handler after the
+// context.Context or Event it may take. The events failing any step are
+// reported. Nothing is published or delivered.
+// Returns error if the topic isn't stored, Handler is not a function, or the
+// store can't be read.
+func (bus *Bus) DryRunSchemaMigration(topic string, migration SchemaMigration) (SchemaReport, error) {
+	topic = bus.canonicalTopic(topic)
+	report := SchemaReport{Topic: topic}
+	if !bus.stored(topic) {
+		return report, fmt.Errorf("topic %s is not stored", topic)
+	}
+	fn := reflect.TypeOf(migration.Handler)
+	if fn == nil || fn.Kind() != reflect.Func {
+		return report, fmt.Errorf("handler of topic %s is not a function", topic)
+	}
+	var records []StoreRecord
+	err := bus.store.ReadFrom(topic, migration.From, func(record StoreRecord) error {
+		records = append(records, record)
+		if migration.Last > 0 && len(records) > migration.Last {
+			records = records[1:]
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+	for _, record := range records {
+		report.Checked++
+		if id, err := migration.check(fn, record); err != nil {
+			report.Failures = append(report.Failures, SchemaFailure{record.Offset, id, err})
+		}
+	}
+	return report, nil
+}
+
+// check decodes, upcasts and validates a stored event, returning its ID and
+// the error of the step it fails on
+func (migration *SchemaMigration) check(fn reflect.Type, record StoreRecord) (string, error) {
+	ev := Event{}
+	if err := gob.NewDecoder(bytes.NewReader(record.Data)).Decode(&ev); err != nil {
+		return "", fmt.Errorf("can't decode: %w", err)
+	}
+	args := ev.Args
+	for i, upcast := range migration.Upcasters {
+		var err error
+		if args, err = upcast(args); err != nil {
+			return ev.ID, fmt.Errorf("upcaster %d: %w", i, err)
+		}
+	}
+	if migration.Validate != nil {
+		if err := migration.Validate(args); err != nil {
+			return ev.ID, fmt.Errorf("invalid: %w", err)
+		}
+	}
+	return ev.ID, checkArguments(fn, args)
+}
+
+// checkArguments returns an error if args can't be delivered to a handler of
+// type fn, after the context.Context or Event it may take first
+func checkArguments(fn reflect.Type, args []interface{}) error {
+	first := 0
+	if fn.NumIn() > 0 && (fn.In(0) == contextType || fn.In(0) == eventType) {
+		first = 1
+		if fn.NumIn() == 1 && fn.In(0) == eventType {
+			return nil // takes the envelope alone
+		}
+	}
+	n := fn.NumIn() - first
+	if fn.IsVariadic() && len(args) < n-1 || !fn.IsVariadic() && len(args) != n {
+		return fmt.Errorf("%d arguments, handler takes %d", len(args), n)
+	}
+	for i, arg := range args {
+		param := parameterType(fn, first+i)
+		if arg == nil {
+			switch param.Kind() {
+			case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice:
+				continue
+			}
+			return fmt.Errorf("argument %d is nil, handler takes %s", i, param)
+		}
+		if !reflect.TypeOf(arg).AssignableTo(param) {
+			return fmt.Errorf("argument %d is %T, handler takes %s", i, arg, param)
+		}
+	}
+	return nil
+}
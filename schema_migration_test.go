@@ -0,0 +1,80 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type storedOrderV2 struct {
+	ID       int
+	Total    float64
+	Currency string
+}
+
+func init() {
+	gob.Register(storedOrderV2{})
+}
+
+func TestDryRunSchemaMigration(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	bus := New(WithStore(store, "orders"))
+	bus.Publish("orders", storedOrder{1, 10})
+	bus.PublishEvent(Event{Topic: "orders", ID: "negative", Args: []interface{}{storedOrder{2, -5}}})
+	bus.Publish("orders", storedOrder{3, 30}, "extra")
+	bus.Publish("orders", storedOrder{4, 40})
+
+	migration := SchemaMigration{
+		Handler: func(ctx context.Context, order storedOrderV2) {},
+		Upcasters: []Upcaster{func(args []interface{}) ([]interface{}, error) {
+			if len(args) == 0 {
+				return nil, errors.New("no order")
+			}
+			order, ok := args[0].(storedOrder)
+			if !ok {
+				return args, nil
+			}
+			return append([]interface{}{storedOrderV2{order.ID, order.Total, "EUR"}}, args[1:]...), nil
+		}},
+		Validate: func(args []interface{}) error {
+			if args[0].(storedOrderV2).Total < 0 {
+				return errors.New("negative total")
+			}
+			return nil
+		},
+	}
+	report, err := bus.DryRunSchemaMigration("orders", migration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 4 || len(report.Failures) != 2 {
+		t.Fatal(report)
+	}
+	if failure := report.Failures[0]; failure.Offset != 1 || failure.ID != "negative" || !strings.Contains(failure.Err.Error(), "negative total") {
+		t.Fatal(failure)
+	}
+	if failure := report.Failures[1]; failure.Offset != 2 || !strings.Contains(failure.Err.Error(), "2 arguments") {
+		t.Fatal(failure)
+	}
+
+	migration.Last = 2
+	if report, _ := bus.DryRunSchemaMigration("orders", migration); report.Checked != 2 || len(report.Failures) != 1 {
+		t.Fatal(report)
+	}
+	migration.Last, migration.Upcasters, migration.Validate = 0, nil, nil
+	if report, _ := bus.DryRunSchemaMigration("orders", migration); len(report.Failures) != 4 {
+		t.Fatal("old payloads accepted by the new handler", report)
+	}
+	if _, err := bus.DryRunSchemaMigration("carts", migration); err == nil {
+		t.Fatal("dry run of a topic that isn't stored")
+	}
+	if _, err := bus.DryRunSchemaMigration("orders", SchemaMigration{Handler: 1}); err == nil {
+		t.Fatal("dry run without a handler")
+	}
+}
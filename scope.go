@@ -0,0 +1,92 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+)
+
+// Scope - tracks the async deliveries caused by the events published in it,
+// transitively, see Bus.Scope
+type Scope struct {
+	bus     *Bus
+	ctx     context.Context
+	pending int           // async deliveries scheduled and not finished yet
+	idle    chan struct{} // closed when pending drops to zero
+	lock    sync.Mutex
+}
+
+// scopeKey - context key of the Scope of an event
+type scopeKey struct{}
+
+// scopeFrom returns the Scope of ctx, nil if it has none
+func scopeFrom(ctx context.Context) *Scope {
+	s, _ := ctx.Value(scopeKey{}).(*Scope)
+	return s
+}
+
+// Scope returns a scope whose events are published with ctx. The async
+// deliveries of these events are tracked, and so are the ones of the events
+// their handlers publish with the context they receive, and so on, so Wait
+// returns once everything an action caused on the bus has settled. Handlers
+// publishing without their context (or from goroutines of their own) leave
+// the scope.
+func (bus *Bus) Scope(ctx context.Context) *Scope {
+	s := &Scope{bus: bus}
+	s.ctx = context.WithValue(ctx, scopeKey{}, s)
+	return s
+}
+
+// Context returns the context of the scope, publishing with it (e.g. with
+// PublishCtx or PublishGather) publishes in the scope
+func (s *Scope) Context() context.Context {
+	return s.ctx
+}
+
+// Publish works like Bus.PublishCtx with the context of the scope
+func (s *Scope) Publish(topic string, args ...interface{}) error {
+	return s.bus.PublishCtx(s.ctx, topic, args...)
+}
+
+// Wait blocks until the async deliveries tracked by the scope have finished,
+// including the ones they caused in turn.
+// Returns ctx.Err() if ctx is done first.
+func (s *Scope) Wait(ctx context.Context) error {
+	s.lock.Lock()
+	if s.pending == 0 {
+		s.lock.Unlock()
+		return nil
+	}
+	idle := s.idle
+	s.lock.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// add records an async delivery about to be scheduled
+func (s *Scope) add() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.pending == 0 {
+		s.idle = make(chan struct{})
+	}
+	s.pending++
+}
+
+// release records the end of an async delivery
+func (s *Scope) release() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.pending--; s.pending == 0 {
+		close(s.idle)
+	}
+}
@@ -0,0 +1,54 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScope(t *testing.T) {
+	bus := New()
+	var settled atomic.Int32
+	bus.SubscribeAsync("order:placed", func(ctx context.Context, id int) {
+		bus.PublishCtx(ctx, "order:billed", id)
+	}, false)
+	bus.Subscribe("order:billed", func(ctx context.Context, id int) {
+		bus.PublishCtx(ctx, "order:shipped", id)
+	})
+	bus.SubscribeAsync("order:shipped", func(id int) {
+		time.Sleep(5 * time.Millisecond)
+		settled.Add(1)
+	}, false)
+
+	scope := bus.Scope(context.Background())
+	if scope.Wait(context.Background()) != nil {
+		t.Fatal("empty scope not settled")
+	}
+	for id := 0; id < 3; id++ {
+		if err := scope.Publish("order:placed", id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := scope.Wait(context.Background()); err != nil || settled.Load() != 3 {
+		t.Fatal(err, settled.Load())
+	}
+}
+
+func TestScopeWaitTimeout(t *testing.T) {
+	bus := New()
+	release := make(chan struct{})
+	bus.SubscribeAsync("slow", func() { <-release }, false)
+	scope := bus.Scope(context.Background())
+	scope.Publish("slow")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := scope.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	close(release)
+	if scope.Wait(context.Background()) != nil {
+		t.Fail()
+	}
+}
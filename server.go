@@ -53,8 +53,8 @@ func NewServer(address, path string, eventBus *Bus) *Server {
 	return server
 }
 
-func (server *Server) rpcCallback(subscribeArg *SubscribeArg) func(args ...interface{}) error {
-	return func(args ...interface{}) error {
+func (server *Server) rpcCallback(subscribeArg *SubscribeArg) func(ev Event) error {
+	return func(ev Event) error {
 		client, err := rpc.DialHTTPPath("tcp", subscribeArg.ClientAddr, subscribeArg.ClientPath)
 		defer client.Close()
 		if err != nil {
@@ -62,7 +62,9 @@ func (server *Server) rpcCallback(subscribeArg *SubscribeArg) func(args ...inter
 		}
 		clientArg := new(ClientArg)
 		clientArg.Topic = subscribeArg.Topic
-		clientArg.Args = args
+		clientArg.Args = ev.Args
+		clientArg.Deadline = ev.Deadline
+		clientArg.Provenance = ev.forwarded("rpc")
 		var reply bool
 		err = client.Call(subscribeArg.ServiceMethod, clientArg, &reply)
 		if err != nil {
@@ -0,0 +1,76 @@
+package eventbus
+
+import (
+	"hash/maphash"
+	"strings"
+	"sync"
+)
+
+// topicShards - number of shards the handler map is split into
+const topicShards = 32
+
+var shardSeed = maphash.MakeSeed()
+
+// topicShard - handlers of the handler map keys hashing to a shard. Its lock
+// is taken with the bus lock held shared: exclusively to change the handlers
+// of a plain topic, shared to read them. Holding the bus lock exclusively
+// gives access to every shard without their locks.
+type topicShard struct {
+	handlers map[string][]*eventHandler
+	sync.RWMutex
+}
+
+// shard returns the shard of a handler map key
+func (bus *Bus) shard(key string) *topicShard {
+	return &bus.shards[maphash.String(shardSeed, key)%topicShards]
+}
+
+// handlersOf returns the handlers of a handler map key; its shard lock or the
+// bus lock held exclusively must be held
+func (bus *Bus) handlersOf(key string) []*eventHandler {
+	return bus.shard(key).handlers[key]
+}
+
+// setHandlers replaces the handlers of a handler map key; its shard lock or
+// the bus lock must be held exclusively
+func (bus *Bus) setHandlers(key string, handlers []*eventHandler) {
+	shard := bus.shard(key)
+	if len(handlers) == 0 {
+		delete(shard.handlers, key)
+		return
+	}
+	if shard.handlers == nil {
+		shard.handlers = make(map[string][]*eventHandler)
+	}
+	shard.handlers[key] = handlers
+}
+
+// keys returns the handler map keys with handlers; the bus lock must be held
+// exclusively
+func (bus *Bus) keys() []string {
+	keys := make([]string, 0)
+	for i := range bus.shards {
+		for key := range bus.shards[i].handlers {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// lockTopic locks the bus to change the handlers of a handler map key and
+// returns the function unlocking it. A plain topic only takes its shard, so
+// publishes on the topics of other shards go on; wildcard patterns and regexes
+// take the whole bus, as every publish matches topics against them.
+func (bus *Bus) lockTopic(key string) (unlock func()) {
+	if bus.hierarchy.isPattern(key) || strings.HasPrefix(key, regexKeyPrefix) {
+		bus.lock.Lock()
+		return bus.lock.Unlock
+	}
+	bus.lock.RLock()
+	shard := bus.shard(key)
+	shard.Lock()
+	return func() {
+		shard.Unlock()
+		bus.lock.RUnlock()
+	}
+}
@@ -0,0 +1,166 @@
+package eventbus
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// TopicSLO - control topic receiving SLOEvent notifications
+	TopicSLO = "bus:slo"
+	// DefaultSLOWindow - default number of recent deliveries an SLO is evaluated on
+	DefaultSLOWindow = 100
+	// DefaultSLOQuantile - default quantile an SLO is evaluated at
+	DefaultSLOQuantile = 0.99
+)
+
+// SLO objectives reported in SLOEvent
+const (
+	ObjectiveHandlerLatency = "handler_latency"
+	ObjectiveDeliveryLag    = "delivery_lag"
+)
+
+// SLO - latency objectives of a topic, evaluated over a sliding window of deliveries
+type SLO struct {
+	// HandlerLatency is the maximum handler run time at Quantile; zero disables it
+	HandlerLatency time.Duration
+	// DeliveryLag is the maximum time from Publish to handler start at Quantile; zero disables it
+	DeliveryLag time.Duration
+	// Quantile evaluated, defaults to DefaultSLOQuantile
+	Quantile float64
+	// Window is the number of most recent deliveries evaluated, defaults to DefaultSLOWindow.
+	// Nothing is reported before the window is full.
+	Window int
+}
+
+// SLOEvent - published on TopicSLO when an objective starts or stops being breached
+type SLOEvent struct {
+	Topic     string
+	Objective string // ObjectiveHandlerLatency or ObjectiveDeliveryLag
+	Breached  bool   // false when the objective recovered
+	Quantile  float64
+	Threshold time.Duration
+	Observed  time.Duration
+}
+
+// latencyWindow keeps the last len(samples) durations, and the same
+// durations in order so quantiles are read without sorting
+type latencyWindow struct {
+	samples []time.Duration
+	sorted  []time.Duration
+	next    int
+	full    bool
+}
+
+func newLatencyWindow(size int) *latencyWindow {
+	return &latencyWindow{samples: make([]time.Duration, size), sorted: make([]time.Duration, 0, size)}
+}
+
+func (window *latencyWindow) add(sample time.Duration) {
+	if window.full {
+		evicted, _ := slices.BinarySearch(window.sorted, window.samples[window.next])
+		window.sorted = slices.Delete(window.sorted, evicted, evicted+1)
+	}
+	at, _ := slices.BinarySearch(window.sorted, sample)
+	window.sorted = slices.Insert(window.sorted, at, sample)
+	window.samples[window.next] = sample
+	window.next++
+	if window.next == len(window.samples) {
+		window.next = 0
+		window.full = true
+	}
+}
+
+func (window *latencyWindow) quantile(q float64) time.Duration {
+	n := len(window.sorted)
+	if n == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(q*float64(n))) - 1 // nearest rank
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= n {
+		idx = n - 1
+	}
+	return window.sorted[idx]
+}
+
+type sloTracker struct {
+	slo         SLO
+	latency     *latencyWindow
+	lag         *latencyWindow
+	latencyBust bool
+	lagBust     bool
+}
+
+type sloRegistry struct {
+	trackers map[string]*sloTracker
+	sync.Mutex
+}
+
+// SetSLO starts evaluating latency objectives of a topic. Breaches and
+// recoveries are published as SLOEvent on TopicSLO.
+func (bus *Bus) SetSLO(topic string, slo SLO) {
+	if slo.Quantile <= 0 || slo.Quantile > 1 {
+		slo.Quantile = DefaultSLOQuantile
+	}
+	if slo.Window <= 0 {
+		slo.Window = DefaultSLOWindow
+	}
+	bus.slos.Lock()
+	defer bus.slos.Unlock()
+	if bus.slos.trackers == nil {
+		bus.slos.trackers = make(map[string]*sloTracker)
+	}
+	bus.slos.trackers[topic] = &sloTracker{
+		slo:     slo,
+		latency: newLatencyWindow(slo.Window),
+		lag:     newLatencyWindow(slo.Window),
+	}
+}
+
+// RemoveSLO stops evaluating the objectives of a topic
+func (bus *Bus) RemoveSLO(topic string) {
+	bus.slos.Lock()
+	defer bus.slos.Unlock()
+	delete(bus.slos.trackers, topic)
+}
+
+func (bus *Bus) hasSLO(topic string) bool {
+	bus.slos.Lock()
+	defer bus.slos.Unlock()
+	_, ok := bus.slos.trackers[topic]
+	return ok
+}
+
+func (bus *Bus) observeSLO(topic string, lag, latency time.Duration) {
+	bus.slos.Lock()
+	defer bus.slos.Unlock()
+	tracker, ok := bus.slos.trackers[topic]
+	if !ok {
+		return
+	}
+	tracker.latency.add(latency)
+	tracker.lag.add(lag)
+	if !tracker.latency.full {
+		return
+	}
+	slo := tracker.slo
+	if slo.HandlerLatency > 0 {
+		observed := tracker.latency.quantile(slo.Quantile)
+		if breached := observed > slo.HandlerLatency; breached != tracker.latencyBust {
+			tracker.latencyBust = breached
+			bus.publishControl(TopicSLO, SLOEvent{topic, ObjectiveHandlerLatency, breached, slo.Quantile, slo.HandlerLatency, observed})
+		}
+	}
+	if slo.DeliveryLag > 0 {
+		observed := tracker.lag.quantile(slo.Quantile)
+		if breached := observed > slo.DeliveryLag; breached != tracker.lagBust {
+			tracker.lagBust = breached
+			bus.publishControl(TopicSLO, SLOEvent{topic, ObjectiveDeliveryLag, breached, slo.Quantile, slo.DeliveryLag, observed})
+		}
+	}
+}
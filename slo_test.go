@@ -0,0 +1,104 @@
+package eventbus
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSLO(t *testing.T) {
+	bus := New()
+	events := make(chan SLOEvent, 10)
+	bus.Subscribe(TopicSLO, func(event SLOEvent) {
+		events <- event
+	})
+
+	delay := time.Duration(0)
+	bus.Subscribe("topic", func() {
+		time.Sleep(delay)
+	})
+	bus.SetSLO("topic", SLO{HandlerLatency: 5 * time.Millisecond, Quantile: 0.5, Window: 4})
+
+	for i := 0; i < 4; i++ {
+		bus.Publish("topic")
+	}
+	bus.WaitAsync()
+	if len(events) != 0 {
+		t.Fatal("unexpected SLO event")
+	}
+
+	delay = 10 * time.Millisecond
+	for i := 0; i < 3; i++ {
+		bus.Publish("topic")
+	}
+	bus.WaitAsync()
+	if len(events) != 1 {
+		t.Fatal(len(events))
+	}
+	event := <-events
+	if !event.Breached || event.Topic != "topic" || event.Objective != ObjectiveHandlerLatency || event.Observed < 10*time.Millisecond {
+		t.Log(event)
+		t.Fail()
+	}
+
+	delay = 0
+	for i := 0; i < 4; i++ {
+		bus.Publish("topic")
+	}
+	bus.WaitAsync()
+	if len(events) != 1 || (<-events).Breached {
+		t.Fail()
+	}
+}
+
+func TestLatencyWindow(t *testing.T) {
+	window := newLatencyWindow(4)
+	if window.quantile(0.5) != 0 {
+		t.Fail()
+	}
+	for _, sample := range []time.Duration{4, 1, 3, 2, 5} {
+		window.add(sample)
+	}
+	if !window.full || window.quantile(1) != 5 || window.quantile(0.5) != 2 || window.quantile(0.1) != 1 {
+		t.Fail()
+	}
+}
+
+func TestLatencyWindowSorted(t *testing.T) {
+	window := newLatencyWindow(50)
+	for i := 0; i < 1000; i++ {
+		window.add(time.Duration(rand.Intn(20))) // duplicates included
+		n := len(window.sorted)
+		expected := slices.Clone(window.samples[:n])
+		if window.full {
+			expected = slices.Clone(window.samples)
+		}
+		slices.Sort(expected)
+		if !slices.Equal(window.sorted, expected) {
+			t.Fatal(window.sorted, expected)
+		}
+	}
+}
+
+func TestSLOEventsOrdered(t *testing.T) {
+	bus := New()
+	var breached []bool
+	bus.Subscribe(TopicSLO, func(event SLOEvent) {
+		breached = append(breached, event.Breached)
+	})
+	bus.SetSLO("topic", SLO{DeliveryLag: time.Millisecond, Quantile: 1, Window: 1})
+	for i := 0; i < 50; i++ {
+		bus.observeSLO("topic", 2*time.Millisecond, 0)
+		bus.observeSLO("topic", 0, 0)
+	}
+	bus.WaitAsync()
+	if len(breached) != 100 {
+		t.Fatal(len(breached))
+	}
+	for i, b := range breached {
+		if b != (i%2 == 0) {
+			t.Fatal(breached) // a recovery delivered before its breach
+		}
+	}
+}
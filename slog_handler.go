@@ -0,0 +1,151 @@
+package eventbus
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// DefaultLoggerKey - attribute key holding the logger name of a record
+const DefaultLoggerKey = "logger"
+
+// LogRecord - log record published by SlogHandler
+type LogRecord struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Logger  string
+	Attrs   map[string]interface{} // group-qualified keys, e.g. "request.id"
+}
+
+// SlogHandlerOptions - configuration of a SlogHandler
+type SlogHandlerOptions struct {
+	// Level is the minimum level published; defaults to slog.LevelInfo
+	Level slog.Leveler
+	// LoggerKey is the attribute naming the logger; defaults to DefaultLoggerKey
+	LoggerKey string
+	// Topic returns the topic a record is published on; defaults to
+	// "log:<level>" or "log:<logger>:<level>" when the record has a logger name
+	Topic func(record LogRecord) string
+}
+
+// SlogHandler - slog.Handler publishing log records as events on a bus
+type SlogHandler struct {
+	bus    *Bus
+	opts   SlogHandlerOptions
+	attrs  map[string]interface{}
+	groups []string
+}
+
+// NewSlogHandler - create a slog.Handler publishing records on the bus
+func NewSlogHandler(bus *Bus, opts *SlogHandlerOptions) *SlogHandler {
+	handler := &SlogHandler{bus: bus, attrs: make(map[string]interface{})}
+	if opts != nil {
+		handler.opts = *opts
+	}
+	if handler.opts.Level == nil {
+		handler.opts.Level = slog.LevelInfo
+	}
+	if handler.opts.LoggerKey == "" {
+		handler.opts.LoggerKey = DefaultLoggerKey
+	}
+	if handler.opts.Topic == nil {
+		handler.opts.Topic = defaultLogTopic
+	}
+	return handler
+}
+
+func defaultLogTopic(record LogRecord) string {
+	level := strings.ToLower(record.Level.String())
+	if record.Logger != "" {
+		return "log:" + record.Logger + ":" + level
+	}
+	return "log:" + level
+}
+
+// Enabled reports whether records at the level are published
+func (handler *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= handler.opts.Level.Level()
+}
+
+// Handle publishes the record on the topic chosen by the options. Returns the
+// error of the publish, e.g. ErrBusClosed once the bus is closed.
+func (handler *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	record := LogRecord{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Attrs:   make(map[string]interface{}, len(handler.attrs)+r.NumAttrs()),
+	}
+	for key, value := range handler.attrs {
+		record.Attrs[key] = value
+	}
+	prefix := handler.prefix()
+	r.Attrs(func(attr slog.Attr) bool {
+		addAttr(record.Attrs, prefix, attr)
+		return true
+	})
+	if logger, ok := record.Attrs[handler.opts.LoggerKey].(string); ok {
+		record.Logger = logger
+	}
+	return handler.bus.Publish(handler.opts.Topic(record), record)
+}
+
+// WithAttrs returns a handler adding attrs to every record
+func (handler *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := handler.clone()
+	prefix := handler.prefix()
+	for _, attr := range attrs {
+		addAttr(clone.attrs, prefix, attr)
+	}
+	return clone
+}
+
+// WithGroup returns a handler qualifying subsequent attributes with name
+func (handler *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+	clone := handler.clone()
+	clone.groups = append(clone.groups, name)
+	return clone
+}
+
+func (handler *SlogHandler) clone() *SlogHandler {
+	clone := &SlogHandler{
+		bus:    handler.bus,
+		opts:   handler.opts,
+		attrs:  make(map[string]interface{}, len(handler.attrs)),
+		groups: append([]string(nil), handler.groups...),
+	}
+	for key, value := range handler.attrs {
+		clone.attrs[key] = value
+	}
+	return clone
+}
+
+func (handler *SlogHandler) prefix() string {
+	if len(handler.groups) == 0 {
+		return ""
+	}
+	return strings.Join(handler.groups, ".") + "."
+}
+
+// addAttr stores attr in attrs, flattening groups into dotted keys
+func addAttr(attrs map[string]interface{}, prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range value.Group() {
+			addAttr(attrs, prefix, member)
+		}
+		return
+	}
+	if attr.Key == "" {
+		return
+	}
+	attrs[prefix+attr.Key] = value.Any()
+}
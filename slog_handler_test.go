@@ -0,0 +1,71 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSlogHandler(t *testing.T) {
+	bus := New()
+	logger := slog.New(NewSlogHandler(bus, nil))
+
+	var errors []LogRecord
+	bus.Subscribe("log:db:error", func(record LogRecord) {
+		errors = append(errors, record)
+	})
+	infos := 0
+	bus.Subscribe("log:info", func(record LogRecord) {
+		infos++
+	})
+
+	logger.Debug("not published")
+	logger.Info("started", "port", 8080)
+	logger.With("logger", "db").WithGroup("query").Error("failed", "table", "users")
+
+	if infos != 1 {
+		t.Fail()
+	}
+	if len(errors) != 1 {
+		t.Fatal(errors)
+	}
+	record := errors[0]
+	if record.Message != "failed" || record.Logger != "db" || record.Level != slog.LevelError {
+		t.Log(record)
+		t.Fail()
+	}
+	if record.Attrs["query.table"] != "users" {
+		t.Log(record.Attrs)
+		t.Fail()
+	}
+}
+
+func TestSlogHandlerOptions(t *testing.T) {
+	bus := New()
+	logger := slog.New(NewSlogHandler(bus, &SlogHandlerOptions{
+		Level: slog.LevelWarn,
+		Topic: func(record LogRecord) string { return "alerts" },
+	}))
+
+	count := 0
+	bus.Subscribe("alerts", func(record LogRecord) {
+		count++
+	})
+	logger.Info("ignored")
+	logger.Warn("disk almost full")
+	if count != 1 {
+		t.Fail()
+	}
+}
+
+func TestSlogHandlerPublishError(t *testing.T) {
+	bus := New()
+	handler := NewSlogHandler(bus, nil)
+	bus.Close(context.Background())
+	err := handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "closed", 0))
+	if !errors.Is(err, ErrBusClosed) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,74 @@
+package eventbus
+
+import (
+	"fmt"
+	"time"
+)
+
+// TopicSlowConsumer - topic on which SlowConsumer notifications are published
+const TopicSlowConsumer = "bus:slow_consumer"
+
+// SlowConsumer - async subscription unsubscribed because its queue stayed
+// over the limit for longer than the grace period
+type SlowConsumer struct {
+	Topic   string
+	Handler string
+	Pending int           // deliveries queued or running when it was evicted
+	Over    time.Duration // time spent over the limit
+}
+
+// slowConsumers - limits set by WithSlowConsumerEviction
+type slowConsumers struct {
+	limit int
+	grace time.Duration
+}
+
+// WithSlowConsumerEviction unsubscribes async handlers with more than limit
+// deliveries queued or running for longer than grace, publishes a
+// SlowConsumer on TopicSlowConsumer and puts them in quarantine (see
+// Quarantine), so a leaked or deadlocked handler can't grow the process
+// forever. Evicted handlers don't receive any new event; the deliveries
+// already queued still run.
+func WithSlowConsumerEviction(limit int, grace time.Duration) Option {
+	return func(bus *Bus) {
+		bus.slowConsumers = slowConsumers{limit, grace}
+	}
+}
+
+// queue counts a new async delivery to a handler, returning false if the
+// handler is evicted as a slow consumer; the bus lock must be held
+func (bus *Bus) queue(handler *eventHandler, topic string) bool {
+	if bus.slowConsumers.limit <= 0 {
+		return true
+	}
+	if handler.evicted.Load() {
+		return false
+	}
+	pending := int(handler.pending.Add(1))
+	if pending <= bus.slowConsumers.limit {
+		handler.overSince.Store(0)
+		return true
+	}
+	now := time.Now()
+	handler.overSince.CompareAndSwap(0, now.UnixNano())
+	over := now.Sub(time.Unix(0, handler.overSince.Load()))
+	if over <= bus.slowConsumers.grace || !handler.evicted.CompareAndSwap(false, true) {
+		return true
+	}
+	handler.pending.Add(-1)
+	bus.evict(handler, func(topic string) {
+		bus.quarantineLocked(topic, handler, "slow consumer", fmt.Errorf("%d deliveries pending for %s", pending-1, over))
+	})
+	bus.publishControl(TopicSlowConsumer, SlowConsumer{topic, handlerName(handler.callBack.Pointer()), pending - 1, over})
+	return false
+}
+
+// dequeue counts the end of an async delivery to a handler
+func (bus *Bus) dequeue(handler *eventHandler) {
+	if bus.slowConsumers.limit <= 0 {
+		return
+	}
+	if int(handler.pending.Add(-1)) <= bus.slowConsumers.limit {
+		handler.overSince.Store(0)
+	}
+}
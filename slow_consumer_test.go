@@ -0,0 +1,63 @@
+package eventbus
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSlowConsumerEviction(t *testing.T) {
+	bus := New(WithSlowConsumerEviction(1, 20*time.Millisecond))
+	evicted := make(chan SlowConsumer, 1)
+	bus.Subscribe(TopicSlowConsumer, func(event SlowConsumer) {
+		evicted <- event
+	})
+	release := make(chan struct{})
+	var calls atomic.Int32
+	bus.SubscribeAsync("topic", func(int) {
+		calls.Add(1)
+		<-release
+	}, false)
+
+	bus.Publish("topic", 1)
+	bus.Publish("topic", 2)
+	bus.Publish("topic", 3)
+	time.Sleep(30 * time.Millisecond)
+	bus.Publish("topic", 4)
+	close(release)
+	bus.WaitAsync()
+
+	select {
+	case event := <-evicted:
+		if event.Topic != "topic" || event.Pending < 1 || event.Over < 20*time.Millisecond {
+			t.Fatal(event)
+		}
+	default:
+		t.Fatal("no slow consumer notification")
+	}
+	if bus.HasCallback("topic") {
+		t.Fail()
+	}
+	if list := bus.Quarantine(); len(list) != 1 || list[0].Reason != "slow consumer" {
+		t.Fatal(list)
+	}
+	if calls.Load() != 3 {
+		t.Fatal(calls.Load())
+	}
+}
+
+func TestSlowConsumerRecovers(t *testing.T) {
+	bus := New(WithSlowConsumerEviction(1, 20*time.Millisecond))
+	bus.SubscribeAsync("topic", func(int) {
+		time.Sleep(5 * time.Millisecond)
+	}, false)
+	bus.Publish("topic", 1)
+	bus.Publish("topic", 2)
+	bus.WaitAsync()
+	time.Sleep(30 * time.Millisecond)
+	bus.Publish("topic", 3)
+	bus.WaitAsync()
+	if !bus.HasCallback("topic") {
+		t.Fail()
+	}
+}
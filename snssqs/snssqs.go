@@ -0,0 +1,480 @@
+// Package snssqs emulates the Amazon SNS and SQS clients
+// (github.com/aws/aws-sdk-go-v2/service/sns and .../sqs) on an event bus, so
+// code publishing to SNS topics and consuming SQS queues can run in-process,
+// locally or in tests, without network emulators.
+//
+// Types and methods mirror the part of the clients most code uses, without
+// depending on them (plain strings and ints replace the SDK's pointers): code
+// calling the clients through a small interface of its own switches with an
+// adapter of a few lines. SNS topics are bus topics named after their ARN and
+// queues bus topics named after their URL; an SQS subscription to an SNS
+// topic forwards its notifications to the queue, wrapped in the SNS JSON
+// envelope unless the subscription has RawMessageDelivery set.
+package snssqs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+const (
+	// Region - region of the emulated ARNs and queue URLs
+	Region = "local"
+	// Account - account ID of the emulated ARNs and queue URLs
+	Account = "000000000000"
+	// DefaultVisibilityTimeout - visibility timeout of the queues created without one
+	DefaultVisibilityTimeout = 30 * time.Second
+)
+
+var (
+	// ErrNotFound - error of the operations on a topic, subscription or queue
+	// that doesn't exist
+	ErrNotFound = errors.New("not found")
+	// ErrReceiptHandleInvalid - error deleting a message with a receipt handle
+	// that isn't its latest one
+	ErrReceiptHandleInvalid = errors.New("receipt handle invalid")
+)
+
+// Notification - message published on an SNS topic, the payload of the
+// events of its bus topic
+type Notification struct {
+	MessageId         string
+	TopicArn          string
+	Subject           string
+	Message           string
+	MessageAttributes map[string]string
+	Timestamp         time.Time
+}
+
+// CreateTopicInput - input of SNS.CreateTopic
+type CreateTopicInput struct {
+	Name string
+}
+
+// CreateTopicOutput - output of SNS.CreateTopic
+type CreateTopicOutput struct {
+	TopicArn string
+}
+
+// PublishInput - input of SNS.Publish
+type PublishInput struct {
+	TopicArn          string
+	Message           string
+	Subject           string
+	MessageAttributes map[string]string
+}
+
+// PublishOutput - output of SNS.Publish
+type PublishOutput struct {
+	MessageId string
+}
+
+// SubscribeInput - input of SNS.Subscribe
+type SubscribeInput struct {
+	TopicArn string
+	Protocol string // "sqs", the only protocol supported
+	Endpoint string // ARN or URL of the queue
+	// Attributes - "RawMessageDelivery": "true" forwards the message without
+	// the SNS JSON envelope
+	Attributes map[string]string
+}
+
+// SubscribeOutput - output of SNS.Subscribe
+type SubscribeOutput struct {
+	SubscriptionArn string
+}
+
+// UnsubscribeInput - input of SNS.Unsubscribe
+type UnsubscribeInput struct {
+	SubscriptionArn string
+}
+
+// UnsubscribeOutput - output of SNS.Unsubscribe
+type UnsubscribeOutput struct{}
+
+// SNS - Amazon SNS client backed by an event bus
+type SNS struct {
+	bus    *eventbus.Bus
+	topics map[string]bool
+	subs   map[string]*eventbus.Subscription
+	lock   sync.Mutex
+}
+
+// NewSNS returns an SNS client whose topics live on bus
+func NewSNS(bus *eventbus.Bus) *SNS {
+	return &SNS{bus: bus, topics: make(map[string]bool), subs: make(map[string]*eventbus.Subscription)}
+}
+
+// CreateTopic creates a topic, or returns the ARN of the existing one
+func (c *SNS) CreateTopic(ctx context.Context, in *CreateTopicInput) (*CreateTopicOutput, error) {
+	arn := fmt.Sprintf("arn:aws:sns:%s:%s:%s", Region, Account, in.Name)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.topics[arn] = true
+	return &CreateTopicOutput{TopicArn: arn}, nil
+}
+
+// Publish publishes a message on a topic, which its subscriptions receive
+// synchronously.
+// Returns ErrNotFound if the topic doesn't exist.
+func (c *SNS) Publish(ctx context.Context, in *PublishInput) (*PublishOutput, error) {
+	c.lock.Lock()
+	exists := c.topics[in.TopicArn]
+	c.lock.Unlock()
+	if !exists {
+		return nil, fmt.Errorf("topic %s: %w", in.TopicArn, ErrNotFound)
+	}
+	notification := Notification{
+		MessageId:         c.bus.NewID(),
+		TopicArn:          in.TopicArn,
+		Subject:           in.Subject,
+		Message:           in.Message,
+		MessageAttributes: in.MessageAttributes,
+		Timestamp:         time.Now(),
+	}
+	if err := c.bus.PublishCtx(ctx, in.TopicArn, notification); err != nil {
+		return nil, err
+	}
+	return &PublishOutput{MessageId: notification.MessageId}, nil
+}
+
+// Subscribe subscribes an SQS queue to a topic.
+// Returns ErrNotFound if the topic doesn't exist, or error if the protocol
+// isn't "sqs".
+func (c *SNS) Subscribe(ctx context.Context, in *SubscribeInput) (*SubscribeOutput, error) {
+	if in.Protocol != "sqs" {
+		return nil, fmt.Errorf("unsupported protocol %q", in.Protocol)
+	}
+	queueURL := in.Endpoint
+	if name, ok := strings.CutPrefix(in.Endpoint, fmt.Sprintf("arn:aws:sqs:%s:%s:", Region, Account)); ok {
+		queueURL = QueueURL(name)
+	}
+	raw := in.Attributes["RawMessageDelivery"] == "true"
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !c.topics[in.TopicArn] {
+		return nil, fmt.Errorf("topic %s: %w", in.TopicArn, ErrNotFound)
+	}
+	handle, err := c.bus.SubscribeHandle(in.TopicArn, func(ctx context.Context, n Notification) error {
+		body, attributes := n.Message, n.MessageAttributes
+		if !raw {
+			body, attributes = envelope(n), nil
+		}
+		return c.bus.PublishCtx(ctx, queueURL, Message{MessageId: c.bus.NewID(), Body: body, MessageAttributes: attributes})
+	})
+	if err != nil {
+		return nil, err
+	}
+	arn := in.TopicArn + ":" + c.bus.NewID()
+	c.subs[arn] = handle
+	return &SubscribeOutput{SubscriptionArn: arn}, nil
+}
+
+// Unsubscribe deletes a subscription.
+// Returns ErrNotFound if it doesn't exist.
+func (c *SNS) Unsubscribe(ctx context.Context, in *UnsubscribeInput) (*UnsubscribeOutput, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	handle, ok := c.subs[in.SubscriptionArn]
+	if !ok {
+		return nil, fmt.Errorf("subscription %s: %w", in.SubscriptionArn, ErrNotFound)
+	}
+	handle.Unsubscribe()
+	delete(c.subs, in.SubscriptionArn)
+	return &UnsubscribeOutput{}, nil
+}
+
+// envelope returns the JSON document SNS delivers a notification as
+func envelope(n Notification) string {
+	type attribute struct {
+		Type  string
+		Value string
+	}
+	attributes := make(map[string]attribute, len(n.MessageAttributes))
+	for name, value := range n.MessageAttributes {
+		attributes[name] = attribute{"String", value}
+	}
+	doc, _ := json.Marshal(struct {
+		Type              string
+		MessageId         string
+		TopicArn          string
+		Subject           string `json:",omitempty"`
+		Message           string
+		Timestamp         string
+		MessageAttributes map[string]attribute `json:",omitempty"`
+	}{"Notification", n.MessageId, n.TopicArn, n.Subject, n.Message, n.Timestamp.UTC().Format(time.RFC3339Nano), attributes})
+	return string(doc)
+}
+
+// QueueURL returns the URL of a queue, which is its bus topic
+func QueueURL(name string) string {
+	return fmt.Sprintf("http://sqs.%s/%s/%s", Region, Account, name)
+}
+
+// Message - SQS message
+type Message struct {
+	MessageId         string
+	ReceiptHandle     string
+	Body              string
+	MessageAttributes map[string]string
+	// Attributes - "ApproximateReceiveCount" and "SentTimestamp" (Unix milliseconds)
+	Attributes map[string]string
+}
+
+// CreateQueueInput - input of SQS.CreateQueue
+type CreateQueueInput struct {
+	QueueName string
+	// Attributes - "VisibilityTimeout" in seconds, DefaultVisibilityTimeout if not set
+	Attributes map[string]string
+}
+
+// CreateQueueOutput - output of SQS.CreateQueue
+type CreateQueueOutput struct {
+	QueueUrl string
+}
+
+// DeleteQueueInput - input of SQS.DeleteQueue
+type DeleteQueueInput struct {
+	QueueUrl string
+}
+
+// DeleteQueueOutput - output of SQS.DeleteQueue
+type DeleteQueueOutput struct{}
+
+// SendMessageInput - input of SQS.SendMessage
+type SendMessageInput struct {
+	QueueUrl          string
+	MessageBody       string
+	MessageAttributes map[string]string
+	DelaySeconds      int32
+}
+
+// SendMessageOutput - output of SQS.SendMessage
+type SendMessageOutput struct {
+	MessageId string
+}
+
+// ReceiveMessageInput - input of SQS.ReceiveMessage
+type ReceiveMessageInput struct {
+	QueueUrl            string
+	MaxNumberOfMessages int32 // 1 if zero, 10 at most
+	WaitTimeSeconds     int32 // long polling, 20 at most
+	VisibilityTimeout   int32 // seconds, the visibility timeout of the queue if zero
+}
+
+// ReceiveMessageOutput - output of SQS.ReceiveMessage
+type ReceiveMessageOutput struct {
+	Messages []Message
+}
+
+// DeleteMessageInput - input of SQS.DeleteMessage
+type DeleteMessageInput struct {
+	QueueUrl      string
+	ReceiptHandle string
+}
+
+// DeleteMessageOutput - output of SQS.DeleteMessage
+type DeleteMessageOutput struct{}
+
+// SQS - Amazon SQS client backed by an event bus
+type SQS struct {
+	bus    *eventbus.Bus
+	queues map[string]*queue
+	lock   sync.Mutex
+}
+
+// queue - messages of an SQS queue
+type queue struct {
+	visibility time.Duration
+	handle     *eventbus.Subscription
+	messages   []*queued
+	arrived    chan struct{} // signaled when a message is queued
+	lock       sync.Mutex
+}
+
+// queued - message of a queue, invisible until visibleAt
+type queued struct {
+	msg       Message
+	sent      time.Time
+	visibleAt time.Time
+	receives  int
+}
+
+// NewSQS returns an SQS client whose queues live on bus
+func NewSQS(bus *eventbus.Bus) *SQS {
+	return &SQS{bus: bus, queues: make(map[string]*queue)}
+}
+
+// CreateQueue creates a queue, or returns the URL of the existing one.
+// Returns error if the visibility timeout attribute isn't a number of seconds.
+func (c *SQS) CreateQueue(ctx context.Context, in *CreateQueueInput) (*CreateQueueOutput, error) {
+	url := QueueURL(in.QueueName)
+	visibility := DefaultVisibilityTimeout
+	if seconds, ok := in.Attributes["VisibilityTimeout"]; ok {
+		n, err := strconv.Atoi(seconds)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid VisibilityTimeout %q", seconds)
+		}
+		visibility = time.Duration(n) * time.Second
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.queues[url]; ok {
+		return &CreateQueueOutput{QueueUrl: url}, nil
+	}
+	q := &queue{visibility: visibility, arrived: make(chan struct{}, 1)}
+	handle, err := c.bus.SubscribeHandle(url, q.push)
+	if err != nil {
+		return nil, err
+	}
+	q.handle = handle
+	c.queues[url] = q
+	return &CreateQueueOutput{QueueUrl: url}, nil
+}
+
+// DeleteQueue deletes a queue and its messages.
+// Returns ErrNotFound if it doesn't exist.
+func (c *SQS) DeleteQueue(ctx context.Context, in *DeleteQueueInput) (*DeleteQueueOutput, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	q, ok := c.queues[in.QueueUrl]
+	if !ok {
+		return nil, fmt.Errorf("queue %s: %w", in.QueueUrl, ErrNotFound)
+	}
+	q.handle.Unsubscribe()
+	delete(c.queues, in.QueueUrl)
+	return &DeleteQueueOutput{}, nil
+}
+
+// SendMessage sends a message to a queue, published on its bus topic after
+// DelaySeconds.
+// Returns ErrNotFound if the queue doesn't exist.
+func (c *SQS) SendMessage(ctx context.Context, in *SendMessageInput) (*SendMessageOutput, error) {
+	if _, err := c.queue(in.QueueUrl); err != nil {
+		return nil, err
+	}
+	msg := Message{MessageId: c.bus.NewID(), Body: in.MessageBody, MessageAttributes: in.MessageAttributes}
+	if in.DelaySeconds > 0 {
+		time.AfterFunc(time.Duration(in.DelaySeconds)*time.Second, func() { c.bus.Publish(in.QueueUrl, msg) })
+	} else if err := c.bus.PublishCtx(ctx, in.QueueUrl, msg); err != nil {
+		return nil, err
+	}
+	return &SendMessageOutput{MessageId: msg.MessageId}, nil
+}
+
+// ReceiveMessage receives the visible messages of a queue, waiting up to
+// WaitTimeSeconds for one if there are none, and hides them for the
+// visibility timeout: they are received again unless deleted by then.
+// Returns ErrNotFound if the queue doesn't exist, or ctx.Err() if ctx is done
+// while waiting.
+func (c *SQS) ReceiveMessage(ctx context.Context, in *ReceiveMessageInput) (*ReceiveMessageOutput, error) {
+	q, err := c.queue(in.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	limit := min(max(int(in.MaxNumberOfMessages), 1), 10)
+	visibility := q.visibility
+	if in.VisibilityTimeout > 0 {
+		visibility = time.Duration(in.VisibilityTimeout) * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(min(in.WaitTimeSeconds, 20)) * time.Second)
+	for {
+		messages, next := q.receive(c.bus, limit, visibility)
+		wait := time.Until(deadline)
+		if len(messages) > 0 || wait <= 0 {
+			return &ReceiveMessageOutput{Messages: messages}, nil
+		}
+		if !next.IsZero() {
+			wait = min(wait, time.Until(next))
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-q.arrived:
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+		timer.Stop()
+	}
+}
+
+// DeleteMessage deletes a received message.
+// Returns ErrNotFound if the queue doesn't exist, or ErrReceiptHandleInvalid
+// if the receipt handle isn't the latest one of a message of the queue.
+func (c *SQS) DeleteMessage(ctx context.Context, in *DeleteMessageInput) (*DeleteMessageOutput, error) {
+	q, err := c.queue(in.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for i, m := range q.messages {
+		if m.msg.ReceiptHandle == in.ReceiptHandle && in.ReceiptHandle != "" {
+			q.messages = append(q.messages[:i], q.messages[i+1:]...)
+			return &DeleteMessageOutput{}, nil
+		}
+	}
+	return nil, ErrReceiptHandleInvalid
+}
+
+// queue returns the queue with the given URL.
+// Returns ErrNotFound if it doesn't exist.
+func (c *SQS) queue(url string) (*queue, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	q, ok := c.queues[url]
+	if !ok {
+		return nil, fmt.Errorf("queue %s: %w", url, ErrNotFound)
+	}
+	return q, nil
+}
+
+// push queues a message published on the bus topic of the queue
+func (q *queue) push(msg Message) {
+	now := time.Now()
+	q.lock.Lock()
+	q.messages = append(q.messages, &queued{msg: msg, sent: now, visibleAt: now})
+	q.lock.Unlock()
+	select {
+	case q.arrived <- struct{}{}:
+	default:
+	}
+}
+
+// receive returns up to limit visible messages, hidden for visibility, and
+// the time the next hidden message becomes visible (zero if there is none)
+func (q *queue) receive(bus *eventbus.Bus, limit int, visibility time.Duration) (messages []Message, next time.Time) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	now := time.Now()
+	for _, m := range q.messages {
+		if m.visibleAt.After(now) {
+			if next.IsZero() || m.visibleAt.Before(next) {
+				next = m.visibleAt
+			}
+			continue
+		}
+		if len(messages) == limit {
+			continue
+		}
+		m.receives++
+		m.visibleAt = now.Add(visibility)
+		m.msg.ReceiptHandle = bus.NewID()
+		msg := m.msg
+		msg.Attributes = map[string]string{
+			"ApproximateReceiveCount": strconv.Itoa(m.receives),
+			"SentTimestamp":           strconv.FormatInt(m.sent.UnixMilli(), 10),
+		}
+		messages = append(messages, msg)
+	}
+	return messages, next
+}
@@ -0,0 +1,100 @@
+package snssqs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	eventbus "github.com/asaskevich/EventBus"
+)
+
+func TestSendReceiveDelete(t *testing.T) {
+	ctx := context.Background()
+	sqs := NewSQS(eventbus.New())
+	if _, err := sqs.SendMessage(ctx, &SendMessageInput{QueueUrl: QueueURL("jobs")}); !errors.Is(err, ErrNotFound) {
+		t.Fatal(err)
+	}
+	queue, err := sqs.CreateQueue(ctx, &CreateQueueInput{QueueName: "jobs", Attributes: map[string]string{"VisibilityTimeout": "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent, err := sqs.SendMessage(ctx, &SendMessageInput{QueueUrl: queue.QueueUrl, MessageBody: "job 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received, err := sqs.ReceiveMessage(ctx, &ReceiveMessageInput{QueueUrl: queue.QueueUrl, MaxNumberOfMessages: 10})
+	if err != nil || len(received.Messages) != 1 {
+		t.Fatal(received, err)
+	}
+	msg := received.Messages[0]
+	if msg.MessageId != sent.MessageId || msg.Body != "job 1" || msg.Attributes["ApproximateReceiveCount"] != "1" {
+		t.Fatal(msg)
+	}
+	if hidden, _ := sqs.ReceiveMessage(ctx, &ReceiveMessageInput{QueueUrl: queue.QueueUrl}); len(hidden.Messages) != 0 {
+		t.Fatal("received message still visible", hidden)
+	}
+
+	// long polling returns once the visibility timeout expires
+	again, err := sqs.ReceiveMessage(ctx, &ReceiveMessageInput{QueueUrl: queue.QueueUrl, WaitTimeSeconds: 2})
+	if err != nil || len(again.Messages) != 1 || again.Messages[0].Attributes["ApproximateReceiveCount"] != "2" {
+		t.Fatal(again, err)
+	}
+	if _, err := sqs.DeleteMessage(ctx, &DeleteMessageInput{QueueUrl: queue.QueueUrl, ReceiptHandle: msg.ReceiptHandle}); !errors.Is(err, ErrReceiptHandleInvalid) {
+		t.Fatal(err)
+	}
+	if _, err := sqs.DeleteMessage(ctx, &DeleteMessageInput{QueueUrl: queue.QueueUrl, ReceiptHandle: again.Messages[0].ReceiptHandle}); err != nil {
+		t.Fatal(err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := sqs.ReceiveMessage(waitCtx, &ReceiveMessageInput{QueueUrl: queue.QueueUrl, WaitTimeSeconds: 20}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+}
+
+func TestTopicToQueue(t *testing.T) {
+	ctx := context.Background()
+	bus := eventbus.New()
+	sns, sqs := NewSNS(bus), NewSQS(bus)
+	topic, _ := sns.CreateTopic(ctx, &CreateTopicInput{Name: "orders"})
+	wrapped, _ := sqs.CreateQueue(ctx, &CreateQueueInput{QueueName: "billing"})
+	raw, _ := sqs.CreateQueue(ctx, &CreateQueueInput{QueueName: "shipping"})
+	if _, err := sns.Subscribe(ctx, &SubscribeInput{TopicArn: topic.TopicArn, Protocol: "sqs", Endpoint: "arn:aws:sqs:local:000000000000:billing"}); err != nil {
+		t.Fatal(err)
+	}
+	sub, err := sns.Subscribe(ctx, &SubscribeInput{TopicArn: topic.TopicArn, Protocol: "sqs", Endpoint: raw.QueueUrl, Attributes: map[string]string{"RawMessageDelivery": "true"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published, err := sns.Publish(ctx, &PublishInput{TopicArn: topic.TopicArn, Message: "order 1", MessageAttributes: map[string]string{"tenant": "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	received, _ := sqs.ReceiveMessage(ctx, &ReceiveMessageInput{QueueUrl: wrapped.QueueUrl})
+	var notification struct {
+		Type, MessageId, TopicArn, Message string
+	}
+	if len(received.Messages) != 1 || json.Unmarshal([]byte(received.Messages[0].Body), &notification) != nil {
+		t.Fatal(received)
+	}
+	if notification.Type != "Notification" || notification.MessageId != published.MessageId || notification.Message != "order 1" {
+		t.Fatal(notification)
+	}
+	received, _ = sqs.ReceiveMessage(ctx, &ReceiveMessageInput{QueueUrl: raw.QueueUrl})
+	if len(received.Messages) != 1 || received.Messages[0].Body != "order 1" || received.Messages[0].MessageAttributes["tenant"] != "a" {
+		t.Fatal(received)
+	}
+
+	if _, err := sns.Unsubscribe(ctx, &UnsubscribeInput{SubscriptionArn: sub.SubscriptionArn}); err != nil {
+		t.Fatal(err)
+	}
+	sns.Publish(ctx, &PublishInput{TopicArn: topic.TopicArn, Message: "order 2"})
+	if received, _ := sqs.ReceiveMessage(ctx, &ReceiveMessageInput{QueueUrl: raw.QueueUrl}); len(received.Messages) != 0 {
+		t.Fatal(received)
+	}
+}
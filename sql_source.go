@@ -0,0 +1,102 @@
+package eventbus
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+)
+
+// Data change operations reported by SQLSource
+const (
+	OpInsert = "insert"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// DataChange - data change event published by SQLSource
+type DataChange struct {
+	Table        string
+	Op           string // OpInsert, OpUpdate or OpDelete
+	Query        string // empty for explicit notifications
+	Args         []interface{}
+	RowsAffected int64
+}
+
+var dataChangeQuery = regexp.MustCompile("(?is)^\\s*(insert\\s+(?:or\\s+\\w+\\s+)?into|update|delete\\s+from)\\s+[\"`\\[]?([\\w.]+)")
+
+// SQLSource - *sql.DB wrapper publishing a DataChange event after every
+// successful INSERT, UPDATE or DELETE against one of the configured tables.
+// Statements are matched by inspecting the query text, so only Exec and
+// ExecContext are observed; changes made in transactions or through stored
+// procedures can be announced explicitly with Notify.
+type SQLSource struct {
+	*sql.DB
+	bus    *Bus
+	tables map[string]bool
+	// Topic returns the topic a change is published on; defaults to "sql:<table>:<op>"
+	Topic func(change DataChange) string
+}
+
+// NewSQLSource - wrap db, publishing changes to tables on the bus
+func NewSQLSource(db *sql.DB, bus *Bus, tables ...string) *SQLSource {
+	source := &SQLSource{DB: db, bus: bus, tables: make(map[string]bool, len(tables))}
+	for _, table := range tables {
+		source.tables[strings.ToLower(table)] = true
+	}
+	source.Topic = func(change DataChange) string {
+		return "sql:" + change.Table + ":" + change.Op
+	}
+	return source
+}
+
+// Exec executes a query and publishes the resulting change
+func (source *SQLSource) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return source.ExecContext(context.Background(), query, args...)
+}
+
+// ExecContext executes a query and publishes the resulting change
+func (source *SQLSource) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := source.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return result, err
+	}
+	table, op := parseDataChange(query)
+	if !source.tables[table] {
+		return result, nil
+	}
+	rows, _ := result.RowsAffected()
+	source.publish(DataChange{table, op, query, args, rows})
+	return result, nil
+}
+
+// Notify publishes a change made outside of Exec, e.g. inside a committed transaction
+func (source *SQLSource) Notify(table, op string, args ...interface{}) {
+	table = strings.ToLower(table)
+	if source.tables[table] {
+		source.publish(DataChange{Table: table, Op: op, Args: args})
+	}
+}
+
+func (source *SQLSource) publish(change DataChange) {
+	source.bus.Publish(source.Topic(change), change)
+}
+
+// parseDataChange returns the lower-cased table and operation of a data
+// modification query, or empty strings for any other query
+func parseDataChange(query string) (table, op string) {
+	match := dataChangeQuery.FindStringSubmatch(query)
+	if match == nil {
+		return "", ""
+	}
+	table = strings.ToLower(match[2])
+	switch strings.ToLower(match[1][:1]) {
+	case "i":
+		op = OpInsert
+	case "u":
+		op = OpUpdate
+	default:
+		op = OpDelete
+	}
+	return table, op
+}
@@ -0,0 +1,78 @@
+package eventbus
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+// fakeDriver accepts every statement and reports one affected row
+type fakeDriver struct{}
+type fakeConn struct{}
+type fakeStmt struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error)         { return fakeConn{}, nil }
+func (fakeConn) Prepare(string) (driver.Stmt, error)        { return fakeStmt{}, nil }
+func (fakeConn) Close() error                               { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                  { return nil, errors.New("not supported") }
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return nil, errors.New("not supported") }
+
+func init() {
+	sql.Register("eventbus-fake", fakeDriver{})
+}
+
+func TestSQLSource(t *testing.T) {
+	db, _ := sql.Open("eventbus-fake", "")
+	defer db.Close()
+
+	bus := New()
+	source := NewSQLSource(db, bus, "Users")
+	var changes []DataChange
+	for _, topic := range []string{"sql:users:insert", "sql:users:update", "sql:users:delete", "sql:orders:insert"} {
+		bus.Subscribe(topic, func(change DataChange) {
+			changes = append(changes, change)
+		})
+	}
+
+	source.Exec("INSERT INTO users (name) VALUES (?)", "ann")
+	source.Exec("update `users` set name = ? where id = ?", "bob", 1)
+	source.Exec("INSERT INTO orders (id) VALUES (1)")
+	source.Exec("SELECT 1")
+	source.Notify("users", OpDelete, 1)
+
+	if len(changes) != 3 {
+		t.Fatal(changes)
+	}
+	if changes[0].Op != OpInsert || changes[0].RowsAffected != 1 || changes[0].Args[0] != "ann" {
+		t.Log(changes[0])
+		t.Fail()
+	}
+	if changes[1].Op != OpUpdate || changes[1].Table != "users" {
+		t.Log(changes[1])
+		t.Fail()
+	}
+	if changes[2].Op != OpDelete || changes[2].Query != "" {
+		t.Log(changes[2])
+		t.Fail()
+	}
+}
+
+func TestParseDataChange(t *testing.T) {
+	cases := map[string][2]string{
+		"insert or replace into kv values (1)": {"kv", OpInsert},
+		"  DELETE FROM public.users":           {"public.users", OpDelete},
+		"UPDATE [items] SET x = 1":             {"items", OpUpdate},
+		"select * from users":                  {"", ""},
+	}
+	for query, expected := range cases {
+		table, op := parseDataChange(query)
+		if table != expected[0] || op != expected[1] {
+			t.Log(query, table, op)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"time"
+)
+
+// Store - durable log of the events of topics, see WithStore. Offsets of a
+// topic start at 0 and increase by one with every appended record.
+type Store interface {
+	// Append appends a record to the log of a topic, returning its offset
+	// once it is durably stored
+	Append(topic string, data []byte) (offset uint64, err error)
+	// ReadFrom calls fn with the records of the log of a topic from offset
+	// on, in order, stopping at the first error fn returns
+	ReadFrom(topic string, offset uint64, fn func(record StoreRecord) error) error
+	// Truncate drops the records of the log of a topic before offset
+	Truncate(topic string, before uint64) error
+}
+
+// StoreRecord - record of the log of a topic in a Store
+type StoreRecord struct {
+	Offset uint64
+	Data   []byte
+}
+
+// replayKey - context key marking the events republished by ReplayFromStore
+type replayKey struct{}
+
+// WithStore appends the events published on topics to store before they are
+// delivered, so they can be replayed after a restart with ReplayFromStore.
+// Events are stored as gob encoded envelopes (see Event): the types of the
+// arguments other than the basic ones must be registered with gob.Register.
+// A publish whose event can't be stored returns the error and delivers
+// nothing.
+func WithStore(store Store, topics ...string) Option {
+	return func(bus *Bus) {
+		bus.store = store
+		bus.storeTopics = make(map[string]bool, len(topics))
+		for _, topic := range topics {
+			bus.storeTopics[topic] = true
+		}
+	}
+}
+
+// ReplayFromStore republishes the events of a topic stored from offset on,
+// in order, with their original envelope (ID, time, headers and tags), to the
+// current subscribers, paced by WithReplayThrottle. Events whose deadline
+// passed are skipped. Replayed events are not stored again. Returns the offset following the last event replayed, to
+// resume from.
+// Returns error if the topic isn't stored, an event can't be read or decoded,
+// or republishing one fails.
+func (bus *Bus) ReplayFromStore(topic string, offset uint64) (uint64, error) {
+	topic = bus.canonicalTopic(topic)
+	if !bus.stored(topic) {
+		return offset, fmt.Errorf("topic %s is not stored", topic)
+	}
+	ctx := context.WithValue(context.Background(), replayKey{}, true)
+	err := bus.store.ReadFrom(topic, offset, func(record StoreRecord) error {
+		if err := bus.throttle.wait(ctx); err != nil {
+			return err
+		}
+		ev := Event{}
+		if err := gob.NewDecoder(bytes.NewReader(record.Data)).Decode(&ev); err != nil {
+			return fmt.Errorf("event %d of topic %s: %w", record.Offset, topic, err)
+		}
+		if err := bus.publishEvent(ctx, ev); err != nil {
+			return err
+		}
+		offset = record.Offset + 1
+		return nil
+	})
+	return offset, err
+}
+
+// stored reports whether the events of a topic are stored
+func (bus *Bus) stored(topic string) bool {
+	if bus.store == nil {
+		return false
+	}
+	for stored := range bus.storeTopics {
+		if bus.canonicalTopic(stored) == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// persist appends an event published with ctx on a stored topic to the store
+func (bus *Bus) persist(ctx context.Context, topic string, args []interface{}) error {
+	if replayed, _ := ctx.Value(replayKey{}).(bool); replayed || !bus.stored(topic) {
+		return nil
+	}
+	bus.lock.RLock()
+	closed := bus.closed
+	bus.lock.RUnlock()
+	if closed {
+		return ErrBusClosed
+	}
+	ev := Event{ID: bus.NewID(), Time: time.Now()}
+	if envelope, ok := ctx.Value(eventKey{}).(*Event); ok {
+		ev = *envelope
+	}
+	ev.Topic, ev.Args, ev.Tags = topic, args, TagsFromContext(ctx)
+	ev.Deadline, _ = ctx.Deadline()
+	ev.Provenance = Provenance(ctx)
+	data := bytes.Buffer{}
+	if err := gob.NewEncoder(&data).Encode(ev); err != nil {
+		return fmt.Errorf("can't store event of topic %s: %w", topic, err)
+	}
+	if _, err := bus.store.Append(topic, data.Bytes()); err != nil {
+		return fmt.Errorf("can't store event of topic %s: %w", topic, err)
+	}
+	return nil
+}
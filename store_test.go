@@ -0,0 +1,104 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type storedOrder struct {
+	ID    int
+	Total float64
+}
+
+func init() {
+	gob.Register(storedOrder{})
+}
+
+func TestReplayFromStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	bus := New(WithStore(store, "orders"))
+	bus.Publish("orders", storedOrder{1, 10})
+	bus.PublishEvent(Event{Topic: "orders", ID: "order-2", Args: []interface{}{storedOrder{2, 20}}})
+	bus.Publish("carts", storedOrder{3, 30}) // not stored
+	if err := bus.Publish("orders", func() {}); err == nil {
+		t.Fatal("unstorable event published")
+	}
+
+	restarted := New(WithStore(store, "orders"))
+	var ids []string
+	var totals []float64
+	restarted.Subscribe("orders", func(ev Event) {
+		ids = append(ids, ev.ID)
+		totals = append(totals, ev.Args[0].(storedOrder).Total)
+	})
+	next, err := restarted.ReplayFromStore("orders", 0)
+	if err != nil || next != 2 {
+		t.Fatal(next, err)
+	}
+	if !slices.Equal(totals, []float64{10, 20}) || ids[0] == "" || ids[1] != "order-2" {
+		t.Fatal(ids, totals)
+	}
+	if next, _ := restarted.ReplayFromStore("orders", next); next != 2 || len(totals) != 2 {
+		t.Fatal(next, totals)
+	}
+	if _, err := restarted.ReplayFromStore("carts", 0); err == nil {
+		t.Fatal("replayed a topic that isn't stored")
+	}
+	if got := readAll(t, store, "orders", 0); len(got) != 2 {
+		t.Fatal("replayed events stored again", len(got))
+	}
+}
+
+func TestStoreFromReplayedHandler(t *testing.T) {
+	store, _ := NewFileStore(t.TempDir())
+	defer store.Close()
+	bus := New(WithStore(store, "orders", "invoices"))
+	bus.Publish("orders", 1)
+	bus.Subscribe("orders", func(ctx context.Context, id int) { bus.PublishCtx(ctx, "invoices", id) })
+	bus.ReplayFromStore("orders", 0)
+	if got := readAll(t, store, "invoices", 0); len(got) != 1 {
+		t.Fatal("event published by a replayed handler not stored")
+	}
+
+	bus.Close(context.Background())
+	if err := bus.Publish("orders", 2); !errors.Is(err, ErrBusClosed) {
+		t.Fatal(err)
+	}
+	if got := readAll(t, store, "orders", 0); len(got) != 1 {
+		t.Fatal("event published on a closed bus stored")
+	}
+}
+
+func TestReplayFromStoreSkipsExpired(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	bus := New(WithStore(store, "orders"))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	bus.PublishCtx(ctx, "orders", storedOrder{1, 10})
+	bus.Publish("orders", storedOrder{2, 20})
+	<-ctx.Done()
+
+	restarted := New(WithStore(store, "orders"))
+	var totals []float64
+	restarted.Subscribe("orders", func(order storedOrder) {
+		totals = append(totals, order.Total)
+	})
+	if next, err := restarted.ReplayFromStore("orders", 0); err != nil || next != 2 {
+		t.Fatal(next, err)
+	}
+	if !slices.Equal(totals, []float64{20}) {
+		t.Fatal("expired event replayed", totals)
+	}
+}
@@ -0,0 +1,114 @@
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Subscription - handle of a single subscription. Unlike Unsubscribe, which
+// finds the handler by comparing function pointers, it always removes the
+// subscription it was returned for, even for closures and method values.
+type Subscription struct {
+	bus     *Bus
+	topic   string
+	handler *eventHandler
+}
+
+// SubscribeHandle runs SubscribeHandle on package-level bus singleton
+func SubscribeHandle(topic string, fn interface{}) (*Subscription, error) {
+	return b.Load().SubscribeHandle(topic, fn)
+}
+
+// SubscribeHandle works like Subscribe and returns the handle of the subscription.
+func (bus *Bus) SubscribeHandle(topic string, fn interface{}) (*Subscription, error) {
+	return bus.subscribeHandle(topic, fn, &eventHandler{
+		callBack: reflect.ValueOf(fn),
+	})
+}
+
+// SubscribeAsyncHandle runs SubscribeAsyncHandle on package-level bus singleton
+func SubscribeAsyncHandle(topic string, fn interface{}, transactional bool) (*Subscription, error) {
+	return b.Load().SubscribeAsyncHandle(topic, fn, transactional)
+}
+
+// SubscribeAsyncHandle works like SubscribeAsync and returns the handle of the subscription.
+func (bus *Bus) SubscribeAsyncHandle(topic string, fn interface{}, transactional bool) (*Subscription, error) {
+	return bus.subscribeHandle(topic, fn, &eventHandler{
+		callBack: reflect.ValueOf(fn), async: true, transactional: transactional,
+	})
+}
+
+// SubscribeOnceHandle runs SubscribeOnceHandle on package-level bus singleton
+func SubscribeOnceHandle(topic string, fn interface{}) (*Subscription, error) {
+	return b.Load().SubscribeOnceHandle(topic, fn)
+}
+
+// SubscribeOnceHandle works like SubscribeOnce and returns the handle of the subscription.
+func (bus *Bus) SubscribeOnceHandle(topic string, fn interface{}) (*Subscription, error) {
+	return bus.subscribeHandle(topic, fn, &eventHandler{
+		callBack: reflect.ValueOf(fn), flagOnce: true,
+	})
+}
+
+func (bus *Bus) subscribeHandle(topic string, fn interface{}, handler *eventHandler) (*Subscription, error) {
+	if err := bus.doSubscribe(topic, fn, handler); err != nil {
+		return nil, err
+	}
+	return &Subscription{bus, topic, handler}, nil
+}
+
+// Topic returns the topic the subscription was made on
+func (sub *Subscription) Topic() string {
+	return sub.topic
+}
+
+// IsActive returns true until the subscription is removed, by Unsubscribe or
+// by the bus (e.g. after its once delivery)
+func (sub *Subscription) IsActive() bool {
+	sub.bus.lock.RLock()
+	defer sub.bus.lock.RUnlock()
+	_, idx := sub.bus.locate(sub.handler)
+	return idx >= 0
+}
+
+// Unsubscribe removes the subscription.
+// Returns error if it is no longer active.
+func (sub *Subscription) Unsubscribe() error {
+	for {
+		sub.bus.lock.RLock()
+		topic, idx := sub.bus.locate(sub.handler)
+		sub.bus.lock.RUnlock()
+		if idx < 0 {
+			return fmt.Errorf("subscription to topic %s is not active", sub.topic)
+		}
+		if sub.bus.detach(topic, sub.handler) {
+			return nil
+		}
+		// moved to another topic (see Alias) or removed meanwhile, look again
+	}
+}
+
+// Use applies middleware to the later deliveries to the subscription, see
+// WrapSubscription. Returns the subscription for chaining.
+func (sub *Subscription) Use(middleware ...DeliveryMiddleware) *Subscription {
+	sub.handler.wrap(middleware)
+	return sub
+}
+
+// locate returns the key a handler is subscribed to and its index, -1 if it is
+// not subscribed; the bus lock must be held, but no shard lock
+func (bus *Bus) locate(handler *eventHandler) (string, int) {
+	for i := range bus.shards {
+		shard := &bus.shards[i]
+		shard.RLock()
+		for topic, handlers := range shard.handlers {
+			if idx := slices.Index(handlers, handler); idx >= 0 {
+				shard.RUnlock()
+				return topic, idx
+			}
+		}
+		shard.RUnlock()
+	}
+	return "", -1
+}
@@ -0,0 +1,58 @@
+package eventbus
+
+import (
+	"testing"
+)
+
+func TestSubscriptionClosures(t *testing.T) {
+	bus := New()
+	calls := make([]int, 2)
+	var subs []*Subscription
+	for i := range calls {
+		sub, err := bus.SubscribeHandle("topic", func() { calls[i]++ })
+		if err != nil || sub.Topic() != "topic" || !sub.IsActive() {
+			t.Fatal(err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if subs[1].Unsubscribe() != nil || subs[1].IsActive() || !subs[0].IsActive() {
+		t.Fail()
+	}
+	if subs[1].Unsubscribe() == nil {
+		t.Fail()
+	}
+	bus.Publish("topic")
+	if calls[0] != 1 || calls[1] != 0 {
+		t.Fatal(calls)
+	}
+}
+
+func TestSubscriptionOnce(t *testing.T) {
+	bus := New()
+	sub, _ := bus.SubscribeOnceHandle("topic", func() {})
+	bus.Publish("topic")
+	if sub.IsActive() || bus.HasCallback("topic") {
+		t.Fail()
+	}
+}
+
+func TestSubscriptionAsyncUse(t *testing.T) {
+	bus := New()
+	received := make(chan int, 1)
+	sub, _ := bus.SubscribeAsyncHandle("topic", func(a int) { received <- a }, false)
+	sub.Use(func(next DeliverFunc) DeliverFunc {
+		return func(delivery *Delivery) error {
+			delivery.Args[0] = delivery.Args[0].(int) * 10
+			return next(delivery)
+		}
+	})
+	bus.Publish("topic", 4)
+	bus.WaitAsync()
+	if <-received != 40 {
+		t.Fail()
+	}
+	if _, err := bus.SubscribeHandle("topic", 1); err == nil {
+		t.Fail()
+	}
+}
@@ -0,0 +1,78 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"maps"
+)
+
+// Tagger - computes the routing tags of an event from its topic and arguments,
+// e.g. {"region": "eu", "priority": "high"}
+type Tagger func(topic string, args []interface{}) map[string]string
+
+// tagsKey - context key of the tags of an event
+type tagsKey struct{}
+
+// TagEvents returns publish middleware attaching the tags computed by tagger
+// to every event published on the bus (see Use), so the classification of
+// events is kept apart from the subscriptions routing on it (see MatchTags).
+// Tags attached by outer middleware or the publisher are kept unless tagger
+// sets them too.
+func TagEvents(tagger Tagger) PublishMiddleware {
+	return func(next PublishFunc) PublishFunc {
+		return func(ctx context.Context, topic string, args []interface{}) error {
+			return next(WithTags(ctx, tagger(topic, args)), topic, args)
+		}
+	}
+}
+
+// WithTags returns a copy of ctx carrying tags in addition to the tags ctx
+// already carries, to publish an event with PublishCtx
+func WithTags(ctx context.Context, tags map[string]string) context.Context {
+	if len(tags) == 0 {
+		return ctx
+	}
+	merged := maps.Clone(TagsFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(tags))
+	}
+	maps.Copy(merged, tags)
+	return context.WithValue(ctx, tagsKey{}, merged)
+}
+
+// TagsFromContext returns the tags carried by ctx, e.g. the context passed to
+// a handler, nil if there are none. The map must not be modified.
+func TagsFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(tagsKey{}).(map[string]string)
+	return tags
+}
+
+// MatchTags delivers to the subscription only the events tagged with all the
+// given tags (see TagEvents and WithTags), replacing the tags given before.
+// Untagged events don't match unless tags is empty.
+// Returns error if the subscription is not active.
+func (sub *Subscription) MatchTags(tags map[string]string) error {
+	bus := sub.bus
+	bus.lock.Lock()
+	defer bus.lock.Unlock()
+	if _, idx := bus.locate(sub.handler); idx < 0 {
+		return fmt.Errorf("subscription to topic %s is not active", sub.topic)
+	}
+	sub.handler.tags = maps.Clone(tags)
+	return nil
+}
+
+// matchTags returns true if an event published with ctx matches the tags of
+// a handler
+func matchTags(ctx context.Context, handler *eventHandler) bool {
+	if len(handler.tags) == 0 {
+		return true
+	}
+	tags := TagsFromContext(ctx)
+	for key, value := range handler.tags {
+		if tag, ok := tags[key]; !ok || tag != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMatchTags(t *testing.T) {
+	bus := New()
+	bus.Use(TagEvents(func(topic string, args []interface{}) map[string]string {
+		if args[0].(int) > 100 {
+			return map[string]string{"priority": "high"}
+		}
+		return map[string]string{"priority": "low"}
+	}))
+	var high, eu, all []int
+	sub, _ := bus.SubscribeHandle("orders", func(n int) { high = append(high, n) })
+	if err := sub.MatchTags(map[string]string{"priority": "high"}); err != nil {
+		t.Fatal(err)
+	}
+	sub, _ = bus.SubscribeHandle("orders", func(n int) { eu = append(eu, n) })
+	sub.MatchTags(map[string]string{"priority": "high", "region": "eu"})
+	bus.Subscribe("orders", func(ctx context.Context, n int) {
+		if TagsFromContext(ctx)["priority"] == "" {
+			t.Error("untagged", n)
+		}
+		all = append(all, n)
+	})
+
+	bus.Publish("orders", 1)
+	bus.Publish("orders", 200)
+	bus.PublishCtx(WithTags(context.Background(), map[string]string{"region": "eu"}), "orders", 300)
+	if len(high) != 2 || high[0] != 200 || high[1] != 300 {
+		t.Fatal(high)
+	}
+	if len(eu) != 1 || eu[0] != 300 {
+		t.Fatal(eu)
+	}
+	if len(all) != 3 {
+		t.Fatal(all)
+	}
+
+	sub.Unsubscribe()
+	if sub.MatchTags(nil) == nil {
+		t.Fail()
+	}
+}
+
+func TestEventTags(t *testing.T) {
+	bus := New()
+	var tags map[string]string
+	sub, _ := bus.SubscribeHandle("orders", func(ev Event) { tags = ev.Tags })
+	sub.MatchTags(map[string]string{"region": "eu"})
+	bus.PublishEvent(Event{Topic: "orders", Args: []interface{}{1}, Tags: map[string]string{"region": "us"}})
+	if tags != nil {
+		t.Fatal(tags)
+	}
+	bus.PublishEvent(Event{Topic: "orders", Args: []interface{}{1}, Tags: map[string]string{"region": "eu"}})
+	if tags["region"] != "eu" {
+		t.Fatal(tags)
+	}
+}
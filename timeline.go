@@ -0,0 +1,155 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Timeline - handler invocations captured by topic traces, laid out in time
+// to review the concurrency of deliveries, see NewTimeline
+type Timeline struct {
+	Start      time.Time // publish time of the earliest event
+	Deliveries []TimelineDelivery
+}
+
+// TimelineDelivery - a handler invocation of a Timeline
+type TimelineDelivery struct {
+	TraceEntry
+	Overlaps   bool // ran concurrently with another delivery to the same handler
+	OutOfOrder bool // started before the delivery to the same handler of an event published earlier
+}
+
+// Timeline returns the timeline of the invocations captured by the traces of
+// topics (see EnableTrace)
+func (bus *Bus) Timeline(topics ...string) *Timeline {
+	var entries []TraceEntry
+	for _, topic := range topics {
+		entries = append(entries, bus.Traces(topic)...)
+	}
+	return NewTimeline(entries)
+}
+
+// NewTimeline lays out trace entries by start time, flagging the deliveries
+// to a handler that overlap or don't follow the publishing order of their
+// events
+func NewTimeline(entries []TraceEntry) *Timeline {
+	timeline := &Timeline{Deliveries: make([]TimelineDelivery, 0, len(entries))}
+	for _, entry := range entries {
+		timeline.Deliveries = append(timeline.Deliveries, TimelineDelivery{TraceEntry: entry})
+		if timeline.Start.IsZero() || entry.Published.Before(timeline.Start) {
+			timeline.Start = entry.Published
+		}
+	}
+	slices.SortStableFunc(timeline.Deliveries, func(a, b TimelineDelivery) int {
+		return a.Start.Compare(b.Start)
+	})
+	for i := range timeline.Deliveries {
+		first := &timeline.Deliveries[i]
+		for j := i + 1; j < len(timeline.Deliveries); j++ {
+			second := &timeline.Deliveries[j]
+			if first.Topic != second.Topic || first.Handler != second.Handler {
+				continue
+			}
+			if second.Start.Before(first.End) {
+				first.Overlaps, second.Overlaps = true, true
+			}
+			if second.Published.Before(first.Published) {
+				first.OutOfOrder = true
+			}
+		}
+	}
+	return timeline
+}
+
+// WriteJSON writes the timeline as JSON, with times as microseconds since the
+// start of the timeline
+func (t *Timeline) WriteJSON(w io.Writer) error {
+	type delivery struct {
+		Topic      string `json:"topic"`
+		Handler    string `json:"handler"`
+		Args       string `json:"args"`
+		Results    string `json:"results"`
+		Published  int64  `json:"published_us"`
+		Start      int64  `json:"start_us"`
+		End        int64  `json:"end_us"`
+		Overlaps   bool   `json:"overlaps,omitempty"`
+		OutOfOrder bool   `json:"out_of_order,omitempty"`
+	}
+	deliveries := make([]delivery, 0, len(t.Deliveries))
+	for _, d := range t.Deliveries {
+		deliveries = append(deliveries, delivery{
+			Topic:      d.Topic,
+			Handler:    d.Handler,
+			Args:       d.Args,
+			Results:    d.Results,
+			Published:  d.Published.Sub(t.Start).Microseconds(),
+			Start:      d.Start.Sub(t.Start).Microseconds(),
+			End:        d.End.Sub(t.Start).Microseconds(),
+			Overlaps:   d.Overlaps,
+			OutOfOrder: d.OutOfOrder,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Start      time.Time  `json:"start"`
+		Deliveries []delivery `json:"deliveries"`
+	}{t.Start, deliveries})
+}
+
+// WriteMermaid writes the timeline as a Mermaid gantt chart, in milliseconds
+// since the start of the timeline: publishes are milestones and every handler
+// has a section with its deliveries, flagged ones marked critical
+func (t *Timeline) WriteMermaid(w io.Writer) error {
+	out := strings.Builder{}
+	out.WriteString("gantt\n    dateFormat x\n    axisFormat %S.%L\n    section publishes\n")
+	type publish struct {
+		topic string
+		at    time.Time
+	}
+	var published []publish
+	handlers := make(map[string][]TimelineDelivery)
+	var order []string
+	for _, d := range t.Deliveries {
+		if p := (publish{d.Topic, d.Published}); !slices.Contains(published, p) {
+			published = append(published, p)
+		}
+		if _, ok := handlers[d.Handler]; !ok {
+			order = append(order, d.Handler)
+		}
+		handlers[d.Handler] = append(handlers[d.Handler], d)
+	}
+	slices.SortStableFunc(published, func(a, b publish) int { return a.at.Compare(b.at) })
+	for _, p := range published {
+		at := t.offset(p.at)
+		fmt.Fprintf(&out, "    %s :milestone, %d, %d\n", mermaidText(p.topic), at, at)
+	}
+	for _, handler := range order {
+		fmt.Fprintf(&out, "    section %s\n", mermaidText(handler))
+		for _, d := range handlers[handler] {
+			start := t.offset(d.Start)
+			end := max(t.offset(d.End), start+1) // keep instant deliveries visible
+			tag := ""
+			if d.Overlaps || d.OutOfOrder {
+				tag = "crit, "
+			}
+			fmt.Fprintf(&out, "    %s %s :%s%d, %d\n", mermaidText(d.Topic), mermaidText(d.Args), tag, start, end)
+		}
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// offset returns the milliseconds from the start of the timeline to at
+func (t *Timeline) offset(at time.Time) int64 {
+	return at.Sub(t.Start).Milliseconds()
+}
+
+// mermaidText escapes the characters ending a Mermaid gantt task name
+func mermaidText(s string) string {
+	return strings.NewReplacer("#", "#35;", ":", "#58;", ";", "#59;", "\n", " ").Replace(s)
+}
@@ -0,0 +1,76 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeline(t *testing.T) {
+	at := func(ms int) time.Time { return time.Unix(0, 0).Add(time.Duration(ms) * time.Millisecond) }
+	timeline := NewTimeline([]TraceEntry{
+		{Topic: "order:placed", Handler: "billing", Args: "[1]", Published: at(0), Start: at(1), End: at(5)},
+		{Topic: "order:placed", Handler: "billing", Args: "[2]", Published: at(2), Start: at(3), End: at(4)},
+		{Topic: "order:placed", Handler: "shipping", Args: "[2]", Published: at(2), Start: at(8), End: at(9)},
+		{Topic: "order:placed", Handler: "shipping", Args: "[1]", Published: at(0), Start: at(9), End: at(10)},
+	})
+	if !timeline.Start.Equal(at(0)) || len(timeline.Deliveries) != 4 {
+		t.Fatal(timeline)
+	}
+	billing1, billing2, shipping2, shipping1 := timeline.Deliveries[0], timeline.Deliveries[1], timeline.Deliveries[2], timeline.Deliveries[3]
+	if !billing1.Overlaps || !billing2.Overlaps || billing1.OutOfOrder || billing2.OutOfOrder {
+		t.Fatal(billing1, billing2)
+	}
+	if shipping2.Overlaps || shipping1.Overlaps || !shipping2.OutOfOrder || shipping1.OutOfOrder {
+		t.Fatal(shipping2, shipping1)
+	}
+
+	out := strings.Builder{}
+	if err := timeline.WriteMermaid(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"order#58;placed :milestone, 0, 0\n",
+		"order#58;placed :milestone, 2, 2\n",
+		"section billing\n    order#58;placed [1] :crit, 1, 5\n",
+		"section shipping\n    order#58;placed [2] :crit, 8, 9\n    order#58;placed [1] :9, 10\n",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Fatal(out.String())
+		}
+	}
+
+	out.Reset()
+	if err := timeline.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Deliveries []struct {
+			Handler    string `json:"handler"`
+			Start      int64  `json:"start_us"`
+			OutOfOrder bool   `json:"out_of_order"`
+		} `json:"deliveries"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Deliveries) != 4 || decoded.Deliveries[2].Start != 8000 || !decoded.Deliveries[2].OutOfOrder {
+		t.Fatal(out.String())
+	}
+}
+
+func TestBusTimeline(t *testing.T) {
+	bus := New()
+	bus.EnableTrace("topic", 10, 0)
+	bus.Subscribe("topic", func(a int) {})
+	bus.Publish("topic", 1)
+	bus.Publish("topic", 2)
+	timeline := bus.Timeline("topic")
+	if len(timeline.Deliveries) != 2 || timeline.Deliveries[0].Args != "[1]" || timeline.Start.After(timeline.Deliveries[0].Start) {
+		t.Fatal(timeline)
+	}
+	if timeline.Deliveries[0].Overlaps || timeline.Deliveries[0].OutOfOrder {
+		t.Fatal(timeline.Deliveries[0])
+	}
+}
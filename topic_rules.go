@@ -0,0 +1,157 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidTopic - error wrapped by topic naming rule violations
+var ErrInvalidTopic = errors.New("invalid topic")
+
+// ErrReservedTopic - error wrapped by publishes on control topics and
+// subscriptions to control topics the bus doesn't publish on. It wraps
+// ErrInvalidTopic.
+var ErrReservedTopic = fmt.Errorf("%w: reserved", ErrInvalidTopic)
+
+// controlPrefix - prefix of the topics the bus publishes its own events on.
+// Only the bus publishes on them; the known ones can always be subscribed to,
+// whatever the topic rules.
+const controlPrefix = "bus:"
+
+// IsControlTopic reports whether a topic is a control topic, one the bus
+// publishes its own events on, e.g. for bridges that must not forward them
+func IsControlTopic(topic string) bool {
+	return strings.HasPrefix(topic, controlPrefix)
+}
+
+// controlTopics - control topics the bus publishes on
+var controlTopics = map[string]bool{
+	TopicBackfill:         true,
+	TopicCanary:           true,
+	TopicConcurrentAccess: true,
+	TopicDivergence:       true,
+	TopicGroupFailure:     true,
+	TopicLifecycle:        true,
+	TopicPanic:            true,
+	TopicPayloadMutation:  true,
+	TopicSignal:           true,
+	TopicSLO:              true,
+	TopicSlowConsumer:     true,
+	TopicWatchdog:         true,
+}
+
+// TopicRules - naming convention enforced on the topics passed to the
+// Subscribe methods and Publish
+type TopicRules struct {
+	Pattern     *regexp.Regexp    // whole topic must match, if set
+	Separator   string            // separates segments, "." if empty
+	Segment     *regexp.Regexp    // every segment must match, if set
+	MinSegments int               // no minimum if zero
+	MaxSegments int               // no maximum if zero
+	Reserved    []string          // prefixes that can be subscribed to but not published on
+	Aliases     map[string]string // legacy topic names mapped to their canonical names
+}
+
+// Validate returns an error wrapping ErrInvalidTopic if topic breaks the rules,
+// reserved prefixes aside
+func (rules *TopicRules) Validate(topic string) error {
+	if topic == "" {
+		return fmt.Errorf("%w: empty topic", ErrInvalidTopic)
+	}
+	if rules.Pattern != nil && !rules.Pattern.MatchString(topic) {
+		return fmt.Errorf("%w %q: doesn't match %s", ErrInvalidTopic, topic, rules.Pattern)
+	}
+	separator := rules.Separator
+	if separator == "" {
+		separator = "."
+	}
+	segments := strings.Split(topic, separator)
+	if rules.MinSegments > 0 && len(segments) < rules.MinSegments {
+		return fmt.Errorf("%w %q: %d segments, at least %d required", ErrInvalidTopic, topic, len(segments), rules.MinSegments)
+	}
+	if rules.MaxSegments > 0 && len(segments) > rules.MaxSegments {
+		return fmt.Errorf("%w %q: %d segments, at most %d allowed", ErrInvalidTopic, topic, len(segments), rules.MaxSegments)
+	}
+	for _, segment := range segments {
+		if segment == "" {
+			return fmt.Errorf("%w %q: empty segment", ErrInvalidTopic, topic)
+		}
+		if rules.Segment != nil && !rules.Segment.MatchString(segment) {
+			return fmt.Errorf("%w %q: segment %q doesn't match %s", ErrInvalidTopic, topic, segment, rules.Segment)
+		}
+	}
+	return nil
+}
+
+// reserved returns the reserved prefix topic starts with, if any
+func (rules *TopicRules) reserved(topic string) (string, bool) {
+	for _, prefix := range rules.Reserved {
+		if strings.HasPrefix(topic, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// topicNames holds the topic rules and aliases of a bus
+type topicNames struct {
+	rules   *TopicRules
+	aliases map[string]string
+	warned  map[string]bool // aliases a deprecation warning was logged for
+	sync.RWMutex
+}
+
+// SetTopicRules enforces rules on the topics subscribed to and published on
+// from now on; existing subscriptions are left alone. The rules' aliases
+// replace the alias table of the bus. nil removes the rules and aliases.
+func (bus *Bus) SetTopicRules(rules *TopicRules) {
+	names := &bus.topicNames
+	names.Lock()
+	defer names.Unlock()
+	names.rules = rules
+	names.aliases, names.warned = nil, nil
+	if rules != nil && len(rules.Aliases) > 0 {
+		names.aliases = make(map[string]string, len(rules.Aliases))
+		for legacy, canonical := range rules.Aliases {
+			names.aliases[legacy] = canonical
+		}
+	}
+}
+
+// checkTopic resolves the canonical name of a topic and validates it for
+// subscribing, or publishing if publish is set
+func (bus *Bus) checkTopic(topic string, publish bool) (string, error) {
+	topic = bus.canonicalTopic(topic)
+	if strings.HasPrefix(topic, controlPrefix) {
+		return topic, checkControl(topic, publish)
+	}
+	names := &bus.topicNames
+	names.RLock()
+	rules := names.rules
+	names.RUnlock()
+	if rules == nil {
+		return topic, nil
+	}
+	if prefix, ok := rules.reserved(topic); ok {
+		if publish {
+			return topic, fmt.Errorf("%w %q: prefix %q is reserved", ErrInvalidTopic, topic, prefix)
+		}
+		return topic, nil
+	}
+	return topic, rules.Validate(topic)
+}
+
+// checkControl validates a control topic for subscribing, or publishing if
+// publish is set
+func checkControl(topic string, publish bool) error {
+	if publish {
+		return fmt.Errorf("%w %q: only the bus publishes on control topics", ErrReservedTopic, topic)
+	}
+	if !controlTopics[topic] {
+		return fmt.Errorf("%w %q: unknown control topic", ErrReservedTopic, topic)
+	}
+	return nil
+}
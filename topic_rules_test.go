@@ -0,0 +1,107 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestTopicRulesValidate(t *testing.T) {
+	rules := &TopicRules{
+		Segment:     regexp.MustCompile(`^[a-z][a-z0-9_]*$`),
+		MinSegments: 2,
+		MaxSegments: 3,
+	}
+	for _, topic := range []string{"user.created", "billing.invoice.paid"} {
+		if err := rules.Validate(topic); err != nil {
+			t.Error(err)
+		}
+	}
+	for _, topic := range []string{"", "user", "a.b.c.d", "user..created", "User.created", "user.created!"} {
+		if err := rules.Validate(topic); !errors.Is(err, ErrInvalidTopic) {
+			t.Errorf("%q accepted", topic)
+		}
+	}
+	pattern := &TopicRules{Pattern: regexp.MustCompile(`^[a-z]+:[a-z]+$`), Separator: ":"}
+	if pattern.Validate("user:created") != nil || pattern.Validate("user.created") == nil {
+		t.Fail()
+	}
+}
+
+func TestSetTopicRules(t *testing.T) {
+	bus := New()
+	bus.SetTopicRules(&TopicRules{
+		Segment:  regexp.MustCompile(`^[a-z]+$`),
+		Reserved: []string{"internal."},
+		Aliases:  map[string]string{"UserCreated": "user.created"},
+	})
+	calls := 0
+	handler := func() { calls++ }
+	if bus.Subscribe("Bad Topic", handler) == nil {
+		t.Fail()
+	}
+	if bus.Subscribe("internal.audit", handler) != nil {
+		t.Fail() // reserved topics can be subscribed to
+	}
+	if !errors.Is(bus.Publish("internal.audit"), ErrInvalidTopic) {
+		t.Fail()
+	}
+	if bus.Subscribe(TopicSLO, func(SLOEvent) {}) != nil {
+		t.Fail()
+	}
+
+	// legacy names work against the canonical topic
+	if bus.Subscribe("UserCreated", handler) != nil {
+		t.Fail()
+	}
+	if !bus.HasCallback("user.created") || !bus.HasCallback("UserCreated") {
+		t.Fail()
+	}
+	if bus.Publish("user.created") != nil || bus.Publish("UserCreated") != nil {
+		t.Fail()
+	}
+	if bus.Publish("Bad Topic") == nil {
+		t.Fail()
+	}
+	if calls != 2 {
+		t.Fail()
+	}
+	if bus.Unsubscribe("UserCreated", handler) != nil || bus.HasCallback("user.created") {
+		t.Fail()
+	}
+
+	bus.SetTopicRules(nil)
+	if bus.Publish("Bad Topic") != nil || bus.Publish("internal.audit") != nil {
+		t.Fail()
+	}
+}
+
+func TestReservedTopics(t *testing.T) {
+	bus := New()
+	calls := 0
+	bus.Subscribe(TopicLifecycle, func(LifecyclePhase) { calls++ })
+	if !errors.Is(bus.Publish(TopicLifecycle, LifecycleReady), ErrReservedTopic) {
+		t.Fail()
+	}
+	if !errors.Is(bus.PublishCtx(context.Background(), TopicPanic), ErrReservedTopic) {
+		t.Fail()
+	}
+	if !errors.Is(bus.Subscribe("bus:typo", func() {}), ErrInvalidTopic) {
+		t.Fail() // unknown control topics can't be subscribed to
+	}
+	bus.Alias("spoof", TopicLifecycle)
+	if !errors.Is(bus.Publish("spoof", LifecycleReady), ErrReservedTopic) {
+		t.Fail()
+	}
+	bus.PublishLifecycle(LifecycleReady) // sanctioned
+	if calls != 1 {
+		t.Fatal(calls)
+	}
+}
+
+func TestIsControlTopic(t *testing.T) {
+	if !IsControlTopic(TopicPanic) || !IsControlTopic("bus:unknown") || IsControlTopic("orders:bus:created") {
+		t.Fail()
+	}
+}
@@ -0,0 +1,326 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultSnapshotSize - default maximum length of a serialized args/results snapshot
+const DefaultSnapshotSize = 256
+
+// TraceEntry - a handler invocation captured by a topic trace
+type TraceEntry struct {
+	Topic     string
+	Handler   string
+	Args      string // bounded snapshot of the event arguments
+	Results   string // bounded snapshot of the handler return values
+	Published time.Time
+	Start     time.Time
+	End       time.Time
+	// Provenance - topics the event went through before Topic, see Event.Provenance
+	Provenance []Hop
+}
+
+// traceBuffer is a fixed size ring buffer of trace entries for one topic
+type traceBuffer struct {
+	entries      []TraceEntry
+	next         int
+	full         bool
+	snapshotSize int
+	bytes        int64 // approximate memory held by the entries
+}
+
+type tracer struct {
+	buffers map[string]*traceBuffer
+	sync.Mutex
+}
+
+// EnableTrace starts capturing the last `capacity` handler invocations of a topic,
+// each with a snapshot of the arguments and results truncated to `snapshotSize` bytes
+// (DefaultSnapshotSize if zero or negative). Enabling an already traced topic
+// resets its buffer.
+func (bus *Bus) EnableTrace(topic string, capacity, snapshotSize int) {
+	if capacity <= 0 {
+		return
+	}
+	if snapshotSize <= 0 {
+		snapshotSize = DefaultSnapshotSize
+	}
+	bus.tracer.Lock()
+	if bus.tracer.buffers == nil {
+		bus.tracer.buffers = make(map[string]*traceBuffer)
+	}
+	var released int64
+	if previous, ok := bus.tracer.buffers[topic]; ok {
+		released = previous.bytes
+	}
+	bus.tracer.buffers[topic] = &traceBuffer{
+		entries:      make([]TraceEntry, capacity),
+		snapshotSize: snapshotSize,
+	}
+	bus.tracer.Unlock()
+	bus.memory.retain(topic, -released)
+}
+
+// DisableTrace stops capturing invocations of a topic and drops its buffer.
+func (bus *Bus) DisableTrace(topic string) {
+	bus.tracer.Lock()
+	var released int64
+	if buffer, ok := bus.tracer.buffers[topic]; ok {
+		released = buffer.bytes
+	}
+	delete(bus.tracer.buffers, topic)
+	bus.tracer.Unlock()
+	bus.memory.retain(topic, -released)
+}
+
+// Traces returns the captured invocations of a topic, oldest first.
+func (bus *Bus) Traces(topic string) []TraceEntry {
+	bus.tracer.Lock()
+	defer bus.tracer.Unlock()
+	buffer, ok := bus.tracer.buffers[topic]
+	if !ok {
+		return nil
+	}
+	if !buffer.full {
+		return append([]TraceEntry(nil), buffer.entries[:buffer.next]...)
+	}
+	entries := make([]TraceEntry, 0, len(buffer.entries))
+	entries = append(entries, buffer.entries[buffer.next:]...)
+	return append(entries, buffer.entries[:buffer.next]...)
+}
+
+// tracedTopics returns the traced topics, sorted
+func (bus *Bus) tracedTopics() []string {
+	bus.tracer.Lock()
+	defer bus.tracer.Unlock()
+	topics := make([]string, 0, len(bus.tracer.buffers))
+	for topic := range bus.tracer.buffers {
+		topics = append(topics, topic)
+	}
+	slices.Sort(topics)
+	return topics
+}
+
+// TraceHandler returns an admin HTTP handler for topic traces, next to
+// QuarantineHandler: GET lists the traced topics as JSON, or with a topic=<t>
+// query value the captured invocations of t, oldest first, along with their
+// provenance. POST with topic=<t> and capacity=<n> form values (and an
+// optional snapshot_size=<n>) enables the trace of t, DELETE with topic=<t>
+// disables it.
+func (bus *Bus) TraceHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			if !r.URL.Query().Has("topic") {
+				json.NewEncoder(w).Encode(bus.tracedTopics())
+				return
+			}
+			type hop struct {
+				Topic string    `json:"topic"`
+				Time  time.Time `json:"time"`
+				Via   string    `json:"via"`
+			}
+			type item struct {
+				Topic      string    `json:"topic"`
+				Handler    string    `json:"handler"`
+				Args       string    `json:"args"`
+				Results    string    `json:"results"`
+				Published  time.Time `json:"published"`
+				Start      time.Time `json:"start"`
+				End        time.Time `json:"end"`
+				Provenance []hop     `json:"provenance,omitempty"`
+			}
+			items := []item{}
+			for _, entry := range bus.Traces(r.URL.Query().Get("topic")) {
+				it := item{Topic: entry.Topic, Handler: entry.Handler, Args: entry.Args, Results: entry.Results,
+					Published: entry.Published, Start: entry.Start, End: entry.End}
+				for _, h := range entry.Provenance {
+					it.Provenance = append(it.Provenance, hop{h.Topic, h.Time, h.Via})
+				}
+				items = append(items, it)
+			}
+			json.NewEncoder(w).Encode(items)
+		case http.MethodPost:
+			topic := r.FormValue("topic")
+			capacity, err := strconv.Atoi(r.FormValue("capacity"))
+			if topic == "" || err != nil || capacity <= 0 {
+				http.Error(w, "topic and a positive capacity are required", http.StatusBadRequest)
+				return
+			}
+			snapshotSize := 0
+			if value := r.FormValue("snapshot_size"); value != "" {
+				if snapshotSize, err = strconv.Atoi(value); err != nil {
+					http.Error(w, "snapshot_size must be a number", http.StatusBadRequest)
+					return
+				}
+			}
+			bus.EnableTrace(topic, capacity, snapshotSize)
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodDelete:
+			topic := r.FormValue("topic")
+			if topic == "" {
+				http.Error(w, "topic is required", http.StatusBadRequest)
+				return
+			}
+			bus.DisableTrace(topic)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func (bus *Bus) isTraced(topic string) bool {
+	bus.tracer.Lock()
+	defer bus.tracer.Unlock()
+	_, ok := bus.tracer.buffers[topic]
+	return ok
+}
+
+func (bus *Bus) recordTrace(topic string, handler *eventHandler, args []interface{}, results []interface{}, provenance []Hop, published, start, end time.Time) {
+	bus.tracer.Lock()
+	buffer, ok := bus.tracer.buffers[topic]
+	if !ok {
+		bus.tracer.Unlock()
+		return
+	}
+	delta := -entrySize(buffer.entries[buffer.next])
+	buffer.entries[buffer.next] = TraceEntry{
+		Topic:      topic,
+		Handler:    handlerName(handler.callBack.Pointer()),
+		Args:       snapshot(args, buffer.snapshotSize),
+		Results:    snapshot(results, buffer.snapshotSize),
+		Published:  published,
+		Start:      start,
+		End:        end,
+		Provenance: provenance,
+	}
+	delta += entrySize(buffer.entries[buffer.next])
+	buffer.bytes += delta
+	buffer.next++
+	if buffer.next == len(buffer.entries) {
+		buffer.next = 0
+		buffer.full = true
+	}
+	bus.tracer.Unlock()
+	bus.memory.retain(topic, delta)
+}
+
+// traceBytes returns the approximate memory held by the trace buffer of a topic
+func (bus *Bus) traceBytes(topic string) int64 {
+	bus.tracer.Lock()
+	defer bus.tracer.Unlock()
+	if buffer, ok := bus.tracer.buffers[topic]; ok {
+		return buffer.bytes
+	}
+	return 0
+}
+
+// entrySize estimates the memory held by the strings of a trace entry
+func entrySize(entry TraceEntry) int64 {
+	size := len(entry.Topic) + len(entry.Handler) + len(entry.Args) + len(entry.Results)
+	for _, hop := range entry.Provenance {
+		size += len(hop.Topic) + len(hop.Via)
+	}
+	return int64(size)
+}
+
+// snapshot serializes values and truncates the result to at most size bytes
+func snapshot(values []interface{}, size int) string {
+	w := &boundedWriter{limit: size}
+	writeSnapshot(w, reflect.ValueOf(values))
+	return w.String()
+}
+
+// writeSnapshot formats v like %+v, expanding slices and arrays one element at
+// a time so that formatting a large payload stops once w is full
+func writeSnapshot(w *boundedWriter, v reflect.Value) {
+	if w.full() {
+		return
+	}
+	switch {
+	case v.Kind() == reflect.Interface && v.IsNil():
+		io.WriteString(w, "<nil>")
+	case v.Kind() == reflect.Interface:
+		writeSnapshot(w, v.Elem())
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && !formatted(v):
+		io.WriteString(w, "[")
+		for i := 0; i < v.Len() && !w.full(); i++ {
+			if i > 0 {
+				io.WriteString(w, " ")
+			}
+			writeSnapshot(w, v.Index(i))
+		}
+		io.WriteString(w, "]")
+	case v.Kind() == reflect.String && !formatted(v):
+		io.WriteString(w, v.String())
+	case v.CanInterface():
+		fmt.Fprintf(w, "%+v", v.Interface())
+	default:
+		fmt.Fprintf(w, "%+v", v)
+	}
+}
+
+// formatted reports whether fmt formats v with a method of its type rather
+// than by its kind
+func formatted(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	switch v.Interface().(type) {
+	case fmt.Formatter, fmt.Stringer, error:
+		return true
+	}
+	return false
+}
+
+// boundedWriter - keeps the first bytes written to it, a rune past limit at
+// most
+type boundedWriter struct {
+	data  []byte
+	limit int
+}
+
+func (w *boundedWriter) Write(p []byte) (int, error) {
+	room := max(w.limit+utf8.UTFMax-len(w.data), 0)
+	w.data = append(w.data, p[:min(len(p), room)]...)
+	return len(p), nil
+}
+
+// full reports whether more than limit bytes were written
+func (w *boundedWriter) full() bool {
+	return len(w.data) > w.limit
+}
+
+// String returns the data written, cut on a rune boundary and followed by
+// "..." if longer than the limit
+func (w *boundedWriter) String() string {
+	if !w.full() {
+		return string(w.data)
+	}
+	cut := w.limit
+	for cut > 0 && !utf8.RuneStart(w.data[cut]) {
+		cut--
+	}
+	return string(w.data[:cut]) + "..."
+}
+
+// handlerName returns the name of the function at pc
+func handlerName(pc uintptr) string {
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		return fn.Name()
+	}
+	return fmt.Sprintf("%#x", pc)
+}
@@ -0,0 +1,124 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTraces(t *testing.T) {
+	bus := New()
+	bus.Subscribe("topic", func(a int, s string) error {
+		if a > 1 {
+			return errors.New("too big")
+		}
+		return nil
+	})
+	bus.Publish("topic", 0, "untraced")
+	if bus.Traces("topic") != nil {
+		t.Fail()
+	}
+
+	bus.EnableTrace("topic", 2, 16)
+	bus.Publish("topic", 1, "first")
+	bus.Publish("topic", 2, "second")
+	bus.Publish("topic", 3, strings.Repeat("x", 100))
+
+	traces := bus.Traces("topic")
+	if len(traces) != 2 {
+		t.Fatal(traces)
+	}
+	if traces[0].Args != "[2 second]" || traces[0].Results != "[too big]" {
+		t.Log(traces[0])
+		t.Fail()
+	}
+	if len(traces[1].Args) != 16+len("...") || !strings.HasSuffix(traces[1].Args, "...") {
+		t.Log(traces[1].Args)
+		t.Fail()
+	}
+	if !strings.Contains(traces[1].Handler, "TestTraces") || traces[1].End.Before(traces[1].Start) {
+		t.Log(traces[1])
+		t.Fail()
+	}
+
+	bus.DisableTrace("topic")
+	if bus.Traces("topic") != nil {
+		t.Fail()
+	}
+}
+
+// countingStringer counts the times it is formatted
+type countingStringer struct{ calls *int }
+
+func (s countingStringer) String() string {
+	*s.calls++
+	return "item"
+}
+
+func TestTraceSnapshotBounded(t *testing.T) {
+	if s := snapshot([]interface{}{"ééééé"}, 4); s != "[é..." || !utf8.ValidString(s) {
+		t.Fatal(s)
+	}
+	calls := 0
+	items := make([]countingStringer, 1000)
+	for i := range items {
+		items[i] = countingStringer{&calls}
+	}
+	if s := snapshot([]interface{}{items}, 16); s != "[[item item item..." {
+		t.Fatal(s)
+	}
+	if calls > 4 {
+		t.Fatalf("%d items formatted for a 16 bytes snapshot", calls)
+	}
+	var err error = errors.New("failed")
+	if s := snapshot([]interface{}{nil, err, []byte("ab"), [2]string{"c", "d"}}, 64); s != fmt.Sprintf("%+v", []interface{}{nil, err, []byte("ab"), [2]string{"c", "d"}}) {
+		t.Fatal(s)
+	}
+}
+
+func TestTraceHandler(t *testing.T) {
+	bus := New()
+	bus.Subscribe("topic", func(s string) {})
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		bus.TraceHandler().ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+		return recorder
+	}
+
+	if recorder := serve("POST", "/traces?topic=topic"); recorder.Code != 400 {
+		t.Fatal(recorder.Code)
+	}
+	if recorder := serve("POST", "/traces?topic=topic&capacity=10"); recorder.Code != 204 {
+		t.Fatal(recorder.Code)
+	}
+	bus.Publish("topic", "hello")
+
+	var topics []string
+	if err := json.NewDecoder(serve("GET", "/traces").Body).Decode(&topics); err != nil {
+		t.Fatal(err)
+	}
+	if len(topics) != 1 || topics[0] != "topic" {
+		t.Fatal(topics)
+	}
+	var entries []struct {
+		Topic string `json:"topic"`
+		Args  string `json:"args"`
+	}
+	if err := json.NewDecoder(serve("GET", "/traces?topic=topic").Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Topic != "topic" || !strings.Contains(entries[0].Args, "hello") {
+		t.Fatal(entries)
+	}
+
+	if recorder := serve("DELETE", "/traces?topic=topic"); recorder.Code != 204 {
+		t.Fatal(recorder.Code)
+	}
+	if bus.Traces("topic") != nil {
+		t.Fatal("trace still enabled")
+	}
+}
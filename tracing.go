@@ -0,0 +1,53 @@
+package eventbus
+
+import (
+	"context"
+)
+
+// Span - span started by a Tracer, ended with the error of the operation it
+// covers, if any
+type Span interface {
+	End(err error)
+}
+
+// Tracer - starts the spans of publishes and handler executions, see
+// WithTracer. The eventbusotel sub-package implements it with OpenTelemetry.
+type Tracer interface {
+	// StartPublish starts the span of a publish on topic, continuing the
+	// trace of ctx if any, and returns ctx carrying it
+	StartPublish(ctx context.Context, topic string) (context.Context, Span)
+	// StartHandler starts the span of a handler execution, synchronous or
+	// async, as a child of the publish span carried by ctx
+	StartHandler(ctx context.Context, topic, handler string) (context.Context, Span)
+	// Inject writes the trace context carried by ctx into headers
+	Inject(ctx context.Context, headers map[string]string)
+	// Extract returns ctx carrying the trace context read from headers
+	Extract(ctx context.Context, headers map[string]string) context.Context
+}
+
+// WithTracer traces every Publish and PublishCtx with a span continuing the
+// trace of the publish context, and every handler execution with a child
+// span; handlers taking a context.Context receive the context of their span.
+// The trace context propagates through the headers of the Event envelope:
+// PublishEvent continues the trace found in ev.Headers. Without a Tracer,
+// tracing costs nothing.
+func WithTracer(tracer Tracer) Option {
+	return func(bus *Bus) {
+		bus.spans = tracer
+	}
+}
+
+// startHandlerSpan starts the span of a handler execution, returning the
+// context of the span and the arguments of the handler passing it if the
+// handler takes a context
+func (bus *Bus) startHandlerSpan(ctx context.Context, handler *eventHandler, topic string, args []interface{}) (context.Context, Span, []interface{}) {
+	argCtx, passed := contextArg(handler, args)
+	if passed {
+		ctx = argCtx
+	}
+	ctx, span := bus.spans.StartHandler(ctx, topic, handlerName(handler.callBack.Pointer()))
+	if passed {
+		args = append([]interface{}{ctx}, args[1:]...)
+	}
+	return ctx, span, args
+}
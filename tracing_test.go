@@ -0,0 +1,90 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+type spanKey struct{}
+
+type recordingTracer struct {
+	spans []string
+	sync.Mutex
+}
+
+type recordingSpan struct {
+	tracer *recordingTracer
+	name   string
+}
+
+func (s recordingSpan) End(err error) {
+	s.tracer.Lock()
+	defer s.tracer.Unlock()
+	if err != nil {
+		s.tracer.spans = append(s.tracer.spans, s.name+" failed")
+		return
+	}
+	s.tracer.spans = append(s.tracer.spans, s.name)
+}
+
+func (tracer *recordingTracer) start(ctx context.Context, name string) (context.Context, Span) {
+	if parent, ok := ctx.Value(spanKey{}).(string); ok {
+		name = parent + "/" + name
+	}
+	return context.WithValue(ctx, spanKey{}, name), recordingSpan{tracer, name}
+}
+
+func (tracer *recordingTracer) StartPublish(ctx context.Context, topic string) (context.Context, Span) {
+	return tracer.start(ctx, "publish "+topic)
+}
+
+func (tracer *recordingTracer) StartHandler(ctx context.Context, topic, handler string) (context.Context, Span) {
+	return tracer.start(ctx, "handle "+topic)
+}
+
+func (tracer *recordingTracer) Inject(ctx context.Context, headers map[string]string) {
+	if name, ok := ctx.Value(spanKey{}).(string); ok {
+		headers["span"] = name
+	}
+}
+
+func (tracer *recordingTracer) Extract(ctx context.Context, headers map[string]string) context.Context {
+	if name, ok := headers["span"]; ok {
+		return context.WithValue(ctx, spanKey{}, name)
+	}
+	return ctx
+}
+
+func TestWithTracer(t *testing.T) {
+	tracer := &recordingTracer{}
+	bus := New(WithTracer(tracer))
+	var handlerSpan string
+	bus.Subscribe("orders", func(ctx context.Context, n int) error {
+		handlerSpan, _ = ctx.Value(spanKey{}).(string)
+		return errors.New("failed")
+	})
+	bus.SubscribeAsync("orders", func(n int) {}, false)
+	var forwarded Event
+	bus.Subscribe("forward", func(ev Event) { forwarded = ev })
+
+	bus.Publish("orders", 1)
+	bus.WaitAsync()
+	if handlerSpan != "publish orders/handle orders" {
+		t.Fatal(handlerSpan)
+	}
+	tracer.Lock()
+	slices.Sort(tracer.spans) // the async handler may end before the publish
+	if fmt.Sprint(tracer.spans) != "[publish orders publish orders/handle orders publish orders/handle orders failed]" {
+		t.Fatal(tracer.spans)
+	}
+	tracer.Unlock()
+
+	bus.PublishEvent(Event{Topic: "forward", Headers: map[string]string{"span": "remote"}})
+	if forwarded.Headers["span"] != "remote/publish forward" {
+		t.Fatal(forwarded.Headers)
+	}
+}
@@ -0,0 +1,32 @@
+package eventbus
+
+// Topic - name of a topic whose events are values of type T. Subscribing and
+// publishing through SubscribeTyped and PublishTyped is checked by the compiler,
+// so handlers and publishers can't disagree on the argument types.
+//
+//	var OrderCreated = eventbus.Topic[Order]("orders:created")
+type Topic[T any] string
+
+// Name returns the topic name, e.g. for Unsubscribe
+func (topic Topic[T]) Name() string {
+	return string(topic)
+}
+
+// SubscribeTyped subscribes fn to a typed topic.
+// Returns error if the topic breaks the topic rules.
+func SubscribeTyped[T any](bus *Bus, topic Topic[T], fn func(T)) error {
+	return bus.Subscribe(string(topic), fn)
+}
+
+// SubscribeTypedAsync subscribes fn to a typed topic with an asynchronous callback,
+// see SubscribeAsync.
+// Returns error if the topic breaks the topic rules.
+func SubscribeTypedAsync[T any](bus *Bus, topic Topic[T], fn func(T), transactional bool) error {
+	return bus.SubscribeAsync(string(topic), fn, transactional)
+}
+
+// PublishTyped publishes event on a typed topic.
+// Returns error, without publishing, if the topic breaks the topic rules.
+func PublishTyped[T any](bus *Bus, topic Topic[T], event T) error {
+	return bus.Publish(string(topic), event)
+}
@@ -0,0 +1,60 @@
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TypedPublisher - handle that can only publish to the topic it is bound to,
+// with arguments of the declared types
+type TypedPublisher interface {
+	Topic() string
+	Publish(args ...interface{}) error
+}
+
+type typedPublisher struct {
+	bus      *Bus
+	topic    string
+	argTypes []reflect.Type
+}
+
+// PublisherFor returns a TypedPublisher bound to topic, accepting exactly
+// len(argTypes) arguments assignable to argTypes. Inject it instead of the bus
+// into components that must not publish to arbitrary topics.
+func (bus *Bus) PublisherFor(topic string, argTypes ...reflect.Type) TypedPublisher {
+	return &typedPublisher{bus, topic, argTypes}
+}
+
+func (publisher *typedPublisher) Topic() string {
+	return publisher.topic
+}
+
+// Publish validates the arguments and publishes them on the bound topic.
+// Returns error, without publishing, if the arguments don't match the declared types
+// or the topic breaks the topic rules.
+func (publisher *typedPublisher) Publish(args ...interface{}) error {
+	if len(args) != len(publisher.argTypes) {
+		return fmt.Errorf("topic %s expects %d arguments, got %d", publisher.topic, len(publisher.argTypes), len(args))
+	}
+	for i, arg := range args {
+		expected := publisher.argTypes[i]
+		if arg == nil {
+			if !nillable(expected) {
+				return fmt.Errorf("topic %s argument %d: nil is not a valid %s", publisher.topic, i, expected)
+			}
+			continue
+		}
+		if actual := reflect.TypeOf(arg); !actual.AssignableTo(expected) {
+			return fmt.Errorf("topic %s argument %d: %s is not assignable to %s", publisher.topic, i, actual, expected)
+		}
+	}
+	return publisher.bus.Publish(publisher.topic, args...)
+}
+
+func nillable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		return true
+	}
+	return false
+}
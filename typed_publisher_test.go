@@ -0,0 +1,39 @@
+package eventbus
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPublisherFor(t *testing.T) {
+	bus := New()
+	var received []interface{}
+	bus.Subscribe("orders:created", func(id int, err error) {
+		received = append(received, id, err)
+	})
+
+	publisher := bus.PublisherFor("orders:created", reflect.TypeOf(0), reflect.TypeOf((*error)(nil)).Elem())
+	if publisher.Topic() != "orders:created" {
+		t.Fail()
+	}
+	if publisher.Publish(1, nil) != nil {
+		t.Fail()
+	}
+	if publisher.Publish(2, errors.New("late")) != nil {
+		t.Fail()
+	}
+	if publisher.Publish("3", nil) == nil {
+		t.Fail()
+	}
+	if publisher.Publish(4) == nil {
+		t.Fail()
+	}
+	if bus.PublisherFor("topic", reflect.TypeOf(0)).Publish(nil) == nil {
+		t.Fail()
+	}
+	if len(received) != 4 || received[2] != 2 {
+		t.Log(received)
+		t.Fail()
+	}
+}
@@ -0,0 +1,45 @@
+package eventbus
+
+import (
+	"testing"
+)
+
+type typedOrder struct {
+	ID    int
+	Total float64
+}
+
+func TestTypedTopic(t *testing.T) {
+	bus := New()
+	orders := Topic[typedOrder]("orders:created")
+	var received []typedOrder
+	handler := func(order typedOrder) {
+		received = append(received, order)
+	}
+	if SubscribeTyped(bus, orders, handler) != nil {
+		t.Fail()
+	}
+	if PublishTyped(bus, orders, typedOrder{1, 9.5}) != nil {
+		t.Fail()
+	}
+	if len(received) != 1 || received[0].ID != 1 {
+		t.Fatal(received)
+	}
+	if bus.Unsubscribe(orders.Name(), handler) != nil || bus.HasCallback("orders:created") {
+		t.Fail()
+	}
+}
+
+func TestTypedTopicInterface(t *testing.T) {
+	bus := New()
+	errs := Topic[error]("errors")
+	received := make(chan error, 1)
+	SubscribeTypedAsync(bus, errs, func(err error) {
+		received <- err
+	}, false)
+	PublishTyped(bus, errs, nil)
+	bus.WaitAsync()
+	if err := <-received; err != nil {
+		t.Fail()
+	}
+}
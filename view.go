@@ -0,0 +1,175 @@
+package eventbus
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+)
+
+// ViewLogPrefix - prefix of the store topics logging the changes of
+// persistent views, see PersistentView
+const ViewLogPrefix = "_view."
+
+// View - in-memory key-value state folded from the events of a topic
+type View struct {
+	keyFn   func(args ...interface{}) string
+	valueFn func(args ...interface{}) interface{}
+	data    map[string]interface{}
+	hooks   []func(key string, old, new interface{})
+	store   Store  // logs the changes of persistent views, nil otherwise
+	log     string // store topic of the changes
+	sync.RWMutex
+}
+
+// viewChange - record of the change log of a persistent view: a key set to
+// Value, deleted if nil, or with Reset, the whole state replaced by Snapshot
+type viewChange struct {
+	Key      string
+	Value    interface{}
+	Reset    bool
+	Snapshot map[string]interface{}
+}
+
+// View subscribes a materialized view to a topic. Every event stores
+// valueFn(args) under keyFn(args); a nil value deletes the key.
+// Returns error if the subscription fails.
+func (bus *Bus) View(topic string, keyFn func(args ...interface{}) string, valueFn func(args ...interface{}) interface{}) (*View, error) {
+	view := &View{keyFn: keyFn, valueFn: valueFn, data: make(map[string]interface{})}
+	if err := bus.Subscribe(topic, view.apply); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// PersistentView works like View, but logs the changes of the view to the
+// store of the bus (see WithStore) under ViewLogPrefix+name, and starts from
+// the state logged by the previous views of that name, e.g. before a restart.
+// A change that can't be logged isn't applied, and the handler of the view
+// returns the error. Values are gob encoded: their types other than the basic
+// ones must be registered with gob.Register. Compact bounds the log.
+// Returns error if the bus has no store, the log can't be read or decoded, or
+// the subscription fails.
+func (bus *Bus) PersistentView(name, topic string, keyFn func(args ...interface{}) string, valueFn func(args ...interface{}) interface{}) (*View, error) {
+	if bus.store == nil {
+		return nil, errors.New("persistent views require a store, see WithStore")
+	}
+	view := &View{keyFn: keyFn, valueFn: valueFn, data: make(map[string]interface{}),
+		store: bus.store, log: ViewLogPrefix + name}
+	err := view.store.ReadFrom(view.log, 0, func(record StoreRecord) error {
+		change := viewChange{}
+		if err := gob.NewDecoder(bytes.NewReader(record.Data)).Decode(&change); err != nil {
+			return fmt.Errorf("change %d of view %s: %w", record.Offset, name, err)
+		}
+		switch {
+		case change.Reset:
+			clear(view.data)
+			maps.Copy(view.data, change.Snapshot)
+		case change.Value == nil:
+			delete(view.data, change.Key)
+		default:
+			view.data[change.Key] = change.Value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := bus.Subscribe(topic, view.apply); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// Compact rewrites the change log of a persistent view as its current state,
+// dropping the changes it overrides.
+// Returns error if the view isn't persistent or the log can't be written.
+func (view *View) Compact() error {
+	if view.store == nil {
+		return errors.New("view is not persistent")
+	}
+	view.Lock()
+	defer view.Unlock()
+	start, err := view.append(viewChange{Reset: true, Snapshot: view.data})
+	if err != nil {
+		return err
+	}
+	return view.store.Truncate(view.log, start)
+}
+
+// append appends a change to the log of a persistent view, returning its offset
+func (view *View) append(change viewChange) (uint64, error) {
+	data := bytes.Buffer{}
+	if err := gob.NewEncoder(&data).Encode(change); err != nil {
+		return 0, fmt.Errorf("can't log change of key %s: %w", change.Key, err)
+	}
+	offset, err := view.store.Append(view.log, data.Bytes())
+	if err != nil {
+		return 0, fmt.Errorf("can't log change of key %s: %w", change.Key, err)
+	}
+	return offset, nil
+}
+
+// apply applies an event to the view, logging the change first if the view is
+// persistent
+func (view *View) apply(args ...interface{}) error {
+	key, value := view.keyFn(args...), view.valueFn(args...)
+	view.Lock()
+	if view.store != nil {
+		if _, err := view.append(viewChange{Key: key, Value: value}); err != nil {
+			view.Unlock()
+			return err
+		}
+	}
+	old, existed := view.data[key]
+	if value == nil {
+		delete(view.data, key)
+	} else {
+		view.data[key] = value
+	}
+	hooks := view.hooks
+	view.Unlock()
+	if !existed && value == nil {
+		return nil
+	}
+	for _, hook := range hooks {
+		hook(key, old, value)
+	}
+	return nil
+}
+
+// Get returns the current value of a key
+func (view *View) Get(key string) (interface{}, bool) {
+	view.RLock()
+	defer view.RUnlock()
+	value, ok := view.data[key]
+	return value, ok
+}
+
+// Len returns the number of keys in the view
+func (view *View) Len() int {
+	view.RLock()
+	defer view.RUnlock()
+	return len(view.data)
+}
+
+// Snapshot returns a copy of the current state
+func (view *View) Snapshot() map[string]interface{} {
+	view.RLock()
+	defer view.RUnlock()
+	snapshot := make(map[string]interface{}, len(view.data))
+	for key, value := range view.data {
+		snapshot[key] = value
+	}
+	return snapshot
+}
+
+// OnChange registers a hook called after every change with the previous and
+// new value of the key (nil when the key didn't exist or was deleted)
+func (view *View) OnChange(hook func(key string, old, new interface{})) {
+	view.Lock()
+	defer view.Unlock()
+	view.hooks = append(view.hooks, hook)
+}
@@ -0,0 +1,104 @@
+package eventbus
+
+import (
+	"testing"
+)
+
+func TestView(t *testing.T) {
+	bus := New()
+	view, err := bus.View("stock:changed", func(args ...interface{}) string {
+		return args[0].(string)
+	}, func(args ...interface{}) interface{} {
+		if args[1].(int) == 0 {
+			return nil
+		}
+		return args[1]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := 0
+	view.OnChange(func(key string, old, new interface{}) {
+		changes++
+		if key == "apple" && changes == 2 && (old != 3 || new != 5) {
+			t.Fail()
+		}
+	})
+
+	bus.Publish("stock:changed", "apple", 3)
+	bus.Publish("stock:changed", "apple", 5)
+	bus.Publish("stock:changed", "pear", 1)
+	bus.Publish("stock:changed", "pear", 0)
+	bus.Publish("stock:changed", "plum", 0)
+
+	if value, ok := view.Get("apple"); !ok || value != 5 {
+		t.Fail()
+	}
+	if _, ok := view.Get("pear"); ok {
+		t.Fail()
+	}
+	snapshot := view.Snapshot()
+	snapshot["apple"] = 0
+	if view.Len() != 1 || changes != 4 {
+		t.Fail()
+	}
+	if value, _ := view.Get("apple"); value != 5 {
+		t.Fail()
+	}
+}
+
+func TestPersistentView(t *testing.T) {
+	dir := t.TempDir()
+	key := func(args ...interface{}) string { return args[0].(string) }
+	value := func(args ...interface{}) interface{} {
+		if args[1].(int) == 0 {
+			return nil
+		}
+		return args[1]
+	}
+	if _, err := New().PersistentView("stock", "stock:changed", key, value); err == nil {
+		t.Fatal("persistent view without a store")
+	}
+
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus := New(WithStore(store))
+	view, err := bus.PersistentView("stock", "stock:changed", key, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.Publish("stock:changed", "apple", 3)
+	bus.Publish("stock:changed", "pear", 1)
+	bus.Publish("stock:changed", "apple", 5)
+	bus.Publish("stock:changed", "pear", 0)
+
+	restored, err := New(WithStore(store)).PersistentView("stock", "stock:changed", key, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if apple, _ := restored.Get("apple"); restored.Len() != 1 || apple != 5 {
+		t.Fatal(restored.Snapshot())
+	}
+
+	if err := view.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	records := 0
+	store.ReadFrom(ViewLogPrefix+"stock", 0, func(StoreRecord) error {
+		records++
+		return nil
+	})
+	if records != 1 {
+		t.Fatal(records)
+	}
+	bus.Publish("stock:changed", "plum", 2)
+	restored, err = New(WithStore(store)).PersistentView("stock", "stock:changed", key, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plum, _ := restored.Get("plum"); restored.Len() != 2 || plum != 2 {
+		t.Fatal(restored.Snapshot())
+	}
+}
@@ -0,0 +1,162 @@
+package eventbus
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TopicWatchdog - topic on which the watchdog publishes StuckDelivery reports
+const TopicWatchdog = "bus:watchdog"
+
+// StuckDelivery - async delivery running for longer than the watchdog threshold
+type StuckDelivery struct {
+	Topic   string
+	Handler string
+	Running time.Duration // time spent in the handler when detected
+	Stack   string        // stack of the goroutine running the handler
+}
+
+// watchdog tracks running async deliveries while enabled
+type watchdog struct {
+	threshold time.Duration
+	running   map[*runningDelivery]struct{}
+	sync.Mutex
+}
+
+type runningDelivery struct {
+	topic     string
+	handler   *eventHandler
+	goroutine int
+	started   time.Time
+	reported  bool
+}
+
+// StartWatchdog reports every async delivery still running after threshold
+// with a StuckDelivery event on TopicWatchdog, including the stack of the
+// goroutine running it, to find the handlers WaitAsync is stuck on. Each
+// delivery is reported once. The returned function stops the watchdog and is
+// safe to call more than once. A non-positive threshold disables the watchdog.
+func (bus *Bus) StartWatchdog(threshold time.Duration) (stop func()) {
+	if threshold <= 0 {
+		return func() {}
+	}
+	dog := &bus.watchdog
+	dog.Lock()
+	dog.threshold = threshold
+	dog.running = make(map[*runningDelivery]struct{})
+	dog.Unlock()
+
+	done := make(chan struct{})
+	once := sync.Once{}
+	go func() {
+		ticker := time.NewTicker(threshold / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				bus.checkWatchdog()
+			}
+		}
+	}()
+	return bus.track(func() {
+		once.Do(func() {
+			close(done)
+			dog.Lock()
+			dog.threshold, dog.running = 0, nil
+			dog.Unlock()
+		})
+	})
+}
+
+// watch registers an async delivery with the watchdog when it is enabled,
+// the returned function unregisters it
+func (bus *Bus) watch(topic string, handler *eventHandler) (done func()) {
+	dog := &bus.watchdog
+	dog.Lock()
+	defer dog.Unlock()
+	if dog.threshold <= 0 {
+		return func() {}
+	}
+	delivery := &runningDelivery{topic: topic, handler: handler, goroutine: goroutineID(), started: time.Now()}
+	dog.running[delivery] = struct{}{}
+	running := dog.running
+	return func() {
+		dog.Lock()
+		defer dog.Unlock()
+		delete(running, delivery)
+	}
+}
+
+// checkWatchdog reports the deliveries that exceeded the threshold since the last check
+func (bus *Bus) checkWatchdog() {
+	dog := &bus.watchdog
+	now := time.Now()
+	dog.Lock()
+	stuck := make([]*runningDelivery, 0)
+	for delivery := range dog.running {
+		if !delivery.reported && now.Sub(delivery.started) >= dog.threshold {
+			delivery.reported = true
+			stuck = append(stuck, delivery)
+		}
+	}
+	dog.Unlock()
+	if len(stuck) == 0 {
+		return
+	}
+	stacks := goroutineStacks()
+	for _, delivery := range stuck {
+		bus.publishControl(TopicWatchdog, StuckDelivery{
+			Topic:   delivery.topic,
+			Handler: handlerName(delivery.handler.callBack.Pointer()),
+			Running: now.Sub(delivery.started),
+			Stack:   stacks[delivery.goroutine],
+		})
+	}
+}
+
+// goroutineID returns the id of the calling goroutine, parsed from its stack header
+func goroutineID() int {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	id, _ := parseGoroutineHeader(buf)
+	return id
+}
+
+// goroutineStacks returns the stacks of all goroutines by goroutine id
+func goroutineStacks() map[int]string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := make(map[int]string)
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if id, ok := parseGoroutineHeader(stack); ok {
+			stacks[id] = string(stack)
+		}
+	}
+	return stacks
+}
+
+// parseGoroutineHeader parses the id from a "goroutine 42 [running]:" stack header
+func parseGoroutineHeader(stack []byte) (int, bool) {
+	stack, ok := bytes.CutPrefix(stack, []byte("goroutine "))
+	if !ok {
+		return 0, false
+	}
+	end := bytes.IndexByte(stack, ' ')
+	if end < 0 {
+		return 0, false
+	}
+	id, err := strconv.Atoi(string(stack[:end]))
+	return id, err == nil
+}
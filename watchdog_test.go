@@ -0,0 +1,73 @@
+package eventbus
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func stuckHandler(release chan struct{}) {
+	<-release
+}
+
+func TestWatchdog(t *testing.T) {
+	bus := New()
+	lock := sync.Mutex{}
+	reports := make([]StuckDelivery, 0)
+	bus.Subscribe(TopicWatchdog, func(report StuckDelivery) {
+		lock.Lock()
+		defer lock.Unlock()
+		reports = append(reports, report)
+	})
+	bus.SubscribeAsync("topic", stuckHandler, false)
+	bus.SubscribeAsync("topic", func(chan struct{}) {}, false)
+	stop := bus.StartWatchdog(20 * time.Millisecond)
+	defer stop()
+
+	release := make(chan struct{})
+	bus.Publish("topic", release)
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	bus.WaitAsync()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports", len(reports))
+	}
+	report := reports[0]
+	if report.Topic != "topic" || !strings.Contains(report.Handler, "stuckHandler") || report.Running < 20*time.Millisecond {
+		t.Error(report)
+	}
+	if !strings.Contains(report.Stack, "stuckHandler") {
+		t.Error(report.Stack)
+	}
+}
+
+func TestWatchdogStop(t *testing.T) {
+	bus := New()
+	stop := bus.StartWatchdog(10 * time.Millisecond)
+	stop()
+	stop()
+	reported := false
+	bus.Subscribe(TopicWatchdog, func(StuckDelivery) { reported = true })
+	bus.SubscribeAsync("topic", func() { time.Sleep(50 * time.Millisecond) }, false)
+	bus.Publish("topic")
+	bus.WaitAsync()
+	if reported {
+		t.Fail()
+	}
+}
+
+func TestParseGoroutineHeader(t *testing.T) {
+	if id, ok := parseGoroutineHeader([]byte("goroutine 42 [running]:\nmain.main()")); !ok || id != 42 {
+		t.Fail()
+	}
+	if _, ok := parseGoroutineHeader([]byte("created by main.main")); ok {
+		t.Fail()
+	}
+	if goroutineID() <= 0 {
+		t.Fail()
+	}
+}
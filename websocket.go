@@ -0,0 +1,420 @@
+package eventbus
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultWebsocketBuffer - default number of events queued per WebSocket
+// connection
+const DefaultWebsocketBuffer = 256
+
+const (
+	websocketGUID       = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11" // RFC 6455 handshake key suffix
+	maxWebsocketMessage = 1 << 20
+	websocketWriteWait  = 10 * time.Second
+
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+// WebsocketOptions - configuration of WebsocketHandler
+type WebsocketOptions struct {
+	// Publish lets clients publish events
+	Publish bool
+	// Allow reports whether the client of a request may subscribe to, or
+	// publish on if publish is set, a topic; nil allows every topic but the
+	// bus: control topics, whose events are also left out of the patterns
+	// subscribed to
+	Allow func(r *http.Request, topic string, publish bool) bool
+	// CheckOrigin accepts the origin of a request; nil accepts requests
+	// without Origin header and those from the host they are sent to
+	CheckOrigin func(r *http.Request) bool
+	// Buffer is the number of events queued per connection,
+	// DefaultWebsocketBuffer if zero. A connection falling further behind is
+	// closed rather than slowing the publishers down.
+	Buffer int
+}
+
+// WebsocketMessage - JSON message exchanged with the clients of
+// WebsocketHandler. Clients send "subscribe", "unsubscribe" and "publish"
+// messages; the handler sends "event" messages and "error" messages for the
+// client messages it rejects.
+type WebsocketMessage struct {
+	Type    string            `json:"type"`
+	Topic   string            `json:"topic,omitempty"`
+	ID      string            `json:"id,omitempty"`
+	Time    time.Time         `json:"time,omitzero"`
+	Args    []interface{}     `json:"args,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// WebsocketHandler returns an http.Handler upgrading requests to WebSocket
+// connections, on which browser and other clients subscribe to topics
+// (possibly wildcard patterns) and receive their events as JSON, and
+// optionally publish. The topics listed in the topic query parameters are
+// subscribed to when the connection opens. Arguments published by clients
+// are decoded from JSON as interface{} values (float64, string, bool,
+// []interface{}, map[string]interface{}).
+func (bus *Bus) WebsocketHandler(opts *WebsocketOptions) http.Handler {
+	options := WebsocketOptions{}
+	if opts != nil {
+		options = *opts
+	}
+	if options.Buffer <= 0 {
+		options.Buffer = DefaultWebsocketBuffer
+	}
+	if options.CheckOrigin == nil {
+		options.CheckOrigin = sameOrigin
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !options.CheckOrigin(r) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		session := &websocketSession{
+			bus:           bus,
+			options:       options,
+			request:       r,
+			subscriptions: make(map[string]*Subscription),
+			outbox:        make(chan WebsocketMessage, options.Buffer),
+			done:          make(chan struct{}),
+		}
+		defer session.unsubscribe()
+		for _, topic := range r.URL.Query()["topic"] {
+			session.subscribe(topic) // before the handshake completes, so no event is missed
+		}
+		conn, err := upgradeWebsocket(w, r)
+		if err != nil {
+			return // the response is written by upgradeWebsocket
+		}
+		session.conn = conn
+		session.run()
+	})
+}
+
+// sameOrigin accepts requests without Origin header, or from the host they
+// are sent to
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(parsed.Host, r.Host)
+}
+
+// allowTopic reports whether allow lets the client of a request subscribe to,
+// or publish on if publish is set, a topic. Without allow, every topic but the
+// control topics is allowed.
+func allowTopic(allow func(r *http.Request, topic string, publish bool) bool, r *http.Request, topic string, publish bool) bool {
+	if allow == nil {
+		return !strings.HasPrefix(topic, controlPrefix)
+	}
+	return allow(r, topic, publish)
+}
+
+// upgradeWebsocket performs the opening handshake of a WebSocket connection
+func upgradeWebsocket(w http.ResponseWriter, r *http.Request) (*websocketConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet || key == "" ||
+		!headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "websocket handshake expected", http.StatusBadRequest)
+		return nil, errors.New("not a websocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusUpgradeRequired)
+		return nil, errors.New("unsupported websocket version")
+	}
+	netConn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, err
+	}
+	accept := sha1.Sum([]byte(key + websocketGUID))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(accept[:]))
+	if err := rw.Flush(); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	return &websocketConn{conn: netConn, reader: rw.Reader}, nil
+}
+
+// headerContains reports whether a comma separated header has a token, case
+// insensitively
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// websocketConn - WebSocket connection framing messages, RFC 6455. The
+// client side masks the frames it writes.
+type websocketConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	client bool
+	writes sync.Mutex
+}
+
+// readMessage reads the next data message, answering pings and reassembling
+// fragmented messages. Returns io.EOF once the peer closed the connection.
+func (conn *websocketConn) readMessage() ([]byte, error) {
+	var message []byte
+	for {
+		fin, opcode, payload, err := conn.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsClose:
+			conn.writeFrame(wsClose, payload[:min(2, len(payload))]) // echoes the status code
+			return nil, io.EOF
+		case wsPing:
+			if err := conn.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+		case wsPong:
+		case wsText, wsBinary, wsContinuation:
+			message = append(message, payload...)
+			if len(message) > maxWebsocketMessage {
+				return nil, errors.New("websocket message too large")
+			}
+			if fin {
+				return message, nil
+			}
+		default:
+			return nil, fmt.Errorf("unknown websocket opcode %d", opcode)
+		}
+	}
+}
+
+// readFrame reads a frame, unmasking its payload
+func (conn *websocketConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	header := make([]byte, 2, 8)
+	if _, err = io.ReadFull(conn.reader, header); err != nil {
+		return
+	}
+	fin, opcode = header[0]&0x80 != 0, header[0]&0x0f
+	masked := header[1]&0x80 != 0
+	if masked == conn.client {
+		return fin, opcode, nil, errors.New("websocket frame masked by the wrong side")
+	}
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		header = header[:2]
+		if _, err = io.ReadFull(conn.reader, header); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(header))
+	case 127:
+		header = header[:8]
+		if _, err = io.ReadFull(conn.reader, header); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(header)
+	}
+	if length > maxWebsocketMessage {
+		return fin, opcode, nil, errors.New("websocket message too large")
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(conn.reader, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(conn.reader, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeFrame writes a final frame
+func (conn *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	conn.writes.Lock()
+	defer conn.writes.Unlock()
+	frame := []byte{0x80 | opcode}
+	maskBit := byte(0)
+	if conn.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, maskBit|byte(n))
+	case n <= math.MaxUint16:
+		frame = binary.BigEndian.AppendUint16(append(frame, maskBit|126), uint16(n))
+	default:
+		frame = binary.BigEndian.AppendUint64(append(frame, maskBit|127), uint64(n))
+	}
+	if conn.client {
+		mask := make([]byte, 4)
+		rand.Read(mask)
+		frame = append(frame, mask...)
+		masked := make([]byte, len(payload))
+		for i := range payload {
+			masked[i] = payload[i] ^ mask[i%4]
+		}
+		payload = masked
+	}
+	conn.conn.SetWriteDeadline(time.Now().Add(websocketWriteWait))
+	_, err := conn.conn.Write(append(frame, payload...))
+	return err
+}
+
+// websocketSession - subscriptions of a WebSocket connection
+type websocketSession struct {
+	bus           *Bus
+	options       WebsocketOptions
+	request       *http.Request
+	conn          *websocketConn
+	subscriptions map[string]*Subscription // by topic, used by the serving goroutine only
+	outbox        chan WebsocketMessage
+	done          chan struct{}
+	once          sync.Once
+}
+
+// run serves the connection until the client goes away; the subscriptions
+// are left to the caller
+func (session *websocketSession) run() {
+	defer session.close()
+	go session.write()
+	for {
+		data, err := session.conn.readMessage()
+		if err != nil {
+			return
+		}
+		msg := WebsocketMessage{}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			session.fail("", err)
+			continue
+		}
+		switch msg.Type {
+		case "subscribe":
+			session.subscribe(msg.Topic)
+		case "unsubscribe":
+			if sub, ok := session.subscriptions[msg.Topic]; ok {
+				sub.Unsubscribe()
+				delete(session.subscriptions, msg.Topic)
+			}
+		case "publish":
+			session.publish(msg)
+		default:
+			session.fail(msg.Topic, fmt.Errorf("unknown message type %q", msg.Type))
+		}
+	}
+}
+
+// unsubscribe cancels the subscriptions of the connection
+func (session *websocketSession) unsubscribe() {
+	for topic, sub := range session.subscriptions {
+		sub.Unsubscribe()
+		delete(session.subscriptions, topic)
+	}
+}
+
+func (session *websocketSession) subscribe(topic string) {
+	if _, ok := session.subscriptions[topic]; ok {
+		return
+	}
+	if !allowTopic(session.options.Allow, session.request, topic, false) {
+		session.fail(topic, fmt.Errorf("subscribing to topic %s is not allowed", topic))
+		return
+	}
+	sub, err := session.bus.SubscribeHandle(topic, func(ev Event) {
+		if session.options.Allow == nil && strings.HasPrefix(ev.Topic, controlPrefix) {
+			return // control event matching a pattern
+		}
+		session.send(WebsocketMessage{Type: "event", Topic: ev.Topic, ID: ev.ID, Time: ev.Time, Args: ev.Args, Headers: ev.Headers})
+	})
+	if err != nil {
+		session.fail(topic, err)
+		return
+	}
+	session.subscriptions[topic] = sub
+}
+
+func (session *websocketSession) publish(msg WebsocketMessage) {
+	if !session.options.Publish || !allowTopic(session.options.Allow, session.request, msg.Topic, true) {
+		session.fail(msg.Topic, fmt.Errorf("publishing on topic %s is not allowed", msg.Topic))
+		return
+	}
+	err := session.bus.PublishEvent(Event{Topic: msg.Topic, ID: msg.ID, Args: msg.Args, Headers: msg.Headers})
+	if err != nil {
+		session.fail(msg.Topic, err)
+	}
+}
+
+// fail reports an error to the client
+func (session *websocketSession) fail(topic string, err error) {
+	session.send(WebsocketMessage{Type: "error", Topic: topic, Error: err.Error()})
+}
+
+// send queues a message, closing the connection if the client is too far
+// behind
+func (session *websocketSession) send(msg WebsocketMessage) {
+	select {
+	case session.outbox <- msg:
+	case <-session.done:
+	default:
+		session.close()
+	}
+}
+
+// write writes the queued messages until the connection is closed
+func (session *websocketSession) write() {
+	for {
+		select {
+		case msg := <-session.outbox:
+			data, err := json.Marshal(msg)
+			if err != nil {
+				data, _ = json.Marshal(WebsocketMessage{Type: "error", Topic: msg.Topic, Error: err.Error()})
+			}
+			if session.conn.writeFrame(wsText, data) != nil {
+				session.close()
+				return
+			}
+		case <-session.done:
+			return
+		}
+	}
+}
+
+func (session *websocketSession) close() {
+	session.once.Do(func() {
+		close(session.done)
+		session.conn.conn.Close()
+	})
+}
@@ -0,0 +1,133 @@
+package eventbus
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebsocketHandler(t *testing.T) {
+	bus := New()
+	server := httptest.NewServer(bus.WebsocketHandler(&WebsocketOptions{
+		Publish: true,
+		Allow: func(r *http.Request, topic string, publish bool) bool {
+			return !strings.HasPrefix(topic, "private")
+		},
+	}))
+	defer server.Close()
+	conn := dialWebsocket(t, server, "/?topic=orders")
+
+	bus.Publish("orders", "order 1", 2)
+	if msg := readWebsocket(t, conn); msg.Type != "event" || msg.Topic != "orders" || len(msg.Args) != 2 || msg.Args[0] != "order 1" || msg.Args[1] != 2.0 {
+		t.Fatal(msg)
+	}
+
+	writeWebsocket(t, conn, WebsocketMessage{Type: "subscribe", Topic: "private"})
+	if msg := readWebsocket(t, conn); msg.Type != "error" || msg.Topic != "private" {
+		t.Fatal(msg)
+	}
+	received := make(chan []interface{}, 1)
+	bus.Subscribe("payments", func(amount float64) { received <- []interface{}{amount} })
+	writeWebsocket(t, conn, WebsocketMessage{Type: "publish", Topic: "payments", Args: []interface{}{9.5}})
+	select {
+	case args := <-received:
+		if args[0] != 9.5 {
+			t.Fatal(args)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("publish not delivered")
+	}
+
+	writeWebsocket(t, conn, WebsocketMessage{Type: "unsubscribe", Topic: "orders"})
+	writeWebsocket(t, conn, WebsocketMessage{Type: "publish", Topic: TopicPanic})
+	if msg := readWebsocket(t, conn); msg.Type != "error" || msg.Topic != TopicPanic {
+		t.Fatal(msg) // reserved topic
+	}
+	if bus.HasCallback("orders") {
+		t.Fail()
+	}
+
+	conn.writeFrame(wsClose, nil)
+	if _, err := conn.readMessage(); err == nil {
+		t.Fail()
+	}
+	time.Sleep(10 * time.Millisecond) // the handler unsubscribes once the connection ends
+	if bus.HasCallback("orders") {
+		t.Fail()
+	}
+}
+
+func TestWebsocketRejected(t *testing.T) {
+	server := httptest.NewServer(New().WebsocketHandler(nil))
+	defer server.Close()
+	if resp, err := http.Get(server.URL); err != nil || resp.StatusCode != http.StatusBadRequest {
+		t.Fatal(resp, err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Origin", "https://evil.example")
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatal(resp, err)
+	}
+}
+
+func TestWebsocketControlTopics(t *testing.T) {
+	bus := New(WithSeparator(":"))
+	server := httptest.NewServer(bus.WebsocketHandler(nil))
+	defer server.Close()
+	conn := dialWebsocket(t, server, "/?topic=%23")
+
+	writeWebsocket(t, conn, WebsocketMessage{Type: "subscribe", Topic: TopicPanic})
+	if msg := readWebsocket(t, conn); msg.Type != "error" || msg.Topic != TopicPanic {
+		t.Fatal(msg)
+	}
+	bus.publishSystem(TopicPanic, "report")
+	bus.Publish("orders", 1)
+	if msg := readWebsocket(t, conn); msg.Type != "event" || msg.Topic != "orders" {
+		t.Fatal(msg) // the control event matching # is left out
+	}
+}
+
+// dialWebsocket opens a WebSocket connection to path on server
+func dialWebsocket(t *testing.T, server *httptest.Server, path string) *websocketConn {
+	netConn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { netConn.Close() })
+	req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	if err := req.Write(netConn); err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil || resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatal(resp, err)
+	}
+	return &websocketConn{conn: netConn, reader: reader, client: true}
+}
+
+func readWebsocket(t *testing.T, conn *websocketConn) WebsocketMessage {
+	conn.conn.SetReadDeadline(time.Now().Add(time.Second))
+	data, err := conn.readMessage()
+	msg := WebsocketMessage{}
+	if err != nil || json.Unmarshal(data, &msg) != nil {
+		t.Fatal(string(data), err)
+	}
+	return msg
+}
+
+func writeWebsocket(t *testing.T, conn *websocketConn, msg WebsocketMessage) {
+	data, _ := json.Marshal(msg)
+	if err := conn.writeFrame(wsText, data); err != nil {
+		t.Fatal(err)
+	}
+}